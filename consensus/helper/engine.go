@@ -62,6 +62,9 @@ func (eng *EngineImpl) ProcessTransactionMsg(msg *pb.Message, tx *pb.Transaction
 		if err != nil {
 			response = &pb.Response{Status: pb.Response_FAILURE,
 				Msg: []byte(fmt.Sprintf("Error:%s", err))}
+			if chaincodeError, ok := err.(*pb.ChaincodeError); ok {
+				response.ChaincodeError = chaincodeError
+			}
 		} else {
 			response = &pb.Response{Status: pb.Response_SUCCESS, Msg: result}
 		}
@@ -180,10 +180,19 @@ func (h *Helper) ExecTxs(id interface{}, txs []*pb.Transaction) ([]byte, error)
 	// cxt := context.WithValue(context.Background(), "security", h.coordinator.GetSecHelper())
 	// TODO return directly once underlying implementation no longer returns []error
 
-	succeededTxs, res, ccevents, txerrs, err := chaincode.ExecuteTransactions(context.Background(), chaincode.DefaultChain, txs)
+	succeededTxs, res, ccevents, readWriteSets, txerrs, err := chaincode.ExecuteTransactions(context.Background(), chaincode.DefaultChain, txs)
 
 	h.curBatch = append(h.curBatch, succeededTxs...) // TODO, remove after issue 579
 
+	txresults := transactionResults(txs, ccevents, readWriteSets, txerrs)
+	h.curBatchErrs = append(h.curBatchErrs, txresults...) // TODO, remove after issue 579
+
+	return res, err
+}
+
+// transactionResults builds the result of each executed transaction, keeping
+// the structure of a ChaincodeError a transaction failed with
+func transactionResults(txs []*pb.Transaction, ccevents []*pb.ChaincodeEvent, readWriteSets [][]*pb.ChaincodeReadWriteSet, txerrs []error) []*pb.TransactionResult {
 	//copy errs to result
 	txresults := make([]*pb.TransactionResult, len(txerrs))
 
@@ -191,14 +200,13 @@ func (h *Helper) ExecTxs(id interface{}, txs []*pb.Transaction) ([]byte, error)
 	for i, e := range txerrs {
 		//NOTE- it'll be nice if we can have error values. For now success == 0, error == 1
 		if txerrs[i] != nil {
-			txresults[i] = &pb.TransactionResult{Txid: txs[i].Txid, Error: e.Error(), ErrorCode: 1, ChaincodeEvent: ccevents[i]}
+			txresults[i] = &pb.TransactionResult{Txid: txs[i].Txid, Error: e.Error(), ErrorCode: 1, ChaincodeEvent: ccevents[i], ReadWriteSets: readWriteSets[i],
+				ChaincodeError: pb.ChaincodeErrorOf(e)}
 		} else {
-			txresults[i] = &pb.TransactionResult{Txid: txs[i].Txid, ChaincodeEvent: ccevents[i]}
+			txresults[i] = &pb.TransactionResult{Txid: txs[i].Txid, ChaincodeEvent: ccevents[i], ReadWriteSets: readWriteSets[i]}
 		}
 	}
-	h.curBatchErrs = append(h.curBatchErrs, txresults...) // TODO, remove after issue 579
-
-	return res, err
+	return txresults
 }
 
 // CommitTxBatch gets invoked when the current transaction-batch needs
@@ -16,8 +16,30 @@ limitations under the License.
 
 package helper
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
 
 func TestHelper(t *testing.T) {
 	t.Skip("Helper functions already tested in other consensus components")
 }
+
+func TestTransactionResults(t *testing.T) {
+	txs := []*pb.Transaction{{Txid: "succeeded"}, {Txid: "failed"}, {Txid: "rejected"}}
+	chaincodeError := &pb.ChaincodeError{Code: 402, Message: "insufficient balance", Details: "balance 5 is less than 10"}
+	txerrs := []error{nil, fmt.Errorf("Transaction or query returned with failure: boom"), chaincodeError}
+	results := transactionResults(txs, make([]*pb.ChaincodeEvent, 3), make([][]*pb.ChaincodeReadWriteSet, 3), txerrs)
+
+	if results[0].ErrorCode != 0 || results[0].ChaincodeError != nil {
+		t.Fatalf("Expected a successful result, got %v", results[0])
+	}
+	if results[1].ErrorCode != 1 || results[1].ChaincodeError != nil {
+		t.Fatalf("Expected a failed result without a chaincode error, got %v", results[1])
+	}
+	if results[2].Txid != "rejected" || results[2].Error != chaincodeError.Error() || results[2].ChaincodeError != chaincodeError {
+		t.Fatalf("Expected the structured chaincode error in the result, got %v", results[2])
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"golang.org/x/net/context"
 
 	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/hyperledger/fabric/core/ledger"
 	pb "github.com/hyperledger/fabric/protos"
 )
 
@@ -78,3 +79,19 @@ func (*ServerAdmin) StopServer(context.Context, *empty.Empty) (*pb.ServerStatus,
 	defer os.Exit(0)
 	return status, nil
 }
+
+// VerifyState recomputes the state hash from the world state of the running
+// peer and compares it with the state hash of the last block
+func (*ServerAdmin) VerifyState(context.Context, *empty.Empty) (*pb.StateVerification, error) {
+	ledgerPtr, err := ledger.GetLedger()
+	if err != nil {
+		return nil, err
+	}
+	verification, err := ledgerPtr.VerifyState()
+	if err != nil {
+		log.Errorf("Error verifying the state: %s", err)
+		return nil, err
+	}
+	log.Debugf("returning state verification: %s", verification)
+	return verification, nil
+}
@@ -0,0 +1,222 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+const maxCallDepthDefault int = 8
+
+// callFrame is a chaincode executing in a transaction. A queried chaincode
+// is read-only, and so is every chaincode it calls.
+type callFrame struct {
+	chaincode string
+	readOnly  bool
+}
+
+// txCalls tracks the chaincodes a transaction is executing, from the
+// chaincode the transaction called down to the one running now, and the
+// keys each chaincode read and wrote
+type txCalls struct {
+	stack []callFrame
+
+	// set by trackReadWriteSets: the read and write sets are then kept after
+	// the stack unwinds, until takeReadWriteSets
+	track      bool
+	chaincodes []string
+	reads      map[string]map[string]bool
+	writes     map[string]map[string]bool
+}
+
+// callStacks maps the ID of each executing transaction to its calls
+type callStacks struct {
+	sync.Mutex
+	txs map[string]*txCalls
+}
+
+func (tx *txCalls) callers() []string {
+	callers := make([]string, len(tx.stack))
+	for i, frame := range tx.stack {
+		callers[i] = frame.chaincode
+	}
+	return callers
+}
+
+func (tx *txCalls) resetReadWriteSets() {
+	tx.chaincodes = nil
+	tx.reads = make(map[string]map[string]bool)
+	tx.writes = make(map[string]map[string]bool)
+}
+
+func (tx *txCalls) record(sets map[string]map[string]bool, chaincode string, keys []string) {
+	if sets[chaincode] == nil {
+		sets[chaincode] = make(map[string]bool)
+	}
+	for _, key := range keys {
+		sets[chaincode][key] = true
+	}
+}
+
+// enterCall pushes chaincode on the call stack of transaction txid and
+// returns the chaincodes that called it, outermost first. It fails if
+// chaincode is already on the stack, if the stack would get deeper than
+// chaincode.maxcalldepth or if a read-only chaincode invokes chaincode
+// for a transaction.
+func (chaincodeSupport *ChaincodeSupport) enterCall(txid string, chaincode string, readOnly bool) ([]string, error) {
+	calls := &chaincodeSupport.calls
+	calls.Lock()
+	defer calls.Unlock()
+
+	if calls.txs == nil {
+		calls.txs = make(map[string]*txCalls)
+	}
+	tx := calls.txs[txid]
+	if tx == nil {
+		tx = &txCalls{}
+		calls.txs[txid] = tx
+	}
+	if len(tx.stack) == 0 {
+		// A new execution of the transaction, e.g. the second one of the
+		// determinism check, starts over
+		tx.resetReadWriteSets()
+	}
+
+	callers := tx.callers()
+	if len(callers) > 0 {
+		caller := callers[len(callers)-1]
+		callStack := strings.Join(append(callers, chaincode), " -> ")
+		for _, frame := range tx.stack {
+			if frame.chaincode == chaincode {
+				return nil, fmt.Errorf("Chaincode %s cannot be called by %s: it is already executing in transaction %s (%s)", chaincode, caller, txid, callStack)
+			}
+			if frame.readOnly && !readOnly {
+				return nil, fmt.Errorf("Chaincode %s cannot be invoked by %s: %s was queried and is read-only in transaction %s (%s)", chaincode, caller, frame.chaincode, txid, callStack)
+			}
+		}
+		if len(callers) >= chaincodeSupport.maxCallDepth {
+			return nil, fmt.Errorf("Chaincode %s cannot be called by %s: the call depth would exceed the maximum of %d in transaction %s (%s)", chaincode, caller, chaincodeSupport.maxCallDepth, txid, callStack)
+		}
+	}
+
+	tx.stack = append(tx.stack, callFrame{chaincode: chaincode, readOnly: readOnly})
+	if tx.reads[chaincode] == nil {
+		tx.chaincodes = append(tx.chaincodes, chaincode)
+		tx.record(tx.reads, chaincode, nil)
+		tx.record(tx.writes, chaincode, nil)
+	}
+	return callers, nil
+}
+
+// exitCall pops the chaincode on top of the call stack of transaction txid
+func (chaincodeSupport *ChaincodeSupport) exitCall(txid string) {
+	calls := &chaincodeSupport.calls
+	calls.Lock()
+	defer calls.Unlock()
+
+	tx := calls.txs[txid]
+	if tx == nil || len(tx.stack) == 0 {
+		return
+	}
+	tx.stack = tx.stack[:len(tx.stack)-1]
+	if len(tx.stack) == 0 && !tx.track {
+		delete(calls.txs, txid)
+	}
+}
+
+func (chaincodeSupport *ChaincodeSupport) recordKeys(txid string, chaincode string, keys []string, write bool) {
+	calls := &chaincodeSupport.calls
+	calls.Lock()
+	defer calls.Unlock()
+
+	tx := calls.txs[txid]
+	if tx == nil || !tx.track {
+		return
+	}
+	if write {
+		tx.record(tx.writes, chaincode, keys)
+	} else {
+		tx.record(tx.reads, chaincode, keys)
+	}
+}
+
+// recordReads adds keys to the read set of chaincode in transaction txid
+func (chaincodeSupport *ChaincodeSupport) recordReads(txid string, chaincode string, keys ...string) {
+	chaincodeSupport.recordKeys(txid, chaincode, keys, false)
+}
+
+// recordWrites adds keys to the write set of chaincode in transaction txid
+func (chaincodeSupport *ChaincodeSupport) recordWrites(txid string, chaincode string, keys ...string) {
+	chaincodeSupport.recordKeys(txid, chaincode, keys, true)
+}
+
+// trackReadWriteSets makes the next execution of transaction txid record
+// the keys each chaincode reads and writes, see takeReadWriteSets
+func (chaincodeSupport *ChaincodeSupport) trackReadWriteSets(txid string) {
+	calls := &chaincodeSupport.calls
+	calls.Lock()
+	defer calls.Unlock()
+
+	if calls.txs == nil {
+		calls.txs = make(map[string]*txCalls)
+	}
+	tx := &txCalls{track: true}
+	tx.resetReadWriteSets()
+	calls.txs[txid] = tx
+}
+
+// takeReadWriteSets stops tracking transaction txid and returns the read
+// and write sets of its chaincodes, in the order they were first called.
+// It returns nil if the transaction did not call other chaincodes.
+func (chaincodeSupport *ChaincodeSupport) takeReadWriteSets(txid string) []*pb.ChaincodeReadWriteSet {
+	calls := &chaincodeSupport.calls
+	calls.Lock()
+	defer calls.Unlock()
+
+	tx := calls.txs[txid]
+	if tx == nil {
+		return nil
+	}
+	delete(calls.txs, txid)
+	if len(tx.chaincodes) < 2 {
+		return nil
+	}
+
+	readWriteSets := make([]*pb.ChaincodeReadWriteSet, len(tx.chaincodes))
+	for i, chaincode := range tx.chaincodes {
+		readWriteSets[i] = &pb.ChaincodeReadWriteSet{
+			ChaincodeID: chaincode,
+			Reads:       sortedKeys(tx.reads[chaincode]),
+			Writes:      sortedKeys(tx.writes[chaincode]),
+		}
+	}
+	return readWriteSets
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,105 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCallStack(t *testing.T) {
+	chaincodeSupport := &ChaincodeSupport{maxCallDepth: 3}
+
+	callers, err := chaincodeSupport.enterCall("tx1", "a", false)
+	if err != nil || len(callers) != 0 {
+		t.Fatalf("Expected a to be called by the transaction, got %v, %s", callers, err)
+	}
+	callers, err = chaincodeSupport.enterCall("tx1", "b", false)
+	if err != nil || !reflect.DeepEqual(callers, []string{"a"}) {
+		t.Fatalf("Expected b to be called by a, got %v, %s", callers, err)
+	}
+
+	// a is already executing
+	if _, err = chaincodeSupport.enterCall("tx1", "a", false); err == nil || !strings.Contains(err.Error(), "a -> b -> a") {
+		t.Fatalf("Expected a cycle to be rejected, got %v", err)
+	}
+
+	// c is queried, so it cannot invoke d
+	if _, err = chaincodeSupport.enterCall("tx1", "c", true); err != nil {
+		t.Fatalf("Error querying c: %s", err)
+	}
+	if _, err = chaincodeSupport.enterCall("tx1", "d", false); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Fatalf("Expected an invocation by a read-only chaincode to be rejected, got %v", err)
+	}
+
+	// the stack holds a, b and c, the maximum
+	if _, err = chaincodeSupport.enterCall("tx1", "d", true); err == nil || !strings.Contains(err.Error(), "maximum of 3") {
+		t.Fatalf("Expected a call beyond the maximum depth to be rejected, got %v", err)
+	}
+
+	// other transactions have their own stacks
+	if _, err = chaincodeSupport.enterCall("tx2", "b", false); err != nil {
+		t.Fatalf("Error calling b in another transaction: %s", err)
+	}
+	chaincodeSupport.exitCall("tx2")
+
+	chaincodeSupport.exitCall("tx1")
+	chaincodeSupport.exitCall("tx1")
+	chaincodeSupport.exitCall("tx1")
+	if len(chaincodeSupport.calls.txs) != 0 {
+		t.Fatalf("Expected the call stacks to be gone once the transactions end, got %v", chaincodeSupport.calls.txs)
+	}
+}
+
+func TestCallStackReadWriteSets(t *testing.T) {
+	chaincodeSupport := &ChaincodeSupport{maxCallDepth: 3}
+
+	// a transaction calling a single chaincode has no read and write sets
+	chaincodeSupport.trackReadWriteSets("tx1")
+	chaincodeSupport.enterCall("tx1", "a", false)
+	chaincodeSupport.recordWrites("tx1", "a", "k1")
+	chaincodeSupport.exitCall("tx1")
+	if readWriteSets := chaincodeSupport.takeReadWriteSets("tx1"); readWriteSets != nil {
+		t.Fatalf("Expected no read and write sets, got %v", readWriteSets)
+	}
+
+	chaincodeSupport.trackReadWriteSets("tx2")
+	for i := 0; i < 2; i++ {
+		// the second execution, as done by the determinism check, starts over
+		chaincodeSupport.enterCall("tx2", "a", false)
+		chaincodeSupport.recordReads("tx2", "a", "k2", "k1")
+		chaincodeSupport.enterCall("tx2", "b", true)
+		chaincodeSupport.recordReads("tx2", "b", "k3")
+		chaincodeSupport.exitCall("tx2")
+		chaincodeSupport.recordWrites("tx2", "a", "k1")
+		chaincodeSupport.exitCall("tx2")
+	}
+	readWriteSets := chaincodeSupport.takeReadWriteSets("tx2")
+	if len(readWriteSets) != 2 {
+		t.Fatalf("Expected the read and write sets of a and b, got %v", readWriteSets)
+	}
+	if readWriteSets[0].ChaincodeID != "a" || !reflect.DeepEqual(readWriteSets[0].Reads, []string{"k1", "k2"}) || !reflect.DeepEqual(readWriteSets[0].Writes, []string{"k1"}) {
+		t.Fatalf("Unexpected read and write sets of a: %v", readWriteSets[0])
+	}
+	if readWriteSets[1].ChaincodeID != "b" || !reflect.DeepEqual(readWriteSets[1].Reads, []string{"k3"}) || len(readWriteSets[1].Writes) != 0 {
+		t.Fatalf("Unexpected read and write sets of b: %v", readWriteSets[1])
+	}
+	if len(chaincodeSupport.calls.txs) != 0 {
+		t.Fatalf("Expected tracking to stop, got %v", chaincodeSupport.calls.txs)
+	}
+}
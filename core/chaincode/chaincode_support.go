@@ -116,6 +116,12 @@ func NewChaincodeSupport(chainname ChainName, getPeerEndpoint func() (*pb.PeerEn
 	}
 
 	s.userRunsCC = userrunsCC
+	s.determinismCheck = userrunsCC && viper.GetBool("chaincode.determinismcheck")
+
+	s.maxCallDepth = viper.GetInt("chaincode.maxcalldepth")
+	if s.maxCallDepth <= 0 {
+		s.maxCallDepth = maxCallDepthDefault
+	}
 
 	s.ccStartupTimeout = ccstartuptimeout
 
@@ -164,6 +170,9 @@ type ChaincodeSupport struct {
 	ccStartupTimeout     time.Duration
 	chaincodeInstallPath string
 	userRunsCC           bool
+	determinismCheck     bool
+	maxCallDepth         int
+	calls                callStacks
 	secHelper            crypto.Peer
 	peerNetworkID        string
 	peerID               string
@@ -646,8 +655,18 @@ func (chaincodeSupport *ChaincodeSupport) Execute(ctxt context.Context, chaincod
 	}
 	chaincodeSupport.runningChaincodes.Unlock()
 
+	// Queries, and every chaincode they call, are read-only
+	callers, err := chaincodeSupport.enterCall(msg.Txid, chaincode, msg.Type == pb.ChaincodeMessage_QUERY)
+	if err != nil {
+		return nil, err
+	}
+	defer chaincodeSupport.exitCall(msg.Txid)
+	if msg.SecurityContext == nil {
+		msg.SecurityContext = &pb.ChaincodeSecurityContext{}
+	}
+	msg.SecurityContext.CallStack = callers
+
 	var notfy chan *pb.ChaincodeMessage
-	var err error
 	if notfy, err = chrte.handler.sendExecuteMessage(msg, tx); err != nil {
 		return nil, fmt.Errorf("Error sending %s: %s", msg.Type.String(), err)
 	}
@@ -17,6 +17,7 @@ limitations under the License.
 package chaincode
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"time"
@@ -98,6 +99,12 @@ func Execute(ctxt context.Context, chain *ChaincodeSupport, t *pb.Transaction) (
 		}
 
 		markTxBegin(ledger, t)
+		checkDeterminism := t.Type == pb.Transaction_CHAINCODE_INVOKE && chain.determinismCheck
+		var firstStateDeltaHash []byte
+		var firstSucceeded bool
+		if checkDeterminism {
+			firstStateDeltaHash, firstSucceeded = executeForDeterminismCheck(ctxt, chain, ledger, chaincode, ccMsg, timeout, t)
+		}
 		resp, err := chain.Execute(ctxt, chaincode, ccMsg, timeout, t)
 		if err != nil {
 			// Rollback transaction
@@ -114,12 +121,23 @@ func Execute(ctxt context.Context, chain *ChaincodeSupport, t *pb.Transaction) (
 			}
 
 			if resp.Type == pb.ChaincodeMessage_COMPLETED || resp.Type == pb.ChaincodeMessage_QUERY_COMPLETED {
+				if checkDeterminism && (!firstSucceeded ||
+					!bytes.Equal(firstStateDeltaHash, ledger.GetCurrentTxStateDelta().ComputeCryptoHash())) {
+					// Rollback transaction
+					markTxFinish(ledger, t, false)
+					chaincodeLogger.Errorf("Transaction %s of chaincode %s is not deterministic", t.Txid, chaincode)
+					return nil, nil, fmt.Errorf("Transaction %s is not deterministic: two executions of it changed the state differently", t.Txid)
+				}
 				// Success
 				markTxFinish(ledger, t, true)
 				return resp.Payload, resp.ChaincodeEvent, nil
 			} else if resp.Type == pb.ChaincodeMessage_ERROR || resp.Type == pb.ChaincodeMessage_QUERY_ERROR {
 				// Rollback transaction
 				markTxFinish(ledger, t, false)
+				if resp.ChaincodeError != nil {
+					// Keep the structure of the error for the clients
+					return nil, resp.ChaincodeEvent, resp.ChaincodeError
+				}
 				return nil, resp.ChaincodeEvent, fmt.Errorf("Transaction or query returned with failure: %s", string(resp.Payload))
 			}
 			markTxFinish(ledger, t, false)
@@ -135,8 +153,9 @@ func Execute(ctxt context.Context, chain *ChaincodeSupport, t *pb.Transaction) (
 //ExecuteTransactions - will execute transactions on the array one by one
 //will return an array of errors one for each transaction. If the execution
 //succeeded, array element will be nil. returns []byte of state hash or
-//error
-func ExecuteTransactions(ctxt context.Context, cname ChainName, xacts []*pb.Transaction) (succeededTXs []*pb.Transaction, stateHash []byte, ccevents []*pb.ChaincodeEvent, txerrs []error, err error) {
+//error. For each transaction that called other chaincodes, readWriteSets
+//holds the keys every chaincode read and wrote.
+func ExecuteTransactions(ctxt context.Context, cname ChainName, xacts []*pb.Transaction) (succeededTXs []*pb.Transaction, stateHash []byte, ccevents []*pb.ChaincodeEvent, readWriteSets [][]*pb.ChaincodeReadWriteSet, txerrs []error, err error) {
 	var chain = GetChain(cname)
 	if chain == nil {
 		// TODO: We should never get here, but otherwise a good reminder to better handle
@@ -145,13 +164,16 @@ func ExecuteTransactions(ctxt context.Context, cname ChainName, xacts []*pb.Tran
 
 	txerrs = make([]error, len(xacts))
 	ccevents = make([]*pb.ChaincodeEvent, len(xacts))
+	readWriteSets = make([][]*pb.ChaincodeReadWriteSet, len(xacts))
 	var succeededTxs = make([]*pb.Transaction, 0)
 	for i, t := range xacts {
+		chain.trackReadWriteSets(t.Txid)
 		_, ccevents[i], txerrs[i] = Execute(ctxt, chain, t)
+		readWriteSets[i] = chain.takeReadWriteSets(t.Txid)
 		if txerrs[i] == nil {
 			succeededTxs = append(succeededTxs, t)
 		} else {
-			sendTxRejectedEvent(xacts[i], txerrs[i].Error())
+			sendTxRejectedEvent(xacts[i], txerrs[i])
 		}
 	}
 
@@ -161,7 +183,7 @@ func ExecuteTransactions(ctxt context.Context, cname ChainName, xacts []*pb.Tran
 		stateHash, err = lgr.GetTempStateHash()
 	}
 
-	return succeededTxs, stateHash, ccevents, txerrs, err
+	return succeededTxs, stateHash, ccevents, readWriteSets, txerrs, err
 }
 
 // GetSecureContext returns the security context from the context object or error
@@ -200,6 +222,22 @@ func getTimeout(cID *pb.ChaincodeID) (time.Duration, error) {
 	return -1, errFailedToGetChainCodeSpecForTransaction
 }
 
+// executeForDeterminismCheck executes an invoke transaction whose changes are
+// then rolled back, so that it can be executed again and the state changes of
+// both executions compared. It returns the hash of the state changes and
+// whether the execution succeeded.
+func executeForDeterminismCheck(ctxt context.Context, chain *ChaincodeSupport, ledger *ledger.Ledger, chaincode string, msg *pb.ChaincodeMessage, timeout time.Duration, t *pb.Transaction) ([]byte, bool) {
+	resp, err := chain.Execute(ctxt, chaincode, msg, timeout, t)
+	succeeded := err == nil && resp != nil && resp.Type == pb.ChaincodeMessage_COMPLETED
+	var stateDeltaHash []byte
+	if succeeded {
+		stateDeltaHash = ledger.GetCurrentTxStateDelta().ComputeCryptoHash()
+	}
+	markTxFinish(ledger, t, false)
+	markTxBegin(ledger, t)
+	return stateDeltaHash, succeeded
+}
+
 func markTxBegin(ledger *ledger.Ledger, t *pb.Transaction) {
 	if t.Type == pb.Transaction_CHAINCODE_QUERY {
 		return
@@ -214,6 +252,6 @@ func markTxFinish(ledger *ledger.Ledger, t *pb.Transaction, successful bool) {
 	ledger.TxFinished(t.Txid, successful)
 }
 
-func sendTxRejectedEvent(tx *pb.Transaction, errorMsg string) {
-	producer.Send(producer.CreateRejectionEvent(tx, errorMsg))
+func sendTxRejectedEvent(tx *pb.Transaction, err error) {
+	producer.Send(producer.CreateRejectionEvent(tx, err))
 }
@@ -18,6 +18,7 @@ package chaincode
 
 import (
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"strconv"
@@ -28,6 +29,7 @@ import (
 
 	"path/filepath"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/core/container"
 	"github.com/hyperledger/fabric/core/container/ccintf"
 	"github.com/hyperledger/fabric/core/crypto"
@@ -1369,6 +1371,147 @@ func TestRangeQuery(t *testing.T) {
 	closeListenerAndSleep(lis)
 }
 
+// chaincodeStreamRecorder stands in for the chaincode end of a handler's stream
+// and records the messages the peer sends
+type chaincodeStreamRecorder struct {
+	sent chan *pb.ChaincodeMessage
+}
+
+func (stream *chaincodeStreamRecorder) Send(msg *pb.ChaincodeMessage) error {
+	stream.sent <- msg
+	return nil
+}
+
+func (stream *chaincodeStreamRecorder) Recv() (*pb.ChaincodeMessage, error) {
+	return nil, io.EOF
+}
+
+func (stream *chaincodeStreamRecorder) receive(t *testing.T, txid string) *pb.ChaincodeMessage {
+	select {
+	case msg := <-stream.sent:
+		if msg.Txid != txid {
+			t.Fatalf("Expected a message for txid %s, got %s for %s", txid, msg.Type, msg.Txid)
+		}
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for a message for txid %s", txid)
+		return nil
+	}
+}
+
+// newQueryingHandler returns the handler of a registered chaincode which is
+// executing the query txid
+func newQueryingHandler(t *testing.T, chaincodeID string, txid string) (*Handler, *chaincodeStreamRecorder) {
+	chaincodeSupport := &ChaincodeSupport{runningChaincodes: &runningChaincodes{chaincodeMap: make(map[string]*chaincodeRTEnv)}}
+	stream := &chaincodeStreamRecorder{sent: make(chan *pb.ChaincodeMessage, 10)}
+	handler := newChaincodeSupportHandler(chaincodeSupport, stream)
+
+	payload, _ := proto.Marshal(&pb.ChaincodeID{Name: chaincodeID})
+	if err := handler.HandleMessage(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_REGISTER, Payload: payload}); err != nil {
+		t.Fatalf("Error registering chaincode: %s", err)
+	}
+	stream.receive(t, "")
+	if err := handler.HandleMessage(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_READY}); err != nil {
+		t.Fatalf("Error readying chaincode: %s", err)
+	}
+
+	if _, err := handler.sendExecuteMessage(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_QUERY, Txid: txid}, nil); err != nil {
+		t.Fatalf("Error sending query: %s", err)
+	}
+	stream.receive(t, txid)
+	return handler, stream
+}
+
+// commitState commits the key-values of chaincodeID to the ledger
+func commitState(t *testing.T, ledgerObj *ledger.Ledger, chaincodeID string, kvs map[string][]byte) {
+	ledgerObj.BeginTxBatch(1)
+	ledgerObj.TxBegin("txUuid")
+	if err := ledgerObj.SetStateMultipleKeys(chaincodeID, kvs); err != nil {
+		t.Fatalf("Error setting state: %s", err)
+	}
+	ledgerObj.TxFinished("txUuid", true)
+	if err := ledgerObj.CommitTxBatch(1, nil, nil, nil); err != nil {
+		t.Fatalf("Error committing state: %s", err)
+	}
+}
+
+func TestHandleQueryState(t *testing.T) {
+	ledgerObj := ledger.InitTestLedger(t)
+	commitState(t, ledgerObj, "mycc", map[string][]byte{
+		"a": []byte(`{"owner": "alice", "size": 1}`),
+		"b": []byte(`{"owner": "bob", "size": 2}`),
+		"c": []byte(`{"owner": "alice", "size": 3}`),
+	})
+	handler, stream := newQueryingHandler(t, "mycc", "query1")
+
+	payload, _ := proto.Marshal(&pb.QueryState{Query: `{"selector": {"owner": "alice"}, "sort": [{"size": "desc"}]}`})
+	if err := handler.HandleMessage(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_QUERY_STATE, Payload: payload, Txid: "query1"}); err != nil {
+		t.Fatalf("Error handling %s: %s", pb.ChaincodeMessage_QUERY_STATE, err)
+	}
+	msg := stream.receive(t, "query1")
+	if msg.Type != pb.ChaincodeMessage_RESPONSE {
+		t.Fatalf("Expected %s, got %s: %s", pb.ChaincodeMessage_RESPONSE, msg.Type, msg.Payload)
+	}
+	response := &pb.RangeQueryStateResponse{}
+	if err := proto.Unmarshal(msg.Payload, response); err != nil {
+		t.Fatalf("Error unmarshalling response: %s", err)
+	}
+	var keys []string
+	for _, kv := range response.KeysAndValues {
+		keys = append(keys, kv.Key)
+	}
+	if strings.Join(keys, ",") != "c,a" || response.HasMore {
+		t.Fatalf("Expected keys c,a without more, got %v (hasMore=%t)", keys, response.HasMore)
+	}
+
+	// a malformed query is reported to the chaincode
+	payload, _ = proto.Marshal(&pb.QueryState{Query: `{"selector": `})
+	handler.HandleMessage(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_QUERY_STATE, Payload: payload, Txid: "query1"})
+	if msg = stream.receive(t, "query1"); msg.Type != pb.ChaincodeMessage_ERROR {
+		t.Fatalf("Expected %s for a malformed query, got %s", pb.ChaincodeMessage_ERROR, msg.Type)
+	}
+}
+
+func TestHandleGetStateMultiple(t *testing.T) {
+	ledgerObj := ledger.InitTestLedger(t)
+	commitState(t, ledgerObj, "mycc", map[string][]byte{"a": []byte("value_a"), "c": []byte("value_c")})
+	handler, stream := newQueryingHandler(t, "mycc", "query1")
+
+	payload, _ := proto.Marshal(&pb.GetStateMultiple{Keys: []string{"a", "b", "c"}})
+	if err := handler.HandleMessage(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_STATE_MULTIPLE, Payload: payload, Txid: "query1"}); err != nil {
+		t.Fatalf("Error handling %s: %s", pb.ChaincodeMessage_GET_STATE_MULTIPLE, err)
+	}
+	msg := stream.receive(t, "query1")
+	if msg.Type != pb.ChaincodeMessage_RESPONSE {
+		t.Fatalf("Expected %s, got %s: %s", pb.ChaincodeMessage_RESPONSE, msg.Type, msg.Payload)
+	}
+	response := &pb.GetStateMultipleResponse{}
+	if err := proto.Unmarshal(msg.Payload, response); err != nil {
+		t.Fatalf("Error unmarshalling response: %s", err)
+	}
+	// the missing key b keeps its place with an empty value
+	if len(response.Values) != 3 || string(response.Values[0]) != "value_a" || len(response.Values[1]) != 0 || string(response.Values[2]) != "value_c" {
+		t.Fatalf("Expected the values of a, b and c, got %q", response.Values)
+	}
+}
+
+func TestHandlePutStateMultipleInQuery(t *testing.T) {
+	ledgerObj := ledger.InitTestLedger(t)
+	handler, stream := newQueryingHandler(t, "mycc", "query1")
+
+	payload, _ := proto.Marshal(&pb.PutStateMultiple{KeysAndValues: []*pb.PutStateInfo{{Key: "a", Value: []byte("value_a")}}})
+	if err := handler.HandleMessage(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_PUT_STATE_MULTIPLE, Payload: payload, Txid: "query1"}); err == nil {
+		t.Fatalf("Expected %s to be rejected in a query", pb.ChaincodeMessage_PUT_STATE_MULTIPLE)
+	}
+	if msg := stream.receive(t, "query1"); msg.Type != pb.ChaincodeMessage_ERROR || !strings.Contains(string(msg.Payload), "query context") {
+		t.Fatalf("Expected %s for a write in a query, got %s: %s", pb.ChaincodeMessage_ERROR, msg.Type, msg.Payload)
+	}
+
+	if value, err := ledgerObj.GetState("mycc", "a", false); err != nil || value != nil {
+		t.Fatalf("Expected a to stay unset, got %q (%v)", value, err)
+	}
+}
+
 func TestGetEvent(t *testing.T) {
 	testDBWrapper.CleanDB(t)
 	var opts []grpc.ServerOption
@@ -41,8 +41,8 @@ const (
 	initstate        = "init"        //in:ESTABLISHED, rcv:-, send: INIT
 	readystate       = "ready"       //in:ESTABLISHED,TRANSACTION, rcv:COMPLETED
 	transactionstate = "transaction" //in:READY, rcv: xact from consensus, send: TRANSACTION
-	busyinitstate    = "busyinit"    //in:INIT, rcv: PUT_STATE, DEL_STATE, INVOKE_CHAINCODE
-	busyxactstate    = "busyxact"    //in:TRANSACION, rcv: PUT_STATE, DEL_STATE, INVOKE_CHAINCODE
+	busyinitstate    = "busyinit"    //in:INIT, rcv: PUT_STATE, PUT_STATE_MULTIPLE, DEL_STATE, INVOKE_CHAINCODE
+	busyxactstate    = "busyxact"    //in:TRANSACION, rcv: PUT_STATE, PUT_STATE_MULTIPLE, DEL_STATE, INVOKE_CHAINCODE
 	endstate         = "end"         //in:INIT,ESTABLISHED, rcv: error, terminate container
 
 )
@@ -244,6 +244,16 @@ func (handler *Handler) encrypt(txid string, payload []byte) ([]byte, error) {
 	return handler.encryptOrDecrypt(true, txid, payload)
 }
 
+// isConfidential returns true if the state accessed by the transaction txid is encrypted
+func (handler *Handler) isConfidential(txid string) bool {
+	if handler.chaincodeSupport.getSecHelper() == nil {
+		return false
+	}
+	txctx := handler.getTxContext(txid)
+	return txctx == nil || txctx.transactionSecContext == nil ||
+		txctx.transactionSecContext.ConfidentialityLevel != pb.ConfidentialityLevel_PUBLIC
+}
+
 func (handler *Handler) getSecurityBinding(tx *pb.Transaction) ([]byte, error) {
 	secHelper := handler.chaincodeSupport.getSecHelper()
 	if secHelper == nil {
@@ -390,9 +400,11 @@ func newChaincodeSupportHandler(chaincodeSupport *ChaincodeSupport, peerChatStre
 			{Name: pb.ChaincodeMessage_READY.String(), Src: []string{establishedstate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_TRANSACTION.String(), Src: []string{readystate}, Dst: transactionstate},
 			{Name: pb.ChaincodeMessage_PUT_STATE.String(), Src: []string{transactionstate}, Dst: busyxactstate},
+			{Name: pb.ChaincodeMessage_PUT_STATE_MULTIPLE.String(), Src: []string{transactionstate}, Dst: busyxactstate},
 			{Name: pb.ChaincodeMessage_DEL_STATE.String(), Src: []string{transactionstate}, Dst: busyxactstate},
 			{Name: pb.ChaincodeMessage_INVOKE_CHAINCODE.String(), Src: []string{transactionstate}, Dst: busyxactstate},
 			{Name: pb.ChaincodeMessage_PUT_STATE.String(), Src: []string{initstate}, Dst: busyinitstate},
+			{Name: pb.ChaincodeMessage_PUT_STATE_MULTIPLE.String(), Src: []string{initstate}, Dst: busyinitstate},
 			{Name: pb.ChaincodeMessage_DEL_STATE.String(), Src: []string{initstate}, Dst: busyinitstate},
 			{Name: pb.ChaincodeMessage_INVOKE_CHAINCODE.String(), Src: []string{initstate}, Dst: busyinitstate},
 			{Name: pb.ChaincodeMessage_COMPLETED.String(), Src: []string{initstate, readystate, transactionstate}, Dst: readystate},
@@ -401,11 +413,26 @@ func newChaincodeSupportHandler(chaincodeSupport *ChaincodeSupport, peerChatStre
 			{Name: pb.ChaincodeMessage_GET_STATE.String(), Src: []string{busyinitstate}, Dst: busyinitstate},
 			{Name: pb.ChaincodeMessage_GET_STATE.String(), Src: []string{transactionstate}, Dst: transactionstate},
 			{Name: pb.ChaincodeMessage_GET_STATE.String(), Src: []string{busyxactstate}, Dst: busyxactstate},
+			{Name: pb.ChaincodeMessage_GET_STATE_MULTIPLE.String(), Src: []string{readystate}, Dst: readystate},
+			{Name: pb.ChaincodeMessage_GET_STATE_MULTIPLE.String(), Src: []string{initstate}, Dst: initstate},
+			{Name: pb.ChaincodeMessage_GET_STATE_MULTIPLE.String(), Src: []string{busyinitstate}, Dst: busyinitstate},
+			{Name: pb.ChaincodeMessage_GET_STATE_MULTIPLE.String(), Src: []string{transactionstate}, Dst: transactionstate},
+			{Name: pb.ChaincodeMessage_GET_STATE_MULTIPLE.String(), Src: []string{busyxactstate}, Dst: busyxactstate},
+			{Name: pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String(), Src: []string{readystate}, Dst: readystate},
+			{Name: pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String(), Src: []string{initstate}, Dst: initstate},
+			{Name: pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String(), Src: []string{busyinitstate}, Dst: busyinitstate},
+			{Name: pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String(), Src: []string{transactionstate}, Dst: transactionstate},
+			{Name: pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String(), Src: []string{busyxactstate}, Dst: busyxactstate},
 			{Name: pb.ChaincodeMessage_RANGE_QUERY_STATE.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_RANGE_QUERY_STATE.String(), Src: []string{initstate}, Dst: initstate},
 			{Name: pb.ChaincodeMessage_RANGE_QUERY_STATE.String(), Src: []string{busyinitstate}, Dst: busyinitstate},
 			{Name: pb.ChaincodeMessage_RANGE_QUERY_STATE.String(), Src: []string{transactionstate}, Dst: transactionstate},
 			{Name: pb.ChaincodeMessage_RANGE_QUERY_STATE.String(), Src: []string{busyxactstate}, Dst: busyxactstate},
+			{Name: pb.ChaincodeMessage_QUERY_STATE.String(), Src: []string{readystate}, Dst: readystate},
+			{Name: pb.ChaincodeMessage_QUERY_STATE.String(), Src: []string{initstate}, Dst: initstate},
+			{Name: pb.ChaincodeMessage_QUERY_STATE.String(), Src: []string{busyinitstate}, Dst: busyinitstate},
+			{Name: pb.ChaincodeMessage_QUERY_STATE.String(), Src: []string{transactionstate}, Dst: transactionstate},
+			{Name: pb.ChaincodeMessage_QUERY_STATE.String(), Src: []string{busyxactstate}, Dst: busyxactstate},
 			{Name: pb.ChaincodeMessage_RANGE_QUERY_STATE_NEXT.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_RANGE_QUERY_STATE_NEXT.String(), Src: []string{initstate}, Dst: initstate},
 			{Name: pb.ChaincodeMessage_RANGE_QUERY_STATE_NEXT.String(), Src: []string{busyinitstate}, Dst: busyinitstate},
@@ -428,10 +455,14 @@ func newChaincodeSupportHandler(chaincodeSupport *ChaincodeSupport, peerChatStre
 			"before_" + pb.ChaincodeMessage_COMPLETED.String():              func(e *fsm.Event) { v.beforeCompletedEvent(e, v.FSM.Current()) },
 			"before_" + pb.ChaincodeMessage_INIT.String():                   func(e *fsm.Event) { v.beforeInitState(e, v.FSM.Current()) },
 			"after_" + pb.ChaincodeMessage_GET_STATE.String():               func(e *fsm.Event) { v.afterGetState(e, v.FSM.Current()) },
+			"after_" + pb.ChaincodeMessage_GET_STATE_MULTIPLE.String():      func(e *fsm.Event) { v.afterGetStateMultiple(e, v.FSM.Current()) },
+			"after_" + pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String():     func(e *fsm.Event) { v.afterGetHistoryForKey(e, v.FSM.Current()) },
 			"after_" + pb.ChaincodeMessage_RANGE_QUERY_STATE.String():       func(e *fsm.Event) { v.afterRangeQueryState(e, v.FSM.Current()) },
 			"after_" + pb.ChaincodeMessage_RANGE_QUERY_STATE_NEXT.String():  func(e *fsm.Event) { v.afterRangeQueryStateNext(e, v.FSM.Current()) },
 			"after_" + pb.ChaincodeMessage_RANGE_QUERY_STATE_CLOSE.String(): func(e *fsm.Event) { v.afterRangeQueryStateClose(e, v.FSM.Current()) },
+			"after_" + pb.ChaincodeMessage_QUERY_STATE.String():             func(e *fsm.Event) { v.afterQueryState(e, v.FSM.Current()) },
 			"after_" + pb.ChaincodeMessage_PUT_STATE.String():               func(e *fsm.Event) { v.afterPutState(e, v.FSM.Current()) },
+			"after_" + pb.ChaincodeMessage_PUT_STATE_MULTIPLE.String():      func(e *fsm.Event) { v.afterPutStateMultiple(e, v.FSM.Current()) },
 			"after_" + pb.ChaincodeMessage_DEL_STATE.String():               func(e *fsm.Event) { v.afterDelState(e, v.FSM.Current()) },
 			"after_" + pb.ChaincodeMessage_INVOKE_CHAINCODE.String():        func(e *fsm.Event) { v.afterInvokeChaincode(e, v.FSM.Current()) },
 			"enter_" + establishedstate:                                     func(e *fsm.Event) { v.enterEstablishedState(e, v.FSM.Current()) },
@@ -625,6 +656,9 @@ func (handler *Handler) handleGetState(msg *pb.ChaincodeMessage) {
 
 		readCommittedState := !handler.getIsTransaction(msg.Txid)
 		res, err := ledgerObj.GetState(chaincodeID, key, readCommittedState)
+		if err == nil {
+			handler.chaincodeSupport.recordReads(msg.Txid, chaincodeID, key)
+		}
 		if err != nil {
 			// Send error msg back to chaincode. GetState will not trigger event
 			payload := []byte(err.Error())
@@ -652,6 +686,166 @@ func (handler *Handler) handleGetState(msg *pb.ChaincodeMessage) {
 	}()
 }
 
+// afterGetStateMultiple handles a GET_STATE_MULTIPLE request from the chaincode.
+func (handler *Handler) afterGetStateMultiple(e *fsm.Event, state string) {
+	msg, ok := e.Args[0].(*pb.ChaincodeMessage)
+	if !ok {
+		e.Cancel(fmt.Errorf("Received unexpected message type"))
+		return
+	}
+	chaincodeLogger.Debugf("[%s]Received %s, invoking get state multiple from ledger", shorttxid(msg.Txid), pb.ChaincodeMessage_GET_STATE_MULTIPLE)
+
+	// Query ledger for state
+	handler.handleGetStateMultiple(msg)
+}
+
+// Handles query to ledger to get the state of several keys in one request
+func (handler *Handler) handleGetStateMultiple(msg *pb.ChaincodeMessage) {
+	// See handleGetState for why this runs in a go routine
+	go func() {
+		// Check if this is the unique state request from this chaincode txid
+		uniqueReq := handler.createTXIDEntry(msg.Txid)
+		if !uniqueReq {
+			// Drop this request
+			chaincodeLogger.Error("Another state request pending for this Txid. Cannot process.")
+			return
+		}
+
+		var serialSendMsg *pb.ChaincodeMessage
+
+		defer func() {
+			handler.deleteTXIDEntry(msg.Txid)
+			chaincodeLogger.Debugf("[%s]handleGetStateMultiple serial send %s", shorttxid(serialSendMsg.Txid), serialSendMsg.Type)
+			handler.serialSend(serialSendMsg)
+		}()
+
+		getStateMultiple := &pb.GetStateMultiple{}
+		unmarshalErr := proto.Unmarshal(msg.Payload, getStateMultiple)
+		if unmarshalErr != nil {
+			payload := []byte(unmarshalErr.Error())
+			chaincodeLogger.Errorf("Failed to unmarshall get state multiple request. Sending %s", pb.ChaincodeMessage_ERROR)
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
+			return
+		}
+
+		ledgerObj, ledgerErr := ledger.GetLedger()
+		if ledgerErr != nil {
+			// Send error msg back to chaincode. GetState will not trigger event
+			payload := []byte(ledgerErr.Error())
+			chaincodeLogger.Errorf("Failed to get chaincode state(%s). Sending %s", ledgerErr, pb.ChaincodeMessage_ERROR)
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
+			return
+		}
+
+		// Invoke ledger to get state
+		chaincodeID := handler.ChaincodeID.Name
+
+		readCommittedState := !handler.getIsTransaction(msg.Txid)
+		values, err := ledgerObj.GetStateMultipleKeys(chaincodeID, getStateMultiple.Keys, readCommittedState)
+		if err == nil {
+			handler.chaincodeSupport.recordReads(msg.Txid, chaincodeID, getStateMultiple.Keys...)
+		}
+		for i := 0; err == nil && i < len(values); i++ {
+			// Decrypt the data if the confidential is enabled; keys without state stay empty
+			if values[i] != nil {
+				values[i], err = handler.decrypt(msg.Txid, values[i])
+			}
+		}
+		var payload []byte
+		if err == nil {
+			payload, err = proto.Marshal(&pb.GetStateMultipleResponse{Values: values})
+		}
+		if err != nil {
+			// Send error msg back to chaincode. GetState will not trigger event
+			chaincodeLogger.Errorf("[%s]Failed to get chaincode state(%s). Sending %s", shorttxid(msg.Txid), err, pb.ChaincodeMessage_ERROR)
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(err.Error()), Txid: msg.Txid}
+			return
+		}
+
+		// Send response msg back to chaincode. GetState will not trigger event
+		chaincodeLogger.Debugf("[%s]Got state of %d keys. Sending %s", shorttxid(msg.Txid), len(values), pb.ChaincodeMessage_RESPONSE)
+		serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: payload, Txid: msg.Txid}
+	}()
+}
+
+// afterGetHistoryForKey handles a GET_HISTORY_FOR_KEY request from the chaincode.
+func (handler *Handler) afterGetHistoryForKey(e *fsm.Event, state string) {
+	msg, ok := e.Args[0].(*pb.ChaincodeMessage)
+	if !ok {
+		e.Cancel(fmt.Errorf("Received unexpected message type"))
+		return
+	}
+	chaincodeLogger.Debugf("[%s]Received %s, invoking get history from ledger", shorttxid(msg.Txid), pb.ChaincodeMessage_GET_HISTORY_FOR_KEY)
+
+	// Query ledger for history
+	handler.handleGetHistoryForKey(msg)
+}
+
+// Handles query to ledger to get the committed modifications of a key
+func (handler *Handler) handleGetHistoryForKey(msg *pb.ChaincodeMessage) {
+	// See handleGetState for why this runs in a go routine
+	go func() {
+		// Check if this is the unique state request from this chaincode txid
+		uniqueReq := handler.createTXIDEntry(msg.Txid)
+		if !uniqueReq {
+			// Drop this request
+			chaincodeLogger.Error("Another state request pending for this Txid. Cannot process.")
+			return
+		}
+
+		var serialSendMsg *pb.ChaincodeMessage
+
+		defer func() {
+			handler.deleteTXIDEntry(msg.Txid)
+			chaincodeLogger.Debugf("[%s]handleGetHistoryForKey serial send %s", shorttxid(serialSendMsg.Txid), serialSendMsg.Type)
+			handler.serialSend(serialSendMsg)
+		}()
+
+		getHistoryForKey := &pb.GetHistoryForKey{}
+		unmarshalErr := proto.Unmarshal(msg.Payload, getHistoryForKey)
+		if unmarshalErr != nil {
+			payload := []byte(unmarshalErr.Error())
+			chaincodeLogger.Errorf("Failed to unmarshall get history request. Sending %s", pb.ChaincodeMessage_ERROR)
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
+			return
+		}
+
+		ledgerObj, ledgerErr := ledger.GetLedger()
+		if ledgerErr != nil {
+			// Send error msg back to chaincode. GetState will not trigger event
+			payload := []byte(ledgerErr.Error())
+			chaincodeLogger.Errorf("Failed to get key history(%s). Sending %s", ledgerErr, pb.ChaincodeMessage_ERROR)
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
+			return
+		}
+
+		// Invoke ledger to get the history
+		chaincodeID := handler.ChaincodeID.Name
+
+		modifications, completeFromBlock, err := ledgerObj.GetHistoryForKey(chaincodeID, getHistoryForKey.Key)
+		for i := 0; err == nil && i < len(modifications); i++ {
+			// Decrypt the data if the confidential is enabled; deletions have no value
+			if modifications[i].Value != nil {
+				modifications[i].Value, err = handler.decrypt(msg.Txid, modifications[i].Value)
+			}
+		}
+		var payload []byte
+		if err == nil {
+			payload, err = proto.Marshal(&pb.GetHistoryForKeyResponse{Modifications: modifications, CompleteFromBlock: completeFromBlock})
+		}
+		if err != nil {
+			// Send error msg back to chaincode. GetState will not trigger event
+			chaincodeLogger.Errorf("[%s]Failed to get key history(%s). Sending %s", shorttxid(msg.Txid), err, pb.ChaincodeMessage_ERROR)
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(err.Error()), Txid: msg.Txid}
+			return
+		}
+
+		// Send response msg back to chaincode. GetState will not trigger event
+		chaincodeLogger.Debugf("[%s]Got %d modifications of key. Sending %s", shorttxid(msg.Txid), len(modifications), pb.ChaincodeMessage_RESPONSE)
+		serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: payload, Txid: msg.Txid}
+	}()
+}
+
 const maxRangeQueryStateLimit = 100
 
 // afterRangeQueryState handles a RANGE_QUERY_STATE request from the chaincode.
@@ -699,8 +893,6 @@ func (handler *Handler) handleRangeQueryState(msg *pb.ChaincodeMessage) {
 			return
 		}
 
-		hasNext := true
-
 		ledger, ledgerErr := ledger.GetLedger()
 		if ledgerErr != nil {
 			// Send error msg back to chaincode. GetState will not trigger event
@@ -713,6 +905,7 @@ func (handler *Handler) handleRangeQueryState(msg *pb.ChaincodeMessage) {
 		chaincodeID := handler.ChaincodeID.Name
 
 		readCommittedState := !handler.getIsTransaction(msg.Txid)
+		// The ledger returns the keys in lexical order
 		rangeIter, err := ledger.GetStateRangeScanIterator(chaincodeID, rangeQueryState.StartKey, rangeQueryState.EndKey, readCommittedState)
 		if err != nil {
 			// Send error msg back to chaincode. GetState will not trigger event
@@ -722,58 +915,139 @@ func (handler *Handler) handleRangeQueryState(msg *pb.ChaincodeMessage) {
 			return
 		}
 
-		iterID := util.GenerateUUID()
-		txContext := handler.getTxContext(msg.Txid)
-		handler.putRangeQueryIterator(txContext, iterID, rangeIter)
+		serialSendMsg = handler.firstRangeQueryStateResponse(msg.Txid, rangeIter)
+	}()
+}
 
-		hasNext = rangeIter.Next()
+// afterQueryState handles a QUERY_STATE request from the chaincode.
+func (handler *Handler) afterQueryState(e *fsm.Event, state string) {
+	msg, ok := e.Args[0].(*pb.ChaincodeMessage)
+	if !ok {
+		e.Cancel(fmt.Errorf("Received unexpected message type"))
+		return
+	}
+	chaincodeLogger.Debugf("Received %s, invoking query state on ledger", pb.ChaincodeMessage_QUERY_STATE)
 
-		var keysAndValues []*pb.RangeQueryStateKeyValue
-		var i = uint32(0)
-		for ; hasNext && i < maxRangeQueryStateLimit; i++ {
-			key, value := rangeIter.GetKeyValue()
-			// Decrypt the data if the confidential is enabled
-			decryptedValue, decryptErr := handler.decrypt(msg.Txid, value)
-			if decryptErr != nil {
-				payload := []byte(decryptErr.Error())
-				chaincodeLogger.Errorf("Failed decrypt value. Sending %s", pb.ChaincodeMessage_ERROR)
-				serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
+	// Query ledger for state
+	handler.handleQueryState(msg)
+	chaincodeLogger.Debug("Exiting QUERY_STATE")
+}
 
-				rangeIter.Close()
-				handler.deleteRangeQueryIterator(txContext, iterID)
+// Handles rich query of the ledger state
+func (handler *Handler) handleQueryState(msg *pb.ChaincodeMessage) {
+	// The defer followed by triggering a go routine dance is needed to ensure that the previous state transition
+	// is completed before the next one is triggered. The previous state transition is deemed complete only when
+	// the afterQueryState function is exited.
+	go func() {
+		// Check if this is the unique state request from this chaincode txid
+		uniqueReq := handler.createTXIDEntry(msg.Txid)
+		if !uniqueReq {
+			// Drop this request
+			chaincodeLogger.Error("Another state request pending for this Txid. Cannot process.")
+			return
+		}
 
-				return
-			}
-			keyAndValue := pb.RangeQueryStateKeyValue{Key: key, Value: decryptedValue}
-			keysAndValues = append(keysAndValues, &keyAndValue)
+		var serialSendMsg *pb.ChaincodeMessage
 
-			hasNext = rangeIter.Next()
+		defer func() {
+			handler.deleteTXIDEntry(msg.Txid)
+			chaincodeLogger.Debugf("[%s]handleQueryState serial send %s", shorttxid(serialSendMsg.Txid), serialSendMsg.Type)
+			handler.serialSend(serialSendMsg)
+		}()
+
+		queryState := &pb.QueryState{}
+		unmarshalErr := proto.Unmarshal(msg.Payload, queryState)
+		if unmarshalErr != nil {
+			payload := []byte(unmarshalErr.Error())
+			chaincodeLogger.Errorf("Failed to unmarshall query request. Sending %s", pb.ChaincodeMessage_ERROR)
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
+			return
 		}
 
-		if !hasNext {
-			rangeIter.Close()
-			handler.deleteRangeQueryIterator(txContext, iterID)
+		// The peer evaluates the query over the stored values, which it cannot read when they are encrypted
+		if handler.isConfidential(msg.Txid) {
+			payload := []byte("Rich queries are not supported on confidential state")
+			chaincodeLogger.Errorf("Query on confidential state. Sending %s", pb.ChaincodeMessage_ERROR)
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
+			return
 		}
 
-		payload := &pb.RangeQueryStateResponse{KeysAndValues: keysAndValues, HasMore: hasNext, ID: iterID}
-		payloadBytes, err := proto.Marshal(payload)
-		if err != nil {
-			rangeIter.Close()
-			handler.deleteRangeQueryIterator(txContext, iterID)
+		ledger, ledgerErr := ledger.GetLedger()
+		if ledgerErr != nil {
+			// Send error msg back to chaincode. GetState will not trigger event
+			payload := []byte(ledgerErr.Error())
+			chaincodeLogger.Errorf("Failed to get ledger. Sending %s", pb.ChaincodeMessage_ERROR)
+			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
+			return
+		}
 
+		chaincodeID := handler.ChaincodeID.Name
+
+		readCommittedState := !handler.getIsTransaction(msg.Txid)
+		resultsIter, err := ledger.QueryState(chaincodeID, queryState.Query, readCommittedState)
+		if err != nil {
 			// Send error msg back to chaincode. GetState will not trigger event
 			payload := []byte(err.Error())
-			chaincodeLogger.Errorf("Failed marshall resopnse. Sending %s", pb.ChaincodeMessage_ERROR)
+			chaincodeLogger.Errorf("Failed to query ledger state. Sending %s", pb.ChaincodeMessage_ERROR)
 			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
 			return
 		}
 
-		chaincodeLogger.Debugf("Got keys and values. Sending %s", pb.ChaincodeMessage_RESPONSE)
-		serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: payloadBytes, Txid: msg.Txid}
-
+		serialSendMsg = handler.firstRangeQueryStateResponse(msg.Txid, resultsIter)
 	}()
 }
 
+// firstRangeQueryStateResponse registers rangeIter with the transaction context so that the chaincode
+// can page through it with RANGE_QUERY_STATE_NEXT, and returns the message holding its first key-values.
+func (handler *Handler) firstRangeQueryStateResponse(txid string, rangeIter statemgmt.RangeScanIterator) *pb.ChaincodeMessage {
+	iterID := util.GenerateUUID()
+	txContext := handler.getTxContext(txid)
+	handler.putRangeQueryIterator(txContext, iterID, rangeIter)
+
+	hasNext := rangeIter.Next()
+
+	var keysAndValues []*pb.RangeQueryStateKeyValue
+	var i = uint32(0)
+	for ; hasNext && i < maxRangeQueryStateLimit; i++ {
+		key, value := rangeIter.GetKeyValue()
+		// Decrypt the data if the confidential is enabled
+		decryptedValue, decryptErr := handler.decrypt(txid, value)
+		if decryptErr != nil {
+			rangeIter.Close()
+			handler.deleteRangeQueryIterator(txContext, iterID)
+
+			payload := []byte(decryptErr.Error())
+			chaincodeLogger.Errorf("Failed decrypt value. Sending %s", pb.ChaincodeMessage_ERROR)
+			return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: txid}
+		}
+		keyAndValue := pb.RangeQueryStateKeyValue{Key: key, Value: decryptedValue}
+		keysAndValues = append(keysAndValues, &keyAndValue)
+		handler.chaincodeSupport.recordReads(txid, handler.ChaincodeID.Name, key)
+
+		hasNext = rangeIter.Next()
+	}
+
+	if !hasNext {
+		rangeIter.Close()
+		handler.deleteRangeQueryIterator(txContext, iterID)
+	}
+
+	payload := &pb.RangeQueryStateResponse{KeysAndValues: keysAndValues, HasMore: hasNext, ID: iterID}
+	payloadBytes, err := proto.Marshal(payload)
+	if err != nil {
+		rangeIter.Close()
+		handler.deleteRangeQueryIterator(txContext, iterID)
+
+		// Send error msg back to chaincode. GetState will not trigger event
+		payload := []byte(err.Error())
+		chaincodeLogger.Errorf("Failed marshall resopnse. Sending %s", pb.ChaincodeMessage_ERROR)
+		return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: txid}
+	}
+
+	chaincodeLogger.Debugf("Got keys and values. Sending %s", pb.ChaincodeMessage_RESPONSE)
+	return &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: payloadBytes, Txid: txid}
+}
+
 // afterRangeQueryState handles a RANGE_QUERY_STATE_NEXT request from the chaincode.
 func (handler *Handler) afterRangeQueryStateNext(e *fsm.Event, state string) {
 	msg, ok := e.Args[0].(*pb.ChaincodeMessage)
@@ -848,6 +1122,7 @@ func (handler *Handler) handleRangeQueryStateNext(msg *pb.ChaincodeMessage) {
 			}
 			keyAndValue := pb.RangeQueryStateKeyValue{Key: key, Value: decryptedValue}
 			keysAndValues = append(keysAndValues, &keyAndValue)
+			handler.chaincodeSupport.recordReads(msg.Txid, handler.ChaincodeID.Name, key)
 
 			hasNext = rangeIter.Next()
 		}
@@ -957,6 +1232,18 @@ func (handler *Handler) afterPutState(e *fsm.Event, state string) {
 	// Put state into ledger handled within enterBusyState
 }
 
+// afterPutStateMultiple handles a PUT_STATE_MULTIPLE request from the chaincode.
+func (handler *Handler) afterPutStateMultiple(e *fsm.Event, state string) {
+	_, ok := e.Args[0].(*pb.ChaincodeMessage)
+	if !ok {
+		e.Cancel(fmt.Errorf("Received unexpected message type"))
+		return
+	}
+	chaincodeLogger.Debugf("Received %s in state %s, invoking put state multiple to ledger", pb.ChaincodeMessage_PUT_STATE_MULTIPLE, state)
+
+	// Put state into ledger handled within enterBusyState
+}
+
 // afterDelState handles a DEL_STATE request from the chaincode.
 func (handler *Handler) afterDelState(e *fsm.Event, state string) {
 	_, ok := e.Args[0].(*pb.ChaincodeMessage)
@@ -1040,10 +1327,47 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 				// Invoke ledger to put state
 				err = ledgerObj.SetState(chaincodeID, putStateInfo.Key, pVal)
 			}
+			if err == nil {
+				handler.chaincodeSupport.recordWrites(msg.Txid, chaincodeID, putStateInfo.Key)
+			}
+		} else if msg.Type.String() == pb.ChaincodeMessage_PUT_STATE_MULTIPLE.String() {
+			putStateMultiple := &pb.PutStateMultiple{}
+			unmarshalErr := proto.Unmarshal(msg.Payload, putStateMultiple)
+			if unmarshalErr != nil {
+				payload := []byte(unmarshalErr.Error())
+				chaincodeLogger.Debugf("[%s]Unable to decipher payload. Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_ERROR)
+				triggerNextStateMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
+				return
+			}
+
+			kvs := make(map[string][]byte, len(putStateMultiple.KeysAndValues))
+			for _, putStateInfo := range putStateMultiple.KeysAndValues {
+				// SetStateMultipleKeys does not check its arguments the way SetState does
+				if putStateInfo.Key == "" || putStateInfo.Value == nil {
+					err = fmt.Errorf("An empty string key or a nil value is not supported. Key='%s'", putStateInfo.Key)
+					break
+				}
+				// Encrypt the data if the confidential is enabled
+				if kvs[putStateInfo.Key], err = handler.encrypt(msg.Txid, putStateInfo.Value); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				// Invoke ledger to put state
+				err = ledgerObj.SetStateMultipleKeys(chaincodeID, kvs)
+			}
+			if err == nil {
+				for key := range kvs {
+					handler.chaincodeSupport.recordWrites(msg.Txid, chaincodeID, key)
+				}
+			}
 		} else if msg.Type.String() == pb.ChaincodeMessage_DEL_STATE.String() {
 			// Invoke ledger to delete state
 			key := string(msg.Payload)
 			err = ledgerObj.DeleteState(chaincodeID, key)
+			if err == nil {
+				handler.chaincodeSupport.recordWrites(msg.Txid, chaincodeID, key)
+			}
 		} else if msg.Type.String() == pb.ChaincodeMessage_INVOKE_CHAINCODE.String() {
 			//check and prohibit C-call-C for CONFIDENTIAL txs
 			if triggerNextStateMsg = handler.canCallChaincode(msg.Txid); triggerNextStateMsg != nil {
@@ -1407,7 +1731,7 @@ func (handler *Handler) HandleMessage(msg *pb.ChaincodeMessage) error {
 	}
 	if handler.FSM.Cannot(msg.Type.String()) {
 		// Check if this is a request from validator in query context
-		if msg.Type.String() == pb.ChaincodeMessage_PUT_STATE.String() || msg.Type.String() == pb.ChaincodeMessage_DEL_STATE.String() || msg.Type.String() == pb.ChaincodeMessage_INVOKE_CHAINCODE.String() {
+		if msg.Type.String() == pb.ChaincodeMessage_PUT_STATE.String() || msg.Type.String() == pb.ChaincodeMessage_PUT_STATE_MULTIPLE.String() || msg.Type.String() == pb.ChaincodeMessage_DEL_STATE.String() || msg.Type.String() == pb.ChaincodeMessage_INVOKE_CHAINCODE.String() {
 			// Check if this TXID is a transaction
 			if !handler.getIsTransaction(msg.Txid) {
 				payload := []byte(fmt.Sprintf("[%s]Cannot handle %s in query context", msg.Txid, msg.Type.String()))
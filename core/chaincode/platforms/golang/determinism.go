@@ -0,0 +1,220 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
+
+// Finding is a construct of a Go chaincode that can make validating peers
+// reach different results when they execute the same transaction
+type Finding struct {
+	Position token.Position
+	Message  string
+}
+
+func (finding Finding) String() string {
+	return fmt.Sprintf("%s: %s", finding.Position, finding.Message)
+}
+
+// wallClockFuncs are the functions of package time that depend on the clock of the peer
+var wallClockFuncs = map[string]bool{
+	"Now": true, "Since": true, "Until": true, "Sleep": true, "Tick": true,
+	"After": true, "AfterFunc": true, "NewTimer": true, "NewTicker": true,
+}
+
+// ioPackages are the packages whose every use does network or file IO
+var ioPackages = map[string]bool{
+	"net": true, "net/http": true, "net/rpc": true, "net/smtp": true,
+	"io/ioutil": true, "os/exec": true, "syscall": true,
+}
+
+// osAllowed are the members of package os that do not depend on the peer
+var osAllowed = map[string]bool{"Exit": true, "Args": true, "Stdout": true, "Stderr": true}
+
+// stateWriteMethods are the stub methods that change the state or the result of a transaction
+var stateWriteMethods = map[string]bool{
+	"PutState": true, "DelState": true, "PutStateMultiple": true,
+	"CreateTable": true, "AlterTable": true, "DeleteTable": true,
+	"InsertRow": true, "ReplaceRow": true, "DeleteRow": true,
+	"SetEvent": true, "InvokeChaincode": true, "DeclareIndex": true, "DropIndex": true,
+}
+
+// CheckDeterminism reports the constructs of the Go chaincode in dir, tests
+// excluded, that can make its executions diverge: wall-clock time, random
+// numbers, goroutines, network and file IO, and state writes in loops over
+// maps, whose iteration order is random. Only the package itself is checked,
+// not the packages it imports.
+func CheckDeterminism(dir string) ([]Finding, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing chaincode in %s: %s", dir, err)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("No Go files found in %s", dir)
+	}
+
+	var findings []Finding
+	for _, pkg := range pkgs {
+		var files []*ast.File
+		for _, file := range pkg.Files {
+			files = append(files, file)
+		}
+		findings = append(findings, checkPackage(fset, files)...)
+	}
+	sort.Sort(findingsByPosition(findings))
+	return findings, nil
+}
+
+func checkPackage(fset *token.FileSet, files []*ast.File) []Finding {
+	// Type checking is only needed to tell maps apart from other ranged
+	// values. Imported packages are left empty, so the errors caused by
+	// their use are expected and ignored.
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	conf := types.Config{
+		Importer: emptyImporter{},
+		Error:    func(error) {},
+	}
+	conf.Check("", fset, files, info)
+
+	checker := &determinismChecker{fset: fset, info: info}
+	for _, file := range files {
+		ast.Inspect(file, checker.inspect)
+	}
+	return checker.findings
+}
+
+type determinismChecker struct {
+	fset     *token.FileSet
+	info     *types.Info
+	findings []Finding
+}
+
+func (checker *determinismChecker) report(node ast.Node, format string, args ...interface{}) {
+	checker.findings = append(checker.findings, Finding{
+		Position: checker.fset.Position(node.Pos()),
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (checker *determinismChecker) inspect(node ast.Node) bool {
+	switch n := node.(type) {
+	case *ast.GoStmt:
+		checker.report(n, "goroutine: the order in which goroutines run differs between peers")
+	case *ast.SelectorExpr:
+		checker.checkSelector(n)
+	case *ast.RangeStmt:
+		if checker.isMap(n.X) {
+			if write := findStateWrite(n.Body); write != nil {
+				checker.report(n, "map iteration: %s is called in a loop over a map, whose order is random", write.Sel.Name)
+			}
+		}
+	}
+	return true
+}
+
+func (checker *determinismChecker) checkSelector(selector *ast.SelectorExpr) {
+	ident, ok := selector.X.(*ast.Ident)
+	if !ok {
+		return
+	}
+	pkgName, ok := checker.info.Uses[ident].(*types.PkgName)
+	if !ok {
+		return
+	}
+	importPath := pkgName.Imported().Path()
+	member := selector.Sel.Name
+	switch {
+	case importPath == "time" && wallClockFuncs[member]:
+		checker.report(selector, "wall-clock time: time.%s differs between peers, use the transaction timestamp instead", member)
+	case importPath == "math/rand" || importPath == "crypto/rand":
+		checker.report(selector, "random numbers: %s.%s differs between peers", pkgName.Name(), member)
+	case ioPackages[importPath] || (importPath == "os" && !osAllowed[member]):
+		checker.report(selector, "network or file IO: %s.%s depends on the peer it runs on", pkgName.Name(), member)
+	}
+}
+
+func (checker *determinismChecker) isMap(expr ast.Expr) bool {
+	t := checker.info.TypeOf(expr)
+	if t == nil {
+		return false
+	}
+	_, ok := t.Underlying().(*types.Map)
+	return ok
+}
+
+// findStateWrite returns the first call to a state writing method in node
+func findStateWrite(node ast.Node) *ast.SelectorExpr {
+	var write *ast.SelectorExpr
+	ast.Inspect(node, func(n ast.Node) bool {
+		if write != nil {
+			return false
+		}
+		if call, ok := n.(*ast.CallExpr); ok {
+			if selector, ok := call.Fun.(*ast.SelectorExpr); ok && stateWriteMethods[selector.Sel.Name] {
+				write = selector
+			}
+		}
+		return true
+	})
+	return write
+}
+
+// emptyImporter imports every package as an empty package named after the
+// last element of its path
+type emptyImporter struct{}
+
+func (emptyImporter) Import(importPath string) (*types.Package, error) {
+	pkg := types.NewPackage(importPath, path.Base(importPath))
+	pkg.MarkComplete()
+	return pkg, nil
+}
+
+type findingsByPosition []Finding
+
+func (f findingsByPosition) Len() int      { return len(f) }
+func (f findingsByPosition) Swap(i, j int) { f[i], f[j] = f[j], f[i] }
+func (f findingsByPosition) Less(i, j int) bool {
+	if f[i].Position.Filename != f[j].Position.Filename {
+		return f[i].Position.Filename < f[j].Position.Filename
+	}
+	return f[i].Position.Offset < f[j].Position.Offset
+}
+
+// CheckDeterminism checks the Go chaincode of spec, which must be a local
+// path under the first element of GOPATH, see CheckDeterminism
+func (goPlatform *Platform) CheckDeterminism(spec *pb.ChaincodeSpec) ([]Finding, error) {
+	gopath := filepath.SplitList(os.Getenv("GOPATH"))[0]
+	return CheckDeterminism(filepath.Join(gopath, "src", spec.ChaincodeID.Path))
+}
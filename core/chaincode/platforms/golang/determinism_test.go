@@ -0,0 +1,115 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const nonDeterministicChaincode = `package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+type Chaincode struct {
+	balances map[string]int
+}
+
+func (t *Chaincode) Invoke(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	stub.PutState("time", []byte(time.Now().String()))
+	nonce := make([]byte, 8)
+	rand.Read(nonce)
+	config, _ := ioutil.ReadFile("/etc/config")
+	go stub.PutState("config", config)
+	for user, balance := range t.balances {
+		stub.PutState(user, []byte(fmt.Sprint(balance)))
+	}
+	var users []string
+	for user := range t.balances {
+		users = append(users, user)
+	}
+	sort.Strings(users)
+	for _, user := range users {
+		stub.PutState(user, nil)
+	}
+	ts, _ := stub.GetTxTimestamp()
+	return []byte(time.Unix(ts.Seconds, 0).String()), nil
+}
+
+func main() {
+	if err := shim.Start(new(Chaincode)); err != nil {
+		fmt.Printf("Error starting chaincode: %s", err)
+		os.Exit(1)
+	}
+	fmt.Println(os.Getenv("HOME"))
+}
+`
+
+func TestCheckDeterminism(t *testing.T) {
+	dir, err := ioutil.TempDir("", "determinism")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	if err = ioutil.WriteFile(filepath.Join(dir, "chaincode.go"), []byte(nonDeterministicChaincode), 0644); err != nil {
+		t.Fatalf("Error writing chaincode: %s", err)
+	}
+	// tests are not checked
+	if err = ioutil.WriteFile(filepath.Join(dir, "chaincode_test.go"), []byte("package main\n\nimport \"time\"\n\nvar start = time.Now()\n"), 0644); err != nil {
+		t.Fatalf("Error writing chaincode test: %s", err)
+	}
+
+	findings, err := CheckDeterminism(dir)
+	if err != nil {
+		t.Fatalf("Error checking chaincode: %s", err)
+	}
+	expected := []struct {
+		line    int
+		message string
+	}{
+		{19, "wall-clock time: time.Now"},
+		{21, "random numbers: rand.Read"},
+		{22, "network or file IO: ioutil.ReadFile"},
+		{23, "goroutine"},
+		{24, "map iteration: PutState"},
+		{44, "network or file IO: os.Getenv"},
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Expected %d findings, got %v", len(expected), findings)
+	}
+	for i, finding := range findings {
+		if finding.Position.Line != expected[i].line || !strings.HasPrefix(finding.Message, expected[i].message) {
+			t.Fatalf("Expected finding %d at line %d starting with %q, got %s", i, expected[i].line, expected[i].message, finding)
+		}
+	}
+
+	if _, err = CheckDeterminism(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("Expected an error checking a missing directory")
+	}
+}
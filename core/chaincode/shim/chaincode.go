@@ -25,13 +25,16 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/fabric/core/chaincode/shim/crypto/attr"
 	"github.com/hyperledger/fabric/core/chaincode/shim/crypto/ecdsa"
+	"github.com/hyperledger/fabric/core/chaincode/shim/richquery"
 	"github.com/hyperledger/fabric/core/comm"
 	pb "github.com/hyperledger/fabric/protos"
 	"github.com/op/go-logging"
@@ -230,6 +233,14 @@ func chatWithPeer(chaincodename string, stream PeerChaincodeStream, cc Chaincode
 	return err
 }
 
+// Error returns an error that Init, Invoke and Query can return to report
+// a failure with a code of the chaincode's choosing, such as "insufficient
+// balance". Unlike other errors, it reaches clients as a structured
+// pb.ChaincodeError rather than as a bare message.
+func Error(code int32, message string, details string) error {
+	return &pb.ChaincodeError{Code: code, Message: message, Details: details}
+}
+
 // -- init stub ---
 // ChaincodeInvocation functionality
 
@@ -269,6 +280,28 @@ func (stub *ChaincodeStub) QueryChaincode(chaincodeName string, args [][]byte) (
 	return handler.handleQueryChaincode(chaincodeName, args, stub.TxID)
 }
 
+// GetCallerChaincodeID returns the name of the chaincode that called this
+// one with InvokeChaincode or QueryChaincode, or "" if the transaction
+// called this chaincode directly. The peer rejects calls to a chaincode that
+// is already executing in the transaction and calls deeper than
+// chaincode.maxcalldepth.
+func (stub *ChaincodeStub) GetCallerChaincodeID() string {
+	callStack := stub.GetCallStack()
+	if len(callStack) == 0 {
+		return ""
+	}
+	return callStack[len(callStack)-1]
+}
+
+// GetCallStack returns the names of the chaincodes that called this one
+// in the current transaction, outermost first.
+func (stub *ChaincodeStub) GetCallStack() []string {
+	if stub.securityContext == nil {
+		return nil
+	}
+	return stub.securityContext.CallStack
+}
+
 // --------- State functions ----------
 
 // GetState returns the byte array value specified by the `key`.
@@ -286,6 +319,33 @@ func (stub *ChaincodeStub) DelState(key string) error {
 	return handler.handleDelState(key, stub.TxID)
 }
 
+// GetStateMultiple returns the values of keys, in the same order, in one
+// round trip to the peer. Keys without state get a nil value.
+func (stub *ChaincodeStub) GetStateMultiple(keys []string) ([][]byte, error) {
+	return handler.handleGetStateMultiple(keys, stub.TxID)
+}
+
+// PutStateMultiple writes the keys and values of kvs into the ledger in one
+// round trip to the peer.
+func (stub *ChaincodeStub) PutStateMultiple(kvs map[string][]byte) error {
+	return handler.handlePutStateMultiple(sortedPutStateInfo(kvs), stub.TxID)
+}
+
+// sortedPutStateInfo lists kvs in key order so the request is the same on
+// every peer.
+func sortedPutStateInfo(kvs map[string][]byte) []*pb.PutStateInfo {
+	keys := make([]string, 0, len(kvs))
+	for key := range kvs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	keysAndValues := make([]*pb.PutStateInfo, len(keys))
+	for i, key := range keys {
+		keysAndValues[i] = &pb.PutStateInfo{Key: key, Value: kvs[key]}
+	}
+	return keysAndValues
+}
+
 //ReadCertAttribute is used to read an specific attribute from the transaction certificate, *attributeName* is passed as input parameter to this function.
 // Example:
 //  attrValue,error:=stub.ReadCertAttribute("position")
@@ -331,8 +391,8 @@ type StateRangeQueryIterator struct {
 // RangeQueryState function can be invoked by a chaincode to query of a range
 // of keys in the state. Assuming the startKey and endKey are in lexical order,
 // an iterator will be returned that can be used to iterate over all keys
-// between the startKey and endKey, inclusive. The order in which keys are
-// returned by the iterator is random.
+// between the startKey and endKey, inclusive. Keys are returned in lexical
+// order.
 func (stub *ChaincodeStub) RangeQueryState(startKey, endKey string) (StateRangeQueryIteratorInterface, error) {
 	response, err := handler.handleRangeQueryState(startKey, endKey, stub.TxID)
 	if err != nil {
@@ -341,6 +401,134 @@ func (stub *ChaincodeStub) RangeQueryState(startKey, endKey string) (StateRangeQ
 	return &StateRangeQueryIterator{handler, stub.TxID, response, 0}, nil
 }
 
+// GetHistoryForKey returns the committed modifications of key, oldest first.
+// Each modification holds the ID, block number and timestamp of the
+// transaction that made it, and the value it wrote or whether it deleted the
+// key. Modifications made by the current transaction are not included. The
+// peer keeps no history for the blocks it got through state transfer or a
+// state snapshot import, so the modifications are complete only from the
+// returned block number on.
+func (stub *ChaincodeStub) GetHistoryForKey(key string) ([]*pb.KeyModification, uint64, error) {
+	return handler.handleGetHistoryForKey(key, stub.TxID)
+}
+
+// QueryState function can be invoked by a chaincode to run a rich query over
+// the JSON values in its state, for instance
+// {"selector":{"Integral":{"$gt":1000}},"sort":[{"Integral":"desc"}],"limit":10}.
+// The query is evaluated by the peer, see package richquery for its syntax.
+// An iterator will be returned that can be used to iterate over the selected
+// keys and values, in the order requested by the query. Values that are not
+// JSON objects are never selected.
+func (stub *ChaincodeStub) QueryState(query string) (StateRangeQueryIteratorInterface, error) {
+	response, err := handler.handleQueryState(query, stub.TxID)
+	if err != nil {
+		return nil, err
+	}
+	return &StateRangeQueryIterator{handler, stub.TxID, response, 0}, nil
+}
+
+// DeclareIndex declares an index on field for the rich queries of this
+// chaincode. Peers build the index when the declaration is committed and keep
+// it up to date, so that queries constraining field with an equality or a
+// range do not scan the whole state. Only numbers and strings are indexed.
+func (stub *ChaincodeStub) DeclareIndex(field string) error {
+	return declareIndex(stub, field)
+}
+
+// DropIndex removes the index declared on field.
+func (stub *ChaincodeStub) DropIndex(field string) error {
+	return dropIndex(stub, field)
+}
+
+func declareIndex(stub tableStub, field string) error {
+	key, err := richquery.IndexDeclarationKey(field)
+	if err != nil {
+		return err
+	}
+	return stub.PutState(key, []byte(field))
+}
+
+func dropIndex(stub tableStub, field string) error {
+	key, err := richquery.IndexDeclarationKey(field)
+	if err != nil {
+		return err
+	}
+	return stub.DelState(key)
+}
+
+// compositeKeyNamespace starts every composite key, so composite keys never
+// collide with simple keys, and separates its parts.
+const compositeKeyNamespace = "\x00"
+
+// CreateCompositeKey combines objectType and attributes into a single key.
+// Keys sharing an objectType and leading attributes sort next to each other
+// and can be found with PartialCompositeKeyQuery.
+func (stub *ChaincodeStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	return createCompositeKey(objectType, attributes)
+}
+
+// SplitCompositeKey splits a key made by CreateCompositeKey back into its
+// objectType and attributes.
+func (stub *ChaincodeStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	return splitCompositeKey(compositeKey)
+}
+
+// PartialCompositeKeyQuery returns the composite keys of objectType whose
+// attributes start with keys, in lexical order.
+func (stub *ChaincodeStub) PartialCompositeKeyQuery(objectType string, keys []string) (StateRangeQueryIteratorInterface, error) {
+	return partialCompositeKeyQuery(stub, objectType, keys)
+}
+
+func validateCompositeKeyAttribute(attribute string) error {
+	if !utf8.ValidString(attribute) {
+		return fmt.Errorf("Composite key part %q is not valid UTF-8", attribute)
+	}
+	for _, r := range attribute {
+		if r == 0 || r == utf8.MaxRune {
+			return fmt.Errorf("Composite key part %q contains U+%04X, which is reserved", attribute, r)
+		}
+	}
+	return nil
+}
+
+func createCompositeKey(objectType string, attributes []string) (string, error) {
+	if objectType == "" {
+		return "", errors.New("Composite key needs an object type")
+	}
+	if err := validateCompositeKeyAttribute(objectType); err != nil {
+		return "", err
+	}
+	key := compositeKeyNamespace + objectType + compositeKeyNamespace
+	for _, attribute := range attributes {
+		if err := validateCompositeKeyAttribute(attribute); err != nil {
+			return "", err
+		}
+		key += attribute + compositeKeyNamespace
+	}
+	return key, nil
+}
+
+func splitCompositeKey(compositeKey string) (string, []string, error) {
+	if !strings.HasPrefix(compositeKey, compositeKeyNamespace) || !strings.HasSuffix(compositeKey, compositeKeyNamespace) || len(compositeKey) < 3 {
+		return "", nil, fmt.Errorf("%q is not a composite key", compositeKey)
+	}
+	parts := strings.Split(compositeKey[1:len(compositeKey)-1], compositeKeyNamespace)
+	if parts[0] == "" {
+		return "", nil, fmt.Errorf("%q is not a composite key", compositeKey)
+	}
+	return parts[0], parts[1:], nil
+}
+
+// partialCompositeKeyQuery ranges from the key made of objectType and keys up
+// to that key followed by utf8.MaxRune, which no attribute may contain.
+func partialCompositeKeyQuery(stub ChaincodeStubInterface, objectType string, keys []string) (StateRangeQueryIteratorInterface, error) {
+	startKey, err := createCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, err
+	}
+	return stub.RangeQueryState(startKey, startKey+string(utf8.MaxRune))
+}
+
 // HasNext returns true if the range query iterator contains additional keys
 // and values.
 func (iter *StateRangeQueryIterator) HasNext() bool {
@@ -403,10 +591,23 @@ var (
 	ErrTableNotFound = errors.New("chaincode: Table not found")
 )
 
+// tableStub is the state access tables are built on. Tables are implemented
+// once against it and shared by ChaincodeStub and MockStub.
+type tableStub interface {
+	GetState(key string) ([]byte, error)
+	PutState(key string, value []byte) error
+	DelState(key string) error
+	RangeQueryState(startKey, endKey string) (StateRangeQueryIteratorInterface, error)
+}
+
 // CreateTable creates a new table given the table name and column definitions
 func (stub *ChaincodeStub) CreateTable(name string, columnDefinitions []*ColumnDefinition) error {
+	return createTable(stub, name, columnDefinitions)
+}
+
+func createTable(stub tableStub, name string, columnDefinitions []*ColumnDefinition) error {
 
-	_, err := stub.getTable(name)
+	_, err := getTable(stub, name)
 	if err == nil {
 		return fmt.Errorf("CreateTable operation failed. Table %s already exists.", name)
 	}
@@ -422,29 +623,9 @@ func (stub *ChaincodeStub) CreateTable(name string, columnDefinitions []*ColumnD
 	nameMap := make(map[string]bool)
 	for i, definition := range columnDefinitions {
 
-		// Check name
-		if definition == nil {
-			return fmt.Errorf("Column definition %d is invalid. Definition must not be nil.", i)
-		}
-		if len(definition.Name) == 0 {
-			return fmt.Errorf("Column definition %d is invalid. Name must be 1 or more characters.", i)
-		}
-		if _, exists := nameMap[definition.Name]; exists {
-			return fmt.Errorf("Invalid table. Table contains duplicate column name '%s'.", definition.Name)
-		}
-		nameMap[definition.Name] = true
-
-		// Check type
-		switch definition.Type {
-		case ColumnDefinition_STRING:
-		case ColumnDefinition_INT32:
-		case ColumnDefinition_INT64:
-		case ColumnDefinition_UINT32:
-		case ColumnDefinition_UINT64:
-		case ColumnDefinition_BYTES:
-		case ColumnDefinition_BOOL:
-		default:
-			return fmt.Errorf("Column definition %s does not have a valid type.", definition.Name)
+		err = validateColumnDefinition(i, definition, nameMap)
+		if err != nil {
+			return err
 		}
 
 		if definition.Key {
@@ -456,7 +637,7 @@ func (stub *ChaincodeStub) CreateTable(name string, columnDefinitions []*ColumnD
 		return errors.New("Inavlid table. One or more columns must be a key.")
 	}
 
-	table := &Table{name, columnDefinitions}
+	table := &Table{Name: name, ColumnDefinitions: columnDefinitions}
 	tableBytes, err := proto.Marshal(table)
 	if err != nil {
 		return fmt.Errorf("Error marshalling table: %s", err)
@@ -472,37 +653,215 @@ func (stub *ChaincodeStub) CreateTable(name string, columnDefinitions []*ColumnD
 	return nil
 }
 
+func validateColumnDefinition(i int, definition *ColumnDefinition, nameMap map[string]bool) error {
+
+	// Check name
+	if definition == nil {
+		return fmt.Errorf("Column definition %d is invalid. Definition must not be nil.", i)
+	}
+	if len(definition.Name) == 0 {
+		return fmt.Errorf("Column definition %d is invalid. Name must be 1 or more characters.", i)
+	}
+	if _, exists := nameMap[definition.Name]; exists {
+		return fmt.Errorf("Invalid table. Table contains duplicate column name '%s'.", definition.Name)
+	}
+	nameMap[definition.Name] = true
+
+	// Check type
+	switch definition.Type {
+	case ColumnDefinition_STRING:
+	case ColumnDefinition_INT32:
+	case ColumnDefinition_INT64:
+	case ColumnDefinition_UINT32:
+	case ColumnDefinition_UINT64:
+	case ColumnDefinition_BYTES:
+	case ColumnDefinition_BOOL:
+	default:
+		return fmt.Errorf("Column definition %s does not have a valid type.", definition.Name)
+	}
+
+	if definition.Key && (definition.Nullable || definition.Indexed) {
+		return fmt.Errorf("Column definition %s is invalid. Key columns can be neither nullable nor indexed.", definition.Name)
+	}
+	return nil
+}
+
+// TableAlteration lists the changes AlterTable makes to a table. Added columns
+// must not be keys and are made nullable, as the rows already in the table
+// have no value for them. Key columns can be neither dropped nor indexed.
+type TableAlteration struct {
+	AddColumns  []*ColumnDefinition
+	DropColumns []string
+	AddIndexes  []string
+	DropIndexes []string
+}
+
+// AlterTable adds and drops columns and indexes of the specified table.
+// Rows are not rewritten when columns are added or dropped: they are migrated
+// to the current columns when read, and stored with them when next inserted
+// or replaced. Adding an index indexes the rows already in the table.
+func (stub *ChaincodeStub) AlterTable(tableName string, alteration TableAlteration) error {
+	return alterTable(stub, tableName, alteration)
+}
+
+func alterTable(stub tableStub, tableName string, alteration TableAlteration) error {
+
+	table, err := getTable(stub, tableName)
+	if err != nil {
+		return err
+	}
+
+	// Drop columns. The remaining definitions are copied so that changing
+	// their indexes leaves the previous schema untouched.
+	dropped := make(map[string]bool)
+	var droppedIndexes []string
+	for _, name := range alteration.DropColumns {
+		definition := findColumnDefinition(table.ColumnDefinitions, name)
+		if definition == nil || dropped[name] {
+			return fmt.Errorf("AlterTable operation failed. Table %s has no column %s.", tableName, name)
+		}
+		if definition.Key {
+			return fmt.Errorf("AlterTable operation failed. Key column %s cannot be dropped.", name)
+		}
+		if definition.Indexed {
+			droppedIndexes = append(droppedIndexes, name)
+		}
+		dropped[name] = true
+	}
+	nameMap := make(map[string]bool)
+	var columnDefinitions []*ColumnDefinition
+	for _, definition := range table.ColumnDefinitions {
+		if !dropped[definition.Name] {
+			remaining := *definition
+			columnDefinitions = append(columnDefinitions, &remaining)
+			nameMap[definition.Name] = true
+		}
+	}
+
+	// Add columns
+	for i, definition := range alteration.AddColumns {
+		err = validateColumnDefinition(i, definition, nameMap)
+		if err != nil {
+			return fmt.Errorf("AlterTable operation failed. %s", err)
+		}
+		if definition.Key {
+			return fmt.Errorf("AlterTable operation failed. Key column %s cannot be added.", definition.Name)
+		}
+		if dropped[definition.Name] {
+			return fmt.Errorf("AlterTable operation failed. Column %s cannot be dropped and added at once.", definition.Name)
+		}
+		added := *definition
+		added.Nullable = true
+		columnDefinitions = append(columnDefinitions, &added)
+	}
+
+	// Add and drop indexes
+	for _, name := range alteration.AddIndexes {
+		definition := findColumnDefinition(columnDefinitions, name)
+		if definition == nil {
+			return fmt.Errorf("AlterTable operation failed. Table %s has no column %s.", tableName, name)
+		}
+		if definition.Key || definition.Indexed {
+			return fmt.Errorf("AlterTable operation failed. Column %s is a key or already indexed.", name)
+		}
+		definition.Indexed = true
+	}
+	for _, name := range alteration.DropIndexes {
+		definition := findColumnDefinition(columnDefinitions, name)
+		if definition == nil || !definition.Indexed {
+			return fmt.Errorf("AlterTable operation failed. Column %s is not indexed.", name)
+		}
+		definition.Indexed = false
+		droppedIndexes = append(droppedIndexes, name)
+	}
+
+	if len(alteration.AddColumns) > 0 || len(alteration.DropColumns) > 0 {
+		table.PreviousSchemas = append(table.PreviousSchemas,
+			&TableSchema{Version: table.Version, ColumnDefinitions: table.ColumnDefinitions})
+		table.Version++
+	}
+	table.ColumnDefinitions = columnDefinitions
+
+	tableBytes, err := proto.Marshal(table)
+	if err != nil {
+		return fmt.Errorf("Error marshalling table: %s", err)
+	}
+	tableNameKey, err := getTableNameKey(tableName)
+	if err != nil {
+		return fmt.Errorf("Error creating table key: %s", err)
+	}
+	err = stub.PutState(tableNameKey, tableBytes)
+	if err != nil {
+		return fmt.Errorf("Error updating table in state: %s", err)
+	}
+
+	for _, name := range droppedIndexes {
+		indexKey, err := buildIndexKeyString(tableName, name, nil)
+		if err != nil {
+			return err
+		}
+		err = deleteKeyRange(stub, indexKey+"0", indexKey+":")
+		if err != nil {
+			return fmt.Errorf("Error dropping index %s: %s", name, err)
+		}
+	}
+	if len(alteration.AddIndexes) > 0 {
+		err = indexRows(stub, table)
+		if err != nil {
+			return fmt.Errorf("Error building indexes: %s", err)
+		}
+	}
+
+	return nil
+}
+
 // GetTable returns the table for the specified table name or ErrTableNotFound
 // if the table does not exist.
 func (stub *ChaincodeStub) GetTable(tableName string) (*Table, error) {
-	return stub.getTable(tableName)
+	return getTable(stub, tableName)
 }
 
 // DeleteTable deletes an entire table and all associated rows.
 func (stub *ChaincodeStub) DeleteTable(tableName string) error {
+	return deleteTable(stub, tableName)
+}
+
+func deleteTable(stub tableStub, tableName string) error {
 	tableNameKey, err := getTableNameKey(tableName)
 	if err != nil {
 		return err
 	}
 
-	// Delete rows
-	iter, err := stub.RangeQueryState(tableNameKey+"1", tableNameKey+":")
+	// Delete rows and index entries
+	err = deleteKeyRange(stub, tableNameKey+"1", tableNameKey+":")
 	if err != nil {
 		return fmt.Errorf("Error deleting table: %s", err)
 	}
+	err = deleteKeyRange(stub, tableNameKey+indexKeySeparator, tableNameKey+indexKeyEnd)
+	if err != nil {
+		return fmt.Errorf("Error deleting table: %s", err)
+	}
+
+	return stub.DelState(tableNameKey)
+}
+
+func deleteKeyRange(stub tableStub, startKey, endKey string) error {
+	iter, err := stub.RangeQueryState(startKey, endKey)
+	if err != nil {
+		return err
+	}
 	defer iter.Close()
 	for iter.HasNext() {
 		key, _, err := iter.Next()
 		if err != nil {
-			return fmt.Errorf("Error deleting table: %s", err)
+			return err
 		}
 		err = stub.DelState(key)
 		if err != nil {
-			return fmt.Errorf("Error deleting table: %s", err)
+			return err
 		}
 	}
-
-	return stub.DelState(tableNameKey)
+	return nil
 }
 
 // InsertRow inserts a new row into the specified table.
@@ -512,7 +871,7 @@ func (stub *ChaincodeStub) DeleteTable(tableName string) error {
 // false and a TableNotFoundError if the specified table name does not exist.
 // false and an error if there is an unexpected error condition.
 func (stub *ChaincodeStub) InsertRow(tableName string, row Row) (bool, error) {
-	return stub.insertRowInternal(tableName, row, false)
+	return insertRowInternal(stub, tableName, row, false)
 }
 
 // ReplaceRow updates the row in the specified table.
@@ -522,11 +881,15 @@ func (stub *ChaincodeStub) InsertRow(tableName string, row Row) (bool, error) {
 // flase and a TableNotFoundError if the specified table name does not exist.
 // false and an error if there is an unexpected error condition.
 func (stub *ChaincodeStub) ReplaceRow(tableName string, row Row) (bool, error) {
-	return stub.insertRowInternal(tableName, row, true)
+	return insertRowInternal(stub, tableName, row, true)
 }
 
 // GetRow fetches a row from the specified table for the given key.
 func (stub *ChaincodeStub) GetRow(tableName string, key []Column) (Row, error) {
+	return getRow(stub, tableName, key)
+}
+
+func getRow(stub tableStub, tableName string, key []Column) (Row, error) {
 
 	var row Row
 
@@ -545,7 +908,14 @@ func (stub *ChaincodeStub) GetRow(tableName string, key []Column) (Row, error) {
 		return row, fmt.Errorf("Error unmarshalling row: %s", err)
 	}
 
-	return row, nil
+	if len(row.Columns) == 0 {
+		return row, nil
+	}
+	table, err := getTable(stub, tableName)
+	if err != nil {
+		return row, err
+	}
+	return migrateRow(table, row)
 
 }
 
@@ -556,13 +926,17 @@ func (stub *ChaincodeStub) GetRow(tableName string, key []Column) (Row, error) {
 // also be called with A only to return all rows that have A and any value
 // for C and D as their key.
 func (stub *ChaincodeStub) GetRows(tableName string, key []Column) (<-chan Row, error) {
+	return getRows(stub, tableName, key)
+}
+
+func getRows(stub tableStub, tableName string, key []Column) (<-chan Row, error) {
 
 	keyString, err := buildKeyString(tableName, key)
 	if err != nil {
 		return nil, err
 	}
 
-	table, err := stub.getTable(tableName)
+	table, err := getTable(stub, tableName)
 	if err != nil {
 		return nil, err
 	}
@@ -570,7 +944,7 @@ func (stub *ChaincodeStub) GetRows(tableName string, key []Column) (<-chan Row,
 	// Need to check for special case where table has a single column
 	if len(table.GetColumnDefinitions()) < 2 && len(key) > 0 {
 
-		row, err := stub.GetRow(tableName, key)
+		row, err := getRow(stub, tableName, key)
 		if err != nil {
 			return nil, err
 		}
@@ -586,27 +960,32 @@ func (stub *ChaincodeStub) GetRows(tableName string, key []Column) (<-chan Row,
 	if err != nil {
 		return nil, fmt.Errorf("Error fetching rows: %s", err)
 	}
-	defer iter.Close()
 
 	rows := make(chan Row)
 
+	// the iterator is drained by the goroutine, so it is closed there
 	go func() {
+		defer close(rows)
+		defer iter.Close()
 		for iter.HasNext() {
 			_, rowBytes, err := iter.Next()
 			if err != nil {
-				close(rows)
+				return
 			}
 
 			var row Row
 			err = proto.Unmarshal(rowBytes, &row)
 			if err != nil {
-				close(rows)
+				return
+			}
+			row, err = migrateRow(table, row)
+			if err != nil {
+				return
 			}
 
 			rows <- row
 
 		}
-		close(rows)
 	}()
 
 	return rows, nil
@@ -615,12 +994,31 @@ func (stub *ChaincodeStub) GetRows(tableName string, key []Column) (<-chan Row,
 
 // DeleteRow deletes the row for the given key from the specified table.
 func (stub *ChaincodeStub) DeleteRow(tableName string, key []Column) error {
+	return deleteRow(stub, tableName, key)
+}
+
+func deleteRow(stub tableStub, tableName string, key []Column) error {
 
 	keyString, err := buildKeyString(tableName, key)
 	if err != nil {
 		return err
 	}
 
+	table, err := getTable(stub, tableName)
+	if err != nil && err != ErrTableNotFound {
+		return err
+	}
+	if table != nil {
+		oldRow, err := fetchRow(stub, table, keyString)
+		if err != nil {
+			return err
+		}
+		err = updateIndexEntries(stub, table, keyString, oldRow, nil)
+		if err != nil {
+			return fmt.Errorf("DeleteRow operation error. Error updating indexes: %s", err)
+		}
+	}
+
 	err = stub.DelState(keyString)
 	if err != nil {
 		return fmt.Errorf("DeleteRow operation error. Error deleting row: %s", err)
@@ -629,6 +1027,75 @@ func (stub *ChaincodeStub) DeleteRow(tableName string, key []Column) error {
 	return nil
 }
 
+// GetRowsByIndex returns the rows of the specified table whose indexed
+// column columnName holds value.
+func (stub *ChaincodeStub) GetRowsByIndex(tableName string, columnName string, value Column) (<-chan Row, error) {
+	return getRowsByIndex(stub, tableName, columnName, value)
+}
+
+func getRowsByIndex(stub tableStub, tableName string, columnName string, value Column) (<-chan Row, error) {
+
+	table, err := getTable(stub, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	column := -1
+	for i, definition := range table.ColumnDefinitions {
+		if definition.Name == columnName && definition.Indexed {
+			column = i
+		}
+	}
+	if column < 0 {
+		return nil, fmt.Errorf("Column '%s' of table '%s' is not indexed.", columnName, tableName)
+	}
+	definition := table.ColumnDefinitions[column]
+	if !columnHasType(&value, definition.Type) {
+		return nil, fmt.Errorf("The type for table '%s', column '%s' is '%s', but the value does not match.",
+			tableName, columnName, definition.Type)
+	}
+
+	indexKey, err := buildIndexKeyString(tableName, columnName, &value)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := stub.RangeQueryState(indexKey+"0", indexKey+":")
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching rows: %s", err)
+	}
+	defer iter.Close()
+
+	// Each index entry requires fetching its row, so the rows are all fetched
+	// here rather than by a goroutine interleaving its state requests with
+	// those of the caller
+	var matches []Row
+	for iter.HasNext() {
+		_, rowKey, err := iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("Error fetching rows: %s", err)
+		}
+		row, err := fetchRow(stub, table, string(rowKey))
+		if err != nil {
+			return nil, err
+		}
+		// An entry whose row no longer holds the value is never returned
+		if row != nil && columnKeyString(row.Columns[column]) == columnKeyString(&value) {
+			matches = append(matches, *row)
+		}
+	}
+
+	rows := make(chan Row)
+	go func() {
+		defer close(rows)
+		for _, row := range matches {
+			rows <- row
+		}
+	}()
+
+	return rows, nil
+}
+
 // VerifySignature verifies the transaction signature and returns `true` if
 // correct and `false` otherwise
 func (stub *ChaincodeStub) VerifySignature(certificate, signature, message []byte) (bool, error) {
@@ -667,7 +1134,7 @@ func (stub *ChaincodeStub) GetTxTimestamp() (*timestamp.Timestamp, error) {
 	return stub.securityContext.TxTimestamp, nil
 }
 
-func (stub *ChaincodeStub) getTable(tableName string) (*Table, error) {
+func getTable(stub tableStub, tableName string) (*Table, error) {
 
 	tableName, err := getTableNameKey(tableName)
 	if err != nil {
@@ -719,28 +1186,7 @@ func buildKeyString(tableName string, keys []Column) (string, error) {
 	keyBuffer.WriteString(tableNameKey)
 
 	for _, key := range keys {
-
-		var keyString string
-		switch key.Value.(type) {
-		case *Column_String_:
-			keyString = key.GetString_()
-		case *Column_Int32:
-			// b := make([]byte, 4)
-			// binary.LittleEndian.PutUint32(b, uint32(key.GetInt32()))
-			// keyBuffer.Write(b)
-			keyString = strconv.FormatInt(int64(key.GetInt32()), 10)
-		case *Column_Int64:
-			keyString = strconv.FormatInt(key.GetInt64(), 10)
-		case *Column_Uint32:
-			keyString = strconv.FormatUint(uint64(key.GetUint32()), 10)
-		case *Column_Uint64:
-			keyString = strconv.FormatUint(key.GetUint64(), 10)
-		case *Column_Bytes:
-			keyString = string(key.GetBytes())
-		case *Column_Bool:
-			keyString = strconv.FormatBool(key.GetBool())
-		}
-
+		keyString := columnKeyString(&key)
 		keyBuffer.WriteString(strconv.Itoa(len(keyString)))
 		keyBuffer.WriteString(keyString)
 	}
@@ -748,6 +1194,235 @@ func buildKeyString(tableName string, keys []Column) (string, error) {
 	return keyBuffer.String(), nil
 }
 
+func columnKeyString(key *Column) string {
+	var keyString string
+	switch key.Value.(type) {
+	case *Column_String_:
+		keyString = key.GetString_()
+	case *Column_Int32:
+		// b := make([]byte, 4)
+		// binary.LittleEndian.PutUint32(b, uint32(key.GetInt32()))
+		// keyBuffer.Write(b)
+		keyString = strconv.FormatInt(int64(key.GetInt32()), 10)
+	case *Column_Int64:
+		keyString = strconv.FormatInt(key.GetInt64(), 10)
+	case *Column_Uint32:
+		keyString = strconv.FormatUint(uint64(key.GetUint32()), 10)
+	case *Column_Uint64:
+		keyString = strconv.FormatUint(key.GetUint64(), 10)
+	case *Column_Bytes:
+		keyString = string(key.GetBytes())
+	case *Column_Bool:
+		keyString = strconv.FormatBool(key.GetBool())
+	}
+	return keyString
+}
+
+// Index entries are stored under the table key followed by indexKeySeparator,
+// which never follows it in the key of a row, the indexed column name and
+// value, and the key of the row without the table key. The value of an entry
+// is the key of its row. The column name and value are preceded by their
+// length in indexKeyLengthFormat, whose fixed width keeps the prefix of one
+// name or value from matching the entries of another.
+const (
+	indexKeySeparator    = "#"
+	indexKeyEnd          = "$"
+	indexKeyLengthFormat = "%010d"
+)
+
+// buildIndexKeyString returns the prefix of the index entries of columnName
+// for value, or of all the entries of columnName if value is nil
+func buildIndexKeyString(tableName string, columnName string, value *Column) (string, error) {
+
+	var keyBuffer bytes.Buffer
+
+	tableNameKey, err := getTableNameKey(tableName)
+	if err != nil {
+		return "", err
+	}
+
+	keyBuffer.WriteString(tableNameKey)
+	keyBuffer.WriteString(indexKeySeparator)
+	keyBuffer.WriteString(fmt.Sprintf(indexKeyLengthFormat, len(columnName)))
+	keyBuffer.WriteString(columnName)
+	if value != nil {
+		valueString := columnKeyString(value)
+		keyBuffer.WriteString(fmt.Sprintf(indexKeyLengthFormat, len(valueString)))
+		keyBuffer.WriteString(valueString)
+	}
+
+	return keyBuffer.String(), nil
+}
+
+// updateIndexEntries replaces the index entries for the values of oldRow by
+// those for the values of newRow, where both are the row stored under rowKey
+// in table before and after a change. Either row is nil if it does not exist.
+func updateIndexEntries(stub tableStub, table *Table, rowKey string, oldRow, newRow *Row) error {
+	tableNameKey, err := getTableNameKey(table.Name)
+	if err != nil {
+		return err
+	}
+	entryKey := func(row *Row, i int) (string, error) {
+		if row == nil || row.Columns[i].Value == nil {
+			return "", nil
+		}
+		indexKey, err := buildIndexKeyString(table.Name, table.ColumnDefinitions[i].Name, row.Columns[i])
+		if err != nil {
+			return "", err
+		}
+		return indexKey + rowKey[len(tableNameKey):], nil
+	}
+
+	for i, definition := range table.ColumnDefinitions {
+		if !definition.Indexed {
+			continue
+		}
+		oldEntry, err := entryKey(oldRow, i)
+		if err != nil {
+			return err
+		}
+		newEntry, err := entryKey(newRow, i)
+		if err != nil {
+			return err
+		}
+		if oldEntry == newEntry {
+			continue
+		}
+		if oldEntry != "" {
+			err = stub.DelState(oldEntry)
+			if err != nil {
+				return err
+			}
+		}
+		if newEntry != "" {
+			err = stub.PutState(newEntry, []byte(rowKey))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// indexRows adds the index entries of every row of table. The rows are read
+// before any entry is written.
+func indexRows(stub tableStub, table *Table) error {
+	tableNameKey, err := getTableNameKey(table.Name)
+	if err != nil {
+		return err
+	}
+
+	iter, err := stub.RangeQueryState(tableNameKey+"1", tableNameKey+":")
+	if err != nil {
+		return err
+	}
+	var rowKeys []string
+	var rows []*Row
+	for iter.HasNext() {
+		rowKey, rowBytes, err := iter.Next()
+		if err != nil {
+			iter.Close()
+			return err
+		}
+		row, err := unmarshalRow(table, rowBytes)
+		if err != nil {
+			iter.Close()
+			return err
+		}
+		rowKeys = append(rowKeys, rowKey)
+		rows = append(rows, row)
+	}
+	iter.Close()
+
+	for i, row := range rows {
+		err = updateIndexEntries(stub, table, rowKeys[i], nil, row)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchRow returns the row of table stored under rowKey, migrated to the
+// current columns of table, or nil if there is no such row
+func fetchRow(stub tableStub, table *Table, rowKey string) (*Row, error) {
+	rowBytes, err := stub.GetState(rowKey)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching row for key %s: %s", rowKey, err)
+	}
+	if rowBytes == nil {
+		return nil, nil
+	}
+	return unmarshalRow(table, rowBytes)
+}
+
+func unmarshalRow(table *Table, rowBytes []byte) (*Row, error) {
+	var row Row
+	err := proto.Unmarshal(rowBytes, &row)
+	if err != nil {
+		return nil, fmt.Errorf("Error unmarshalling row: %s", err)
+	}
+	row, err = migrateRow(table, row)
+	if err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// migrateRow returns row, written with the columns of an earlier version of
+// table, with the current columns of table. A column takes its value from the
+// row if it already existed when the row was written and has not been dropped
+// since; otherwise, the column has been added since and its value is null.
+func migrateRow(table *Table, row Row) (Row, error) {
+	if row.Version == table.Version {
+		return row, nil
+	}
+
+	schemas := make([]*TableSchema, 0, len(table.PreviousSchemas)+1)
+	schemas = append(schemas, table.PreviousSchemas...)
+	schemas = append(schemas, &TableSchema{Version: table.Version, ColumnDefinitions: table.ColumnDefinitions})
+	var rowSchema *TableSchema
+	for _, schema := range schemas {
+		if schema.Version == row.Version {
+			rowSchema = schema
+		}
+	}
+	if rowSchema == nil || len(row.Columns) != len(rowSchema.ColumnDefinitions) {
+		return row, fmt.Errorf("Row of table '%s' does not match any version of the table.", table.Name)
+	}
+
+	migrated := Row{Version: table.Version}
+	for _, definition := range table.ColumnDefinitions {
+		// The column existed when the row was written if every version since
+		// has a column of the same name and type
+		column := &Column{}
+		for i := len(schemas) - 1; i >= 0 && schemas[i].Version >= row.Version; i-- {
+			previous := findColumnDefinition(schemas[i].ColumnDefinitions, definition.Name)
+			if previous == nil || previous.Type != definition.Type {
+				break
+			}
+			if schemas[i] == rowSchema {
+				for j, rowDefinition := range rowSchema.ColumnDefinitions {
+					if rowDefinition.Name == definition.Name {
+						column = row.Columns[j]
+					}
+				}
+			}
+		}
+		migrated.Columns = append(migrated.Columns, column)
+	}
+	return migrated, nil
+}
+
+func findColumnDefinition(columnDefinitions []*ColumnDefinition, name string) *ColumnDefinition {
+	for _, definition := range columnDefinitions {
+		if definition.Name == name {
+			return definition
+		}
+	}
+	return nil
+}
+
 func getKeyAndVerifyRow(table Table, row Row) ([]Column, error) {
 
 	var keys []Column
@@ -759,27 +1434,15 @@ func getKeyAndVerifyRow(table Table, row Row) ([]Column, error) {
 
 	for i, column := range row.Columns {
 
-		// Check types
-		var expectedType bool
-		switch column.Value.(type) {
-		case *Column_String_:
-			expectedType = table.ColumnDefinitions[i].Type == ColumnDefinition_STRING
-		case *Column_Int32:
-			expectedType = table.ColumnDefinitions[i].Type == ColumnDefinition_INT32
-		case *Column_Int64:
-			expectedType = table.ColumnDefinitions[i].Type == ColumnDefinition_INT64
-		case *Column_Uint32:
-			expectedType = table.ColumnDefinitions[i].Type == ColumnDefinition_UINT32
-		case *Column_Uint64:
-			expectedType = table.ColumnDefinitions[i].Type == ColumnDefinition_UINT64
-		case *Column_Bytes:
-			expectedType = table.ColumnDefinitions[i].Type == ColumnDefinition_BYTES
-		case *Column_Bool:
-			expectedType = table.ColumnDefinitions[i].Type == ColumnDefinition_BOOL
-		default:
-			expectedType = false
+		// Check types. Nullable columns may hold no value.
+		if column == nil {
+			return keys, fmt.Errorf("Column '%s' of the row for table '%s' is nil.",
+				table.ColumnDefinitions[i].Name, table.Name)
+		}
+		if column.Value == nil && table.ColumnDefinitions[i].Nullable {
+			continue
 		}
-		if !expectedType {
+		if !columnHasType(column, table.ColumnDefinitions[i].Type) {
 			return keys, fmt.Errorf("The type for table '%s', column '%s' is '%s', but the column in the row does not match.",
 				table.Name, table.ColumnDefinitions[i].Name, table.ColumnDefinitions[i].Type)
 		}
@@ -793,19 +1456,25 @@ func getKeyAndVerifyRow(table Table, row Row) ([]Column, error) {
 	return keys, nil
 }
 
-func (stub *ChaincodeStub) isRowPresent(tableName string, key []Column) (bool, error) {
-	keyString, err := buildKeyString(tableName, key)
-	if err != nil {
-		return false, err
+func columnHasType(column *Column, columnType ColumnDefinition_Type) bool {
+	switch column.Value.(type) {
+	case *Column_String_:
+		return columnType == ColumnDefinition_STRING
+	case *Column_Int32:
+		return columnType == ColumnDefinition_INT32
+	case *Column_Int64:
+		return columnType == ColumnDefinition_INT64
+	case *Column_Uint32:
+		return columnType == ColumnDefinition_UINT32
+	case *Column_Uint64:
+		return columnType == ColumnDefinition_UINT64
+	case *Column_Bytes:
+		return columnType == ColumnDefinition_BYTES
+	case *Column_Bool:
+		return columnType == ColumnDefinition_BOOL
+	default:
+		return false
 	}
-	rowBytes, err := stub.GetState(keyString)
-	if err != nil {
-		return false, fmt.Errorf("Error fetching row for key %s: %s", keyString, err)
-	}
-	if rowBytes != nil {
-		return true, nil
-	}
-	return false, nil
 }
 
 // insertRowInternal inserts a new row into the specified table.
@@ -814,9 +1483,9 @@ func (stub *ChaincodeStub) isRowPresent(tableName string, key []Column) (bool, e
 // false and no error if a row already exists for the given key.
 // false and a TableNotFoundError if the specified table name does not exist.
 // false and an error if there is an unexpected error condition.
-func (stub *ChaincodeStub) insertRowInternal(tableName string, row Row, update bool) (bool, error) {
+func insertRowInternal(stub tableStub, tableName string, row Row, update bool) (bool, error) {
 
-	table, err := stub.getTable(tableName)
+	table, err := getTable(stub, tableName)
 	if err != nil {
 		return false, err
 	}
@@ -826,22 +1495,29 @@ func (stub *ChaincodeStub) insertRowInternal(tableName string, row Row, update b
 		return false, err
 	}
 
-	present, err := stub.isRowPresent(tableName, key)
+	keyString, err := buildKeyString(tableName, key)
 	if err != nil {
 		return false, err
 	}
+	oldRow, err := fetchRow(stub, table, keyString)
+	if err != nil {
+		return false, err
+	}
+	present := oldRow != nil
 	if (present && !update) || (!present && update) {
 		return false, nil
 	}
 
+	// Rows are always stored with the current version of the table
+	row.Version = table.Version
 	rowBytes, err := proto.Marshal(&row)
 	if err != nil {
 		return false, fmt.Errorf("Error marshalling row: %s", err)
 	}
 
-	keyString, err := buildKeyString(tableName, key)
+	err = updateIndexEntries(stub, table, keyString, oldRow, &row)
 	if err != nil {
-		return false, err
+		return false, fmt.Errorf("Error updating indexes of table %s: %s", tableName, err)
 	}
 	err = stub.PutState(keyString, rowBytes)
 	if err != nil {
@@ -66,6 +66,25 @@ func shorttxid(txid string) string {
 	return txid[0:8]
 }
 
+// newErrorMessage makes the ERROR or QUERY_ERROR message reporting err. An
+// error made with Error is attached as a structured error as well.
+func newErrorMessage(msgType pb.ChaincodeMessage_Type, txid string, err error) *pb.ChaincodeMessage {
+	msg := &pb.ChaincodeMessage{Type: msgType, Payload: []byte(err.Error()), Txid: txid}
+	if chaincodeError, ok := err.(*pb.ChaincodeError); ok {
+		msg.ChaincodeError = chaincodeError
+	}
+	return msg
+}
+
+// messageError returns the error reported by an ERROR or QUERY_ERROR
+// message, structured if the chaincode that failed returned one
+func messageError(msg *pb.ChaincodeMessage) error {
+	if msg.ChaincodeError != nil {
+		return msg.ChaincodeError
+	}
+	return errors.New(string(msg.Payload))
+}
+
 func (handler *Handler) serialSend(msg *pb.ChaincodeMessage) error {
 	handler.serialLock.Lock()
 	defer handler.serialLock.Unlock()
@@ -230,10 +249,10 @@ func (handler *Handler) handleInit(msg *pb.ChaincodeMessage) {
 		handler.deleteIsTransaction(msg.Txid)
 
 		if err != nil {
-			payload := []byte(err.Error())
 			// Send ERROR message to chaincode support and change state
 			chaincodeLogger.Errorf("[%s]Init failed. Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_ERROR)
-			nextStateMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid, ChaincodeEvent: stub.chaincodeEvent}
+			nextStateMsg = newErrorMessage(pb.ChaincodeMessage_ERROR, msg.Txid, err)
+			nextStateMsg.ChaincodeEvent = stub.chaincodeEvent
 			return
 		}
 
@@ -298,10 +317,10 @@ func (handler *Handler) handleTransaction(msg *pb.ChaincodeMessage) {
 		handler.deleteIsTransaction(msg.Txid)
 
 		if err != nil {
-			payload := []byte(err.Error())
 			// Send ERROR message to chaincode support and change state
 			chaincodeLogger.Errorf("[%s]Transaction execution failed. Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_ERROR)
-			nextStateMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid, ChaincodeEvent: stub.chaincodeEvent}
+			nextStateMsg = newErrorMessage(pb.ChaincodeMessage_ERROR, msg.Txid, err)
+			nextStateMsg.ChaincodeEvent = stub.chaincodeEvent
 			return
 		}
 
@@ -346,10 +365,9 @@ func (handler *Handler) handleQuery(msg *pb.ChaincodeMessage) {
 		handler.deleteIsTransaction(msg.Txid)
 
 		if err != nil {
-			payload := []byte(err.Error())
 			// Send ERROR message to chaincode support and change state
 			chaincodeLogger.Errorf("[%s]Query execution failed. Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_QUERY_ERROR)
-			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_QUERY_ERROR, Payload: payload, Txid: msg.Txid}
+			serialSendMsg = newErrorMessage(pb.ChaincodeMessage_QUERY_ERROR, msg.Txid, err)
 			return
 		}
 
@@ -580,6 +598,121 @@ func (handler *Handler) handleDelState(key string, txid string) error {
 	return errors.New("Incorrect chaincode message received")
 }
 
+// handleGetStateMultiple fetches the state of several keys from the ledger in one request.
+func (handler *Handler) handleGetStateMultiple(keys []string, txid string) ([][]byte, error) {
+	payloadBytes, err := proto.Marshal(&pb.GetStateMultiple{Keys: keys})
+	if err != nil {
+		return nil, errors.New("Failed to process get state multiple request")
+	}
+
+	// Create the channel on which to communicate the response from validating peer
+	respChan, uniqueReqErr := handler.createChannel(txid)
+	if uniqueReqErr != nil {
+		chaincodeLogger.Debug("Another state request pending for this Txid. Cannot process.")
+		return nil, uniqueReqErr
+	}
+
+	defer handler.deleteChannel(txid)
+
+	// Send GET_STATE_MULTIPLE message to validator chaincode support
+	msg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_STATE_MULTIPLE, Payload: payloadBytes, Txid: txid}
+	chaincodeLogger.Debugf("[%s]Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_GET_STATE_MULTIPLE)
+	if err = handler.serialSend(msg); err != nil {
+		chaincodeLogger.Errorf("[%s]error sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_GET_STATE_MULTIPLE)
+		return nil, errors.New("could not send msg")
+	}
+
+	// Wait on responseChannel for response
+	responseMsg, ok := handler.receiveChannel(respChan)
+	if !ok {
+		chaincodeLogger.Errorf("[%s]Received unexpected message type", shorttxid(responseMsg.Txid))
+		return nil, errors.New("Received unexpected message type")
+	}
+
+	if responseMsg.Type.String() == pb.ChaincodeMessage_RESPONSE.String() {
+		// Success response
+		chaincodeLogger.Debugf("[%s]Received %s. Successfully got state multiple", shorttxid(responseMsg.Txid), pb.ChaincodeMessage_RESPONSE)
+
+		response := &pb.GetStateMultipleResponse{}
+		if err = proto.Unmarshal(responseMsg.Payload, response); err != nil {
+			chaincodeLogger.Errorf("[%s]unmarshall error", shorttxid(responseMsg.Txid))
+			return nil, errors.New("Error unmarshalling GetStateMultipleResponse.")
+		}
+		if len(response.Values) != len(keys) {
+			return nil, fmt.Errorf("Expected %d values, got %d", len(keys), len(response.Values))
+		}
+		// Like GetState, keys without state get a nil value
+		for i, value := range response.Values {
+			if len(value) == 0 {
+				response.Values[i] = nil
+			}
+		}
+		return response.Values, nil
+	}
+	if responseMsg.Type.String() == pb.ChaincodeMessage_ERROR.String() {
+		// Error response
+		chaincodeLogger.Errorf("[%s]Received %s", shorttxid(responseMsg.Txid), pb.ChaincodeMessage_ERROR)
+		return nil, errors.New(string(responseMsg.Payload[:]))
+	}
+
+	// Incorrect chaincode message received
+	chaincodeLogger.Errorf("Incorrect chaincode message %s recieved. Expecting %s or %s", responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR)
+	return nil, errors.New("Incorrect chaincode message received")
+}
+
+// handlePutStateMultiple puts the state of several keys into the ledger in one request.
+func (handler *Handler) handlePutStateMultiple(keysAndValues []*pb.PutStateInfo, txid string) error {
+	// Check if this is a transaction
+	if !handler.isTransaction[txid] {
+		return errors.New("Cannot put state in query context")
+	}
+
+	payloadBytes, err := proto.Marshal(&pb.PutStateMultiple{KeysAndValues: keysAndValues})
+	if err != nil {
+		return errors.New("Failed to process put state multiple request")
+	}
+
+	// Create the channel on which to communicate the response from validating peer
+	respChan, uniqueReqErr := handler.createChannel(txid)
+	if uniqueReqErr != nil {
+		chaincodeLogger.Errorf("[%s]Another state request pending for this Txid. Cannot process.", shorttxid(txid))
+		return uniqueReqErr
+	}
+
+	defer handler.deleteChannel(txid)
+
+	// Send PUT_STATE_MULTIPLE message to validator chaincode support
+	msg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_PUT_STATE_MULTIPLE, Payload: payloadBytes, Txid: txid}
+	chaincodeLogger.Debugf("[%s]Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_PUT_STATE_MULTIPLE)
+	if err = handler.serialSend(msg); err != nil {
+		chaincodeLogger.Errorf("[%s]error sending %s %s", shorttxid(msg.Txid), pb.ChaincodeMessage_PUT_STATE_MULTIPLE, err)
+		return errors.New("could not send msg")
+	}
+
+	// Wait on responseChannel for response
+	responseMsg, ok := handler.receiveChannel(respChan)
+	if !ok {
+		chaincodeLogger.Errorf("[%s]Received unexpected message type", shorttxid(msg.Txid))
+		return errors.New("Received unexpected message type")
+	}
+
+	if responseMsg.Type.String() == pb.ChaincodeMessage_RESPONSE.String() {
+		// Success response
+		chaincodeLogger.Debugf("[%s]Received %s. Successfully updated state of %d keys", shorttxid(responseMsg.Txid), pb.ChaincodeMessage_RESPONSE, len(keysAndValues))
+		return nil
+	}
+
+	if responseMsg.Type.String() == pb.ChaincodeMessage_ERROR.String() {
+		// Error response
+		chaincodeLogger.Errorf("[%s]Received %s. Payload: %s", shorttxid(responseMsg.Txid), pb.ChaincodeMessage_ERROR, responseMsg.Payload)
+		return errors.New(string(responseMsg.Payload[:]))
+	}
+
+	// Incorrect chaincode message received
+	chaincodeLogger.Errorf("[%s]Incorrect chaincode message %s received. Expecting %s or %s", shorttxid(responseMsg.Txid), responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR)
+	return errors.New("Incorrect chaincode message received")
+}
+
 func (handler *Handler) handleRangeQueryState(startKey, endKey string, txid string) (*pb.RangeQueryStateResponse, error) {
 	// Create the channel on which to communicate the response from validating peer
 	respChan, uniqueReqErr := handler.createChannel(txid)
@@ -634,6 +767,112 @@ func (handler *Handler) handleRangeQueryState(startKey, endKey string, txid stri
 	return nil, errors.New("Incorrect chaincode message received")
 }
 
+func (handler *Handler) handleGetHistoryForKey(key string, txid string) ([]*pb.KeyModification, uint64, error) {
+	payloadBytes, err := proto.Marshal(&pb.GetHistoryForKey{Key: key})
+	if err != nil {
+		return nil, 0, errors.New("Failed to process get history request")
+	}
+
+	// Create the channel on which to communicate the response from validating peer
+	respChan, uniqueReqErr := handler.createChannel(txid)
+	if uniqueReqErr != nil {
+		chaincodeLogger.Debug("Another state request pending for this Txid. Cannot process.")
+		return nil, 0, uniqueReqErr
+	}
+
+	defer handler.deleteChannel(txid)
+
+	// Send GET_HISTORY_FOR_KEY message to validator chaincode support
+	msg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_HISTORY_FOR_KEY, Payload: payloadBytes, Txid: txid}
+	chaincodeLogger.Debugf("[%s]Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_GET_HISTORY_FOR_KEY)
+	if err = handler.serialSend(msg); err != nil {
+		chaincodeLogger.Errorf("[%s]error sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_GET_HISTORY_FOR_KEY)
+		return nil, 0, errors.New("could not send msg")
+	}
+
+	// Wait on responseChannel for response
+	responseMsg, ok := handler.receiveChannel(respChan)
+	if !ok {
+		chaincodeLogger.Errorf("[%s]Received unexpected message type", shorttxid(txid))
+		return nil, 0, errors.New("Received unexpected message type")
+	}
+
+	if responseMsg.Type.String() == pb.ChaincodeMessage_RESPONSE.String() {
+		// Success response
+		chaincodeLogger.Debugf("[%s]Received %s. Successfully got key history", shorttxid(responseMsg.Txid), pb.ChaincodeMessage_RESPONSE)
+
+		response := &pb.GetHistoryForKeyResponse{}
+		if err = proto.Unmarshal(responseMsg.Payload, response); err != nil {
+			chaincodeLogger.Errorf("[%s]unmarshall error", shorttxid(responseMsg.Txid))
+			return nil, 0, errors.New("Error unmarshalling GetHistoryForKeyResponse.")
+		}
+		return response.Modifications, response.CompleteFromBlock, nil
+	}
+	if responseMsg.Type.String() == pb.ChaincodeMessage_ERROR.String() {
+		// Error response
+		chaincodeLogger.Errorf("[%s]Received %s", shorttxid(responseMsg.Txid), pb.ChaincodeMessage_ERROR)
+		return nil, 0, errors.New(string(responseMsg.Payload[:]))
+	}
+
+	// Incorrect chaincode message received
+	chaincodeLogger.Errorf("Incorrect chaincode message %s recieved. Expecting %s or %s", responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR)
+	return nil, 0, errors.New("Incorrect chaincode message received")
+}
+
+func (handler *Handler) handleQueryState(query string, txid string) (*pb.RangeQueryStateResponse, error) {
+	// Create the channel on which to communicate the response from validating peer
+	respChan, uniqueReqErr := handler.createChannel(txid)
+	if uniqueReqErr != nil {
+		chaincodeLogger.Debugf("[%s]Another state request pending for this Txid. Cannot process.", shorttxid(txid))
+		return nil, uniqueReqErr
+	}
+
+	defer handler.deleteChannel(txid)
+
+	// Send QUERY_STATE message to validator chaincode support
+	payload := &pb.QueryState{Query: query}
+	payloadBytes, err := proto.Marshal(payload)
+	if err != nil {
+		return nil, errors.New("Failed to process query state request")
+	}
+	msg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_QUERY_STATE, Payload: payloadBytes, Txid: txid}
+	chaincodeLogger.Debugf("[%s]Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_QUERY_STATE)
+	if err = handler.serialSend(msg); err != nil {
+		chaincodeLogger.Errorf("[%s]error sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_QUERY_STATE)
+		return nil, errors.New("could not send msg")
+	}
+
+	// Wait on responseChannel for response
+	responseMsg, ok := handler.receiveChannel(respChan)
+	if !ok {
+		chaincodeLogger.Errorf("[%s]Received unexpected message type", txid)
+		return nil, errors.New("Received unexpected message type")
+	}
+
+	if responseMsg.Type.String() == pb.ChaincodeMessage_RESPONSE.String() {
+		// Success response
+		chaincodeLogger.Debugf("[%s]Received %s. Successfully got query results", shorttxid(responseMsg.Txid), pb.ChaincodeMessage_RESPONSE)
+
+		queryResponse := &pb.RangeQueryStateResponse{}
+		unmarshalErr := proto.Unmarshal(responseMsg.Payload, queryResponse)
+		if unmarshalErr != nil {
+			chaincodeLogger.Errorf("[%s]unmarshall error", shorttxid(responseMsg.Txid))
+			return nil, errors.New("Error unmarshalling RangeQueryStateResponse.")
+		}
+
+		return queryResponse, nil
+	}
+	if responseMsg.Type.String() == pb.ChaincodeMessage_ERROR.String() {
+		// Error response
+		chaincodeLogger.Errorf("[%s]Received %s", shorttxid(responseMsg.Txid), pb.ChaincodeMessage_ERROR)
+		return nil, errors.New(string(responseMsg.Payload[:]))
+	}
+
+	// Incorrect chaincode message received
+	chaincodeLogger.Errorf("Incorrect chaincode message %s recieved. Expecting %s or %s", responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR)
+	return nil, errors.New("Incorrect chaincode message received")
+}
+
 func (handler *Handler) handleRangeQueryStateNext(id, txid string) (*pb.RangeQueryStateResponse, error) {
 	// Create the channel on which to communicate the response from validating peer
 	respChan, uniqueReqErr := handler.createChannel(txid)
@@ -795,7 +1034,7 @@ func (handler *Handler) handleInvokeChaincode(chaincodeName string, args [][]byt
 			return respMsg.Payload, nil
 		}
 		chaincodeLogger.Errorf("[%s]Received %s. Error from chaincode", shorttxid(responseMsg.Txid), respMsg.Type.String())
-		return nil, errors.New(string(respMsg.Payload[:]))
+		return nil, messageError(respMsg)
 	}
 	if responseMsg.Type.String() == pb.ChaincodeMessage_ERROR.String() {
 		// Error response
@@ -854,7 +1093,7 @@ func (handler *Handler) handleQueryChaincode(chaincodeName string, args [][]byte
 			return respMsg.Payload, nil
 		}
 		chaincodeLogger.Errorf("[%s]Error from chaincode: %s", shorttxid(responseMsg.Txid), string(respMsg.Payload[:]))
-		return nil, errors.New(string(respMsg.Payload[:]))
+		return nil, messageError(respMsg)
 	}
 	if responseMsg.Type.String() == pb.ChaincodeMessage_ERROR.String() {
 		// Error response
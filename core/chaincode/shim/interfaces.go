@@ -20,6 +20,7 @@ package shim
 import (
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/fabric/core/chaincode/shim/crypto/attr"
+	pb "github.com/hyperledger/fabric/protos"
 )
 
 // Chaincode interface must be implemented by all chaincodes. The fabric runs
@@ -59,6 +60,15 @@ type ChaincodeStubInterface interface {
 	// create a new transaction message.
 	QueryChaincode(chaincodeName string, args [][]byte) ([]byte, error)
 
+	// GetCallerChaincodeID returns the name of the chaincode that called this
+	// one with InvokeChaincode or QueryChaincode, or "" if the transaction
+	// called this chaincode directly.
+	GetCallerChaincodeID() string
+
+	// GetCallStack returns the names of the chaincodes that called this one
+	// in the current transaction, outermost first.
+	GetCallStack() []string
+
 	// GetState returns the byte array value specified by the `key`.
 	GetState(key string) ([]byte, error)
 
@@ -68,13 +78,52 @@ type ChaincodeStubInterface interface {
 	// DelState removes the specified `key` and its value from the ledger.
 	DelState(key string) error
 
+	// GetStateMultiple returns the values of keys, in the same order, in one
+	// round trip to the peer. Keys without state get a nil value.
+	GetStateMultiple(keys []string) ([][]byte, error)
+
+	// PutStateMultiple writes the keys and values of kvs into the ledger in one
+	// round trip to the peer.
+	PutStateMultiple(kvs map[string][]byte) error
+
 	// RangeQueryState function can be invoked by a chaincode to query of a range
 	// of keys in the state. Assuming the startKey and endKey are in lexical
 	// an iterator will be returned that can be used to iterate over all keys
-	// between the startKey and endKey, inclusive. The order in which keys are
-	// returned by the iterator is random.
+	// between the startKey and endKey, inclusive. Keys are returned in lexical
+	// order.
 	RangeQueryState(startKey, endKey string) (StateRangeQueryIteratorInterface, error)
 
+	// GetHistoryForKey returns the committed modifications of key, oldest
+	// first, with the transaction that made each of them, and the block number
+	// from which on the modifications are complete.
+	GetHistoryForKey(key string) ([]*pb.KeyModification, uint64, error)
+
+	// QueryState function can be invoked by a chaincode to run a rich query
+	// over the JSON values in its state. The query is a JSON selector that may
+	// sort and limit the results, see package richquery for its syntax. An
+	// iterator will be returned over the selected keys and values, in the order
+	// requested by the query.
+	QueryState(query string) (StateRangeQueryIteratorInterface, error)
+
+	// DeclareIndex declares an index on field for the rich queries of this
+	// chaincode, maintained by the peers once the declaration is committed.
+	DeclareIndex(field string) error
+
+	// DropIndex removes the index declared on field.
+	DropIndex(field string) error
+
+	// CreateCompositeKey combines objectType and attributes into a single key.
+	// The parts must be valid UTF-8 and must not contain U+0000 or U+10FFFF.
+	CreateCompositeKey(objectType string, attributes []string) (string, error)
+
+	// SplitCompositeKey splits a key made by CreateCompositeKey back into its
+	// objectType and attributes.
+	SplitCompositeKey(compositeKey string) (string, []string, error)
+
+	// PartialCompositeKeyQuery returns an iterator over the composite keys of
+	// objectType whose leading attributes are keys, in lexical order.
+	PartialCompositeKeyQuery(objectType string, keys []string) (StateRangeQueryIteratorInterface, error)
+
 	// CreateTable creates a new table given the table name and column definitions
 	CreateTable(name string, columnDefinitions []*ColumnDefinition) error
 
@@ -85,6 +134,11 @@ type ChaincodeStubInterface interface {
 	// DeleteTable deletes an entire table and all associated rows.
 	DeleteTable(tableName string) error
 
+	// AlterTable adds and drops columns and indexes of the specified table.
+	// Rows are migrated to the current columns when read, and stored with
+	// them when next inserted or replaced.
+	AlterTable(tableName string, alteration TableAlteration) error
+
 	// InsertRow inserts a new row into the specified table.
 	// Returns -
 	// true and no error if the row is successfully inserted.
@@ -115,6 +169,10 @@ type ChaincodeStubInterface interface {
 	// DeleteRow deletes the row for the given key from the specified table.
 	DeleteRow(tableName string, key []Column) error
 
+	// GetRowsByIndex returns the rows of the specified table whose indexed
+	// column columnName holds value.
+	GetRowsByIndex(tableName string, columnName string, value Column) (<-chan Row, error)
+
 	// ReadCertAttribute is used to read an specific attribute from the transaction certificate,
 	// *attributeName* is passed as input parameter to this function.
 	// Example:
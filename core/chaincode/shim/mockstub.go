@@ -19,12 +19,21 @@ limitations under the License.
 package shim
 
 import (
+	"bytes"
 	"container/list"
 	"errors"
+	"fmt"
+	"reflect"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/fabric/core/chaincode/shim/crypto/attr"
+	"github.com/hyperledger/fabric/core/chaincode/shim/crypto/ecdsa"
+	"github.com/hyperledger/fabric/core/chaincode/shim/richquery"
+	pb "github.com/hyperledger/fabric/protos"
 	"github.com/op/go-logging"
 )
 
@@ -56,6 +65,64 @@ type MockStub struct {
 	// stores a transaction uuid while being Invoked / Deployed
 	// TODO if a chaincode uses recursion this may need to be a stack of TxIDs or possibly a reference counting map
 	TxID string
+
+	// the identity of the caller, set with MockCaller
+	caller MockIdentity
+
+	// the timestamp set with MockTxTimestamp, used for every transaction when not nil
+	txTimestamp *timestamp.Timestamp
+
+	// the timestamp of the current transaction
+	currentTimestamp *timestamp.Timestamp
+
+	// the event set by the current transaction
+	chaincodeEvent *pb.ChaincodeEvent
+
+	// Events holds the events set by the transactions that have ended, in order
+	Events []*pb.ChaincodeEvent
+
+	// the writes of the current transaction, in order, to roll it back
+	journal []mockWrite
+
+	// peer chaincodes that joined the current transaction; they commit or
+	// roll back with it
+	peers []*MockStub
+
+	// set while a query runs, when PutState and DelState fail
+	readOnly bool
+
+	// the modifications of each key by the transactions that have ended
+	history map[string][]*pb.KeyModification
+
+	// the number of transactions that have ended; each forms its own block
+	blockNumber uint64
+
+	// set with MockDeterminismCheck, when transactions are executed twice
+	checkDeterminism bool
+
+	// the names of the stubs calling this one, outermost first
+	callStack []string
+}
+
+// maxMockCallDepth is the default chaincode.maxcalldepth of the peer
+const maxMockCallDepth = 8
+
+// mockWrite is what a key held before a transaction wrote it.
+type mockWrite struct {
+	key     string
+	value   []byte
+	existed bool
+}
+
+// MockIdentity is the security context a MockStub presents to the chaincode
+// as its caller. When Attributes is nil, attributes are read from
+// Certificate like ChaincodeStub does, so a real TCert can be used;
+// otherwise they are looked up in Attributes.
+type MockIdentity struct {
+	Certificate []byte
+	Attributes  map[string][]byte
+	Metadata    []byte
+	Binding     []byte
 }
 
 func (stub *MockStub) GetTxID() string {
@@ -80,11 +147,139 @@ func (stub *MockStub) GetStringArgs() []string {
 // MockStub doesn't support concurrent transactions at present.
 func (stub *MockStub) MockTransactionStart(txid string) {
 	stub.TxID = txid
+	stub.chaincodeEvent = nil
+	stub.currentTimestamp = stub.txTimestamp
+	if stub.currentTimestamp == nil {
+		now := time.Now()
+		stub.currentTimestamp = &timestamp.Timestamp{Seconds: now.Unix(), Nanos: int32(now.Nanosecond())}
+	}
 }
 
-// End a mocked transaction, clearing the UUID.
+// End a mocked transaction, committing its writes, clearing the UUID and
+// recording its event. Peer chaincodes it invoked are committed too.
 func (stub *MockStub) MockTransactionEnd(uuid string) {
+	for _, peer := range stub.peers {
+		peer.MockTransactionEnd(uuid)
+	}
+	if stub.chaincodeEvent != nil {
+		stub.Events = append(stub.Events, stub.chaincodeEvent)
+	}
+	stub.recordHistory(uuid)
+	stub.endTransaction()
+}
+
+// recordHistory records the final value of every key the current transaction
+// wrote, like the peer does when the transaction is committed in a block.
+func (stub *MockStub) recordHistory(uuid string) {
+	if len(stub.journal) == 0 {
+		return
+	}
+	stub.blockNumber++
+	var keys []string
+	written := make(map[string]bool)
+	for _, write := range stub.journal {
+		if !written[write.key] {
+			written[write.key] = true
+			keys = append(keys, write.key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, ok := stub.State[key]
+		stub.history[key] = append(stub.history[key], &pb.KeyModification{
+			TxID:        uuid,
+			BlockNumber: stub.blockNumber,
+			Value:       value,
+			Timestamp:   stub.currentTimestamp,
+			IsDelete:    !ok,
+		})
+	}
+}
+
+// Roll a mocked transaction back: its writes are undone, its event is
+// dropped and the UUID is cleared, like the peer does with the state delta
+// of a failed transaction. Peer chaincodes it invoked are rolled back too.
+func (stub *MockStub) MockTransactionRollback(uuid string) {
+	for _, peer := range stub.peers {
+		peer.MockTransactionRollback(uuid)
+	}
+	stub.rollbackTo(0)
+	stub.endTransaction()
+}
+
+func (stub *MockStub) endTransaction() {
 	stub.TxID = ""
+	stub.chaincodeEvent = nil
+	stub.journal = nil
+	stub.peers = nil
+}
+
+// rollbackTo undoes the writes of the current transaction after the first n.
+func (stub *MockStub) rollbackTo(n int) {
+	for i := len(stub.journal) - 1; i >= n; i-- {
+		write := stub.journal[i]
+		if write.existed {
+			stub.setState(write.key, write.value)
+		} else {
+			stub.removeState(write.key)
+		}
+	}
+	stub.journal = stub.journal[:n]
+}
+
+// checkpoint records the journal length of this stub and the peers in its
+// transaction.
+func (stub *MockStub) checkpoint(lengths map[*MockStub]int) {
+	if _, ok := lengths[stub]; ok {
+		return
+	}
+	lengths[stub] = len(stub.journal)
+	for _, peer := range stub.peers {
+		peer.checkpoint(lengths)
+	}
+}
+
+// restore undoes the writes made by this stub and the peers in its
+// transaction since checkpoint; peers that joined later are undone entirely.
+func (stub *MockStub) restore(lengths map[*MockStub]int, done map[*MockStub]bool) {
+	if done[stub] {
+		return
+	}
+	done[stub] = true
+	for _, peer := range stub.peers {
+		peer.restore(lengths, done)
+	}
+	stub.rollbackTo(lengths[stub])
+}
+
+// finishTransaction ends the transaction when err is nil and rolls it back otherwise.
+func (stub *MockStub) finishTransaction(uuid string, err error) {
+	if err != nil {
+		stub.MockTransactionRollback(uuid)
+	} else {
+		stub.MockTransactionEnd(uuid)
+	}
+}
+
+// Set the identity of the caller of the following transactions and queries.
+func (stub *MockStub) MockCaller(identity MockIdentity) {
+	stub.caller = identity
+}
+
+// Set the timestamp of the following transactions; nil reverts to the
+// wall clock at the start of each transaction.
+func (stub *MockStub) MockTxTimestamp(ts *timestamp.Timestamp) {
+	stub.txTimestamp = ts
+}
+
+// Check the determinism of the following transactions: MockInit and
+// MockInvoke execute the chaincode twice, rolling the first execution back,
+// and fail the transaction if the two executions differ in the state they
+// write, their result or their event. This reveals chaincode relying on the
+// wall clock, map iteration order, random numbers and the like, which makes
+// validating peers diverge.
+func (stub *MockStub) MockDeterminismCheck(enabled bool) {
+	stub.checkDeterminism = enabled
 }
 
 // Register a peer chaincode with this MockStub
@@ -95,28 +290,79 @@ func (stub *MockStub) MockPeerChaincode(invokableChaincodeName string, otherStub
 }
 
 // Initialise this chaincode,  also starts and ends a transaction.
+// The transaction is rolled back if Init returns an error.
 func (stub *MockStub) MockInit(uuid string, function string, args []string) ([]byte, error) {
 	stub.args = getBytes(function, args)
-	stub.MockTransactionStart(uuid)
-	bytes, err := stub.cc.Init(stub, function, args)
-	stub.MockTransactionEnd(uuid)
-	return bytes, err
+	return stub.executeTransaction(uuid, func() ([]byte, error) {
+		return stub.cc.Init(stub, function, args)
+	})
 }
 
 // Invoke this chaincode, also starts and ends a transaction.
+// The transaction is rolled back if Invoke returns an error.
 func (stub *MockStub) MockInvoke(uuid string, function string, args []string) ([]byte, error) {
 	stub.args = getBytes(function, args)
+	return stub.executeTransaction(uuid, func() ([]byte, error) {
+		return stub.cc.Invoke(stub, function, args)
+	})
+}
+
+// executeTransaction runs execute in a transaction, twice when checking determinism.
+func (stub *MockStub) executeTransaction(uuid string, execute func() ([]byte, error)) ([]byte, error) {
+	stub.MockTransactionStart(uuid)
+	if !stub.checkDeterminism {
+		bytes, err := execute()
+		stub.finishTransaction(uuid, err)
+		return bytes, err
+	}
+
+	// Both executions see the same timestamp, like validating peers do
+	txTimestamp := stub.currentTimestamp
+	firstBytes, firstErr := execute()
+	firstWrites, firstEvent := stub.transactionWrites(), stub.chaincodeEvent
+	stub.MockTransactionRollback(uuid)
+
 	stub.MockTransactionStart(uuid)
-	bytes, err := stub.cc.Invoke(stub, function, args)
-	stub.MockTransactionEnd(uuid)
+	stub.currentTimestamp = txTimestamp
+	bytes, err := execute()
+	if err == nil && (firstErr != nil || !reflect.DeepEqual(firstBytes, bytes) ||
+		!reflect.DeepEqual(firstWrites, stub.transactionWrites()) || !reflect.DeepEqual(firstEvent, stub.chaincodeEvent)) {
+		mockLogger.Error("MockStub", stub.Name, "Transaction", uuid, "is not deterministic")
+		bytes, err = nil, fmt.Errorf("Transaction %s is not deterministic: two executions of it differ", uuid)
+	}
+	stub.finishTransaction(uuid, err)
 	return bytes, err
 }
 
-// Query this chaincode
+// transactionWrites returns the value each key written by the current
+// transaction holds, nil if deleted, in this stub and its peers.
+func (stub *MockStub) transactionWrites() map[string][]byte {
+	writes := make(map[string][]byte)
+	stub.collectWrites(writes, make(map[*MockStub]bool))
+	return writes
+}
+
+func (stub *MockStub) collectWrites(writes map[string][]byte, done map[*MockStub]bool) {
+	if done[stub] {
+		return
+	}
+	done[stub] = true
+	for _, write := range stub.journal {
+		writes[stub.Name+"/"+write.key] = stub.State[write.key]
+	}
+	for _, peer := range stub.peers {
+		peer.collectWrites(writes, done)
+	}
+}
+
+// Query this chaincode. No transaction is needed for queries, and the state
+// is read-only while the query runs.
 func (stub *MockStub) MockQuery(function string, args []string) ([]byte, error) {
 	stub.args = getBytes(function, args)
-	// no transaction needed for queries
+	readOnly := stub.readOnly
+	stub.readOnly = true
 	bytes, err := stub.cc.Query(stub, function, args)
+	stub.readOnly = readOnly
 	return bytes, err
 }
 
@@ -129,12 +375,28 @@ func (stub *MockStub) GetState(key string) ([]byte, error) {
 
 // PutState writes the specified `value` and `key` into the ledger.
 func (stub *MockStub) PutState(key string, value []byte) error {
+	if stub.readOnly {
+		return errors.New("Cannot put state in query context")
+	}
 	if stub.TxID == "" {
 		mockLogger.Error("Cannot PutState without a transactions - call stub.MockTransactionStart()?")
 		return errors.New("Cannot PutState without a transactions - call stub.MockTransactionStart()?")
 	}
 
 	mockLogger.Debug("MockStub", stub.Name, "Putting", key, value)
+	stub.journalWrite(key)
+	stub.setState(key, value)
+	return nil
+}
+
+// journalWrite records what key holds before the current transaction writes it.
+func (stub *MockStub) journalWrite(key string) {
+	value, existed := stub.State[key]
+	stub.journal = append(stub.journal, mockWrite{key: key, value: value, existed: existed})
+}
+
+// setState stores value under key and keeps Keys in order.
+func (stub *MockStub) setState(key string, value []byte) {
 	stub.State[key] = value
 
 	// insert key into ordered list of keys
@@ -166,80 +428,192 @@ func (stub *MockStub) PutState(key string, value []byte) error {
 		stub.Keys.PushFront(key)
 		mockLogger.Debug("MockStub", stub.Name, "Key", key, "is first element in list")
 	}
-
-	return nil
 }
 
 // DelState removes the specified `key` and its value from the ledger.
 func (stub *MockStub) DelState(key string) error {
+	if stub.readOnly {
+		return errors.New("Cannot del state in query context")
+	}
 	mockLogger.Debug("MockStub", stub.Name, "Deleting", key, stub.State[key])
+	if stub.TxID != "" {
+		stub.journalWrite(key)
+	}
+	stub.removeState(key)
+	return nil
+}
+
+// GetStateMultiple returns the values of keys, in the same order.
+func (stub *MockStub) GetStateMultiple(keys []string) ([][]byte, error) {
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		values[i], _ = stub.GetState(key)
+	}
+	return values, nil
+}
+
+// PutStateMultiple writes the keys and values of kvs into the ledger.
+func (stub *MockStub) PutStateMultiple(kvs map[string][]byte) error {
+	for _, keyAndValue := range sortedPutStateInfo(kvs) {
+		if err := stub.PutState(keyAndValue.Key, keyAndValue.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeState deletes key from State and Keys.
+func (stub *MockStub) removeState(key string) {
 	delete(stub.State, key)
 
 	for elem := stub.Keys.Front(); elem != nil; elem = elem.Next() {
 		if strings.Compare(key, elem.Value.(string)) == 0 {
 			stub.Keys.Remove(elem)
+			break
 		}
 	}
-
-	return nil
 }
 
 func (stub *MockStub) RangeQueryState(startKey, endKey string) (StateRangeQueryIteratorInterface, error) {
 	return NewMockStateRangeQueryIterator(stub, startKey, endKey), nil
 }
 
-// Not implemented
+// GetHistoryForKey returns the modifications of key by the transactions that
+// have ended, oldest first. Every mock transaction forms its own block, and
+// the history is complete from the first one.
+func (stub *MockStub) GetHistoryForKey(key string) ([]*pb.KeyModification, uint64, error) {
+	return append([]*pb.KeyModification{}, stub.history[key]...), 0, nil
+}
+
+// QueryState runs a rich query over the JSON values in the mock state, see
+// ChaincodeStub.QueryState. The declared indexes are not used.
+func (stub *MockStub) QueryState(query string) (StateRangeQueryIteratorInterface, error) {
+	q, err := richquery.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	var results []*richquery.Result
+	for elem := stub.Keys.Front(); elem != nil; elem = elem.Next() {
+		key := elem.Value.(string)
+		if result := q.Select(key, stub.State[key]); result != nil {
+			results = append(results, result)
+		}
+	}
+	return &mockQueryResultsIterator{q.Arrange(results), false}, nil
+}
+
+// DeclareIndex declares an index on field for rich queries, see ChaincodeStub.DeclareIndex.
+func (stub *MockStub) DeclareIndex(field string) error {
+	return declareIndex(stub, field)
+}
+
+// DropIndex removes the index declared on field.
+func (stub *MockStub) DropIndex(field string) error {
+	return dropIndex(stub, field)
+}
+
+// CreateCompositeKey combines objectType and attributes into a single key.
+func (stub *MockStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
+	return createCompositeKey(objectType, attributes)
+}
+
+// SplitCompositeKey splits a key made by CreateCompositeKey back into its
+// objectType and attributes.
+func (stub *MockStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
+	return splitCompositeKey(compositeKey)
+}
+
+// PartialCompositeKeyQuery returns the composite keys of objectType whose
+// attributes start with keys, in lexical order.
+func (stub *MockStub) PartialCompositeKeyQuery(objectType string, keys []string) (StateRangeQueryIteratorInterface, error) {
+	return partialCompositeKeyQuery(stub, objectType, keys)
+}
+
+// CreateTable creates a new table given the table name and column definitions
 func (stub *MockStub) CreateTable(name string, columnDefinitions []*ColumnDefinition) error {
-	return nil
+	return createTable(stub, name, columnDefinitions)
 }
 
-// Not implemented
+// GetTable returns the table for the specified table name or ErrTableNotFound
+// if the table does not exist.
 func (stub *MockStub) GetTable(tableName string) (*Table, error) {
-	return nil, nil
+	return getTable(stub, tableName)
 }
 
-// Not implemented
+// DeleteTable deletes an entire table and all associated rows.
 func (stub *MockStub) DeleteTable(tableName string) error {
-	return nil
+	return deleteTable(stub, tableName)
+}
+
+// AlterTable adds and drops columns and indexes of the specified table, see ChaincodeStub.AlterTable.
+func (stub *MockStub) AlterTable(tableName string, alteration TableAlteration) error {
+	return alterTable(stub, tableName, alteration)
 }
 
-// Not implemented
+// InsertRow inserts a new row into the specified table, see ChaincodeStub.InsertRow.
 func (stub *MockStub) InsertRow(tableName string, row Row) (bool, error) {
-	return false, nil
+	return insertRowInternal(stub, tableName, row, false)
 }
 
-// Not implemented
+// ReplaceRow updates the row in the specified table, see ChaincodeStub.ReplaceRow.
 func (stub *MockStub) ReplaceRow(tableName string, row Row) (bool, error) {
-	return false, nil
+	return insertRowInternal(stub, tableName, row, true)
 }
 
-// Not implemented
+// GetRow fetches a row from the specified table for the given key.
 func (stub *MockStub) GetRow(tableName string, key []Column) (Row, error) {
-	var r Row
-	return r, nil
+	return getRow(stub, tableName, key)
 }
 
-// Not implemented
+// GetRows returns multiple rows based on a partial key, see ChaincodeStub.GetRows.
 func (stub *MockStub) GetRows(tableName string, key []Column) (<-chan Row, error) {
-	return nil, nil
+	return getRows(stub, tableName, key)
 }
 
-// Not implemented
+// DeleteRow deletes the row for the given key from the specified table.
 func (stub *MockStub) DeleteRow(tableName string, key []Column) error {
-	return nil
+	return deleteRow(stub, tableName, key)
+}
+
+// GetRowsByIndex returns the rows of the specified table whose indexed
+// column columnName holds value.
+func (stub *MockStub) GetRowsByIndex(tableName string, columnName string, value Column) (<-chan Row, error) {
+	return getRowsByIndex(stub, tableName, columnName, value)
 }
 
 // Invokes a peered chaincode.
 // E.g. stub1.InvokeChaincode("stub2Hash", funcArgs)
 // Before calling this make sure to create another MockStub stub2, call stub2.MockInit(uuid, func, args)
 // and register it with stub1 by calling stub1.MockPeerChaincode("stub2Hash", stub2)
+// The peer chaincode runs in this transaction: its writes are committed or
+// rolled back with it, and undone at once if the peer returns an error.
 func (stub *MockStub) InvokeChaincode(chaincodeName string, args [][]byte) ([]byte, error) {
 	// TODO "args" here should possibly be a serialized pb.ChaincodeInput
+	if stub.readOnly {
+		return nil, errors.New("Cannot invoke chaincode in query context")
+	}
 	function, params := getFuncArgs(args)
 	otherStub := stub.Invokables[chaincodeName]
+	if otherStub == nil {
+		mockLogger.Error("Could not find peer chaincode to invoke", chaincodeName)
+		return nil, errors.New("Could not find peer chaincode to invoke")
+	}
+	if err := stub.enterCall(otherStub); err != nil {
+		return nil, err
+	}
+	defer func() { otherStub.callStack = nil }()
 	mockLogger.Debug("MockStub", stub.Name, "Invoking peer chaincode", otherStub.Name, args)
-	//	function, strings := getFuncArgs(args)
-	bytes, err := otherStub.MockInvoke(stub.TxID, function, params)
+	if otherStub.TxID == "" {
+		otherStub.MockTransactionStart(stub.TxID)
+		stub.peers = append(stub.peers, otherStub)
+	}
+	otherStub.args = getBytes(function, params)
+	checkpoint := map[*MockStub]int{}
+	otherStub.checkpoint(checkpoint)
+	bytes, err := otherStub.cc.Invoke(otherStub, function, params)
+	if err != nil {
+		otherStub.restore(checkpoint, map[*MockStub]bool{})
+	}
 	mockLogger.Debug("MockStub", stub.Name, "Invoked peer chaincode", otherStub.Name, "got", bytes, err)
 	return bytes, err
 }
@@ -252,6 +626,10 @@ func (stub *MockStub) QueryChaincode(chaincodeName string, args [][]byte) ([]byt
 		mockLogger.Error("Could not find peer chaincode to query", chaincodeName)
 		return nil, errors.New("Could not find peer chaincode to query")
 	}
+	if err := stub.enterCall(otherStub); err != nil {
+		return nil, err
+	}
+	defer func() { otherStub.callStack = nil }()
 	mockLogger.Debug("MockStub", stub.Name, "Querying peer chaincode", otherStub.Name, args)
 	function, params := getFuncArgs(args)
 	bytes, err := otherStub.MockQuery(function, params)
@@ -259,53 +637,112 @@ func (stub *MockStub) QueryChaincode(chaincodeName string, args [][]byte) ([]byt
 	return bytes, err
 }
 
-// Not implemented
+// enterCall sets the call stack of otherStub before this stub calls it,
+// failing like the peer does on a cycle or a call deeper than the default
+// chaincode.maxcalldepth
+func (stub *MockStub) enterCall(otherStub *MockStub) error {
+	callStack := append(append([]string{}, stub.callStack...), stub.Name)
+	for _, name := range callStack {
+		if name == otherStub.Name {
+			return fmt.Errorf("Chaincode %s cannot be called by %s: it is already executing (%s -> %s)", otherStub.Name, stub.Name, strings.Join(callStack, " -> "), otherStub.Name)
+		}
+	}
+	if len(callStack) >= maxMockCallDepth {
+		return fmt.Errorf("Chaincode %s cannot be called by %s: the call depth would exceed the maximum of %d", otherStub.Name, stub.Name, maxMockCallDepth)
+	}
+	otherStub.callStack = callStack
+	return nil
+}
+
+// GetCallerChaincodeID returns the name of the stub that called this one
+// with InvokeChaincode or QueryChaincode, or "" outside of such a call.
+func (stub *MockStub) GetCallerChaincodeID() string {
+	if len(stub.callStack) == 0 {
+		return ""
+	}
+	return stub.callStack[len(stub.callStack)-1]
+}
+
+// GetCallStack returns the names of the stubs calling this one, outermost
+// first.
+func (stub *MockStub) GetCallStack() []string {
+	return stub.callStack
+}
+
+// ReadCertAttribute returns the value of a caller attribute.
 func (stub *MockStub) ReadCertAttribute(attributeName string) ([]byte, error) {
-	return nil, nil
+	if stub.caller.Attributes == nil {
+		attributesHandler, err := attr.NewAttributesHandlerImpl(stub)
+		if err != nil {
+			return nil, err
+		}
+		return attributesHandler.GetValue(attributeName)
+	}
+	value, ok := stub.caller.Attributes[attributeName]
+	if !ok {
+		return nil, errors.New("Error reading attribute value 'attribute " + attributeName + " not found'")
+	}
+	return value, nil
 }
 
-// Not implemented
+// VerifyAttribute checks that the caller has attribute attributeName with value attributeValue.
 func (stub *MockStub) VerifyAttribute(attributeName string, attributeValue []byte) (bool, error) {
-	return false, nil
+	value, err := stub.ReadCertAttribute(attributeName)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(value, attributeValue), nil
 }
 
-// Not implemented
+// VerifyAttributes checks every attribute in attrs like VerifyAttribute.
 func (stub *MockStub) VerifyAttributes(attrs ...*attr.Attribute) (bool, error) {
-	return false, nil
+	for _, attribute := range attrs {
+		ok, err := stub.VerifyAttribute(attribute.Name, attribute.Value)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
 }
 
-// Not implemented
+// VerifySignature verifies an ECDSA signature like ChaincodeStub does.
 func (stub *MockStub) VerifySignature(certificate, signature, message []byte) (bool, error) {
-	return false, nil
+	return ecdsa.NewX509ECDSASignatureVerifier().Verify(certificate, signature, message)
 }
 
-// Not implemented
+// GetCallerCertificate returns the certificate set with MockCaller.
 func (stub *MockStub) GetCallerCertificate() ([]byte, error) {
-	return nil, nil
+	return stub.caller.Certificate, nil
 }
 
-// Not implemented
+// GetCallerMetadata returns the metadata set with MockCaller.
 func (stub *MockStub) GetCallerMetadata() ([]byte, error) {
-	return nil, nil
+	return stub.caller.Metadata, nil
 }
 
-// Not implemented
+// GetBinding returns the binding set with MockCaller.
 func (stub *MockStub) GetBinding() ([]byte, error) {
-	return nil, nil
+	return stub.caller.Binding, nil
 }
 
-// Not implemented
+// GetPayload returns the marshalled ChaincodeInput of the current call.
 func (stub *MockStub) GetPayload() ([]byte, error) {
-	return nil, nil
+	return proto.Marshal(&pb.ChaincodeInput{Args: stub.args})
 }
 
-// Not implemented
+// GetTxTimestamp returns the timestamp set with MockTxTimestamp, or the wall
+// clock when the transaction started. Queries outside a transaction get nil.
 func (stub *MockStub) GetTxTimestamp() (*timestamp.Timestamp, error) {
-	return nil, nil
+	if stub.TxID == "" {
+		return stub.txTimestamp, nil
+	}
+	return stub.currentTimestamp, nil
 }
 
-// Not implemented
+// SetEvent sets the event of the current transaction; it is added to Events
+// when the transaction ends.
 func (stub *MockStub) SetEvent(name string, payload []byte) error {
+	stub.chaincodeEvent = &pb.ChaincodeEvent{EventName: name, Payload: payload}
 	return nil
 }
 
@@ -318,6 +755,7 @@ func NewMockStub(name string, cc Chaincode) *MockStub {
 	s.State = make(map[string][]byte)
 	s.Invokables = make(map[string]*MockStub)
 	s.Keys = list.New()
+	s.history = make(map[string][]*pb.KeyModification)
 
 	return s
 }
@@ -343,25 +781,22 @@ func (iter *MockStateRangeQueryIterator) HasNext() bool {
 		return false
 	}
 
-	if iter.Current == nil {
-		mockLogger.Error("HasNext() couldn't get Current")
-		return false
-	}
-
-	if iter.Current.Next() == nil {
-		// we've reached the end of the underlying values
-		mockLogger.Debug("HasNext() but no next")
-		return false
-	}
-
-	if iter.EndKey == iter.Current.Value {
-		// we've reached the end of the specified range
-		mockLogger.Debug("HasNext() at end of specified range")
-		return false
+	for current := iter.Current; current != nil; current = current.Next() {
+		key := current.Value.(string)
+		if iter.EndKey != "" && strings.Compare(key, iter.EndKey) > 0 {
+			// we've reached the end of the specified range
+			mockLogger.Debug("HasNext() at end of specified range")
+			return false
+		}
+		if strings.Compare(key, iter.StartKey) >= 0 {
+			mockLogger.Debug("HasNext() got next")
+			return true
+		}
 	}
 
-	mockLogger.Debug("HasNext() got next")
-	return true
+	// we've reached the end of the underlying values
+	mockLogger.Debug("HasNext() but no next")
+	return false
 }
 
 // Next returns the next key and value in the range query iterator.
@@ -376,13 +811,13 @@ func (iter *MockStateRangeQueryIterator) Next() (string, []byte, error) {
 		return "", nil, errors.New("MockStateRangeQueryIterator.Next() called when it does not HaveNext()")
 	}
 
-	iter.Current = iter.Current.Next()
-
-	if iter.Current == nil {
-		mockLogger.Error("MockStateRangeQueryIterator.Next() went past end of range")
-		return "", nil, errors.New("MockStateRangeQueryIterator.Next() went past end of range")
+	// skip keys before the start of the specified range
+	for strings.Compare(iter.Current.Value.(string), iter.StartKey) < 0 {
+		iter.Current = iter.Current.Next()
 	}
+
 	key := iter.Current.Value.(string)
+	iter.Current = iter.Current.Next()
 	value, err := iter.Stub.GetState(key)
 	return key, value, err
 }
@@ -424,6 +859,33 @@ func NewMockStateRangeQueryIterator(stub *MockStub, startKey string, endKey stri
 	return iter
 }
 
+// mockQueryResultsIterator iterates over the results of MockStub.QueryState
+type mockQueryResultsIterator struct {
+	results []*richquery.Result
+	closed  bool
+}
+
+// HasNext returns true if the iterator contains additional keys and values.
+func (iter *mockQueryResultsIterator) HasNext() bool {
+	return !iter.closed && len(iter.results) > 0
+}
+
+// Next returns the next key and value in the iterator.
+func (iter *mockQueryResultsIterator) Next() (string, []byte, error) {
+	if !iter.HasNext() {
+		return "", nil, errors.New("MockQueryResultsIterator.Next() called when it does not HaveNext()")
+	}
+	result := iter.results[0]
+	iter.results = iter.results[1:]
+	return result.Key, result.Value, nil
+}
+
+// Close closes the iterator.
+func (iter *mockQueryResultsIterator) Close() error {
+	iter.closed = true
+	return nil
+}
+
 func getBytes(function string, args []string) [][]byte {
 	bytes := make([][]byte, 0, len(args)+1)
 	bytes = append(bytes, []byte(function))
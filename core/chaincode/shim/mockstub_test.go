@@ -17,8 +17,17 @@ limitations under the License.
 package shim
 
 import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"strings"
 	"testing"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/core/chaincode/shim/crypto/attr"
+	"github.com/hyperledger/fabric/core/crypto/primitives"
 )
 
 func TestMockStateRangeQueryIterator(t *testing.T) {
@@ -49,4 +58,723 @@ func TestMockStateRangeQueryIterator(t *testing.T) {
 			fmt.Println("Expected value", expectValues[i], "got", value)
 		}
 	}
+	if rqi.HasNext() {
+		fmt.Println("Expected no more keys after", expectKeys[len(expectKeys)-1])
+		t.FailNow()
+	}
+}
+
+func TestMockStateRangeQueryIterator_OpenEnded(t *testing.T) {
+	stub := NewMockStub("rangeTest", nil)
+	stub.MockTransactionStart("init")
+	stub.PutState("a", []byte{61})
+	stub.PutState("c", []byte{63})
+	stub.PutState("b", []byte{62})
+	stub.MockTransactionEnd("init")
+
+	expectKeys := []string{"a", "b", "c"}
+
+	rqi := NewMockStateRangeQueryIterator(stub, "", "")
+	var keys []string
+	for rqi.HasNext() {
+		key, _, err := rqi.Next()
+		if err != nil {
+			fmt.Println("Unexpected error", err)
+			t.FailNow()
+		}
+		keys = append(keys, key)
+	}
+	if fmt.Sprint(keys) != fmt.Sprint(expectKeys) {
+		fmt.Println("Expected keys", expectKeys, "got", keys)
+		t.FailNow()
+	}
+}
+
+func TestMockStateRangeQueryIterator_StartKey(t *testing.T) {
+	stub := NewMockStub("rangeTest", nil)
+	stub.MockTransactionStart("init")
+	stub.PutState("d", []byte{64})
+	stub.PutState("a", []byte{61})
+	stub.PutState("c", []byte{63})
+	stub.PutState("b", []byte{62})
+	stub.MockTransactionEnd("init")
+
+	expectKeys := []string{"b", "c"}
+
+	rqi := NewMockStateRangeQueryIterator(stub, "b", "c")
+	var keys []string
+	for rqi.HasNext() {
+		// HasNext does not advance the iterator
+		if !rqi.HasNext() {
+			fmt.Println("Expected HasNext to hold until Next")
+			t.FailNow()
+		}
+		key, _, err := rqi.Next()
+		if err != nil {
+			fmt.Println("Unexpected error", err)
+			t.FailNow()
+		}
+		keys = append(keys, key)
+	}
+	if fmt.Sprint(keys) != fmt.Sprint(expectKeys) {
+		fmt.Println("Expected keys", expectKeys, "got", keys)
+		t.FailNow()
+	}
+	if _, _, err := rqi.Next(); err == nil {
+		fmt.Println("Expected an error from Next past the end of the range")
+		t.FailNow()
+	}
+}
+
+func TestMockStubTables(t *testing.T) {
+	stub := NewMockStub("tableTest", nil)
+	stub.MockTransactionStart("init")
+	defer stub.MockTransactionEnd("init")
+
+	err := stub.CreateTable("accounts", []*ColumnDefinition{
+		{Name: "Owner", Type: ColumnDefinition_STRING, Key: true},
+		{Name: "Account", Type: ColumnDefinition_INT32, Key: true},
+		{Name: "Balance", Type: ColumnDefinition_INT64, Key: false},
+	})
+	if err != nil {
+		t.Fatalf("CreateTable failed: %s", err)
+	}
+	if err = stub.CreateTable("accounts", nil); err == nil {
+		t.Fatal("Expected creating an existing table to fail")
+	}
+	if _, err = stub.GetTable("missing"); err != ErrTableNotFound {
+		t.Fatalf("Expected ErrTableNotFound, got %v", err)
+	}
+
+	row := func(owner string, account int32, balance int64) Row {
+		return Row{Columns: []*Column{
+			{Value: &Column_String_{String_: owner}},
+			{Value: &Column_Int32{Int32: account}},
+			{Value: &Column_Int64{Int64: balance}},
+		}}
+	}
+	for _, r := range []Row{row("alice", 1, 10), row("alice", 2, 20), row("bob", 1, 30)} {
+		ok, err := stub.InsertRow("accounts", r)
+		if err != nil || !ok {
+			t.Fatalf("InsertRow failed: %v %v", ok, err)
+		}
+	}
+	if ok, _ := stub.InsertRow("accounts", row("alice", 1, 99)); ok {
+		t.Fatal("Expected inserting an existing row to be refused")
+	}
+	if ok, err := stub.ReplaceRow("accounts", row("alice", 1, 11)); err != nil || !ok {
+		t.Fatalf("ReplaceRow failed: %v %v", ok, err)
+	}
+
+	key := []Column{{Value: &Column_String_{String_: "alice"}}, {Value: &Column_Int32{Int32: 1}}}
+	got, err := stub.GetRow("accounts", key)
+	if err != nil || len(got.Columns) != 3 || got.Columns[2].GetInt64() != 11 {
+		t.Fatalf("Expected the replaced row, got %v %v", got, err)
+	}
+
+	rows, err := stub.GetRows("accounts", key[:1])
+	if err != nil {
+		t.Fatalf("GetRows failed: %s", err)
+	}
+	var balances []int64
+	for r := range rows {
+		balances = append(balances, r.Columns[2].GetInt64())
+	}
+	if len(balances) != 2 || balances[0] != 11 || balances[1] != 20 {
+		t.Fatalf("Expected alice's two accounts, got %v", balances)
+	}
+
+	if err = stub.DeleteRow("accounts", key); err != nil {
+		t.Fatalf("DeleteRow failed: %s", err)
+	}
+	if got, _ = stub.GetRow("accounts", key); len(got.Columns) != 0 {
+		t.Fatalf("Expected the row to be deleted, got %v", got)
+	}
+	if err = stub.DeleteTable("accounts"); err != nil {
+		t.Fatalf("DeleteTable failed: %s", err)
+	}
+	if len(stub.State) != 0 {
+		t.Fatalf("Expected DeleteTable to remove every row, %d keys left", len(stub.State))
+	}
+}
+
+func TestMockStubAlterTable(t *testing.T) {
+	stub := NewMockStub("alterTableTest", nil)
+	stub.MockTransactionStart("init")
+	defer stub.MockTransactionEnd("init")
+
+	err := stub.CreateTable("users", []*ColumnDefinition{
+		{Name: "ID", Type: ColumnDefinition_STRING, Key: true},
+		{Name: "Nickname", Type: ColumnDefinition_STRING},
+		{Name: "City", Type: ColumnDefinition_STRING},
+	})
+	if err != nil {
+		t.Fatalf("CreateTable failed: %s", err)
+	}
+	str := func(s string) *Column { return &Column{Value: &Column_String_{String_: s}} }
+	for _, id := range []string{"alice", "bob"} {
+		if ok, err := stub.InsertRow("users", Row{Columns: []*Column{str(id), str(id + "'s nick"), str("Shanghai")}}); err != nil || !ok {
+			t.Fatalf("InsertRow failed: %v %v", ok, err)
+		}
+	}
+
+	// Drop Nickname and add a nullable, indexed Level column; the rows are migrated when read
+	err = stub.AlterTable("users", TableAlteration{
+		DropColumns: []string{"Nickname"},
+		AddColumns:  []*ColumnDefinition{{Name: "Level", Type: ColumnDefinition_UINT32, Indexed: true}},
+		AddIndexes:  []string{"City"},
+	})
+	if err != nil {
+		t.Fatalf("AlterTable failed: %s", err)
+	}
+	alice := []Column{*str("alice")}
+	row, err := stub.GetRow("users", alice)
+	if err != nil || len(row.Columns) != 3 || row.Columns[1].GetString_() != "Shanghai" || row.Columns[2].Value != nil {
+		t.Fatalf("Expected alice migrated to the new columns, got %v %v", row, err)
+	}
+	if ok, err := stub.ReplaceRow("users", Row{Columns: []*Column{str("alice"), str("Beijing"), {Value: &Column_Uint32{Uint32: 2}}}}); err != nil || !ok {
+		t.Fatalf("ReplaceRow failed: %v %v", ok, err)
+	}
+
+	byIndex := func(column string, value Column) []string {
+		rows, err := stub.GetRowsByIndex("users", column, value)
+		if err != nil {
+			t.Fatalf("GetRowsByIndex failed: %s", err)
+		}
+		var ids []string
+		for r := range rows {
+			ids = append(ids, r.Columns[0].GetString_())
+		}
+		return ids
+	}
+	if ids := byIndex("City", *str("Shanghai")); fmt.Sprint(ids) != "[bob]" {
+		t.Fatalf("Expected bob in Shanghai, got %v", ids)
+	}
+	if ids := byIndex("City", *str("Beijing")); fmt.Sprint(ids) != "[alice]" {
+		t.Fatalf("Expected alice in Beijing, got %v", ids)
+	}
+	if ids := byIndex("Level", Column{Value: &Column_Uint32{Uint32: 2}}); fmt.Sprint(ids) != "[alice]" {
+		t.Fatalf("Expected alice at level 2, got %v", ids)
+	}
+	if _, err = stub.GetRowsByIndex("users", "City", Column{Value: &Column_Uint32{Uint32: 2}}); err == nil {
+		t.Fatal("Expected looking up a value of the wrong type to fail")
+	}
+
+	// Re-adding a dropped column does not bring back its old values
+	if err = stub.AlterTable("users", TableAlteration{AddColumns: []*ColumnDefinition{{Name: "Nickname", Type: ColumnDefinition_STRING}}}); err != nil {
+		t.Fatalf("AlterTable failed: %s", err)
+	}
+	if row, _ = stub.GetRow("users", []Column{*str("bob")}); len(row.Columns) != 4 || row.Columns[3].Value != nil {
+		t.Fatalf("Expected bob without a nickname, got %v", row)
+	}
+
+	invalid := []TableAlteration{
+		{DropColumns: []string{"ID"}},
+		{DropColumns: []string{"Missing"}},
+		{AddColumns: []*ColumnDefinition{{Name: "Other", Type: ColumnDefinition_STRING, Key: true}}},
+		{AddColumns: []*ColumnDefinition{{Name: "City", Type: ColumnDefinition_STRING}}},
+		{AddIndexes: []string{"ID"}},
+		{DropIndexes: []string{"Nickname"}},
+	}
+	for _, alteration := range invalid {
+		if err = stub.AlterTable("users", alteration); err == nil {
+			t.Fatalf("Expected alteration %v to fail", alteration)
+		}
+	}
+
+	// Deleting a row removes its index entries, dropping an index removes the rest
+	if err = stub.DeleteRow("users", alice); err != nil {
+		t.Fatalf("DeleteRow failed: %s", err)
+	}
+	if ids := byIndex("City", *str("Beijing")); len(ids) != 0 {
+		t.Fatalf("Expected no user in Beijing, got %v", ids)
+	}
+	if err = stub.AlterTable("users", TableAlteration{DropIndexes: []string{"City"}}); err != nil {
+		t.Fatalf("AlterTable failed: %s", err)
+	}
+	if _, err = stub.GetRowsByIndex("users", "City", *str("Shanghai")); err == nil {
+		t.Fatal("Expected looking up a dropped index to fail")
+	}
+	if err = stub.DeleteTable("users"); err != nil {
+		t.Fatalf("DeleteTable failed: %s", err)
+	}
+	if len(stub.State) != 0 {
+		t.Fatalf("Expected DeleteTable to remove every row and index entry, %d keys left", len(stub.State))
+	}
+}
+
+func TestMockStubIndexKeyCollision(t *testing.T) {
+	stub := NewMockStub("indexCollisionTest", nil)
+	stub.MockTransactionStart("init")
+	defer stub.MockTransactionEnd("init")
+
+	// With variable-width lengths the entries of Code "3abcdefghijk" started
+	// with the entry prefix of Code "2", and those of column "Code1" with the
+	// prefix of the entries of column "Code"
+	err := stub.CreateTable("lots", []*ColumnDefinition{
+		{Name: "ID", Type: ColumnDefinition_STRING, Key: true},
+		{Name: "Code", Type: ColumnDefinition_STRING, Indexed: true},
+		{Name: "Code1", Type: ColumnDefinition_STRING, Indexed: true},
+	})
+	if err != nil {
+		t.Fatalf("CreateTable failed: %s", err)
+	}
+	str := func(s string) *Column { return &Column{Value: &Column_String_{String_: s}} }
+	for _, lot := range [][]string{{"a", "2", "x"}, {"b", "3abcdefghijk", "2"}} {
+		if ok, err := stub.InsertRow("lots", Row{Columns: []*Column{str(lot[0]), str(lot[1]), str(lot[2])}}); err != nil || !ok {
+			t.Fatalf("InsertRow failed: %v %v", ok, err)
+		}
+	}
+
+	byIndex := func(column string, value string) []string {
+		rows, err := stub.GetRowsByIndex("lots", column, *str(value))
+		if err != nil {
+			t.Fatalf("GetRowsByIndex failed: %s", err)
+		}
+		var ids []string
+		for r := range rows {
+			ids = append(ids, r.Columns[0].GetString_())
+		}
+		return ids
+	}
+	if ids := byIndex("Code", "2"); fmt.Sprint(ids) != "[a]" {
+		t.Fatalf("Expected only lot a with code 2, got %v", ids)
+	}
+	if ids := byIndex("Code", "3abcdefghijk"); fmt.Sprint(ids) != "[b]" {
+		t.Fatalf("Expected only lot b with code 3abcdefghijk, got %v", ids)
+	}
+
+	// Dropping the index of Code leaves the entries of Code1
+	if err = stub.AlterTable("lots", TableAlteration{DropIndexes: []string{"Code"}}); err != nil {
+		t.Fatalf("AlterTable failed: %s", err)
+	}
+	if ids := byIndex("Code1", "2"); fmt.Sprint(ids) != "[b]" {
+		t.Fatalf("Expected lot b with code1 2, got %v", ids)
+	}
+}
+
+func TestMockStubCallerAttributes(t *testing.T) {
+	stub := NewMockStub("callerTest", nil)
+	stub.MockCaller(MockIdentity{
+		Attributes: map[string][]byte{"role": []byte("issuer")},
+		Metadata:   []byte("metadata"),
+		Binding:    []byte("binding"),
+	})
+
+	value, err := stub.ReadCertAttribute("role")
+	if err != nil || string(value) != "issuer" {
+		t.Fatalf("Expected role issuer, got %s %v", value, err)
+	}
+	if _, err = stub.ReadCertAttribute("userid"); err == nil {
+		t.Fatal("Expected reading a missing attribute to fail")
+	}
+	ok, err := stub.VerifyAttribute("role", []byte("client"))
+	if err != nil || ok {
+		t.Fatalf("Expected role client not to verify, got %v %v", ok, err)
+	}
+	ok, err = stub.VerifyAttributes(&attr.Attribute{Name: "role", Value: []byte("issuer")})
+	if err != nil || !ok {
+		t.Fatalf("Expected role issuer to verify, got %v %v", ok, err)
+	}
+	metadata, _ := stub.GetCallerMetadata()
+	binding, _ := stub.GetBinding()
+	if string(metadata) != "metadata" || string(binding) != "binding" {
+		t.Fatalf("Expected the configured metadata and binding, got %s %s", metadata, binding)
+	}
+}
+
+func TestMockStubCallerCertificate(t *testing.T) {
+	primitives.SetSecurityLevel("SHA3", 256)
+	pemBytes, err := ioutil.ReadFile("./crypto/attr/test_resources/tcert_clear.dump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode(pemBytes)
+	tcert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stub := NewMockStub("certTest", nil)
+	if _, err = stub.ReadCertAttribute("position"); err == nil {
+		t.Fatal("Expected reading attributes without a certificate to fail")
+	}
+	stub.MockCaller(MockIdentity{Certificate: tcert.Raw})
+	ok, err := stub.VerifyAttribute("position", []byte("Software Engineer"))
+	if err != nil || !ok {
+		t.Fatalf("Expected the certificate's position to verify, got %v %v", ok, err)
+	}
+}
+
+func TestMockStubTxTimestampAndEvents(t *testing.T) {
+	stub := NewMockStub("eventTest", nil)
+
+	stub.MockTransactionStart("tx1")
+	ts, err := stub.GetTxTimestamp()
+	if err != nil || ts == nil || ts.Seconds == 0 {
+		t.Fatalf("Expected a wall clock timestamp, got %v %v", ts, err)
+	}
+	stub.SetEvent("first", []byte("1"))
+	stub.SetEvent("second", []byte("2"))
+	stub.MockTransactionEnd("tx1")
+
+	stub.MockTxTimestamp(&timestamp.Timestamp{Seconds: 1500000000})
+	stub.MockTransactionStart("tx2")
+	ts, _ = stub.GetTxTimestamp()
+	if ts.Seconds != 1500000000 {
+		t.Fatalf("Expected the configured timestamp, got %v", ts)
+	}
+	stub.MockTransactionEnd("tx2")
+
+	stub.MockTransactionStart("tx3")
+	stub.SetEvent("third", nil)
+	stub.MockTransactionEnd("tx3")
+
+	if len(stub.Events) != 2 || stub.Events[0].EventName != "second" || stub.Events[1].EventName != "third" {
+		t.Fatalf("Expected the last event of tx1 and tx3, got %v", stub.Events)
+	}
+}
+
+// kvChaincode puts and deletes keys; "fail" as the last argument makes the
+// invoke return an error after its writes, and "call" invokes a peer first.
+type kvChaincode struct{}
+
+func (cc *kvChaincode) Init(stub ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	return cc.Invoke(stub, function, args)
+}
+
+func (cc *kvChaincode) Invoke(stub ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	fail := len(args) > 0 && args[len(args)-1] == "fail"
+	if fail {
+		args = args[:len(args)-1]
+	}
+	var err error
+	switch function {
+	case "put":
+		err = stub.PutState(args[0], []byte(args[1]))
+	case "del":
+		err = stub.DelState(args[0])
+	case "call":
+		_, err = stub.InvokeChaincode(args[0], getBytes(args[1], args[2:]))
+	}
+	if err == nil && fail {
+		err = errors.New("failed after writing")
+	}
+	return nil, err
+}
+
+func (cc *kvChaincode) Query(stub ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	if function == "put" {
+		return nil, stub.PutState(args[0], []byte(args[1]))
+	}
+	if function == "call" {
+		return stub.InvokeChaincode(args[0], getBytes(args[1], args[2:]))
+	}
+	if function == "ask" {
+		return stub.QueryChaincode(args[0], getBytes(args[1], args[2:]))
+	}
+	if function == "caller" {
+		return []byte(stub.GetCallerChaincodeID()), nil
+	}
+	return stub.GetState(args[0])
+}
+
+// counterChaincode writes how many times it has been invoked, which differs
+// between the two executions of a determinism check.
+type counterChaincode struct {
+	invocations int
+}
+
+func (cc *counterChaincode) Init(stub ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	return nil, nil
+}
+
+func (cc *counterChaincode) Invoke(stub ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	cc.invocations++
+	return nil, stub.PutState("count", []byte(fmt.Sprint(cc.invocations)))
+}
+
+func (cc *counterChaincode) Query(stub ChaincodeStubInterface, function string, args []string) ([]byte, error) {
+	return nil, nil
+}
+
+func TestMockStubDeterminismCheck(t *testing.T) {
+	stub := NewMockStub("deterministicTest", new(kvChaincode))
+	stub.MockDeterminismCheck(true)
+	if _, err := stub.MockInvoke("tx1", "put", []string{"key", "value"}); err != nil {
+		t.Fatalf("Expected a deterministic invoke to succeed, got %s", err)
+	}
+	checkMockState(t, stub, "key", "value")
+	if _, err := stub.MockInvoke("tx2", "put", []string{"key", "other", "fail"}); err == nil || err.Error() != "failed after writing" {
+		t.Fatalf("Expected the invoke's own error, got %v", err)
+	}
+	checkMockState(t, stub, "key", "value")
+
+	counter := new(counterChaincode)
+	stub = NewMockStub("nondeterministicTest", counter)
+	if _, err := stub.MockInvoke("tx1", "count", nil); err != nil {
+		t.Fatalf("Expected the invoke to succeed without the check, got %s", err)
+	}
+	stub.MockDeterminismCheck(true)
+	if _, err := stub.MockInvoke("tx2", "count", nil); err == nil {
+		t.Fatal("Expected a non-deterministic invoke to fail")
+	}
+	checkMockState(t, stub, "count", "1")
+	if counter.invocations != 3 {
+		t.Fatalf("Expected the checked invoke to be executed twice, got %d invocations", counter.invocations)
+	}
+}
+
+func checkMockState(t *testing.T, stub *MockStub, key string, expect string) {
+	value, ok := stub.State[key]
+	if expect == "" && ok {
+		t.Fatalf("Expected %s to be absent from %s, got %s", key, stub.Name, value)
+	}
+	if expect != "" && string(value) != expect {
+		t.Fatalf("Expected %s=%s in %s, got %s", key, expect, stub.Name, value)
+	}
+}
+
+func TestMockStubRollback(t *testing.T) {
+	stub := NewMockStub("rollbackTest", new(kvChaincode))
+	if _, err := stub.MockInit("init", "put", []string{"a", "1"}); err != nil {
+		t.Fatalf("Init failed: %s", err)
+	}
+	stub.MockInvoke("tx1", "put", []string{"b", "2"})
+
+	if _, err := stub.MockInvoke("tx2", "put", []string{"a", "changed", "fail"}); err == nil {
+		t.Fatal("Expected the invoke to fail")
+	}
+	checkMockState(t, stub, "a", "1")
+	if _, err := stub.MockInvoke("tx3", "put", []string{"c", "3", "fail"}); err == nil {
+		t.Fatal("Expected the invoke to fail")
+	}
+	checkMockState(t, stub, "c", "")
+	if _, err := stub.MockInvoke("tx4", "del", []string{"b", "fail"}); err == nil {
+		t.Fatal("Expected the invoke to fail")
+	}
+	checkMockState(t, stub, "b", "2")
+
+	iter, _ := stub.RangeQueryState("", "")
+	var keys []string
+	for iter.HasNext() {
+		key, _, _ := iter.Next()
+		keys = append(keys, key)
+	}
+	if fmt.Sprint(keys) != "[a b]" {
+		t.Fatalf("Expected keys [a b] after the rollbacks, got %v", keys)
+	}
+
+	stub.MockTransactionStart("tx5")
+	stub.PutState("d", []byte("4"))
+	stub.SetEvent("dropped", nil)
+	stub.MockTransactionRollback("tx5")
+	checkMockState(t, stub, "d", "")
+	if len(stub.Events) != 0 || stub.TxID != "" {
+		t.Fatalf("Expected the rollback to drop the event and end the transaction")
+	}
+}
+
+func TestMockStubQueryIsReadOnly(t *testing.T) {
+	stub := NewMockStub("queryTest", new(kvChaincode))
+	other := NewMockStub("otherTest", new(kvChaincode))
+	stub.MockPeerChaincode("other", other)
+
+	_, err := stub.MockQuery("put", []string{"a", "1"})
+	if err == nil || err.Error() != "Cannot put state in query context" {
+		t.Fatalf("Expected PutState to fail in a query, got %v", err)
+	}
+	_, err = stub.MockQuery("call", []string{"other", "put", "a", "1"})
+	if err == nil || err.Error() != "Cannot invoke chaincode in query context" {
+		t.Fatalf("Expected InvokeChaincode to fail in a query, got %v", err)
+	}
+	if _, err = stub.MockInvoke("tx1", "put", []string{"a", "1"}); err != nil {
+		t.Fatalf("Expected PutState to work again after the query, got %v", err)
+	}
+}
+
+func TestMockStubPeerChaincodeRollback(t *testing.T) {
+	first := NewMockStub("first", new(kvChaincode))
+	second := NewMockStub("second", new(kvChaincode))
+	third := NewMockStub("third", new(kvChaincode))
+	first.MockPeerChaincode("second", second)
+	second.MockPeerChaincode("third", third)
+
+	// the caller fails after the peers succeeded: everything is undone
+	_, err := first.MockInvoke("tx1", "call", []string{"second", "call", "third", "put", "k", "v", "fail"})
+	if err == nil {
+		t.Fatal("Expected the invoke to fail")
+	}
+	checkMockState(t, third, "k", "")
+	if second.TxID != "" || third.TxID != "" {
+		t.Fatal("Expected the peers' transactions to end with the caller's")
+	}
+
+	// the same chain succeeding commits every peer's writes
+	_, err = first.MockInvoke("tx2", "call", []string{"second", "call", "third", "put", "k", "v"})
+	if err != nil {
+		t.Fatalf("Expected the invoke to succeed, got %v", err)
+	}
+	checkMockState(t, third, "k", "v")
+
+	// a failing peer is undone, including the peers it called, and the caller
+	// may carry on
+	first.MockTransactionStart("tx3")
+	_, err = first.InvokeChaincode("second", getBytes("call", []string{"third", "put", "k", "changed", "fail"}))
+	if err == nil {
+		t.Fatal("Expected the peer to fail")
+	}
+	checkMockState(t, third, "k", "v")
+	first.PutState("after", []byte("1"))
+	first.MockTransactionEnd("tx3")
+	checkMockState(t, first, "after", "1")
+}
+
+func TestMockStubCallStack(t *testing.T) {
+	first := NewMockStub("first", new(kvChaincode))
+	second := NewMockStub("second", new(kvChaincode))
+	first.MockPeerChaincode("second", second)
+	second.MockPeerChaincode("first", first)
+
+	caller, err := first.MockQuery("ask", []string{"second", "caller"})
+	if err != nil || string(caller) != "first" {
+		t.Fatalf("Expected second to be called by first, got %q, %v", caller, err)
+	}
+	if first.GetCallerChaincodeID() != "" || len(second.GetCallStack()) != 0 {
+		t.Fatal("Expected the call stack to be empty after the call")
+	}
+
+	// second calling first back is a cycle
+	_, err = first.MockInvoke("tx1", "call", []string{"second", "call", "first", "put", "k", "v"})
+	if err == nil || !strings.Contains(err.Error(), "first -> second -> first") {
+		t.Fatalf("Expected the cycle to be rejected, got %v", err)
+	}
+	checkMockState(t, first, "k", "")
+}
+
+func TestMockStubCompositeKeys(t *testing.T) {
+	stub := NewMockStub("compositeKeyTest", nil)
+	stub.MockTransactionStart("init")
+	defer stub.MockTransactionEnd("init")
+
+	for _, parts := range [][]string{
+		{"alice", "2016", "3"}, {"bob", "2016", "1"}, {"alice", "2015", "7"},
+		{"alice", "2016", "1"}, {"alice2", "2016", "1"},
+	} {
+		key, err := stub.CreateCompositeKey("tx", parts)
+		if err != nil {
+			t.Fatalf("CreateCompositeKey failed: %s", err)
+		}
+		stub.PutState(key, []byte(parts[2]))
+	}
+	stub.PutState("tx", []byte("simple key"))
+	other, _ := stub.CreateCompositeKey("txs", []string{"alice"})
+	stub.PutState(other, []byte("other object type"))
+
+	iter, err := stub.PartialCompositeKeyQuery("tx", []string{"alice"})
+	if err != nil {
+		t.Fatalf("PartialCompositeKeyQuery failed: %s", err)
+	}
+	var got []string
+	for iter.HasNext() {
+		key, _, _ := iter.Next()
+		objectType, attributes, err := stub.SplitCompositeKey(key)
+		if err != nil || objectType != "tx" || len(attributes) != 3 || attributes[0] != "alice" {
+			t.Fatalf("Unexpected key %q: %s %v %v", key, objectType, attributes, err)
+		}
+		got = append(got, attributes[1]+"/"+attributes[2])
+	}
+	if fmt.Sprint(got) != "[2015/7 2016/1 2016/3]" {
+		t.Fatalf("Expected alice's keys in lexical order, got %v", got)
+	}
+
+	if _, err = stub.CreateCompositeKey("tx", []string{"a\x00b"}); err == nil {
+		t.Fatal("Expected U+0000 in a part to be refused")
+	}
+	if _, err = stub.CreateCompositeKey("", []string{"a"}); err == nil {
+		t.Fatal("Expected an empty object type to be refused")
+	}
+	if _, _, err = stub.SplitCompositeKey("tx"); err == nil {
+		t.Fatal("Expected a simple key not to split")
+	}
+}
+
+func TestMockStubStateMultiple(t *testing.T) {
+	stub := NewMockStub("multipleTest", new(kvChaincode))
+	stub.MockTransactionStart("tx1")
+	err := stub.PutStateMultiple(map[string][]byte{"b": []byte("2"), "a": []byte("1"), "c": []byte("3")})
+	if err != nil {
+		t.Fatalf("PutStateMultiple failed: %s", err)
+	}
+	stub.MockTransactionEnd("tx1")
+
+	values, err := stub.GetStateMultiple([]string{"c", "missing", "a"})
+	if err != nil || len(values) != 3 || string(values[0]) != "3" || values[1] != nil || string(values[2]) != "1" {
+		t.Fatalf("Expected [3 nil 1], got %q %v", values, err)
+	}
+
+	if err = stub.PutStateMultiple(map[string][]byte{"d": []byte("4")}); err == nil {
+		t.Fatal("Expected PutStateMultiple outside a transaction to fail")
+	}
+	checkMockState(t, stub, "d", "")
+}
+
+func TestMockStubQueryState(t *testing.T) {
+	stub := NewMockStub("queryTest", nil)
+	stub.MockTransactionStart("init")
+	stub.PutState("alice", []byte(`{"Name":"alice","Integral":1500}`))
+	stub.PutState("bob", []byte(`{"Name":"bob","Integral":800}`))
+	stub.PutState("carol", []byte(`{"Name":"carol","Integral":2500}`))
+	stub.PutState("dave", []byte("not json"))
+	if err := stub.DeclareIndex("Integral"); err != nil {
+		t.Fatalf("DeclareIndex failed: %s", err)
+	}
+	stub.MockTransactionEnd("init")
+
+	iter, err := stub.QueryState(`{"selector":{"Integral":{"$gt":1000}},"sort":[{"Integral":"desc"}]}`)
+	if err != nil {
+		t.Fatalf("QueryState failed: %s", err)
+	}
+	var got []string
+	for iter.HasNext() {
+		key, _, _ := iter.Next()
+		got = append(got, key)
+	}
+	iter.Close()
+	if fmt.Sprint(got) != "[carol alice]" {
+		t.Fatalf("Expected [carol alice], got %v", got)
+	}
+
+	if _, err = stub.QueryState(`{"selector":{"Integral":{"$regex":"1"}}}`); err == nil {
+		t.Fatal("Expected an unsupported operator to be refused")
+	}
+	if err = stub.DeclareIndex(""); err == nil {
+		t.Fatal("Expected an empty index field to be refused")
+	}
+}
+
+func TestMockStubGetHistoryForKey(t *testing.T) {
+	stub := NewMockStub("historyTest", new(kvChaincode))
+	stub.MockTxTimestamp(&timestamp.Timestamp{Seconds: 1000})
+	stub.MockInvoke("tx1", "put", []string{"a", "1"})
+	stub.MockInvoke("tx2", "put", []string{"b", "2"})
+	stub.MockInvoke("tx3", "put", []string{"a", "3", "fail"})
+	stub.MockInvoke("tx4", "del", []string{"a"})
+
+	history, completeFromBlock, err := stub.GetHistoryForKey("a")
+	if err != nil {
+		t.Fatalf("GetHistoryForKey failed: %s", err)
+	}
+	if completeFromBlock != 0 {
+		t.Fatalf("Expected the history to be complete from block 0, got %d", completeFromBlock)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 modifications of a, got %v", history)
+	}
+	if history[0].TxID != "tx1" || string(history[0].Value) != "1" || history[0].IsDelete || history[0].Timestamp.Seconds != 1000 {
+		t.Fatalf("Unexpected first modification %v", history[0])
+	}
+	if history[1].TxID != "tx4" || !history[1].IsDelete || history[1].BlockNumber <= history[0].BlockNumber {
+		t.Fatalf("Unexpected second modification %v", history[1])
+	}
 }
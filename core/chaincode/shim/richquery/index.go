@@ -0,0 +1,193 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package richquery
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// IndexDeclarationPrefix is the prefix of the state keys under which a chaincode declares the
+// fields to be indexed for rich queries. Composite keys never start with it as they require a
+// non-empty object type.
+const IndexDeclarationPrefix = "\x00\x00richquery-index\x00"
+
+const (
+	numberTag = byte('n')
+	stringTag = byte('s')
+)
+
+// IndexDeclarationKey returns the state key that declares an index on field
+func IndexDeclarationKey(field string) (string, error) {
+	if field == "" || strings.HasPrefix(field, "$") || strings.ContainsRune(field, 0) {
+		return "", fmt.Errorf("Invalid index field [%s]", field)
+	}
+	return IndexDeclarationPrefix + field, nil
+}
+
+// IndexedField returns the field declared by an index declaration key
+func IndexedField(key string) (string, bool) {
+	if !IsReservedKey(key) {
+		return "", false
+	}
+	return key[len(IndexDeclarationPrefix):], true
+}
+
+// IsReservedKey returns true if key is an index declaration key
+func IsReservedKey(key string) bool {
+	return strings.HasPrefix(key, IndexDeclarationPrefix)
+}
+
+// IndexValue returns the encoding of the value of field in the JSON object value as used by
+// index entries. Only numbers and strings are indexed; ok is false for any other value.
+// Encoded numbers sort in numeric order and encoded strings in lexical order.
+func IndexValue(field string, value []byte) (encoded []byte, ok bool) {
+	document := decodeDocument(value)
+	if document == nil {
+		return nil, false
+	}
+	fieldValue, found := lookup(document, splitPath(nil, field))
+	if !found {
+		return nil, false
+	}
+	return encodeIndexValue(fieldValue)
+}
+
+// IndexRange is a range [Start, End) of encoded values of an indexed field
+type IndexRange struct {
+	Field string
+	Start []byte
+	End   []byte
+}
+
+// IndexRange returns a range of encoded values of one of the indexed fields that holds the values of
+// every document the query can select, or nil if the query does not constrain any indexed field.
+// The range may hold documents that the query does not select, results must still be checked with Select.
+func (query *Query) IndexRange(indexedFields []string) *IndexRange {
+	var best *IndexRange
+	for _, field := range indexedFields {
+		r, equality := query.indexRange(field)
+		if r == nil {
+			continue
+		}
+		if equality {
+			return r
+		}
+		if best == nil {
+			best = r
+		}
+	}
+	return best
+}
+
+func (query *Query) indexRange(field string) (r *IndexRange, equality bool) {
+	path := splitPath(nil, field)
+	var tag byte
+	var lower, upper interface{}
+	for _, c := range conjunction(query.selector) {
+		if !samePath(c.path, path) {
+			continue
+		}
+		switch c.operator {
+		case "$eq", "$gt", "$gte", "$lt", "$lte":
+		default:
+			continue
+		}
+		encoded, ok := encodeIndexValue(c.operand)
+		if !ok {
+			continue
+		}
+		if tag != 0 && encoded[0] != tag {
+			// Conditions on different types cannot all hold, but leave that for Select to decide
+			return nil, false
+		}
+		tag = encoded[0]
+		if c.operator == "$eq" || c.operator == "$gt" || c.operator == "$gte" {
+			if cmp, _ := compare(c.operand, lower); lower == nil || cmp > 0 {
+				lower = c.operand
+			}
+		}
+		if c.operator == "$eq" || c.operator == "$lt" || c.operator == "$lte" {
+			if cmp, _ := compare(c.operand, upper); upper == nil || cmp < 0 {
+				upper = c.operand
+			}
+		}
+		equality = equality || c.operator == "$eq"
+	}
+	if tag == 0 {
+		return nil, false
+	}
+	r = &IndexRange{Field: field, Start: []byte{tag}, End: []byte{tag + 1}}
+	if lower != nil {
+		r.Start, _ = encodeIndexValue(lower)
+	}
+	if upper != nil {
+		// Index entries append a zero byte to the encoded value, so this bound
+		// is past every entry holding the upper value
+		encoded, _ := encodeIndexValue(upper)
+		r.End = append(encoded, 1)
+	}
+	return r, equality
+}
+
+// conjunction returns the field conditions that every selected document satisfies
+func conjunction(c condition) []*fieldCondition {
+	switch t := c.(type) {
+	case *fieldCondition:
+		return []*fieldCondition{t}
+	case andCondition:
+		var conditions []*fieldCondition
+		for _, sub := range t {
+			conditions = append(conditions, conjunction(sub)...)
+		}
+		return conditions
+	}
+	return nil
+}
+
+func samePath(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func encodeIndexValue(value interface{}) ([]byte, bool) {
+	switch v := value.(type) {
+	case float64:
+		bits := math.Float64bits(v)
+		if bits&(1<<63) != 0 {
+			bits = ^bits
+		} else {
+			bits |= 1 << 63
+		}
+		encoded := make([]byte, 9)
+		encoded[0] = numberTag
+		binary.BigEndian.PutUint64(encoded[1:], bits)
+		return encoded, true
+	case string:
+		return append([]byte{stringTag}, v...), true
+	}
+	return nil, false
+}
@@ -0,0 +1,412 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package richquery evaluates rich queries over chaincode state whose values are JSON objects.
+// A query is a JSON document of the form
+//
+//	{"selector": {...}, "sort": [{"field": "asc"}, ...], "limit": n}
+//
+// The selector supports field equality, the comparison operators $eq, $ne, $gt, $gte, $lt,
+// $lte, $in and $exists, and the combinators $and and $or. Nested fields are addressed either
+// with nested selectors or with dotted field names. Ordering comparisons only match values of
+// the same type (numbers with numbers, strings with strings).
+package richquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Query is a parsed rich query
+type Query struct {
+	selector condition
+	sort     []sortField
+	limit    int
+}
+
+// Result is a key-value selected by a query
+type Result struct {
+	Key      string
+	Value    []byte
+	document map[string]interface{}
+}
+
+type sortField struct {
+	path       []string
+	descending bool
+}
+
+type queryDocument struct {
+	Selector map[string]interface{} `json:"selector"`
+	Sort     []interface{}          `json:"sort"`
+	Limit    int                    `json:"limit"`
+}
+
+// Parse parses a rich query
+func Parse(query string) (*Query, error) {
+	var doc queryDocument
+	decoder := json.NewDecoder(strings.NewReader(query))
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("Invalid query: %s", err)
+	}
+	if doc.Selector == nil {
+		return nil, fmt.Errorf("Invalid query: a selector is required")
+	}
+	if doc.Limit < 0 {
+		return nil, fmt.Errorf("Invalid query: limit must not be negative")
+	}
+	selector, err := parseSelector(nil, doc.Selector)
+	if err != nil {
+		return nil, err
+	}
+	sortFields, err := parseSort(doc.Sort)
+	if err != nil {
+		return nil, err
+	}
+	return &Query{selector, sortFields, doc.Limit}, nil
+}
+
+// Select returns the result for key if value is a JSON object matched by the query, and nil otherwise.
+// Keys reserved for index declarations are never selected.
+func (query *Query) Select(key string, value []byte) *Result {
+	if IsReservedKey(key) {
+		return nil
+	}
+	document := decodeDocument(value)
+	if document == nil || !query.selector.matches(document) {
+		return nil
+	}
+	return &Result{key, value, document}
+}
+
+// Arrange sorts the results as requested by the query, or by key if the query has no sort,
+// and truncates them to the query limit
+func (query *Query) Arrange(results []*Result) []*Result {
+	sort.Sort(&resultSorter{results, query.sort})
+	if query.limit > 0 && len(results) > query.limit {
+		results = results[:query.limit]
+	}
+	return results
+}
+
+func decodeDocument(value []byte) map[string]interface{} {
+	var document map[string]interface{}
+	if err := json.Unmarshal(value, &document); err != nil {
+		return nil
+	}
+	return document
+}
+
+func parseSort(sortSpec []interface{}) ([]sortField, error) {
+	var fields []sortField
+	for _, spec := range sortSpec {
+		switch s := spec.(type) {
+		case string:
+			fields = append(fields, sortField{splitPath(nil, s), false})
+		case map[string]interface{}:
+			if len(s) != 1 {
+				return nil, fmt.Errorf("Invalid sort: each entry must name exactly one field")
+			}
+			for field, direction := range s {
+				switch direction {
+				case "asc":
+					fields = append(fields, sortField{splitPath(nil, field), false})
+				case "desc":
+					fields = append(fields, sortField{splitPath(nil, field), true})
+				default:
+					return nil, fmt.Errorf("Invalid sort direction for field %s: %v", field, direction)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("Invalid sort: %v", spec)
+		}
+	}
+	return fields, nil
+}
+
+type condition interface {
+	matches(document map[string]interface{}) bool
+}
+
+type andCondition []condition
+
+type orCondition []condition
+
+type fieldCondition struct {
+	path     []string
+	operator string
+	operand  interface{}
+}
+
+func (conditions andCondition) matches(document map[string]interface{}) bool {
+	for _, c := range conditions {
+		if !c.matches(document) {
+			return false
+		}
+	}
+	return true
+}
+
+func (conditions orCondition) matches(document map[string]interface{}) bool {
+	for _, c := range conditions {
+		if c.matches(document) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *fieldCondition) matches(document map[string]interface{}) bool {
+	value, found := lookup(document, c.path)
+	switch c.operator {
+	case "$eq":
+		return found && equal(value, c.operand)
+	case "$ne":
+		return !found || !equal(value, c.operand)
+	case "$gt", "$gte", "$lt", "$lte":
+		if !found {
+			return false
+		}
+		cmp, ok := compare(value, c.operand)
+		if !ok {
+			return false
+		}
+		switch c.operator {
+		case "$gt":
+			return cmp > 0
+		case "$gte":
+			return cmp >= 0
+		case "$lt":
+			return cmp < 0
+		default:
+			return cmp <= 0
+		}
+	case "$in":
+		if !found {
+			return false
+		}
+		for _, candidate := range c.operand.([]interface{}) {
+			if equal(value, candidate) {
+				return true
+			}
+		}
+		return false
+	case "$exists":
+		return found == c.operand.(bool)
+	}
+	return false
+}
+
+func parseSelector(path []string, selector map[string]interface{}) (condition, error) {
+	// Walk the fields in a fixed order so that equal selectors produce equal conditions
+	var fields []string
+	for field := range selector {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var conditions andCondition
+	for _, field := range fields {
+		value := selector[field]
+		switch {
+		case field == "$and" || field == "$or":
+			list, ok := value.([]interface{})
+			if !ok || len(list) == 0 {
+				return nil, fmt.Errorf("Invalid selector: %s requires a non-empty array of selectors", field)
+			}
+			var subConditions []condition
+			for _, item := range list {
+				subSelector, ok := item.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("Invalid selector: %s requires a non-empty array of selectors", field)
+				}
+				subCondition, err := parseSelector(path, subSelector)
+				if err != nil {
+					return nil, err
+				}
+				subConditions = append(subConditions, subCondition)
+			}
+			if field == "$and" {
+				conditions = append(conditions, andCondition(subConditions))
+			} else {
+				conditions = append(conditions, orCondition(subConditions))
+			}
+		case strings.HasPrefix(field, "$"):
+			if len(path) == 0 {
+				return nil, fmt.Errorf("Invalid selector: operator %s must be applied to a field", field)
+			}
+			c, err := parseOperator(path, field, value)
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, c)
+		default:
+			fieldPath := splitPath(path, field)
+			if subSelector, ok := value.(map[string]interface{}); ok {
+				subCondition, err := parseSelector(fieldPath, subSelector)
+				if err != nil {
+					return nil, err
+				}
+				conditions = append(conditions, subCondition)
+			} else {
+				conditions = append(conditions, &fieldCondition{fieldPath, "$eq", value})
+			}
+		}
+	}
+	return conditions, nil
+}
+
+func parseOperator(path []string, operator string, operand interface{}) (condition, error) {
+	switch operator {
+	case "$eq", "$ne":
+	case "$gt", "$gte", "$lt", "$lte":
+		switch operand.(type) {
+		case float64, string:
+		default:
+			return nil, fmt.Errorf("Invalid selector: operator %s requires a number or a string", operator)
+		}
+	case "$in":
+		if _, ok := operand.([]interface{}); !ok {
+			return nil, fmt.Errorf("Invalid selector: operator %s requires an array", operator)
+		}
+	case "$exists":
+		if _, ok := operand.(bool); !ok {
+			return nil, fmt.Errorf("Invalid selector: operator %s requires a boolean", operator)
+		}
+	default:
+		return nil, fmt.Errorf("Invalid selector: unsupported operator %s", operator)
+	}
+	return &fieldCondition{path, operator, operand}, nil
+}
+
+func splitPath(path []string, field string) []string {
+	fieldPath := make([]string, len(path), len(path)+1)
+	copy(fieldPath, path)
+	return append(fieldPath, strings.Split(field, ".")...)
+}
+
+func lookup(document map[string]interface{}, path []string) (interface{}, bool) {
+	var value interface{} = document
+	for _, name := range path {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, ok = object[name]
+		if !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func equal(a, b interface{}) bool {
+	switch a.(type) {
+	case nil, bool, float64, string:
+		return a == b
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// compare orders two numbers or two strings. ok is false for any other combination of values
+func compare(a, b interface{}) (cmp int, ok bool) {
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(x, y), true
+	}
+	return 0, false
+}
+
+// typeRank orders values of different types when sorting results:
+// missing < null < booleans < numbers < strings < arrays and objects
+func typeRank(value interface{}, found bool) int {
+	if !found {
+		return 0
+	}
+	switch value.(type) {
+	case nil:
+		return 1
+	case bool:
+		return 2
+	case float64:
+		return 3
+	case string:
+		return 4
+	}
+	return 5
+}
+
+func sortCompare(a, b interface{}, aFound, bFound bool) int {
+	aRank, bRank := typeRank(a, aFound), typeRank(b, bFound)
+	if aRank != bRank {
+		return aRank - bRank
+	}
+	if x, ok := a.(bool); ok {
+		y := b.(bool)
+		switch {
+		case !x && y:
+			return -1
+		case x && !y:
+			return 1
+		}
+		return 0
+	}
+	cmp, _ := compare(a, b)
+	return cmp
+}
+
+type resultSorter struct {
+	results []*Result
+	fields  []sortField
+}
+
+func (s *resultSorter) Len() int      { return len(s.results) }
+func (s *resultSorter) Swap(i, j int) { s.results[i], s.results[j] = s.results[j], s.results[i] }
+func (s *resultSorter) Less(i, j int) bool {
+	a, b := s.results[i], s.results[j]
+	for _, field := range s.fields {
+		aValue, aFound := lookup(a.document, field.path)
+		bValue, bFound := lookup(b.document, field.path)
+		cmp := sortCompare(aValue, bValue, aFound, bFound)
+		if cmp == 0 {
+			continue
+		}
+		if field.descending {
+			return cmp > 0
+		}
+		return cmp < 0
+	}
+	return a.Key < b.Key
+}
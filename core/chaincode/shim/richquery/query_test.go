@@ -0,0 +1,169 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package richquery
+
+import (
+	"bytes"
+	"testing"
+)
+
+var testDocuments = map[string]string{
+	"alice": `{"Name":"alice","Integral":1500,"Status":"active","Profile":{"City":"Shanghai"}}`,
+	"bob":   `{"Name":"bob","Integral":800,"Status":"active","Profile":{"City":"Beijing"}}`,
+	"carol": `{"Name":"carol","Integral":2500,"Status":"frozen"}`,
+	"dave":  `{"Name":"dave","Integral":"n/a","Status":"active"}`,
+	"erin":  `not a json object`,
+}
+
+func runQuery(t *testing.T, query string) []string {
+	q, err := Parse(query)
+	if err != nil {
+		t.Fatalf("Error parsing query %s: %s", query, err)
+	}
+	var results []*Result
+	for key, value := range testDocuments {
+		if result := q.Select(key, []byte(value)); result != nil {
+			results = append(results, result)
+		}
+	}
+	var keys []string
+	for _, result := range q.Arrange(results) {
+		keys = append(keys, result.Key)
+	}
+	return keys
+}
+
+func checkKeys(t *testing.T, query string, expected ...string) {
+	keys := runQuery(t, query)
+	if len(keys) != len(expected) {
+		t.Fatalf("Query %s returned %v, expected %v", query, keys, expected)
+	}
+	for i := range keys {
+		if keys[i] != expected[i] {
+			t.Fatalf("Query %s returned %v, expected %v", query, keys, expected)
+		}
+	}
+}
+
+func TestQuery_Selectors(t *testing.T) {
+	checkKeys(t, `{"selector":{}}`, "alice", "bob", "carol", "dave")
+	checkKeys(t, `{"selector":{"Status":"active"}}`, "alice", "bob", "dave")
+	checkKeys(t, `{"selector":{"Integral":{"$gt":1000}}}`, "alice", "carol")
+	checkKeys(t, `{"selector":{"Integral":{"$gte":800,"$lt":2500}}}`, "alice", "bob")
+	checkKeys(t, `{"selector":{"Integral":{"$ne":800}}}`, "alice", "carol", "dave")
+	checkKeys(t, `{"selector":{"Name":{"$in":["bob","carol","zed"]}}}`, "bob", "carol")
+	checkKeys(t, `{"selector":{"Profile":{"$exists":false}}}`, "carol", "dave")
+	checkKeys(t, `{"selector":{"Profile.City":"Beijing"}}`, "bob")
+	checkKeys(t, `{"selector":{"Profile":{"City":{"$lt":"C"}}}}`, "bob")
+	checkKeys(t, `{"selector":{"$or":[{"Status":"frozen"},{"Integral":{"$lte":800}}]}}`, "bob", "carol")
+	checkKeys(t, `{"selector":{"$and":[{"Status":"active"},{"Integral":{"$gt":1000}}]}}`, "alice")
+}
+
+func TestQuery_SortAndLimit(t *testing.T) {
+	checkKeys(t, `{"selector":{},"sort":[{"Integral":"desc"}]}`, "dave", "carol", "alice", "bob")
+	checkKeys(t, `{"selector":{},"sort":["Integral"]}`, "bob", "alice", "carol", "dave")
+	checkKeys(t, `{"selector":{},"sort":[{"Status":"desc"},{"Name":"desc"}]}`, "carol", "dave", "bob", "alice")
+	checkKeys(t, `{"selector":{"Integral":{"$gt":0}},"sort":[{"Integral":"desc"}],"limit":1}`, "carol")
+}
+
+func TestQuery_InvalidQueries(t *testing.T) {
+	invalid := []string{
+		`not json`,
+		`{"sort":["Name"]}`,
+		`{"selector":{"$gt":1}}`,
+		`{"selector":{"Integral":{"$gt":true}}}`,
+		`{"selector":{"Integral":{"$regex":"^1"}}}`,
+		`{"selector":{"$or":[]}}`,
+		`{"selector":{"Name":{"$in":"bob"}}}`,
+		`{"selector":{},"sort":[{"Name":"up"}]}`,
+		`{"selector":{},"limit":-1}`,
+	}
+	for _, query := range invalid {
+		if _, err := Parse(query); err == nil {
+			t.Fatalf("Expected an error parsing query %s", query)
+		}
+	}
+}
+
+func TestQuery_ReservedKeysAreNotSelected(t *testing.T) {
+	key, err := IndexDeclarationKey("Integral")
+	if err != nil {
+		t.Fatalf("Error creating index declaration key: %s", err)
+	}
+	q, _ := Parse(`{"selector":{}}`)
+	if q.Select(key, []byte(`{}`)) != nil {
+		t.Fatalf("Index declaration key should not be selected")
+	}
+	if field, ok := IndexedField(key); !ok || field != "Integral" {
+		t.Fatalf("Expected declared field Integral, got %s", field)
+	}
+	if _, err := IndexDeclarationKey("$gt"); err == nil {
+		t.Fatalf("Expected an error declaring an index on an operator")
+	}
+}
+
+func TestIndexValue_Ordering(t *testing.T) {
+	values := []string{`{"v":-1e10}`, `{"v":-2.5}`, `{"v":0}`, `{"v":3}`, `{"v":1500}`, `{"v":""}`, `{"v":"a"}`, `{"v":"ab"}`, `{"v":"b"}`}
+	var previous []byte
+	for _, value := range values {
+		encoded, ok := IndexValue("v", []byte(value))
+		if !ok {
+			t.Fatalf("Expected %s to be indexed", value)
+		}
+		if previous != nil && bytes.Compare(previous, encoded) >= 0 {
+			t.Fatalf("Encoded values out of order at %s", value)
+		}
+		previous = encoded
+	}
+	if _, ok := IndexValue("v", []byte(`{"v":true}`)); ok {
+		t.Fatalf("Booleans should not be indexed")
+	}
+	if _, ok := IndexValue("v", []byte(`{"w":1}`)); ok {
+		t.Fatalf("Missing fields should not be indexed")
+	}
+}
+
+func TestIndexRange(t *testing.T) {
+	q, _ := Parse(`{"selector":{"Status":"active","Integral":{"$gt":1000,"$lte":2000}}}`)
+	if r := q.IndexRange([]string{"Name"}); r != nil {
+		t.Fatalf("Expected no index range for an unconstrained field")
+	}
+	r := q.IndexRange([]string{"Integral", "Status"})
+	if r == nil || r.Field != "Status" {
+		t.Fatalf("Expected the equality on Status to be preferred, got %#v", r)
+	}
+	r = q.IndexRange([]string{"Integral"})
+	if r == nil || r.Field != "Integral" {
+		t.Fatalf("Expected a range on Integral, got %#v", r)
+	}
+	inRange := func(value string) bool {
+		encoded, _ := IndexValue("Integral", []byte(value))
+		entry := append(encoded, 0)
+		return bytes.Compare(entry, r.Start) >= 0 && bytes.Compare(entry, r.End) < 0
+	}
+	if !inRange(`{"Integral":1500}`) || !inRange(`{"Integral":2000}`) {
+		t.Fatalf("Expected values within the bounds to be in range")
+	}
+	if inRange(`{"Integral":800}`) || inRange(`{"Integral":2001}`) || inRange(`{"Integral":"1500"}`) {
+		t.Fatalf("Expected values outside the bounds to be out of range")
+	}
+
+	q, _ = Parse(`{"selector":{"$or":[{"Integral":1},{"Integral":2}]}}`)
+	if r := q.IndexRange([]string{"Integral"}); r != nil {
+		t.Fatalf("Expected no index range for a disjunction")
+	}
+}
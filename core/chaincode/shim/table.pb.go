@@ -11,6 +11,7 @@ It is generated from these files:
 It has these top-level messages:
 	ColumnDefinition
 	Table
+	TableSchema
 	Column
 	Row
 */
@@ -71,6 +72,10 @@ type ColumnDefinition struct {
 	Name string                `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
 	Type ColumnDefinition_Type `protobuf:"varint,2,opt,name=type,enum=shim.ColumnDefinition_Type" json:"type,omitempty"`
 	Key  bool                  `protobuf:"varint,3,opt,name=key" json:"key,omitempty"`
+	// nullable columns accept a Column without a value
+	Nullable bool `protobuf:"varint,4,opt,name=nullable" json:"nullable,omitempty"`
+	// indexed columns can be looked up with GetRowsByIndex
+	Indexed bool `protobuf:"varint,5,opt,name=indexed" json:"indexed,omitempty"`
 }
 
 func (m *ColumnDefinition) Reset()                    { *m = ColumnDefinition{} }
@@ -81,6 +86,11 @@ func (*ColumnDefinition) Descriptor() ([]byte, []int) { return fileDescriptor0,
 type Table struct {
 	Name              string              `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
 	ColumnDefinitions []*ColumnDefinition `protobuf:"bytes,2,rep,name=columnDefinitions" json:"columnDefinitions,omitempty"`
+	// version is incremented each time AlterTable adds or drops columns
+	Version uint64 `protobuf:"varint,3,opt,name=version" json:"version,omitempty"`
+	// previousSchemas holds the columns of every previous version, which
+	// rows written before an alteration are migrated from when read
+	PreviousSchemas []*TableSchema `protobuf:"bytes,4,rep,name=previousSchemas" json:"previousSchemas,omitempty"`
 }
 
 func (m *Table) Reset()                    { *m = Table{} }
@@ -95,6 +105,30 @@ func (m *Table) GetColumnDefinitions() []*ColumnDefinition {
 	return nil
 }
 
+func (m *Table) GetPreviousSchemas() []*TableSchema {
+	if m != nil {
+		return m.PreviousSchemas
+	}
+	return nil
+}
+
+type TableSchema struct {
+	Version           uint64              `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	ColumnDefinitions []*ColumnDefinition `protobuf:"bytes,2,rep,name=columnDefinitions" json:"columnDefinitions,omitempty"`
+}
+
+func (m *TableSchema) Reset()                    { *m = TableSchema{} }
+func (m *TableSchema) String() string            { return proto.CompactTextString(m) }
+func (*TableSchema) ProtoMessage()               {}
+func (*TableSchema) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *TableSchema) GetColumnDefinitions() []*ColumnDefinition {
+	if m != nil {
+		return m.ColumnDefinitions
+	}
+	return nil
+}
+
 type Column struct {
 	// Types that are valid to be assigned to Value:
 	//	*Column_String_
@@ -110,11 +144,9 @@ type Column struct {
 func (m *Column) Reset()                    { *m = Column{} }
 func (m *Column) String() string            { return proto.CompactTextString(m) }
 func (*Column) ProtoMessage()               {}
-func (*Column) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (*Column) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
 
-type isColumn_Value interface {
-	isColumn_Value()
-}
+type isColumn_Value interface{ isColumn_Value() }
 
 type Column_String_ struct {
 	String_ string `protobuf:"bytes,1,opt,name=string,oneof"`
@@ -344,12 +376,14 @@ func _Column_OneofSizer(msg proto.Message) (n int) {
 
 type Row struct {
 	Columns []*Column `protobuf:"bytes,1,rep,name=columns" json:"columns,omitempty"`
+	// version of the table the columns were written with
+	Version uint64 `protobuf:"varint,2,opt,name=version" json:"version,omitempty"`
 }
 
 func (m *Row) Reset()                    { *m = Row{} }
 func (m *Row) String() string            { return proto.CompactTextString(m) }
 func (*Row) ProtoMessage()               {}
-func (*Row) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+func (*Row) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
 
 func (m *Row) GetColumns() []*Column {
 	if m != nil {
@@ -361,35 +395,43 @@ func (m *Row) GetColumns() []*Column {
 func init() {
 	proto.RegisterType((*ColumnDefinition)(nil), "shim.ColumnDefinition")
 	proto.RegisterType((*Table)(nil), "shim.Table")
+	proto.RegisterType((*TableSchema)(nil), "shim.TableSchema")
 	proto.RegisterType((*Column)(nil), "shim.Column")
 	proto.RegisterType((*Row)(nil), "shim.Row")
 	proto.RegisterEnum("shim.ColumnDefinition_Type", ColumnDefinition_Type_name, ColumnDefinition_Type_value)
 }
 
-func init() { proto.RegisterFile("chaincode.proto", fileDescriptor0) }
+func init() { proto.RegisterFile("table.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 351 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x6c, 0x52, 0xcd, 0x6a, 0xea, 0x40,
-	0x14, 0x36, 0x66, 0x12, 0xf5, 0x5c, 0xef, 0xbd, 0xe9, 0x50, 0x24, 0xd0, 0x4d, 0xc9, 0xa2, 0xb8,
-	0x69, 0x0a, 0x51, 0x7c, 0x00, 0x6b, 0x69, 0x85, 0xa2, 0x30, 0xc6, 0x45, 0x97, 0x89, 0x9d, 0xd6,
-	0xd0, 0x38, 0x23, 0x26, 0xb6, 0xf8, 0x70, 0x85, 0x3e, 0x5a, 0xcf, 0xcc, 0x24, 0x50, 0xac, 0xbb,
-	0xf3, 0xfd, 0x9d, 0xcc, 0xf9, 0x08, 0xfc, 0x5f, 0xad, 0x93, 0x4c, 0xac, 0xe4, 0x33, 0x0f, 0xb7,
-	0x3b, 0x59, 0x4a, 0x4a, 0x8a, 0x75, 0xb6, 0x09, 0xbe, 0x2c, 0xf0, 0x6e, 0x65, 0xbe, 0xdf, 0x88,
-	0x09, 0x7f, 0xc9, 0x44, 0x56, 0x66, 0x52, 0x50, 0x0a, 0x44, 0x24, 0x1b, 0xee, 0x5b, 0x97, 0x56,
-	0xbf, 0xc3, 0xf4, 0x4c, 0x6f, 0x80, 0x94, 0x87, 0x2d, 0xf7, 0x9b, 0xc8, 0xfd, 0x8b, 0x2e, 0x42,
-	0x95, 0x0e, 0x8f, 0x93, 0x61, 0x8c, 0x16, 0xa6, 0x8d, 0xd4, 0x03, 0xfb, 0x8d, 0x1f, 0x7c, 0x1b,
-	0xfd, 0x6d, 0xa6, 0xc6, 0x60, 0x09, 0x44, 0xe9, 0x14, 0xc0, 0x5d, 0xc4, 0x6c, 0x3a, 0xbb, 0xf7,
-	0x1a, 0xb4, 0x03, 0xce, 0x74, 0x16, 0x0f, 0x22, 0xcf, 0xaa, 0xc6, 0xd1, 0xd0, 0x6b, 0x2a, 0xc7,
-	0xd2, 0xd0, 0x76, 0x3d, 0x23, 0x4f, 0x94, 0x65, 0xfc, 0x14, 0xdf, 0x2d, 0x3c, 0x87, 0xb6, 0x81,
-	0x8c, 0xe7, 0xf3, 0x47, 0xcf, 0x0d, 0x12, 0x70, 0xe2, 0x24, 0xcd, 0xf9, 0xc9, 0x67, 0x4f, 0xe0,
-	0x6c, 0x75, 0xf4, 0xc8, 0x02, 0x6f, 0xb0, 0xfb, 0x7f, 0xa2, 0xde, 0xe9, 0x1b, 0xd8, 0xef, 0x40,
-	0xf0, 0x69, 0x81, 0x6b, 0x7c, 0xd4, 0x07, 0xb7, 0x28, 0x77, 0x99, 0x78, 0x35, 0x9f, 0x79, 0x68,
-	0xb0, 0x0a, 0xd3, 0x1e, 0x38, 0x99, 0x28, 0x07, 0x91, 0xae, 0xc8, 0x41, 0xc1, 0xc0, 0x8a, 0x1f,
-	0x0d, 0x75, 0x15, 0x76, 0xc5, 0x8f, 0x86, 0x6a, 0xd3, 0xde, 0x04, 0x08, 0x0a, 0x7f, 0xd5, 0x26,
-	0x83, 0x6b, 0x05, 0x23, 0x0e, 0x2a, 0xa4, 0x56, 0x30, 0x83, 0xbb, 0xd2, 0x43, 0xc9, 0x0b, 0xdf,
-	0x45, 0xa1, 0xab, 0x76, 0x69, 0x48, 0xcf, 0x81, 0xa4, 0x52, 0xe6, 0x7e, 0x4b, 0xb5, 0x8d, 0xb4,
-	0x46, 0xe3, 0x16, 0x38, 0xef, 0x49, 0xbe, 0xe7, 0xc1, 0x35, 0xd8, 0x4c, 0x7e, 0xd0, 0x2b, 0x68,
-	0x99, 0xdb, 0x0a, 0x7c, 0xbc, 0xaa, 0xa0, 0xfb, 0xb3, 0x02, 0x56, 0x8b, 0xa9, 0xab, 0xff, 0x90,
-	0xc1, 0x77, 0x00, 0x00, 0x00, 0xff, 0xff, 0x55, 0xda, 0xb2, 0xc7, 0x34, 0x02, 0x00, 0x00,
+	// 462 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xac, 0x53, 0xcf, 0x8f, 0x93, 0x40,
+	0x14, 0xee, 0x94, 0x81, 0x76, 0x5f, 0x57, 0x65, 0x27, 0xa6, 0x99, 0xe8, 0x85, 0x10, 0x63, 0x38,
+	0x61, 0xd2, 0x12, 0x2e, 0xde, 0x70, 0xcd, 0x76, 0x13, 0xb3, 0x6b, 0xa6, 0xec, 0xc1, 0x63, 0xbb,
+	0x1d, 0x5b, 0x22, 0x30, 0x84, 0x81, 0x2a, 0xff, 0x99, 0x17, 0xff, 0x2d, 0xcf, 0x66, 0x66, 0x8a,
+	0xe9, 0xd6, 0x1e, 0xbd, 0xbd, 0xef, 0xfd, 0xf8, 0xde, 0x7c, 0x1f, 0x0f, 0x98, 0x34, 0xab, 0x75,
+	0xce, 0xc3, 0xaa, 0x16, 0x8d, 0x20, 0x58, 0xee, 0xb2, 0xc2, 0xff, 0x8d, 0xc0, 0xfd, 0x20, 0xf2,
+	0xb6, 0x28, 0xaf, 0xf9, 0xd7, 0xac, 0xcc, 0x9a, 0x4c, 0x94, 0x84, 0x00, 0x2e, 0x57, 0x05, 0xa7,
+	0xc8, 0x43, 0xc1, 0x05, 0xd3, 0x31, 0x79, 0x07, 0xb8, 0xe9, 0x2a, 0x4e, 0x87, 0x1e, 0x0a, 0x9e,
+	0xcf, 0x5e, 0x87, 0x6a, 0x3a, 0x3c, 0x9d, 0x0c, 0xd3, 0xae, 0xe2, 0x4c, 0x37, 0x12, 0x17, 0xac,
+	0x6f, 0xbc, 0xa3, 0x96, 0x87, 0x82, 0x31, 0x53, 0x21, 0x79, 0x05, 0xe3, 0xb2, 0xcd, 0x73, 0xf5,
+	0x06, 0x8a, 0x75, 0xfa, 0x2f, 0x26, 0x14, 0x46, 0x59, 0xb9, 0xe1, 0x3f, 0xf8, 0x86, 0xda, 0xba,
+	0xd4, 0x43, 0xff, 0x01, 0xb0, 0x62, 0x25, 0x00, 0xce, 0x32, 0x65, 0xb7, 0x77, 0x37, 0xee, 0x80,
+	0x5c, 0x80, 0x7d, 0x7b, 0x97, 0xce, 0x67, 0x2e, 0x3a, 0x84, 0x71, 0xe4, 0x0e, 0x55, 0xc7, 0x83,
+	0x49, 0x5b, 0x7d, 0x1c, 0x47, 0x2e, 0x56, 0x2d, 0xc9, 0x97, 0xf4, 0xe3, 0xd2, 0xb5, 0xc9, 0x18,
+	0x70, 0x72, 0x7f, 0xff, 0xc9, 0x75, 0xfc, 0x9f, 0x08, 0xec, 0x54, 0xaf, 0x3e, 0xa7, 0xf6, 0x1a,
+	0xae, 0x1e, 0x4f, 0xb4, 0x49, 0x3a, 0xf4, 0xac, 0x60, 0x32, 0x9b, 0x9e, 0x97, 0xce, 0xfe, 0x1d,
+	0x50, 0xa2, 0xf6, 0xbc, 0x96, 0x99, 0x28, 0xb5, 0x0d, 0x98, 0xf5, 0x90, 0xbc, 0x87, 0x17, 0x55,
+	0xcd, 0xf7, 0x99, 0x68, 0xe5, 0xf2, 0x71, 0xc7, 0x8b, 0x95, 0xa4, 0x58, 0xb3, 0x5f, 0x19, 0x76,
+	0xfd, 0x32, 0x53, 0x61, 0xa7, 0x9d, 0x7e, 0x01, 0x93, 0xa3, 0xfa, 0xf1, 0x16, 0xf4, 0x74, 0xcb,
+	0x7f, 0x51, 0xe1, 0xff, 0x42, 0xe0, 0x98, 0x3e, 0x42, 0xc1, 0x91, 0x4d, 0x9d, 0x95, 0x5b, 0x63,
+	0xd6, 0x62, 0xc0, 0x0e, 0x98, 0x4c, 0xc1, 0xce, 0xca, 0x66, 0x3e, 0xd3, 0xf7, 0x61, 0x2f, 0x06,
+	0xcc, 0xc0, 0x43, 0x3e, 0x8e, 0xb4, 0x01, 0xd6, 0x21, 0x1f, 0x47, 0x8a, 0xa9, 0x35, 0x03, 0xea,
+	0x12, 0x9e, 0x29, 0x26, 0x83, 0xfb, 0x4a, 0x1c, 0xe9, 0x43, 0xc0, 0x7d, 0x25, 0x8e, 0x14, 0xd7,
+	0xba, 0x6b, 0xb8, 0xa4, 0x8e, 0x87, 0x82, 0x4b, 0xc5, 0xa5, 0x21, 0x79, 0x09, 0x78, 0x2d, 0x44,
+	0x4e, 0x47, 0xea, 0x70, 0x16, 0x03, 0xa6, 0x51, 0x32, 0x02, 0x7b, 0xbf, 0xca, 0x5b, 0xee, 0xdf,
+	0x80, 0xc5, 0xc4, 0x77, 0xf2, 0x16, 0x46, 0x46, 0x9b, 0xa4, 0x48, 0x5b, 0x70, 0x79, 0x6c, 0x01,
+	0xeb, 0x8b, 0xc7, 0x76, 0x0e, 0x9f, 0xd8, 0x99, 0xbc, 0x81, 0xa9, 0xa8, 0xb7, 0xe1, 0xae, 0xab,
+	0x78, 0x9d, 0xf3, 0xcd, 0x96, 0xd7, 0xe6, 0x57, 0x92, 0x09, 0xe8, 0xef, 0xf1, 0x59, 0x81, 0xb5,
+	0xa3, 0x73, 0xf3, 0x3f, 0x03, 0x00, 0xe2, 0x5a, 0x12, 0x33, 0x6d, 0x03, 0x00, 0x00,
 }
@@ -0,0 +1,251 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+const boltDBFileName = "openchain.db"
+
+// Bolt remaps the database file when it grows, which waits for the open read
+// transactions, i.e. snapshots and iterators, to end. Mapping a large region
+// up front lets the ledger write while it holds them.
+const boltDBInitialMmapSize = 1 << 30
+
+// boltDBStore keeps each column family of the openchainDB in a bucket of a
+// boltdb file, a pure Go store that needs no cgo
+type boltDBStore struct {
+	db *bolt.DB
+}
+
+func openBoltDBStore(dbPath string, cfNames []string, create bool) (Store, error) {
+	dbFile := filepath.Join(dbPath, boltDBFileName)
+	if create {
+		if err := os.MkdirAll(dbPath, 0755); err != nil {
+			return nil, err
+		}
+	} else if _, err := os.Stat(dbFile); err != nil {
+		return nil, fmt.Errorf("No boltdb database found in [%s]: %s", dbPath, err)
+	}
+
+	db, err := bolt.Open(dbFile, 0644, &bolt.Options{Timeout: time.Second, InitialMmapSize: boltDBInitialMmapSize})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, cfName := range cfNames {
+			if _, err := tx.CreateBucketIfNotExists([]byte(cfName)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &boltDBStore{db}, nil
+}
+
+// Bolt does not accept empty keys, which the ledger uses, e.g. for the root
+// of the state trie. Keys are stored with a leading zero byte, which keeps
+// their order.
+func boltKey(key []byte) []byte {
+	return append([]byte{0}, key...)
+}
+
+func boltBucket(tx *bolt.Tx, cf string) *bolt.Bucket {
+	bucket := tx.Bucket([]byte(cf))
+	if bucket == nil {
+		panic(fmt.Sprintf("Unknown column family [%s]", cf))
+	}
+	return bucket
+}
+
+func (store *boltDBStore) Get(cf string, key []byte) ([]byte, error) {
+	var value []byte
+	err := store.db.View(func(tx *bolt.Tx) error {
+		value = boltGet(tx, cf, key)
+		return nil
+	})
+	return value, err
+}
+
+// boltGet copies the value, which is only valid during the transaction
+func boltGet(tx *bolt.Tx, cf string, key []byte) []byte {
+	value := boltBucket(tx, cf).Get(boltKey(key))
+	if value == nil {
+		return nil
+	}
+	return makeCopy(value)
+}
+
+func (store *boltDBStore) Put(cf string, key []byte, value []byte) error {
+	return store.db.Update(func(tx *bolt.Tx) error {
+		return boltBucket(tx, cf).Put(boltKey(key), value)
+	})
+}
+
+func (store *boltDBStore) Delete(cf string, key []byte) error {
+	return store.db.Update(func(tx *bolt.Tx) error {
+		return boltBucket(tx, cf).Delete(boltKey(key))
+	})
+}
+
+func (store *boltDBStore) Write(writeBatch *WriteBatch) error {
+	return store.db.Update(func(tx *bolt.Tx) error {
+		for _, op := range writeBatch.ops {
+			var err error
+			if op.delete {
+				err = boltBucket(tx, op.cf).Delete(boltKey(op.key))
+			} else {
+				err = boltBucket(tx, op.cf).Put(boltKey(op.key), op.value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (store *boltDBStore) NewIterator(cf string) Iterator {
+	tx, err := store.db.Begin(false)
+	if err != nil {
+		panic(fmt.Sprintf("Error starting a boltdb transaction: %s", err))
+	}
+	return &boltDBIterator{tx: tx, cursor: boltBucket(tx, cf).Cursor(), ownsTx: true}
+}
+
+func (store *boltDBStore) NewSnapshot() Snapshot {
+	tx, err := store.db.Begin(false)
+	if err != nil {
+		panic(fmt.Sprintf("Error starting a boltdb transaction: %s", err))
+	}
+	return &boltDBSnapshot{tx}
+}
+
+func (store *boltDBStore) DropColumnFamily(cf string) error {
+	return store.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket([]byte(cf)); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket([]byte(cf))
+		return err
+	})
+}
+
+// Property supports boltdb.num-keys for a column family and boltdb.stats
+// for the whole store
+func (store *boltDBStore) Property(cf string, name string) string {
+	switch {
+	case cf != "" && name == "boltdb.num-keys":
+		var numKeys int
+		store.db.View(func(tx *bolt.Tx) error {
+			numKeys = boltBucket(tx, cf).Stats().KeyN
+			return nil
+		})
+		return fmt.Sprintf("%d", numKeys)
+	case cf == "" && name == "boltdb.stats":
+		return fmt.Sprintf("%+v", store.db.Stats())
+	}
+	return ""
+}
+
+func (store *boltDBStore) Close() {
+	if err := store.db.Close(); err != nil {
+		dbLogger.Errorf("Error closing boltdb: %s", err)
+	}
+}
+
+// boltDBSnapshot is a read-only boltdb transaction, which sees the store as
+// it was when the transaction started
+type boltDBSnapshot struct {
+	tx *bolt.Tx
+}
+
+func (snapshot *boltDBSnapshot) Get(cf string, key []byte) ([]byte, error) {
+	return boltGet(snapshot.tx, cf, key), nil
+}
+
+func (snapshot *boltDBSnapshot) NewIterator(cf string) Iterator {
+	return &boltDBIterator{tx: snapshot.tx, cursor: boltBucket(snapshot.tx, cf).Cursor()}
+}
+
+func (snapshot *boltDBSnapshot) Release() {
+	snapshot.tx.Rollback()
+}
+
+// boltDBIterator walks a bucket with a cursor of a read-only transaction.
+// The transaction is ended when the iterator is closed, unless it belongs
+// to a snapshot.
+type boltDBIterator struct {
+	tx     *bolt.Tx
+	cursor *bolt.Cursor
+	ownsTx bool
+	key    []byte
+	value  []byte
+}
+
+func (itr *boltDBIterator) Seek(key []byte) {
+	itr.key, itr.value = itr.cursor.Seek(boltKey(key))
+}
+
+func (itr *boltDBIterator) SeekToFirst() {
+	itr.key, itr.value = itr.cursor.First()
+}
+
+func (itr *boltDBIterator) Valid() bool {
+	return itr.key != nil
+}
+
+func (itr *boltDBIterator) ValidForPrefix(prefix []byte) bool {
+	return itr.key != nil && bytes.HasPrefix(itr.key[1:], prefix)
+}
+
+func (itr *boltDBIterator) Next() {
+	if itr.key != nil {
+		itr.key, itr.value = itr.cursor.Next()
+	}
+}
+
+func (itr *boltDBIterator) Prev() {
+	if itr.key != nil {
+		itr.key, itr.value = itr.cursor.Prev()
+	}
+}
+
+func (itr *boltDBIterator) Key() []byte {
+	return itr.key[1:]
+}
+
+func (itr *boltDBIterator) Value() []byte {
+	return itr.value
+}
+
+func (itr *boltDBIterator) Close() {
+	if itr.ownsTx {
+		itr.tx.Rollback()
+	}
+}
@@ -25,16 +25,9 @@ import (
 
 	"github.com/op/go-logging"
 	"github.com/spf13/viper"
-	"github.com/tecbot/gorocksdb"
 )
 
 var dbLogger = logging.MustGetLogger("db")
-var rocksDBLogLevelMap = map[string]gorocksdb.InfoLogLevel{
-	"debug": gorocksdb.DebugInfoLogLevel,
-	"info":  gorocksdb.InfoInfoLogLevel,
-	"warn":  gorocksdb.WarnInfoLogLevel,
-	"error": gorocksdb.ErrorInfoLogLevel,
-	"fatal": gorocksdb.FatalInfoLogLevel}
 
 const blockchainCF = "blockchainCF"
 const stateCF = "stateCF"
@@ -50,21 +43,27 @@ var columnfamilies = []string{
 	persistCF,    // persistent per-peer state (consensus)
 }
 
-// OpenchainDB encapsulates rocksdb's structures
+// OpenchainDB encapsulates the store of the peer and the names of its column families
 type OpenchainDB struct {
-	DB           *gorocksdb.DB
-	BlockchainCF *gorocksdb.ColumnFamilyHandle
-	StateCF      *gorocksdb.ColumnFamilyHandle
-	StateDeltaCF *gorocksdb.ColumnFamilyHandle
-	IndexesCF    *gorocksdb.ColumnFamilyHandle
-	PersistCF    *gorocksdb.ColumnFamilyHandle
+	store        Store
+	BlockchainCF string
+	StateCF      string
+	StateDeltaCF string
+	IndexesCF    string
+	PersistCF    string
 }
 
 var openchainDB = create()
 
 // Create create an openchainDB instance
 func create() *OpenchainDB {
-	return &OpenchainDB{}
+	return &OpenchainDB{
+		BlockchainCF: blockchainCF,
+		StateCF:      stateCF,
+		StateDeltaCF: stateDeltaCF,
+		IndexesCF:    indexesCF,
+		PersistCF:    persistCF,
+	}
 }
 
 // GetDBHandle gets an opened openchainDB singleton. Note that method Start must always be invoked before this method.
@@ -88,7 +87,7 @@ func (openchainDB *OpenchainDB) GetFromBlockchainCF(key []byte) ([]byte, error)
 }
 
 // GetFromBlockchainCFSnapshot get value for given key from column family in a DB snapshot - blockchainCF
-func (openchainDB *OpenchainDB) GetFromBlockchainCFSnapshot(snapshot *gorocksdb.Snapshot, key []byte) ([]byte, error) {
+func (openchainDB *OpenchainDB) GetFromBlockchainCFSnapshot(snapshot Snapshot, key []byte) ([]byte, error) {
 	return openchainDB.getFromSnapshot(snapshot, openchainDB.BlockchainCF, key)
 }
 
@@ -108,31 +107,31 @@ func (openchainDB *OpenchainDB) GetFromIndexesCF(key []byte) ([]byte, error) {
 }
 
 // GetBlockchainCFIterator get iterator for column family - blockchainCF
-func (openchainDB *OpenchainDB) GetBlockchainCFIterator() *gorocksdb.Iterator {
+func (openchainDB *OpenchainDB) GetBlockchainCFIterator() Iterator {
 	return openchainDB.GetIterator(openchainDB.BlockchainCF)
 }
 
 // GetStateCFIterator get iterator for column family - stateCF
-func (openchainDB *OpenchainDB) GetStateCFIterator() *gorocksdb.Iterator {
+func (openchainDB *OpenchainDB) GetStateCFIterator() Iterator {
 	return openchainDB.GetIterator(openchainDB.StateCF)
 }
 
 // GetStateCFSnapshotIterator get iterator for column family - stateCF. This iterator
 // is based on a snapshot and should be used for long running scans, such as
 // reading the entire state. Remember to call iterator.Close() when you are done.
-func (openchainDB *OpenchainDB) GetStateCFSnapshotIterator(snapshot *gorocksdb.Snapshot) *gorocksdb.Iterator {
+func (openchainDB *OpenchainDB) GetStateCFSnapshotIterator(snapshot Snapshot) Iterator {
 	return openchainDB.getSnapshotIterator(snapshot, openchainDB.StateCF)
 }
 
 // GetStateDeltaCFIterator get iterator for column family - stateDeltaCF
-func (openchainDB *OpenchainDB) GetStateDeltaCFIterator() *gorocksdb.Iterator {
+func (openchainDB *OpenchainDB) GetStateDeltaCFIterator() Iterator {
 	return openchainDB.GetIterator(openchainDB.StateDeltaCF)
 }
 
 // GetSnapshot returns a point-in-time view of the DB. You MUST call snapshot.Release()
 // when you are done with the snapshot.
-func (openchainDB *OpenchainDB) GetSnapshot() *gorocksdb.Snapshot {
-	return openchainDB.DB.NewSnapshot()
+func (openchainDB *OpenchainDB) GetSnapshot() Snapshot {
+	return openchainDB.store.NewSnapshot()
 }
 
 func getDBPath() string {
@@ -146,9 +145,15 @@ func getDBPath() string {
 	return dbPath + "db"
 }
 
-// Open open underlying rocksdb
+// Open opens the store of the type set by peer.db.type
 func (openchainDB *OpenchainDB) open() {
 	dbPath := getDBPath()
+	storeType := viper.GetString("peer.db.type")
+	openStore, err := getStoreOpener(storeType)
+	if err != nil {
+		panic(fmt.Sprintf("Error opening DB: %s", err))
+	}
+
 	missing, err := dirMissingOrEmpty(dbPath)
 	if err != nil {
 		panic(fmt.Sprintf("Error while trying to open DB: %s", err))
@@ -162,114 +167,60 @@ func (openchainDB *OpenchainDB) open() {
 		}
 	}
 
-	opts := gorocksdb.NewDefaultOptions()
-	defer opts.Destroy()
-
-	maxLogFileSize := viper.GetInt("peer.db.maxLogFileSize")
-	if maxLogFileSize > 0 {
-		dbLogger.Infof("Setting rocksdb maxLogFileSize to %d", maxLogFileSize)
-		opts.SetMaxLogFileSize(maxLogFileSize)
-	}
-
-	keepLogFileNum := viper.GetInt("peer.db.keepLogFileNum")
-	if keepLogFileNum > 0 {
-		dbLogger.Infof("Setting rocksdb keepLogFileNum to %d", keepLogFileNum)
-		opts.SetKeepLogFileNum(keepLogFileNum)
-	}
-
-	logLevelStr := viper.GetString("peer.db.loglevel")
-	logLevel, ok := rocksDBLogLevelMap[logLevelStr]
-
-	if ok {
-		dbLogger.Infof("Setting rocks db InfoLogLevel to %d", logLevel)
-		opts.SetInfoLogLevel(logLevel)
-	}
-
-	opts.SetCreateIfMissing(missing)
-	opts.SetCreateIfMissingColumnFamilies(true)
-
-	cfNames := []string{"default"}
-	cfNames = append(cfNames, columnfamilies...)
-	var cfOpts []*gorocksdb.Options
-	for range cfNames {
-		cfOpts = append(cfOpts, opts)
-	}
-
-	db, cfHandlers, err := gorocksdb.OpenDbColumnFamilies(opts, dbPath, cfNames, cfOpts)
-
+	store, err := openStore(dbPath, columnfamilies, missing)
 	if err != nil {
 		panic(fmt.Sprintf("Error opening DB: %s", err))
 	}
-
-	openchainDB.DB = db
-	openchainDB.BlockchainCF = cfHandlers[1]
-	openchainDB.StateCF = cfHandlers[2]
-	openchainDB.StateDeltaCF = cfHandlers[3]
-	openchainDB.IndexesCF = cfHandlers[4]
-	openchainDB.PersistCF = cfHandlers[5]
+	openchainDB.store = store
 }
 
-// Close releases all column family handles and closes rocksdb
+// Close closes the store
 func (openchainDB *OpenchainDB) close() {
-	openchainDB.BlockchainCF.Destroy()
-	openchainDB.StateCF.Destroy()
-	openchainDB.StateDeltaCF.Destroy()
-	openchainDB.IndexesCF.Destroy()
-	openchainDB.PersistCF.Destroy()
-	openchainDB.DB.Close()
+	openchainDB.store.Close()
 }
 
 // DeleteState delets ALL state keys/values from the DB. This is generally
 // only used during state synchronization when creating a new state from
 // a snapshot.
 func (openchainDB *OpenchainDB) DeleteState() error {
-	err := openchainDB.DB.DropColumnFamily(openchainDB.StateCF)
+	err := openchainDB.store.DropColumnFamily(openchainDB.StateCF)
 	if err != nil {
 		dbLogger.Errorf("Error dropping state CF: %s", err)
 		return err
 	}
-	err = openchainDB.DB.DropColumnFamily(openchainDB.StateDeltaCF)
+	err = openchainDB.store.DropColumnFamily(openchainDB.StateDeltaCF)
 	if err != nil {
 		dbLogger.Errorf("Error dropping state delta CF: %s", err)
 		return err
 	}
-	opts := gorocksdb.NewDefaultOptions()
-	defer opts.Destroy()
-	openchainDB.StateCF, err = openchainDB.DB.CreateColumnFamily(opts, stateCF)
-	if err != nil {
-		dbLogger.Errorf("Error creating state CF: %s", err)
-		return err
-	}
-	openchainDB.StateDeltaCF, err = openchainDB.DB.CreateColumnFamily(opts, stateDeltaCF)
+	return nil
+}
+
+// DeleteStateCF deletes all keys/values of column family stateCF, i.e., the
+// persisted state, and keeps the state deltas. This is used when rebuilding the
+// state with another data structure.
+func (openchainDB *OpenchainDB) DeleteStateCF() error {
+	err := openchainDB.store.DropColumnFamily(openchainDB.StateCF)
 	if err != nil {
-		dbLogger.Errorf("Error creating state delta CF: %s", err)
+		dbLogger.Errorf("Error dropping state CF: %s", err)
 		return err
 	}
 	return nil
 }
 
 // Get returns the valud for the given column family and key
-func (openchainDB *OpenchainDB) Get(cfHandler *gorocksdb.ColumnFamilyHandle, key []byte) ([]byte, error) {
-	opt := gorocksdb.NewDefaultReadOptions()
-	defer opt.Destroy()
-	slice, err := openchainDB.DB.GetCF(opt, cfHandler, key)
+func (openchainDB *OpenchainDB) Get(cf string, key []byte) ([]byte, error) {
+	value, err := openchainDB.store.Get(cf, key)
 	if err != nil {
 		dbLogger.Errorf("Error while trying to retrieve key: %s", key)
 		return nil, err
 	}
-	defer slice.Free()
-	if slice.Data() == nil {
-		return nil, nil
-	}
-	data := makeCopy(slice.Data())
-	return data, nil
+	return value, nil
 }
 
 // Put saves the key/value in the given column family
-func (openchainDB *OpenchainDB) Put(cfHandler *gorocksdb.ColumnFamilyHandle, key []byte, value []byte) error {
-	opt := gorocksdb.NewDefaultWriteOptions()
-	defer opt.Destroy()
-	err := openchainDB.DB.PutCF(opt, cfHandler, key, value)
+func (openchainDB *OpenchainDB) Put(cf string, key []byte, value []byte) error {
+	err := openchainDB.store.Put(cf, key, value)
 	if err != nil {
 		dbLogger.Errorf("Error while trying to write key: %s", key)
 		return err
@@ -278,10 +229,8 @@ func (openchainDB *OpenchainDB) Put(cfHandler *gorocksdb.ColumnFamilyHandle, key
 }
 
 // Delete delets the given key in the specified column family
-func (openchainDB *OpenchainDB) Delete(cfHandler *gorocksdb.ColumnFamilyHandle, key []byte) error {
-	opt := gorocksdb.NewDefaultWriteOptions()
-	defer opt.Destroy()
-	err := openchainDB.DB.DeleteCF(opt, cfHandler, key)
+func (openchainDB *OpenchainDB) Delete(cf string, key []byte) error {
+	err := openchainDB.store.Delete(cf, key)
 	if err != nil {
 		dbLogger.Errorf("Error while trying to delete key: %s", key)
 		return err
@@ -289,34 +238,33 @@ func (openchainDB *OpenchainDB) Delete(cfHandler *gorocksdb.ColumnFamilyHandle,
 	return nil
 }
 
-func (openchainDB *OpenchainDB) getFromSnapshot(snapshot *gorocksdb.Snapshot, cfHandler *gorocksdb.ColumnFamilyHandle, key []byte) ([]byte, error) {
-	opt := gorocksdb.NewDefaultReadOptions()
-	defer opt.Destroy()
-	opt.SetSnapshot(snapshot)
-	slice, err := openchainDB.DB.GetCF(opt, cfHandler, key)
+// Write atomically saves all the changes of the given batch
+func (openchainDB *OpenchainDB) Write(writeBatch *WriteBatch) error {
+	return openchainDB.store.Write(writeBatch)
+}
+
+func (openchainDB *OpenchainDB) getFromSnapshot(snapshot Snapshot, cf string, key []byte) ([]byte, error) {
+	value, err := snapshot.Get(cf, key)
 	if err != nil {
 		dbLogger.Errorf("Error while trying to retrieve key: %s", key)
 		return nil, err
 	}
-	defer slice.Free()
-	data := append([]byte(nil), slice.Data()...)
-	return data, nil
+	return value, nil
 }
 
 // GetIterator returns an iterator for the given column family
-func (openchainDB *OpenchainDB) GetIterator(cfHandler *gorocksdb.ColumnFamilyHandle) *gorocksdb.Iterator {
-	opt := gorocksdb.NewDefaultReadOptions()
-	opt.SetFillCache(true)
-	defer opt.Destroy()
-	return openchainDB.DB.NewIteratorCF(opt, cfHandler)
-}
-
-func (openchainDB *OpenchainDB) getSnapshotIterator(snapshot *gorocksdb.Snapshot, cfHandler *gorocksdb.ColumnFamilyHandle) *gorocksdb.Iterator {
-	opt := gorocksdb.NewDefaultReadOptions()
-	defer opt.Destroy()
-	opt.SetSnapshot(snapshot)
-	iter := openchainDB.DB.NewIteratorCF(opt, cfHandler)
-	return iter
+func (openchainDB *OpenchainDB) GetIterator(cf string) Iterator {
+	return openchainDB.store.NewIterator(cf)
+}
+
+func (openchainDB *OpenchainDB) getSnapshotIterator(snapshot Snapshot, cf string) Iterator {
+	return snapshot.NewIterator(cf)
+}
+
+// GetProperty returns a statistic of the given column family, or of the whole
+// DB if cf is empty. See Store.Property.
+func (openchainDB *OpenchainDB) GetProperty(cf string, name string) string {
+	return openchainDB.store.Property(cf, name)
 }
 
 func dirMissingOrEmpty(path string) (bool, error) {
@@ -18,10 +18,10 @@ package db
 
 import (
 	"os"
+	"strconv"
 	"testing"
 
 	"github.com/spf13/viper"
-	"github.com/tecbot/gorocksdb"
 )
 
 // TestDBWrapper wraps the db. Can be used by other modules for testing
@@ -73,29 +73,13 @@ func (testDB *TestDBWrapper) removeDBPath() {
 }
 
 // WriteToDB tests can use this method for persisting a given batch to db
-func (testDB *TestDBWrapper) WriteToDB(t testing.TB, writeBatch *gorocksdb.WriteBatch) {
-	opt := gorocksdb.NewDefaultWriteOptions()
-	defer opt.Destroy()
-	err := GetDBHandle().DB.Write(opt, writeBatch)
+func (testDB *TestDBWrapper) WriteToDB(t testing.TB, writeBatch *WriteBatch) {
+	err := GetDBHandle().Write(writeBatch)
 	if err != nil {
 		t.Fatalf("Error while writing to db. Error:%s", err)
 	}
 }
 
-// GetFromDB gets the value for the given key from default column-family
-func (testDB *TestDBWrapper) GetFromDB(t testing.TB, key []byte) []byte {
-	db := GetDBHandle().DB
-	opt := gorocksdb.NewDefaultReadOptions()
-	defer opt.Destroy()
-	slice, err := db.Get(opt, key)
-	defer slice.Free()
-	if err != nil {
-		t.Fatalf("Error while getting key-value from DB: %s", err)
-	}
-	value := append([]byte(nil), slice.Data()...)
-	return value
-}
-
 // GetFromStateCF tests can use this method for getting value from StateCF column-family
 func (testDB *TestDBWrapper) GetFromStateCF(t testing.TB, key []byte) []byte {
 	openchainDB := GetDBHandle()
@@ -126,19 +110,29 @@ func (testDB *TestDBWrapper) OpenDB(t testing.TB) {
 	Start()
 }
 
-// GetEstimatedNumKeys returns estimated number of key-values in db. This is not accurate in all the cases
+// GetEstimatedNumKeys returns the number of key-values in each column family
 func (testDB *TestDBWrapper) GetEstimatedNumKeys(t testing.TB) map[string]string {
 	openchainDB := GetDBHandle()
 	result := make(map[string]string, 5)
-	result["stateCF"] = openchainDB.DB.GetPropertyCF("rocksdb.estimate-num-keys", openchainDB.StateCF)
-	result["stateDeltaCF"] = openchainDB.DB.GetPropertyCF("rocksdb.estimate-num-keys", openchainDB.StateDeltaCF)
-	result["blockchainCF"] = openchainDB.DB.GetPropertyCF("rocksdb.estimate-num-keys", openchainDB.BlockchainCF)
-	result["indexCF"] = openchainDB.DB.GetPropertyCF("rocksdb.estimate-num-keys", openchainDB.IndexesCF)
+	result["stateCF"] = countKeys(openchainDB, openchainDB.StateCF)
+	result["stateDeltaCF"] = countKeys(openchainDB, openchainDB.StateDeltaCF)
+	result["blockchainCF"] = countKeys(openchainDB, openchainDB.BlockchainCF)
+	result["indexCF"] = countKeys(openchainDB, openchainDB.IndexesCF)
 	return result
 }
 
+func countKeys(openchainDB *OpenchainDB, cf string) string {
+	itr := openchainDB.GetIterator(cf)
+	defer itr.Close()
+	numKeys := 0
+	for itr.SeekToFirst(); itr.Valid(); itr.Next() {
+		numKeys++
+	}
+	return strconv.Itoa(numKeys)
+}
+
 // GetDBStats returns statistics for the database
 func (testDB *TestDBWrapper) GetDBStats() string {
 	openchainDB := GetDBHandle()
-	return openchainDB.DB.GetProperty("rocksdb.stats")
+	return openchainDB.GetProperty("", "rocksdb.stats")
 }
@@ -0,0 +1,204 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// memoryStore keeps the column families of the openchainDB in maps. Nothing
+// is written to disk and the contents are lost when the store is closed, so
+// it is meant for tests.
+type memoryStore struct {
+	sync.RWMutex
+	cfs map[string]map[string][]byte
+}
+
+func openMemoryStore(dbPath string, cfNames []string, create bool) (Store, error) {
+	store := &memoryStore{cfs: make(map[string]map[string][]byte)}
+	for _, cfName := range cfNames {
+		store.cfs[cfName] = make(map[string][]byte)
+	}
+	return store, nil
+}
+
+func (store *memoryStore) cf(cf string) map[string][]byte {
+	kvs, ok := store.cfs[cf]
+	if !ok {
+		panic(fmt.Sprintf("Unknown column family [%s]", cf))
+	}
+	return kvs
+}
+
+func (store *memoryStore) Get(cf string, key []byte) ([]byte, error) {
+	store.RLock()
+	defer store.RUnlock()
+	value, ok := store.cf(cf)[string(key)]
+	if !ok {
+		return nil, nil
+	}
+	return makeCopy(value), nil
+}
+
+func (store *memoryStore) Put(cf string, key []byte, value []byte) error {
+	store.Lock()
+	defer store.Unlock()
+	store.cf(cf)[string(key)] = makeCopy(value)
+	return nil
+}
+
+func (store *memoryStore) Delete(cf string, key []byte) error {
+	store.Lock()
+	defer store.Unlock()
+	delete(store.cf(cf), string(key))
+	return nil
+}
+
+func (store *memoryStore) Write(writeBatch *WriteBatch) error {
+	store.Lock()
+	defer store.Unlock()
+	for _, op := range writeBatch.ops {
+		if op.delete {
+			delete(store.cf(op.cf), string(op.key))
+		} else {
+			store.cf(op.cf)[string(op.key)] = op.value
+		}
+	}
+	return nil
+}
+
+// NewIterator iterates over the keys present when it is created
+func (store *memoryStore) NewIterator(cf string) Iterator {
+	store.RLock()
+	defer store.RUnlock()
+	return newMemoryIterator(store.cf(cf))
+}
+
+// NewSnapshot copies all column families. Values are never modified in
+// place, so they are shared with the store.
+func (store *memoryStore) NewSnapshot() Snapshot {
+	store.RLock()
+	defer store.RUnlock()
+	snapshot := &memoryStore{cfs: make(map[string]map[string][]byte)}
+	for cfName, kvs := range store.cfs {
+		snapshotKVs := make(map[string][]byte, len(kvs))
+		for key, value := range kvs {
+			snapshotKVs[key] = value
+		}
+		snapshot.cfs[cfName] = snapshotKVs
+	}
+	return &memorySnapshot{snapshot}
+}
+
+func (store *memoryStore) DropColumnFamily(cf string) error {
+	store.Lock()
+	defer store.Unlock()
+	store.cf(cf)
+	store.cfs[cf] = make(map[string][]byte)
+	return nil
+}
+
+func (store *memoryStore) Property(cf string, name string) string {
+	return ""
+}
+
+func (store *memoryStore) Close() {
+	store.Lock()
+	defer store.Unlock()
+	store.cfs = nil
+}
+
+type memorySnapshot struct {
+	store *memoryStore
+}
+
+func (snapshot *memorySnapshot) Get(cf string, key []byte) ([]byte, error) {
+	return snapshot.store.Get(cf, key)
+}
+
+func (snapshot *memorySnapshot) NewIterator(cf string) Iterator {
+	return snapshot.store.NewIterator(cf)
+}
+
+func (snapshot *memorySnapshot) Release() {
+	snapshot.store.Close()
+}
+
+// memoryIterator walks the sorted keys of a column family
+type memoryIterator struct {
+	keys   []string
+	values [][]byte
+	pos    int
+}
+
+func newMemoryIterator(kvs map[string][]byte) *memoryIterator {
+	itr := &memoryIterator{keys: make([]string, 0, len(kvs))}
+	for key := range kvs {
+		itr.keys = append(itr.keys, key)
+	}
+	sort.Strings(itr.keys)
+	itr.values = make([][]byte, len(itr.keys))
+	for i, key := range itr.keys {
+		itr.values[i] = kvs[key]
+	}
+	itr.pos = len(itr.keys)
+	return itr
+}
+
+func (itr *memoryIterator) Seek(key []byte) {
+	itr.pos = sort.SearchStrings(itr.keys, string(key))
+}
+
+func (itr *memoryIterator) SeekToFirst() {
+	itr.pos = 0
+}
+
+func (itr *memoryIterator) Valid() bool {
+	return itr.pos >= 0 && itr.pos < len(itr.keys)
+}
+
+func (itr *memoryIterator) ValidForPrefix(prefix []byte) bool {
+	return itr.Valid() && bytes.HasPrefix([]byte(itr.keys[itr.pos]), prefix)
+}
+
+func (itr *memoryIterator) Next() {
+	if itr.Valid() {
+		itr.pos++
+	}
+}
+
+func (itr *memoryIterator) Prev() {
+	if itr.Valid() {
+		itr.pos--
+	}
+}
+
+func (itr *memoryIterator) Key() []byte {
+	return []byte(itr.keys[itr.pos])
+}
+
+func (itr *memoryIterator) Value() []byte {
+	return itr.values[itr.pos]
+}
+
+func (itr *memoryIterator) Close() {
+	itr.keys = nil
+	itr.values = nil
+}
@@ -0,0 +1,251 @@
+//go:build cgo
+// +build cgo
+
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+	"github.com/tecbot/gorocksdb"
+)
+
+var rocksDBLogLevelMap = map[string]gorocksdb.InfoLogLevel{
+	"debug": gorocksdb.DebugInfoLogLevel,
+	"info":  gorocksdb.InfoInfoLogLevel,
+	"warn":  gorocksdb.WarnInfoLogLevel,
+	"error": gorocksdb.ErrorInfoLogLevel,
+	"fatal": gorocksdb.FatalInfoLogLevel}
+
+func init() {
+	storeTypes["rocksdb"] = openRocksDBStore
+	defaultStoreType = "rocksdb"
+}
+
+// rocksDBStore keeps the column families of the openchainDB in rocksdb
+type rocksDBStore struct {
+	db  *gorocksdb.DB
+	cfs map[string]*gorocksdb.ColumnFamilyHandle
+}
+
+func openRocksDBStore(dbPath string, cfNames []string, create bool) (Store, error) {
+	opts := gorocksdb.NewDefaultOptions()
+	defer opts.Destroy()
+
+	maxLogFileSize := viper.GetInt("peer.db.maxLogFileSize")
+	if maxLogFileSize > 0 {
+		dbLogger.Infof("Setting rocksdb maxLogFileSize to %d", maxLogFileSize)
+		opts.SetMaxLogFileSize(maxLogFileSize)
+	}
+
+	keepLogFileNum := viper.GetInt("peer.db.keepLogFileNum")
+	if keepLogFileNum > 0 {
+		dbLogger.Infof("Setting rocksdb keepLogFileNum to %d", keepLogFileNum)
+		opts.SetKeepLogFileNum(keepLogFileNum)
+	}
+
+	logLevelStr := viper.GetString("peer.db.loglevel")
+	logLevel, ok := rocksDBLogLevelMap[logLevelStr]
+
+	if ok {
+		dbLogger.Infof("Setting rocks db InfoLogLevel to %d", logLevel)
+		opts.SetInfoLogLevel(logLevel)
+	}
+
+	opts.SetCreateIfMissing(create)
+	opts.SetCreateIfMissingColumnFamilies(true)
+
+	allCFNames := append([]string{"default"}, cfNames...)
+	var cfOpts []*gorocksdb.Options
+	for range allCFNames {
+		cfOpts = append(cfOpts, opts)
+	}
+
+	db, cfHandlers, err := gorocksdb.OpenDbColumnFamilies(opts, dbPath, allCFNames, cfOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &rocksDBStore{db: db, cfs: make(map[string]*gorocksdb.ColumnFamilyHandle)}
+	for i, cfName := range allCFNames {
+		store.cfs[cfName] = cfHandlers[i]
+	}
+	return store, nil
+}
+
+func (store *rocksDBStore) cfHandle(cf string) *gorocksdb.ColumnFamilyHandle {
+	cfHandle, ok := store.cfs[cf]
+	if !ok {
+		panic(fmt.Sprintf("Unknown column family [%s]", cf))
+	}
+	return cfHandle
+}
+
+func (store *rocksDBStore) Get(cf string, key []byte) ([]byte, error) {
+	opt := gorocksdb.NewDefaultReadOptions()
+	defer opt.Destroy()
+	return store.get(opt, cf, key)
+}
+
+func (store *rocksDBStore) get(opt *gorocksdb.ReadOptions, cf string, key []byte) ([]byte, error) {
+	slice, err := store.db.GetCF(opt, store.cfHandle(cf), key)
+	if err != nil {
+		return nil, err
+	}
+	defer slice.Free()
+	if slice.Data() == nil {
+		return nil, nil
+	}
+	return makeCopy(slice.Data()), nil
+}
+
+func (store *rocksDBStore) Put(cf string, key []byte, value []byte) error {
+	opt := gorocksdb.NewDefaultWriteOptions()
+	defer opt.Destroy()
+	return store.db.PutCF(opt, store.cfHandle(cf), key, value)
+}
+
+func (store *rocksDBStore) Delete(cf string, key []byte) error {
+	opt := gorocksdb.NewDefaultWriteOptions()
+	defer opt.Destroy()
+	return store.db.DeleteCF(opt, store.cfHandle(cf), key)
+}
+
+func (store *rocksDBStore) Write(writeBatch *WriteBatch) error {
+	rocksBatch := gorocksdb.NewWriteBatch()
+	defer rocksBatch.Destroy()
+	for _, op := range writeBatch.ops {
+		if op.delete {
+			rocksBatch.DeleteCF(store.cfHandle(op.cf), op.key)
+		} else {
+			rocksBatch.PutCF(store.cfHandle(op.cf), op.key, op.value)
+		}
+	}
+	opt := gorocksdb.NewDefaultWriteOptions()
+	defer opt.Destroy()
+	return store.db.Write(opt, rocksBatch)
+}
+
+func (store *rocksDBStore) NewIterator(cf string) Iterator {
+	opt := gorocksdb.NewDefaultReadOptions()
+	opt.SetFillCache(true)
+	defer opt.Destroy()
+	return &rocksDBIterator{store.db.NewIteratorCF(opt, store.cfHandle(cf))}
+}
+
+func (store *rocksDBStore) NewSnapshot() Snapshot {
+	return &rocksDBSnapshot{store, store.db.NewSnapshot()}
+}
+
+// DropColumnFamily drops column family cf and creates it again, which is
+// faster than deleting its keys one by one
+func (store *rocksDBStore) DropColumnFamily(cf string) error {
+	err := store.db.DropColumnFamily(store.cfHandle(cf))
+	if err != nil {
+		return err
+	}
+	opts := gorocksdb.NewDefaultOptions()
+	defer opts.Destroy()
+	cfHandle, err := store.db.CreateColumnFamily(opts, cf)
+	if err != nil {
+		return err
+	}
+	store.cfs[cf] = cfHandle
+	return nil
+}
+
+func (store *rocksDBStore) Property(cf string, name string) string {
+	if cf == "" {
+		return store.db.GetProperty(name)
+	}
+	return store.db.GetPropertyCF(name, store.cfHandle(cf))
+}
+
+// Close releases all column family handles and closes rocksdb
+func (store *rocksDBStore) Close() {
+	for _, cfHandle := range store.cfs {
+		cfHandle.Destroy()
+	}
+	store.db.Close()
+}
+
+type rocksDBSnapshot struct {
+	store    *rocksDBStore
+	snapshot *gorocksdb.Snapshot
+}
+
+func (snapshot *rocksDBSnapshot) Get(cf string, key []byte) ([]byte, error) {
+	opt := gorocksdb.NewDefaultReadOptions()
+	defer opt.Destroy()
+	opt.SetSnapshot(snapshot.snapshot)
+	return snapshot.store.get(opt, cf, key)
+}
+
+func (snapshot *rocksDBSnapshot) NewIterator(cf string) Iterator {
+	opt := gorocksdb.NewDefaultReadOptions()
+	defer opt.Destroy()
+	opt.SetSnapshot(snapshot.snapshot)
+	return &rocksDBIterator{snapshot.store.db.NewIteratorCF(opt, snapshot.store.cfHandle(cf))}
+}
+
+func (snapshot *rocksDBSnapshot) Release() {
+	snapshot.snapshot.Release()
+}
+
+// rocksDBIterator returns the keys and values of a rocksdb iterator without
+// copying them. Rocksdb frees them when the iterator moves or is closed.
+type rocksDBIterator struct {
+	itr *gorocksdb.Iterator
+}
+
+func (itr *rocksDBIterator) Seek(key []byte) {
+	itr.itr.Seek(key)
+}
+
+func (itr *rocksDBIterator) SeekToFirst() {
+	itr.itr.SeekToFirst()
+}
+
+func (itr *rocksDBIterator) Valid() bool {
+	return itr.itr.Valid()
+}
+
+func (itr *rocksDBIterator) ValidForPrefix(prefix []byte) bool {
+	return itr.itr.ValidForPrefix(prefix)
+}
+
+func (itr *rocksDBIterator) Next() {
+	itr.itr.Next()
+}
+
+func (itr *rocksDBIterator) Prev() {
+	itr.itr.Prev()
+}
+
+func (itr *rocksDBIterator) Key() []byte {
+	return itr.itr.Key().Data()
+}
+
+func (itr *rocksDBIterator) Value() []byte {
+	return itr.itr.Value().Data()
+}
+
+func (itr *rocksDBIterator) Close() {
+	itr.itr.Close()
+}
@@ -0,0 +1,135 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Store is a key-value storage backend of the openchainDB. Keys and values
+// are kept in named column families, the keys of each column family are
+// sorted bytewise.
+type Store interface {
+	// Get returns the value of key in column family cf, or nil if the key is not present
+	Get(cf string, key []byte) ([]byte, error)
+	Put(cf string, key []byte, value []byte) error
+	Delete(cf string, key []byte) error
+
+	// Write applies all the changes of writeBatch atomically
+	Write(writeBatch *WriteBatch) error
+
+	// NewIterator returns an iterator over the latest data in column family
+	// cf. Remember to call iterator.Close() when you are done.
+	NewIterator(cf string) Iterator
+
+	// NewSnapshot returns a point-in-time view of the store. You MUST call
+	// snapshot.Release() when you are done with the snapshot.
+	NewSnapshot() Snapshot
+
+	// DropColumnFamily deletes all keys of column family cf
+	DropColumnFamily(cf string) error
+
+	// Property returns a statistic of the store, of column family cf or of
+	// the whole store if cf is empty. Property names are specific to the
+	// store type, unknown properties are returned empty.
+	Property(cf string, name string) string
+
+	Close()
+}
+
+// Iterator iterates over the keys of a column family in order. The slices
+// returned by Key and Value are only valid until the iterator moves.
+type Iterator interface {
+	Seek(key []byte)
+	SeekToFirst()
+	Valid() bool
+	ValidForPrefix(prefix []byte) bool
+	Next()
+	Prev()
+	Key() []byte
+	Value() []byte
+	Close()
+}
+
+// Snapshot is a point-in-time view of a store
+type Snapshot interface {
+	Get(cf string, key []byte) ([]byte, error)
+	NewIterator(cf string) Iterator
+	Release()
+}
+
+// storeOpener opens the store at dbPath with the given column families,
+// creating it if create is set
+type storeOpener func(dbPath string, cfNames []string, create bool) (Store, error)
+
+// storeTypes maps the values of peer.db.type to the stores they open. The
+// rocksdb store needs cgo and librocksdb, and is only added by peers built
+// with cgo.
+var storeTypes = map[string]storeOpener{
+	"boltdb": openBoltDBStore,
+	"memory": openMemoryStore,
+}
+
+// defaultStoreType is opened if peer.db.type is not set. Peers built with
+// cgo open rocksdb instead.
+var defaultStoreType = "boltdb"
+
+func getStoreOpener(storeType string) (storeOpener, error) {
+	if storeType == "" {
+		storeType = defaultStoreType
+	}
+	opener, ok := storeTypes[storeType]
+	if !ok {
+		var known []string
+		for name := range storeTypes {
+			known = append(known, name)
+		}
+		sort.Strings(known)
+		return nil, fmt.Errorf("Unknown DB type [%s], expected one of %s", storeType, strings.Join(known, ", "))
+	}
+	return opener, nil
+}
+
+type writeBatchOp struct {
+	cf     string
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+// WriteBatch holds changes to the openchainDB that are written together by
+// OpenchainDB.Write
+type WriteBatch struct {
+	ops []writeBatchOp
+}
+
+// NewWriteBatch creates an empty WriteBatch
+func NewWriteBatch() *WriteBatch {
+	return &WriteBatch{}
+}
+
+// PutCF adds saving the key/value in the given column family to the batch
+func (writeBatch *WriteBatch) PutCF(cf string, key []byte, value []byte) {
+	writeBatch.ops = append(writeBatch.ops, writeBatchOp{cf: cf, key: makeCopy(key), value: makeCopy(value)})
+}
+
+// DeleteCF adds deleting the key in the given column family to the batch
+func (writeBatch *WriteBatch) DeleteCF(cf string, key []byte) {
+	writeBatch.ops = append(writeBatch.ops, writeBatchOp{cf: cf, key: makeCopy(key), delete: true})
+}
@@ -0,0 +1,132 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package db
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestStoreTypes(t *testing.T) {
+	for storeType := range storeTypes {
+		t.Logf("Testing store type [%s]", storeType)
+		testStoreType(t, storeType)
+	}
+}
+
+func testStoreType(t *testing.T, storeType string) {
+	viper.Set("peer.db.type", storeType)
+	defer viper.Set("peer.db.type", "")
+	testDBWrapper := NewTestDBWrapper()
+	testDBWrapper.CleanDB(t)
+	defer testDBWrapper.cleanup()
+	openchainDB := GetDBHandle()
+
+	performBasicReadWrite(openchainDB, t)
+
+	writeBatch := NewWriteBatch()
+	writeBatch.PutCF(openchainDB.PersistCF, []byte("a/1"), []byte("value1"))
+	writeBatch.PutCF(openchainDB.PersistCF, []byte("a/2"), []byte("value2"))
+	writeBatch.PutCF(openchainDB.PersistCF, []byte("b/1"), []byte("value3"))
+	writeBatch.PutCF(openchainDB.PersistCF, []byte("a/3"), []byte("value4"))
+	writeBatch.DeleteCF(openchainDB.PersistCF, []byte("a/3"))
+	if err := openchainDB.Write(writeBatch); err != nil {
+		t.Fatalf("Error while writing to db: %s", err)
+	}
+
+	snapshot := openchainDB.GetSnapshot()
+	defer snapshot.Release()
+	openchainDB.Put(openchainDB.PersistCF, []byte("a/0"), []byte("value0"))
+
+	// the keys with a prefix, in order, in the latest data and in the snapshot
+	testPrefixScan(t, openchainDB.GetIterator(openchainDB.PersistCF), []byte("a/"), []string{"a/0", "a/1", "a/2"})
+	testPrefixScan(t, snapshot.NewIterator(openchainDB.PersistCF), []byte("a/"), []string{"a/1", "a/2"})
+
+	itr := openchainDB.GetIterator(openchainDB.PersistCF)
+	defer itr.Close()
+	itr.Seek([]byte("b"))
+	itr.Prev()
+	if !itr.Valid() || !bytes.Equal(itr.Key(), []byte("a/2")) || !bytes.Equal(itr.Value(), []byte("value2")) {
+		t.Fatalf("Expected the iterator to move back to [a/2], found valid=%t", itr.Valid())
+	}
+	itr.Seek([]byte("c"))
+	if itr.Valid() {
+		t.Fatalf("Expected the iterator to be past the last key, found [%s]", itr.Key())
+	}
+
+	if value, err := snapshot.Get(openchainDB.PersistCF, []byte("a/0")); err != nil || value != nil {
+		t.Fatalf("Expected no value in the snapshot, found [%s], %v", value, err)
+	}
+
+	openchainDB.Put(openchainDB.StateCF, []byte("key"), []byte("value"))
+	if err := openchainDB.DeleteState(); err != nil {
+		t.Fatalf("Error deleting the state: %s", err)
+	}
+	if value, _ := openchainDB.GetFromStateCF([]byte("key")); value != nil {
+		t.Fatalf("Expected the state to be deleted, found [%s]", value)
+	}
+	openchainDB.Put(openchainDB.StateCF, []byte("key"), []byte("value"))
+	if value, _ := openchainDB.GetFromStateCF([]byte("key")); !bytes.Equal(value, []byte("value")) {
+		t.Fatalf("Expected the state to be writable after its deletion, found [%s]", value)
+	}
+}
+
+func TestBoltDBStoreReopen(t *testing.T) {
+	viper.Set("peer.db.type", "boltdb")
+	defer viper.Set("peer.db.type", "")
+	testDBWrapper := NewTestDBWrapper()
+	testDBWrapper.CleanDB(t)
+	defer testDBWrapper.cleanup()
+
+	GetDBHandle().Put(GetDBHandle().BlockchainCF, []byte("key"), []byte("value"))
+	Stop()
+	Start()
+	value, err := GetDBHandle().GetFromBlockchainCF([]byte("key"))
+	if err != nil || !bytes.Equal(value, []byte("value")) {
+		t.Fatalf("Expected the value to survive a restart, found [%s], %v", value, err)
+	}
+}
+
+func TestUnknownStoreType(t *testing.T) {
+	viper.Set("peer.db.type", "nosuchdb")
+	defer viper.Set("peer.db.type", "")
+	defer deleteTestDBPath()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("Expected opening an unknown DB type to panic")
+		}
+	}()
+	Start()
+}
+
+func testPrefixScan(t *testing.T, itr Iterator, prefix []byte, expectedKeys []string) {
+	defer itr.Close()
+	var keys []string
+	for itr.Seek(prefix); itr.ValidForPrefix(prefix); itr.Next() {
+		keys = append(keys, string(itr.Key()))
+	}
+	if len(keys) != len(expectedKeys) {
+		t.Fatalf("Expected keys %v, found %v", expectedKeys, keys)
+	}
+	for i := range keys {
+		if keys[i] != expectedKeys[i] {
+			t.Fatalf("Expected keys %v, found %v", expectedKeys, keys)
+		}
+	}
+}
@@ -254,6 +254,12 @@ func (d *Devops) invokeOrQuery(ctx context.Context, chaincodeInvocationSpec *pb.
 	}
 	resp := d.coord.ExecuteTransaction(transaction)
 	if resp.Status == pb.Response_FAILURE {
+		if resp.ChaincodeError != nil {
+			// The chaincode failed the query with a shim.Error. It is returned in
+			// the response, as gRPC only passes on the message of an error, so
+			// that callers can tell its code apart
+			return resp, nil
+		}
 		err = fmt.Errorf(string(resp.Msg))
 	} else {
 		if !invoke && nil != sec && viper.GetBool("security.privacy") {
@@ -17,10 +17,13 @@ limitations under the License.
 package core
 
 import (
+	"net"
 	"testing"
 
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
 
+	"github.com/hyperledger/fabric/core/peer"
 	pb "github.com/hyperledger/fabric/protos"
 )
 
@@ -92,3 +95,43 @@ func TestDevops_Spec_NoVersion(t *testing.T) {
 	t.Logf("Deploy result = %s, err = %s", buildResult, err)
 	//performHandshake(t, peerClientConn)
 }
+
+// chaincodeErrorCoordinator fails every transaction with a structured
+// chaincode error
+type chaincodeErrorCoordinator struct {
+	peer.MessageHandlerCoordinator
+	chaincodeError *pb.ChaincodeError
+}
+
+func (coord *chaincodeErrorCoordinator) ExecuteTransaction(transaction *pb.Transaction) *pb.Response {
+	return &pb.Response{Status: pb.Response_FAILURE, Msg: []byte(coord.chaincodeError.Error()), ChaincodeError: coord.chaincodeError}
+}
+
+func TestDevops_Query_ChaincodeError(t *testing.T) {
+	chaincodeError := &pb.ChaincodeError{Code: 402, Message: "insufficient balance", Details: "balance 5 is less than 10"}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Error starting the listener: %s", err)
+	}
+	grpcServer := grpc.NewServer()
+	pb.RegisterDevopsServer(grpcServer, NewDevopsServer(&chaincodeErrorCoordinator{chaincodeError: chaincodeError}))
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Error connecting to the devops server: %s", err)
+	}
+	defer conn.Close()
+
+	spec := &pb.ChaincodeSpec{Type: pb.ChaincodeSpec_GOLANG, ChaincodeID: &pb.ChaincodeID{Name: "mycc"},
+		CtorMsg: &pb.ChaincodeInput{Args: [][]byte{[]byte("balance")}}}
+	resp, err := pb.NewDevopsClient(conn).Query(context.Background(), &pb.ChaincodeInvocationSpec{ChaincodeSpec: spec})
+	if err != nil {
+		t.Fatalf("Expected the chaincode error in the response, got the error %s", err)
+	}
+	if resp.Status != pb.Response_FAILURE || resp.ChaincodeError == nil || resp.ChaincodeError.Code != chaincodeError.Code ||
+		resp.ChaincodeError.Message != chaincodeError.Message || resp.ChaincodeError.Details != chaincodeError.Details {
+		t.Fatalf("Expected the chaincode error %v to survive gRPC, got %v", chaincodeError, resp)
+	}
+}
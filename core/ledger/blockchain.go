@@ -20,11 +20,11 @@ import (
 	"bytes"
 	"encoding/binary"
 	"strconv"
+	"sync"
 
 	"github.com/hyperledger/fabric/core/db"
 	"github.com/hyperledger/fabric/core/util"
 	"github.com/hyperledger/fabric/protos"
-	"github.com/tecbot/gorocksdb"
 	"golang.org/x/net/context"
 )
 
@@ -35,6 +35,10 @@ type blockchain struct {
 	previousBlockHash  []byte
 	indexer            blockchainIndexer
 	lastProcessedBlock *lastProcessedBlock
+	// prunedHeight is raised by the pruning in the background, and held
+	// while reading a block so that it is not pruned in between
+	prunedHeight     uint64
+	prunedHeightLock sync.RWMutex
 }
 
 type lastProcessedBlock struct {
@@ -50,8 +54,11 @@ func newBlockchain() (*blockchain, error) {
 	if err != nil {
 		return nil, err
 	}
-	blockchain := &blockchain{0, nil, nil, nil}
-	blockchain.size = size
+	prunedHeight, err := fetchPrunedHeightFromDB()
+	if err != nil {
+		return nil, err
+	}
+	blockchain := &blockchain{size: size, prunedHeight: prunedHeight}
 	if size > 0 {
 		previousBlock, err := fetchBlockFromDB(size - 1)
 		if err != nil {
@@ -96,9 +103,38 @@ func (blockchain *blockchain) getSize() uint64 {
 
 // getBlock get block at arbitrary height in block chain
 func (blockchain *blockchain) getBlock(blockNumber uint64) (*protos.Block, error) {
+	blockchain.prunedHeightLock.RLock()
+	defer blockchain.prunedHeightLock.RUnlock()
+	if blockNumber < blockchain.prunedHeight {
+		return nil, newPrunedError(blockNumber, blockchain.prunedHeight)
+	}
 	return fetchBlockFromDB(blockNumber)
 }
 
+// getBlockHeader returns the block at arbitrary height in block chain and its
+// hash. If the block has been pruned, its transactions are missing but the
+// hash is still the hash of the full block.
+func (blockchain *blockchain) getBlockHeader(blockNumber uint64) (*protos.Block, []byte, error) {
+	blockchain.prunedHeightLock.RLock()
+	defer blockchain.prunedHeightLock.RUnlock()
+	if blockNumber >= blockchain.prunedHeight {
+		block, err := fetchBlockFromDB(blockNumber)
+		if err != nil || block == nil {
+			return nil, nil, err
+		}
+		blockHash, err := block.GetHash()
+		if err != nil {
+			return nil, nil, err
+		}
+		return block, blockHash, nil
+	}
+	prunedBlock, err := fetchPrunedBlockFromDB(blockNumber)
+	if err != nil || prunedBlock == nil {
+		return nil, nil, err
+	}
+	return prunedBlock.Block, prunedBlock.BlockHash, nil
+}
+
 // getBlockByHash get block by block hash
 func (blockchain *blockchain) getBlockByHash(blockHash []byte) (*protos.Block, error) {
 	blockNumber, err := blockchain.indexer.fetchBlockNumberByBlockHash(blockHash)
@@ -188,7 +224,7 @@ func (blockchain *blockchain) buildBlock(block *protos.Block, stateHash []byte)
 }
 
 func (blockchain *blockchain) addPersistenceChangesForNewBlock(ctx context.Context,
-	block *protos.Block, stateHash []byte, writeBatch *gorocksdb.WriteBatch) (uint64, error) {
+	block *protos.Block, stateHash []byte, writeBatch *db.WriteBatch) (uint64, error) {
 	block = blockchain.buildBlock(block, stateHash)
 	if block.NonHashData == nil {
 		block.NonHashData = &protos.NonHashData{LocalLedgerCommitTimestamp: util.CreateUtcTimestamp()}
@@ -218,8 +254,7 @@ func (blockchain *blockchain) blockPersistenceStatus(success bool) {
 		blockchain.size++
 		blockchain.previousBlockHash = blockchain.lastProcessedBlock.blockHash
 		if !blockchain.indexer.isSynchronous() {
-			writeBatch := gorocksdb.NewWriteBatch()
-			defer writeBatch.Destroy()
+			writeBatch := db.NewWriteBatch()
 			blockchain.indexer.createIndexes(blockchain.lastProcessedBlock.block,
 				blockchain.lastProcessedBlock.blockNumber, blockchain.lastProcessedBlock.blockHash, writeBatch)
 		}
@@ -232,8 +267,7 @@ func (blockchain *blockchain) persistRawBlock(block *protos.Block, blockNumber u
 	if blockBytesErr != nil {
 		return blockBytesErr
 	}
-	writeBatch := gorocksdb.NewWriteBatch()
-	defer writeBatch.Destroy()
+	writeBatch := db.NewWriteBatch()
 	writeBatch.PutCF(db.GetDBHandle().BlockchainCF, encodeBlockNumberDBKey(blockNumber), blockBytes)
 
 	blockHash, err := block.GetHash()
@@ -254,9 +288,7 @@ func (blockchain *blockchain) persistRawBlock(block *protos.Block, blockNumber u
 		blockchain.indexer.createIndexes(block, blockNumber, blockHash, writeBatch)
 	}
 
-	opt := gorocksdb.NewDefaultWriteOptions()
-	defer opt.Destroy()
-	err = db.GetDBHandle().DB.Write(opt, writeBatch)
+	err = db.GetDBHandle().Write(writeBatch)
 	if err != nil {
 		return err
 	}
@@ -285,7 +317,7 @@ func fetchBlockchainSizeFromDB() (uint64, error) {
 	return decodeToUint64(bytes), nil
 }
 
-func fetchBlockchainSizeFromSnapshot(snapshot *gorocksdb.Snapshot) (uint64, error) {
+func fetchBlockchainSizeFromSnapshot(snapshot db.Snapshot) (uint64, error) {
 	blockNumberBytes, err := db.GetDBHandle().GetFromBlockchainCFSnapshot(snapshot, blockCountKey)
 	if err != nil {
 		return 0, err
@@ -23,7 +23,6 @@ import (
 	"github.com/hyperledger/fabric/core/db"
 	"github.com/hyperledger/fabric/protos"
 	"github.com/op/go-logging"
-	"github.com/tecbot/gorocksdb"
 )
 
 var indexLogger = logging.MustGetLogger("indexes")
@@ -34,7 +33,7 @@ var prefixAddressBlockNumCompositeKey = byte(3)
 type blockchainIndexer interface {
 	isSynchronous() bool
 	start(blockchain *blockchain) error
-	createIndexes(block *protos.Block, blockNumber uint64, blockHash []byte, writeBatch *gorocksdb.WriteBatch) error
+	createIndexes(block *protos.Block, blockNumber uint64, blockHash []byte, writeBatch *db.WriteBatch) error
 	fetchBlockNumberByBlockHash(blockHash []byte) (uint64, error)
 	fetchTransactionIndexByID(txID string) (uint64, uint64, error)
 	stop()
@@ -57,7 +56,7 @@ func (indexer *blockchainIndexerSync) start(blockchain *blockchain) error {
 }
 
 func (indexer *blockchainIndexerSync) createIndexes(
-	block *protos.Block, blockNumber uint64, blockHash []byte, writeBatch *gorocksdb.WriteBatch) error {
+	block *protos.Block, blockNumber uint64, blockHash []byte, writeBatch *db.WriteBatch) error {
 	return addIndexDataForPersistence(block, blockNumber, blockHash, writeBatch)
 }
 
@@ -74,7 +73,7 @@ func (indexer *blockchainIndexerSync) stop() {
 }
 
 // Functions for persisting and retrieving index data
-func addIndexDataForPersistence(block *protos.Block, blockNumber uint64, blockHash []byte, writeBatch *gorocksdb.WriteBatch) error {
+func addIndexDataForPersistence(block *protos.Block, blockNumber uint64, blockHash []byte, writeBatch *db.WriteBatch) error {
 	openchainDB := db.GetDBHandle()
 	cf := openchainDB.IndexesCF
 
@@ -0,0 +1,133 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+	"github.com/hyperledger/fabric/protos"
+)
+
+// The key history shares the indexes column family with the blockchain indexes
+// and the rich query indexes of the state, which use the key prefixes 1 to 4.
+// Unlike the state deltas, the history is never pruned.
+var prefixKeyHistoryKey = byte(5)
+
+// The blocks put on the chain without the state deltas of their transactions,
+// by state transfer or a state snapshot import, leave no history. The history
+// is complete from the block stored under this key on.
+var keyHistoryCompleteFromKey = []byte{byte(6)}
+
+// addKeyHistoryForPersistence adds to writeBatch a history entry for every key modified by
+// the transactions of block blockNumber, given the state delta of each successful transaction
+func addKeyHistoryForPersistence(transactions []*protos.Transaction, blockNumber uint64,
+	txStateDeltas map[string]*statemgmt.StateDelta, writeBatch *db.WriteBatch) error {
+	cf := db.GetDBHandle().IndexesCF
+	for txIndex, tx := range transactions {
+		txStateDelta, ok := txStateDeltas[tx.Txid]
+		if !ok {
+			continue
+		}
+		for _, chaincodeID := range txStateDelta.GetUpdatedChaincodeIds(true) {
+			updates := txStateDelta.GetUpdates(chaincodeID)
+			keys := make([]string, 0, len(updates))
+			for key := range updates {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				updatedValue := updates[key]
+				modification := &protos.KeyModification{
+					TxID:        tx.Txid,
+					BlockNumber: blockNumber,
+					Value:       updatedValue.GetValue(),
+					Timestamp:   tx.Timestamp,
+					IsDelete:    updatedValue.IsDeleted(),
+				}
+				modificationBytes, err := proto.Marshal(modification)
+				if err != nil {
+					return err
+				}
+				writeBatch.PutCF(cf, encodeKeyHistoryKey(chaincodeID, key, blockNumber, uint64(txIndex)), modificationBytes)
+			}
+		}
+	}
+	return nil
+}
+
+// fetchKeyHistoryFromDB returns the committed modifications of key by chaincodeID, oldest first
+func fetchKeyHistoryFromDB(chaincodeID string, key string) ([]*protos.KeyModification, error) {
+	prefix := encodeKeyHistoryPrefix(chaincodeID, key)
+	itr := db.GetDBHandle().GetIterator(db.GetDBHandle().IndexesCF)
+	defer itr.Close()
+	modifications := []*protos.KeyModification{}
+	for itr.Seek(prefix); itr.ValidForPrefix(prefix); itr.Next() {
+		modification := &protos.KeyModification{}
+		err := proto.Unmarshal(itr.Value(), modification)
+		if err != nil {
+			return nil, err
+		}
+		modifications = append(modifications, modification)
+	}
+	return modifications, nil
+}
+
+// fetchKeyHistoryCompleteFromDB returns the lowest block number from which the
+// history holds every committed modification
+func fetchKeyHistoryCompleteFromDB() (uint64, error) {
+	completeFromBytes, err := db.GetDBHandle().GetFromIndexesCF(keyHistoryCompleteFromKey)
+	if err != nil {
+		return 0, err
+	}
+	if completeFromBytes == nil {
+		return 0, nil
+	}
+	return decodeToUint64(completeFromBytes), nil
+}
+
+// recordKeyHistoryGap records that block blockNumber was put on the chain
+// without adding its modifications to the history
+func recordKeyHistoryGap(blockNumber uint64) error {
+	completeFrom, err := fetchKeyHistoryCompleteFromDB()
+	if err != nil {
+		return err
+	}
+	if blockNumber < completeFrom {
+		return nil
+	}
+	openchainDB := db.GetDBHandle()
+	return openchainDB.Put(openchainDB.IndexesCF, keyHistoryCompleteFromKey, encodeUint64(blockNumber+1))
+}
+
+// The chaincode ID and the key are length-prefixed so that the entries of a key never
+// share a prefix with the entries of another key. The block number and the index of
+// the transaction in the block keep the entries of a key in commit order.
+func encodeKeyHistoryPrefix(chaincodeID string, key string) []byte {
+	b := proto.NewBuffer([]byte{prefixKeyHistoryKey})
+	b.EncodeStringBytes(chaincodeID)
+	b.EncodeStringBytes(key)
+	return b.Bytes()
+}
+
+func encodeKeyHistoryKey(chaincodeID string, key string, blockNumber uint64, txIndex uint64) []byte {
+	historyKey := encodeKeyHistoryPrefix(chaincodeID, key)
+	historyKey = append(historyKey, encodeUint64(blockNumber)...)
+	return append(historyKey, encodeUint64(txIndex)...)
+}
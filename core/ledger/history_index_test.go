@@ -0,0 +1,118 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/hyperledger/fabric/protos"
+)
+
+func TestLedgerKeyHistory(t *testing.T) {
+	ledgerTestWrapper := createFreshDBAndTestLedgerWrapper(t)
+	ledger := ledgerTestWrapper.ledger
+
+	firstBlockNumber := ledger.GetBlockchainSize()
+	tx1, uuid1 := buildTestTx(t)
+	tx2, uuid2 := buildTestTx(t)
+	tx3, uuid3 := buildTestTx(t)
+	ledger.BeginTxBatch(1)
+	ledger.TxBegin(uuid1)
+	ledger.SetState("chaincode1", "key1", []byte("value1"))
+	ledger.SetState("chaincode1", "key1\x00suffix", []byte("other key"))
+	ledger.TxFinished(uuid1, true)
+	ledger.TxBegin(uuid2)
+	ledger.SetState("chaincode1", "key1", []byte("value2"))
+	ledger.TxFinished(uuid2, false)
+	ledger.TxBegin(uuid3)
+	ledger.SetState("chaincode1", "key1", []byte("value3"))
+	ledger.TxFinished(uuid3, true)
+	err := ledger.CommitTxBatch(1, []*protos.Transaction{tx1, tx2, tx3}, nil, []byte("proof"))
+	testutil.AssertNoError(t, err, "Error committing block")
+
+	tx4, uuid4 := buildTestTx(t)
+	ledger.BeginTxBatch(2)
+	ledger.TxBegin(uuid4)
+	ledger.DeleteState("chaincode1", "key1")
+	ledger.TxFinished(uuid4, true)
+	err = ledger.CommitTxBatch(2, []*protos.Transaction{tx4}, nil, []byte("proof"))
+	testutil.AssertNoError(t, err, "Error committing block")
+
+	history, completeFrom, err := ledger.GetHistoryForKey("chaincode1", "key1")
+	testutil.AssertNoError(t, err, "Error fetching key history")
+	testutil.AssertEquals(t, completeFrom, uint64(0))
+	testutil.AssertEquals(t, len(history), 3)
+	if len(history) != 3 {
+		t.FailNow()
+	}
+	testutil.AssertEquals(t, history[0].TxID, uuid1)
+	testutil.AssertEquals(t, history[0].BlockNumber, firstBlockNumber)
+	testutil.AssertEquals(t, history[0].Value, []byte("value1"))
+	testutil.AssertEquals(t, history[0].Timestamp, tx1.Timestamp)
+	testutil.AssertEquals(t, history[1].TxID, uuid3)
+	testutil.AssertEquals(t, history[1].Value, []byte("value3"))
+	testutil.AssertEquals(t, history[2].TxID, uuid4)
+	testutil.AssertEquals(t, history[2].BlockNumber, firstBlockNumber+1)
+	testutil.AssertEquals(t, history[2].IsDelete, true)
+	testutil.AssertNil(t, history[2].Value)
+
+	history, _, err = ledger.GetHistoryForKey("chaincode2", "key1")
+	testutil.AssertNoError(t, err, "Error fetching key history")
+	testutil.AssertEquals(t, len(history), 0)
+}
+
+func TestLedgerKeyHistoryGap(t *testing.T) {
+	ledgerTestWrapper := createFreshDBAndTestLedgerWrapper(t)
+	ledger := ledgerTestWrapper.ledger
+
+	tx1, uuid1 := buildTestTx(t)
+	ledger.BeginTxBatch(1)
+	ledger.TxBegin(uuid1)
+	ledger.SetState("chaincode1", "key1", []byte("value1"))
+	ledger.TxFinished(uuid1, true)
+	err := ledger.CommitTxBatch(1, []*protos.Transaction{tx1}, nil, []byte("proof"))
+	testutil.AssertNoError(t, err, "Error committing block")
+
+	// a block obtained through state transfer, its state delta leaves no history
+	tx2, _ := buildTestTx(t)
+	block := protos.NewBlock([]*protos.Transaction{tx2}, nil)
+	blockNumber := ledger.GetBlockchainSize()
+	delta := statemgmt.NewStateDelta()
+	delta.Set("chaincode1", "key1", []byte("value2"), nil)
+	testutil.AssertNoError(t, ledger.ApplyStateDelta(1, delta), "Error applying state delta")
+	testutil.AssertNoError(t, ledger.CommitStateDelta(1), "Error committing state delta")
+	block.StateHash, err = ledger.GetTempStateHash()
+	testutil.AssertNoError(t, err, "Error computing the state hash")
+	testutil.AssertNoError(t, ledger.PutRawBlock(block, blockNumber), "Error putting raw block")
+
+	tx3, uuid3 := buildTestTx(t)
+	ledger.BeginTxBatch(3)
+	ledger.TxBegin(uuid3)
+	ledger.SetState("chaincode1", "key1", []byte("value3"))
+	ledger.TxFinished(uuid3, true)
+	err = ledger.CommitTxBatch(3, []*protos.Transaction{tx3}, nil, []byte("proof"))
+	testutil.AssertNoError(t, err, "Error committing block")
+
+	history, completeFrom, err := ledger.GetHistoryForKey("chaincode1", "key1")
+	testutil.AssertNoError(t, err, "Error fetching key history")
+	testutil.AssertEquals(t, completeFrom, blockNumber+1)
+	testutil.AssertEquals(t, len(history), 2)
+	testutil.AssertEquals(t, history[1].TxID, uuid3)
+	testutil.AssertEquals(t, history[1].BlockNumber, blockNumber+1)
+}
@@ -28,7 +28,6 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/statemgmt/state"
 	"github.com/hyperledger/fabric/events/producer"
 	"github.com/op/go-logging"
-	"github.com/tecbot/gorocksdb"
 
 	"github.com/hyperledger/fabric/protos"
 	"golang.org/x/net/context"
@@ -48,6 +47,8 @@ const (
 	ErrorTypeResourceNotFound = ErrorType("ResourceNotFound")
 	//ErrorTypeBlockNotFound used to indicate if a block is not found when looked up by it's hash
 	ErrorTypeBlockNotFound = ErrorType("ErrorTypeBlockNotFound")
+	//ErrorTypePruned used to indicate that the transactions of a block have been pruned
+	ErrorTypePruned = ErrorType("Pruned")
 )
 
 //Error can be used for throwing an error from ledger code.
@@ -82,6 +83,8 @@ type Ledger struct {
 	blockchain *blockchain
 	state      *state.State
 	currentID  interface{}
+	pruning    *pruningConfig
+	pruner     *blockPruner
 }
 
 var ledger *Ledger
@@ -103,8 +106,13 @@ func GetNewLedger() (*Ledger, error) {
 		return nil, err
 	}
 
+	pruning, err := loadPruningConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	state := state.NewState()
-	return &Ledger{blockchain, state, nil}, nil
+	return &Ledger{blockchain, state, nil, pruning, &blockPruner{}}, nil
 }
 
 /////////////////// Transaction-batch related methods ///////////////////////////////
@@ -156,8 +164,7 @@ func (ledger *Ledger) CommitTxBatch(id interface{}, transactions []*protos.Trans
 		return err
 	}
 
-	writeBatch := gorocksdb.NewWriteBatch()
-	defer writeBatch.Destroy()
+	writeBatch := db.NewWriteBatch()
 	block := protos.NewBlock(transactions, metadata)
 
 	ccEvents := []*protos.ChaincodeEvent{}
@@ -190,10 +197,19 @@ func (ledger *Ledger) CommitTxBatch(id interface{}, transactions []*protos.Trans
 		ledger.blockchain.blockPersistenceStatus(false)
 		return err
 	}
-	ledger.state.AddChangesForPersistence(newBlockNumber, writeBatch)
-	opt := gorocksdb.NewDefaultWriteOptions()
-	defer opt.Destroy()
-	dbErr := db.GetDBHandle().DB.Write(opt, writeBatch)
+	err = addKeyHistoryForPersistence(transactions, newBlockNumber, ledger.state.GetTxStateDeltas(), writeBatch)
+	if err != nil {
+		ledger.resetForNextTxGroup(false)
+		ledger.blockchain.blockPersistenceStatus(false)
+		return err
+	}
+	err = ledger.state.AddChangesForPersistence(newBlockNumber, writeBatch)
+	if err != nil {
+		ledger.resetForNextTxGroup(false)
+		ledger.blockchain.blockPersistenceStatus(false)
+		return err
+	}
+	dbErr := db.GetDBHandle().Write(writeBatch)
 	if dbErr != nil {
 		ledger.resetForNextTxGroup(false)
 		ledger.blockchain.blockPersistenceStatus(false)
@@ -208,6 +224,8 @@ func (ledger *Ledger) CommitTxBatch(id interface{}, transactions []*protos.Trans
 	//send chaincode events from transaction results
 	sendChaincodeEvents(transactionResults)
 
+	ledger.pruneBlocksIfDue(newBlockNumber)
+
 	if len(transactionResults) != 0 {
 		ledgerLogger.Debug("There were some erroneous transactions. We need to send a 'TX rejected' message here.")
 	}
@@ -237,6 +255,11 @@ func (ledger *Ledger) TxFinished(txID string, txSuccessful bool) {
 	ledger.state.TxFinish(txID, txSuccessful)
 }
 
+// GetCurrentTxStateDelta returns the state changes made so far by the on-going transaction
+func (ledger *Ledger) GetCurrentTxStateDelta() *statemgmt.StateDelta {
+	return ledger.state.GetCurrentTxStateDelta()
+}
+
 /////////////////// world-state related methods /////////////////////////////////////
 /////////////////////////////////////////////////////////////////////////////////////
 
@@ -264,11 +287,35 @@ func (ledger *Ledger) GetState(chaincodeID string, key string, committed bool) (
 // (assuming lexical order of the keys) for a chaincodeID.
 // If committed is true, the key-values are retrieved only from the db. If committed is false, the results from db
 // are mergerd with the results in memory (giving preference to in-memory data)
-// The key-values in the returned iterator are not guaranteed to be in any specific order
+// The key-values in the returned iterator are in lexical order of the keys
 func (ledger *Ledger) GetStateRangeScanIterator(chaincodeID string, startKey string, endKey string, committed bool) (statemgmt.RangeScanIterator, error) {
 	return ledger.state.GetRangeScanIterator(chaincodeID, startKey, endKey, committed)
 }
 
+// QueryState returns an iterator over the key-values of chaincodeID whose values are JSON objects
+// selected by the given rich query, in the order requested by the query.
+// If committed is true, the key-values are retrieved only from the db. If committed is false, the results from db
+// are mergerd with the results in memory (giving preference to in-memory data)
+func (ledger *Ledger) QueryState(chaincodeID string, query string, committed bool) (statemgmt.RangeScanIterator, error) {
+	return ledger.state.Query(chaincodeID, query, committed)
+}
+
+// GetHistoryForKey returns the committed modifications of key by chaincodeID, oldest first.
+// The history is kept for the blocks committed by this peer; blocks obtained through
+// state transfer or a state snapshot import are not part of it. The modifications are
+// complete from the returned block number on, older ones may be missing.
+func (ledger *Ledger) GetHistoryForKey(chaincodeID string, key string) ([]*protos.KeyModification, uint64, error) {
+	completeFrom, err := fetchKeyHistoryCompleteFromDB()
+	if err != nil {
+		return nil, 0, err
+	}
+	modifications, err := fetchKeyHistoryFromDB(chaincodeID, key)
+	if err != nil {
+		return nil, 0, err
+	}
+	return modifications, completeFrom, nil
+}
+
 // SetState sets state to given value for chaincodeID and key. Does not immideatly writes to DB
 func (ledger *Ledger) SetState(chaincodeID string, key string, value []byte) error {
 	if key == "" || value == nil {
@@ -414,7 +461,12 @@ func (ledger *Ledger) GetTransactionByID(txID string) (*protos.Transaction, erro
 // PutRawBlock puts a raw block on the chain. This function should only be
 // used for synchronization between peers.
 func (ledger *Ledger) PutRawBlock(block *protos.Block, blockNumber uint64) error {
-	err := ledger.blockchain.persistRawBlock(block, blockNumber)
+	// recorded first, so that the history never claims to cover the block
+	err := recordKeyHistoryGap(blockNumber)
+	if err != nil {
+		return err
+	}
+	err = ledger.blockchain.persistRawBlock(block, blockNumber)
 	if err != nil {
 		return err
 	}
@@ -444,7 +496,8 @@ func (ledger *Ledger) VerifyChain(highBlock, lowBlock uint64) (uint64, error) {
 		return lowBlock, ErrOutOfBounds
 	}
 
-	currentBlock, err := ledger.GetBlockByNumber(highBlock)
+	// Pruned blocks are verified with their headers
+	currentBlock, _, err := ledger.blockchain.getBlockHeader(highBlock)
 	if err != nil {
 		return highBlock, fmt.Errorf("Error fetching block %d.", highBlock)
 	}
@@ -453,17 +506,13 @@ func (ledger *Ledger) VerifyChain(highBlock, lowBlock uint64) (uint64, error) {
 	}
 
 	for i := highBlock; i > lowBlock; i-- {
-		previousBlock, err := ledger.GetBlockByNumber(i - 1)
+		previousBlock, previousBlockHash, err := ledger.blockchain.getBlockHeader(i - 1)
 		if err != nil {
 			return i, nil
 		}
 		if previousBlock == nil {
 			return i, nil
 		}
-		previousBlockHash, err := previousBlock.GetHash()
-		if err != nil {
-			return i, nil
-		}
 		if bytes.Compare(previousBlockHash, currentBlock.PreviousBlockHash) != 0 {
 			return i, nil
 		}
@@ -473,6 +522,54 @@ func (ledger *Ledger) VerifyChain(highBlock, lowBlock uint64) (uint64, error) {
 	return lowBlock, nil
 }
 
+// VerifyState recomputes the state hash from the key-values of the world
+// state, and compares it with the state hash of the last block. It works on a
+// DB snapshot, so blocks can be committed meanwhile. If the state hash differs,
+// or some intermediate crypto-hash which the state implementation keeps in the
+// DB differs from the recomputed one, the result is not consistent and
+// Divergence describes the first bucket or trie node that differs.
+func (ledger *Ledger) VerifyState() (*protos.StateVerification, error) {
+	dbSnapshot := db.GetDBHandle().GetSnapshot()
+	defer dbSnapshot.Release()
+	size, err := fetchBlockchainSizeFromSnapshot(dbSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return nil, fmt.Errorf("Blockchain has no blocks, cannot verify the state")
+	}
+	blockBytes, err := db.GetDBHandle().GetFromBlockchainCFSnapshot(dbSnapshot, encodeBlockNumberDBKey(size-1))
+	if err != nil {
+		return nil, err
+	}
+	if blockBytes == nil {
+		return nil, fmt.Errorf("Block %d is missing, cannot verify the state", size-1)
+	}
+	block, err := protos.UnmarshallBlock(blockBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	computedStateHash, divergence, err := ledger.state.VerifyHash(dbSnapshot)
+	if err != nil {
+		return nil, fmt.Errorf("Error recomputing the state hash: %s", err)
+	}
+	verification := &protos.StateVerification{
+		BlockNumber:       size - 1,
+		BlockStateHash:    block.StateHash,
+		ComputedStateHash: computedStateHash,
+		Divergence:        divergence,
+		Consistent:        divergence == "" && bytes.Equal(block.StateHash, computedStateHash),
+	}
+	if verification.Consistent {
+		ledgerLogger.Infof("Verified the state hash %x of block %d", computedStateHash, size-1)
+	} else {
+		ledgerLogger.Errorf("The recomputed state hash %x differs from the state hash %x of block %d. Divergence: %s",
+			computedStateHash, block.StateHash, size-1, divergence)
+	}
+	return verification, nil
+}
+
 func (ledger *Ledger) checkValidIDBegin() error {
 	if ledger.currentID != nil {
 		return fmt.Errorf("Another TxGroup [%s] already in-progress", ledger.currentID)
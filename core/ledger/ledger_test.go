@@ -415,6 +415,36 @@ func TestVerifyChain(t *testing.T) {
 	testutil.AssertError(t, err, "Expected error as high block is out of bounds")
 }
 
+func TestVerifyState(t *testing.T) {
+	ledgerTestWrapper := createFreshDBAndTestLedgerWrapper(t)
+	ledger := ledgerTestWrapper.ledger
+	_, err := ledger.VerifyState()
+	testutil.AssertError(t, err, "Expected an error verifying the state of an empty blockchain")
+
+	commitTestBlock(t, ledger, 0, func() {
+		ledger.SetState("chaincode1", "key1", []byte("value1"))
+		ledger.SetState("chaincode2", "key2", []byte("value2"))
+	})
+	commitTestBlock(t, ledger, 1, func() {
+		ledger.DeleteState("chaincode1", "key1")
+		ledger.SetState("chaincode3", "key3", []byte("value3"))
+	})
+	verification, err := ledger.VerifyState()
+	testutil.AssertNoError(t, err, "Error verifying the state")
+	testutil.AssertEquals(t, verification.BlockNumber, uint64(1))
+	testutil.AssertEquals(t, verification.ComputedStateHash, ledgerTestWrapper.GetBlockByNumber(1).StateHash)
+	testutil.AssertEquals(t, verification.Divergence, "")
+	testutil.AssertEquals(t, verification.Consistent, true)
+
+	badBlock := ledgerTestWrapper.GetBlockByNumber(1)
+	badBlock.StateHash = []byte("evil")
+	ledgerTestWrapper.PutRawBlock(badBlock, 1)
+	verification, err = ledger.VerifyState()
+	testutil.AssertNoError(t, err, "Error verifying the state")
+	testutil.AssertEquals(t, verification.BlockStateHash, []byte("evil"))
+	testutil.AssertEquals(t, verification.Consistent, false)
+}
+
 func TestBlockNumberOutOfBoundsError(t *testing.T) {
 	ledgerTestWrapper := createFreshDBAndTestLedgerWrapper(t)
 	ledger := ledgerTestWrapper.ledger
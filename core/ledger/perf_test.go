@@ -28,7 +28,6 @@ import (
 	"github.com/hyperledger/fabric/core/util"
 	"github.com/hyperledger/fabric/protos"
 	"github.com/op/go-logging"
-	"github.com/tecbot/gorocksdb"
 )
 
 func BenchmarkDB(b *testing.B) {
@@ -50,7 +49,7 @@ func BenchmarkDB(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		key := []byte(*keyPrefix + strconv.Itoa(randNumGen.Next()))
-		value := dbWrapper.GetFromDB(b, key)
+		value := dbWrapper.GetFromStateCF(b, key)
 		b.SetBytes(int64(len(value)))
 	}
 }
@@ -203,14 +202,14 @@ func BenchmarkLedgerRandomTransactions(b *testing.B) {
 func populateDB(tb testing.TB, kvSize int, totalKeys int, keyPrefix string) {
 	dbWrapper := db.NewTestDBWrapper()
 	dbWrapper.CleanDB(tb)
-	batch := gorocksdb.NewWriteBatch()
+	batch := db.NewWriteBatch()
 	for i := 0; i < totalKeys; i++ {
 		key := []byte(keyPrefix + strconv.Itoa(i))
 		value := testutil.ConstructRandomBytes(tb, kvSize-len(key))
-		batch.Put(key, value)
+		batch.PutCF(db.GetDBHandle().StateCF, key, value)
 		if i%1000 == 0 {
 			dbWrapper.WriteToDB(tb, batch)
-			batch = gorocksdb.NewWriteBatch()
+			batch = db.NewWriteBatch()
 		}
 	}
 	dbWrapper.CloseDB(tb)
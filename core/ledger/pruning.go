@@ -0,0 +1,304 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/protos"
+	"github.com/spf13/viper"
+)
+
+// The number of blocks pruned in each write to the DB
+const pruneBatchSize = 100
+
+var prunedHeightKey = []byte("prunedHeight")
+
+// pruningConfig is read from ledger.blockchain.pruning in core.yaml
+type pruningConfig struct {
+	enabled      bool
+	retainBlocks uint64
+	interval     uint64
+	archiveDir   string
+}
+
+func loadPruningConfig() (*pruningConfig, error) {
+	config := &pruningConfig{
+		enabled:    viper.GetBool("ledger.blockchain.pruning.enabled"),
+		archiveDir: viper.GetString("ledger.blockchain.pruning.archiveDir"),
+	}
+	if !config.enabled {
+		return config, nil
+	}
+	retainBlocks := viper.GetInt("ledger.blockchain.pruning.retainBlocks")
+	if retainBlocks < 1 {
+		return nil, fmt.Errorf("The number of blocks retained by pruning must be at least 1. Current value is %d.", retainBlocks)
+	}
+	config.retainBlocks = uint64(retainBlocks)
+	config.interval = 1
+	if interval := viper.GetInt("ledger.blockchain.pruning.interval"); interval > 1 {
+		config.interval = uint64(interval)
+	}
+	ledgerLogger.Infof("Pruning the blockchain every %d blocks, retaining the last %d blocks, archiving to [%s]",
+		config.interval, config.retainBlocks, config.archiveDir)
+	return config, nil
+}
+
+// blockPruner prunes the blockchain in the background, so that pruning a
+// long chain for the first time does not hold up the commit of a block
+type blockPruner struct {
+	lock sync.Mutex
+	// the blocks below target are due to be pruned
+	target  uint64
+	running bool
+	done    sync.WaitGroup
+	// held while pruning, by the background and by PruneBlocks
+	pruneLock sync.Mutex
+}
+
+// start raises the target of the pruning to blockNumber, and starts pruning
+// in the background unless a pruning is running already, which carries on
+// to the new target
+func (pruner *blockPruner) start(blockNumber uint64, prune func(uint64) error) {
+	pruner.lock.Lock()
+	defer pruner.lock.Unlock()
+	if blockNumber > pruner.target {
+		pruner.target = blockNumber
+	}
+	if pruner.running {
+		return
+	}
+	pruner.running = true
+	pruner.done.Add(1)
+	go func() {
+		defer pruner.done.Done()
+		var pruned uint64
+		for {
+			pruner.lock.Lock()
+			target := pruner.target
+			if target <= pruned {
+				pruner.running = false
+				pruner.lock.Unlock()
+				return
+			}
+			pruner.lock.Unlock()
+			if err := prune(target); err != nil {
+				ledgerLogger.Errorf("Error pruning the blocks below %d: %s", target, err)
+				pruner.lock.Lock()
+				pruner.running = false
+				pruner.lock.Unlock()
+				return
+			}
+			pruned = target
+		}
+	}()
+}
+
+// wait returns once the pruning in the background, if any, has stopped
+func (pruner *blockPruner) wait() {
+	pruner.done.Wait()
+}
+
+func newPrunedError(blockNumber uint64, prunedHeight uint64) *Error {
+	return newLedgerError(ErrorTypePruned, fmt.Sprintf("Block %d has been pruned, only the blocks from %d are kept whole", blockNumber, prunedHeight))
+}
+
+// GetPrunedHeight returns the number of the lowest block which has not been
+// pruned. The blocks below it only keep their headers.
+func (ledger *Ledger) GetPrunedHeight() uint64 {
+	ledger.blockchain.prunedHeightLock.RLock()
+	defer ledger.blockchain.prunedHeightLock.RUnlock()
+	return ledger.blockchain.prunedHeight
+}
+
+// pruneBlocksIfDue starts pruning the blocks which are no longer retained in
+// the background, when the blockchain height after block blockNumber is a
+// multiple of the pruning interval. The block is committed already, so
+// pruning errors are only logged.
+func (ledger *Ledger) pruneBlocksIfDue(blockNumber uint64) {
+	height := blockNumber + 1
+	if !ledger.pruning.enabled || height%ledger.pruning.interval != 0 || height <= ledger.pruning.retainBlocks {
+		return
+	}
+	ledger.pruner.start(height-ledger.pruning.retainBlocks, ledger.pruneBlocks)
+}
+
+// PruneBlocks removes the transactions of the blocks below blockNumber, and
+// their state deltas, from the ledger. The headers and hashes of the blocks
+// are kept, so that the chain can still be verified. If an archive directory
+// is configured, the blocks are first written to a compressed file there.
+// The last block of the blockchain cannot be pruned.
+func (ledger *Ledger) PruneBlocks(blockNumber uint64) error {
+	if blockNumber >= ledger.GetBlockchainSize() {
+		return ErrOutOfBounds
+	}
+	return ledger.pruneBlocks(blockNumber)
+}
+
+// pruneBlocks prunes the blocks below blockNumber, which has to be below the
+// blockchain size
+func (ledger *Ledger) pruneBlocks(blockNumber uint64) error {
+	ledger.pruner.pruneLock.Lock()
+	defer ledger.pruner.pruneLock.Unlock()
+	fromBlockNumber := ledger.GetPrunedHeight()
+	if blockNumber <= fromBlockNumber {
+		return nil
+	}
+	if ledger.pruning.archiveDir != "" {
+		if err := ledger.archiveBlocks(fromBlockNumber, blockNumber); err != nil {
+			return fmt.Errorf("Error archiving blocks %d to %d: %s", fromBlockNumber, blockNumber-1, err)
+		}
+	}
+
+	for from := fromBlockNumber; from < blockNumber; from += pruneBatchSize {
+		to := from + pruneBatchSize
+		if to > blockNumber {
+			to = blockNumber
+		}
+		writeBatch := db.NewWriteBatch()
+		for n := from; n < to; n++ {
+			block, err := fetchBlockFromDB(n)
+			if err != nil {
+				return err
+			}
+			if block == nil {
+				// Missing after state transfer or a state snapshot import
+				continue
+			}
+			blockHash, err := block.GetHash()
+			if err != nil {
+				return err
+			}
+			block.Transactions = nil
+			prunedBlockBytes, err := proto.Marshal(&protos.PrunedBlock{Block: block, BlockHash: blockHash})
+			if err != nil {
+				return err
+			}
+			writeBatch.PutCF(db.GetDBHandle().BlockchainCF, encodeBlockNumberDBKey(n), prunedBlockBytes)
+		}
+		ledger.state.AddStateDeltaPruningForPersistence(from, to, writeBatch)
+		writeBatch.PutCF(db.GetDBHandle().BlockchainCF, prunedHeightKey, encodeUint64(to))
+		if err := ledger.blockchain.writePrunedBlocks(writeBatch, to); err != nil {
+			return err
+		}
+	}
+	ledgerLogger.Infof("Pruned blocks %d to %d", fromBlockNumber, blockNumber-1)
+	return nil
+}
+
+// writePrunedBlocks writes the pruned blocks below prunedHeight, without
+// readers of the blocks seeing a pruned block as a whole one
+func (blockchain *blockchain) writePrunedBlocks(writeBatch *db.WriteBatch, prunedHeight uint64) error {
+	blockchain.prunedHeightLock.Lock()
+	defer blockchain.prunedHeightLock.Unlock()
+	if err := db.GetDBHandle().Write(writeBatch); err != nil {
+		return err
+	}
+	blockchain.prunedHeight = prunedHeight
+	return nil
+}
+
+// archiveBlocks writes the blocks from fromBlockNumber up to, but not
+// including, toBlockNumber, with their state deltas, as ArchivedBlock records
+// to a gzip compressed file in the archive directory
+func (ledger *Ledger) archiveBlocks(fromBlockNumber uint64, toBlockNumber uint64) error {
+	archiveDir := ledger.pruning.archiveDir
+	if err := os.MkdirAll(archiveDir, 0755); err != nil {
+		return err
+	}
+	archiveFile := filepath.Join(archiveDir, fmt.Sprintf("blocks_%d-%d.gz", fromBlockNumber, toBlockNumber-1))
+	// The archive only gets its name once it is complete
+	tempFile := archiveFile + ".tmp"
+	file, err := os.Create(tempFile)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile)
+	defer file.Close()
+
+	out := gzip.NewWriter(file)
+	for n := fromBlockNumber; n < toBlockNumber; n++ {
+		block, err := fetchBlockFromDB(n)
+		if err != nil {
+			return err
+		}
+		if block == nil {
+			continue
+		}
+		archivedBlock := &protos.ArchivedBlock{BlockNumber: n, Block: block}
+		stateDelta, err := ledger.state.FetchStateDeltaFromDB(n)
+		if err != nil {
+			return err
+		}
+		if stateDelta != nil {
+			archivedBlock.StateDelta = stateDelta.Marshal()
+		}
+		archivedBlockBytes, err := proto.Marshal(archivedBlock)
+		if err != nil {
+			return err
+		}
+		if err = writeRecord(out, archivedBlockBytes); err != nil {
+			return err
+		}
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tempFile, archiveFile); err != nil {
+		return err
+	}
+	ledgerLogger.Infof("Archived blocks %d to %d to [%s]", fromBlockNumber, toBlockNumber-1, archiveFile)
+	return nil
+}
+
+func fetchPrunedBlockFromDB(blockNumber uint64) (*protos.PrunedBlock, error) {
+	prunedBlockBytes, err := db.GetDBHandle().GetFromBlockchainCF(encodeBlockNumberDBKey(blockNumber))
+	if err != nil {
+		return nil, err
+	}
+	if prunedBlockBytes == nil {
+		return nil, nil
+	}
+	prunedBlock := &protos.PrunedBlock{}
+	if err = proto.Unmarshal(prunedBlockBytes, prunedBlock); err != nil {
+		return nil, fmt.Errorf("Could not unmarshal pruned block %d: %s", blockNumber, err)
+	}
+	return prunedBlock, nil
+}
+
+func fetchPrunedHeightFromDB() (uint64, error) {
+	prunedHeightBytes, err := db.GetDBHandle().GetFromBlockchainCF(prunedHeightKey)
+	if err != nil {
+		return 0, err
+	}
+	if prunedHeightBytes == nil {
+		return 0, nil
+	}
+	return decodeToUint64(prunedHeightBytes), nil
+}
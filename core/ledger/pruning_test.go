@@ -0,0 +1,156 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/hyperledger/fabric/protos"
+	"github.com/spf13/viper"
+)
+
+func TestLedgerPruneBlocks(t *testing.T) {
+	archiveDir, err := ioutil.TempDir("", "ledger_pruning_test")
+	testutil.AssertNoError(t, err, "Error creating the archive directory")
+	defer os.RemoveAll(archiveDir)
+	viper.Set("ledger.blockchain.pruning.enabled", true)
+	viper.Set("ledger.blockchain.pruning.retainBlocks", 2)
+	viper.Set("ledger.blockchain.pruning.interval", 1)
+	viper.Set("ledger.blockchain.pruning.archiveDir", archiveDir)
+	defer viper.Set("ledger.blockchain.pruning.enabled", false)
+	defer viper.Set("ledger.blockchain.pruning.archiveDir", "")
+
+	ledgerTestWrapper := createFreshDBAndTestLedgerWrapper(t)
+	ledger := ledgerTestWrapper.ledger
+	var txIDs []string
+	for i := 0; i < 5; i++ {
+		txIDs = append(txIDs, commitTestBlock(t, ledger, i, func() {
+			ledger.SetState("chaincode1", fmt.Sprintf("key%d", i), []byte("value"))
+		}))
+		ledger.pruner.wait()
+	}
+	testutil.AssertEquals(t, ledger.GetPrunedHeight(), uint64(3))
+
+	_, err = ledger.GetBlockByNumber(1)
+	ledgerErr, ok := err.(*Error)
+	if !(ok && ledgerErr.Type() == ErrorTypePruned) {
+		t.Fatalf("Expected a pruned error fetching a pruned block, found %v", err)
+	}
+	_, err = ledger.GetTransactionByID(txIDs[1])
+	ledgerErr, ok = err.(*Error)
+	if !(ok && ledgerErr.Type() == ErrorTypePruned) {
+		t.Fatalf("Expected a pruned error fetching a transaction of a pruned block, found %v", err)
+	}
+	testutil.AssertNotNil(t, ledgerTestWrapper.GetBlockByNumber(3))
+	delta, err := ledger.GetStateDelta(2)
+	testutil.AssertNoError(t, err, "Error fetching the state delta of a pruned block")
+	testutil.AssertNil(t, delta)
+	delta, err = ledger.GetStateDelta(3)
+	testutil.AssertNoError(t, err, "Error fetching the state delta of a retained block")
+	testutil.AssertNotNil(t, delta)
+	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode1", "key0", true), []byte("value"))
+
+	lowBlock, err := ledger.VerifyChain(4, 0)
+	testutil.AssertNoError(t, err, "Error verifying the chain")
+	testutil.AssertEquals(t, lowBlock, uint64(0))
+
+	// each pruning archived one block
+	archivedBlock := readTestBlockArchive(t, filepath.Join(archiveDir, "blocks_1-1.gz"))
+	testutil.AssertEquals(t, archivedBlock.BlockNumber, uint64(1))
+	testutil.AssertEquals(t, archivedBlock.Block.Transactions[0].Txid, txIDs[1])
+	testutil.AssertNotNil(t, archivedBlock.StateDelta)
+	_, prunedBlockHash, err := ledger.blockchain.getBlockHeader(1)
+	testutil.AssertNoError(t, err, "Error fetching the header of a pruned block")
+	archivedBlockHash, err := archivedBlock.Block.GetHash()
+	testutil.AssertNoError(t, err, "Error hashing the archived block")
+	testutil.AssertEquals(t, archivedBlockHash, prunedBlockHash)
+
+	// the pruned height survives a restart
+	ledger, err = GetNewLedger()
+	testutil.AssertNoError(t, err, "Error constructing the ledger")
+	testutil.AssertEquals(t, ledger.GetPrunedHeight(), uint64(3))
+	testutil.AssertEquals(t, ledger.PruneBlocks(5), ErrOutOfBounds)
+}
+
+func TestLedgerPruneBlocksInBackground(t *testing.T) {
+	ledgerTestWrapper := createFreshDBAndTestLedgerWrapper(t)
+	ledger := ledgerTestWrapper.ledger
+	for i := 0; i < 250; i++ {
+		commitTestBlock(t, ledger, i, func() {
+			ledger.SetState("chaincode1", fmt.Sprintf("key%d", i), []byte("value"))
+		})
+	}
+
+	// turning pruning on for a long chain prunes it while blocks are
+	// committed and read
+	ledger.pruning = &pruningConfig{enabled: true, retainBlocks: 10, interval: 1}
+	stopReading := make(chan struct{})
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		for n := uint64(0); ; n = (n + 1) % 250 {
+			select {
+			case <-stopReading:
+				return
+			default:
+			}
+			block, err := ledger.blockchain.getBlock(n)
+			if ledgerErr, ok := err.(*Error); ok && ledgerErr.Type() == ErrorTypePruned {
+				continue
+			}
+			if err != nil || len(block.Transactions) != 1 {
+				t.Errorf("Expected block %d whole or pruned, found %v %v", n, block, err)
+				return
+			}
+		}
+	}()
+	for i := 250; i < 255; i++ {
+		commitTestBlock(t, ledger, i, func() {
+			ledger.SetState("chaincode1", fmt.Sprintf("key%d", i), []byte("value"))
+		})
+	}
+	ledger.pruner.wait()
+	close(stopReading)
+	<-readerDone
+	testutil.AssertEquals(t, ledger.GetPrunedHeight(), uint64(245))
+
+	lowBlock, err := ledger.VerifyChain(254, 0)
+	testutil.AssertNoError(t, err, "Error verifying the chain")
+	testutil.AssertEquals(t, lowBlock, uint64(0))
+}
+
+func readTestBlockArchive(t *testing.T, archiveFile string) *protos.ArchivedBlock {
+	file, err := os.Open(archiveFile)
+	testutil.AssertNoError(t, err, "Error opening the block archive")
+	defer file.Close()
+	in, err := gzip.NewReader(file)
+	testutil.AssertNoError(t, err, "Error reading the block archive")
+	archivedBlockBytes, err := readRecord(bufio.NewReader(in))
+	testutil.AssertNoError(t, err, "Error reading the block archive")
+	archivedBlock := &protos.ArchivedBlock{}
+	err = proto.Unmarshal(archivedBlockBytes, archivedBlock)
+	testutil.AssertNoError(t, err, "Error unmarshalling the archived block")
+	return archivedBlock
+}
@@ -0,0 +1,325 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+	"github.com/hyperledger/fabric/protos"
+)
+
+// A state snapshot file starts with stateSnapshotMagic and a
+// StateSnapshotHeader, followed by the world state as chunks of marshalled
+// state deltas and an empty record. Headers and chunks are records, prefixed
+// with their length as a varint. The file ends with the SHA-256 checksum of
+// all the bytes before it.
+const (
+	stateSnapshotMagic     = "FABRIC-STATE-SNAPSHOT\n"
+	stateSnapshotVersion   = 1
+	stateSnapshotChunkSize = 1000
+)
+
+// ExportStateSnapshot writes the world state at block blockNumber, together
+// with the block, to out. The state at a block before the last one is rolled
+// back with the state deltas of the blocks after it, so it can only be
+// exported while these deltas are kept, see ledger.state.deltaHistorySize in
+// core.yaml.
+func (ledger *Ledger) ExportStateSnapshot(blockNumber uint64, out io.Writer) (*protos.StateSnapshotHeader, error) {
+	snapshot, err := ledger.GetStateSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	defer snapshot.Release()
+	lastBlockNumber := snapshot.GetBlockNumber()
+	if blockNumber > lastBlockNumber {
+		return nil, ErrOutOfBounds
+	}
+	block, err := ledger.GetBlockByNumber(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	previousValues, err := ledger.getPreviousStateValues(blockNumber, lastBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	header := &protos.StateSnapshotHeader{Version: stateSnapshotVersion, BlockNumber: blockNumber, Block: block}
+	headerBytes, err := proto.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	writer := &stateSnapshotWriter{out: out, hash: sha256.New()}
+	if _, err = writer.Write([]byte(stateSnapshotMagic)); err != nil {
+		return nil, err
+	}
+	if err = writeRecord(writer, headerBytes); err != nil {
+		return nil, err
+	}
+
+	for snapshot.Next() {
+		compositeKey, value := snapshot.GetRawKeyValue()
+		if _, ok := previousValues[string(compositeKey)]; ok {
+			continue
+		}
+		if err = writer.addKeyValue(compositeKey, statemgmt.Copy(value)); err != nil {
+			return nil, err
+		}
+	}
+	compositeKeys := make([]string, 0, len(previousValues))
+	for compositeKey := range previousValues {
+		compositeKeys = append(compositeKeys, compositeKey)
+	}
+	sort.Strings(compositeKeys)
+	for _, compositeKey := range compositeKeys {
+		value := previousValues[compositeKey]
+		if value == nil {
+			continue
+		}
+		if err = writer.addKeyValue([]byte(compositeKey), value); err != nil {
+			return nil, err
+		}
+	}
+	if err = writer.finish(); err != nil {
+		return nil, err
+	}
+	ledgerLogger.Infof("Exported the state snapshot of block %d with %d keys", blockNumber, writer.numKeys)
+	return header, nil
+}
+
+// getPreviousStateValues returns the values at block blockNumber of the keys
+// changed by the blocks after it up to lastBlockNumber, nil for the keys which
+// did not exist, by composite key
+func (ledger *Ledger) getPreviousStateValues(blockNumber uint64, lastBlockNumber uint64) (map[string][]byte, error) {
+	previousValues := make(map[string][]byte)
+	for n := lastBlockNumber; n > blockNumber; n-- {
+		delta, err := ledger.state.FetchStateDeltaFromDB(n)
+		if err != nil {
+			return nil, err
+		}
+		if delta == nil {
+			return nil, fmt.Errorf("The state delta of block %d is no longer kept, cannot roll the state back to block %d", n, blockNumber)
+		}
+		for _, chaincodeID := range delta.GetUpdatedChaincodeIds(false) {
+			for key, updatedValue := range delta.GetUpdates(chaincodeID) {
+				previousValues[string(statemgmt.ConstructCompositeKey(chaincodeID, key))] = updatedValue.GetPreviousValue()
+			}
+		}
+	}
+	return previousValues, nil
+}
+
+// ImportStateSnapshot bootstraps an empty ledger from a state snapshot written
+// by ExportStateSnapshot. It checks the checksum of the file, checks that the
+// hash of the block of the snapshot is blockHash, loads the state, checks that
+// its hash is the state hash of the block and puts that block on the chain.
+// The blocks before it are not fetched. The checksum and the state hash only
+// show that the file is consistent, blockHash must come from a trusted source
+// for the imported state to be trusted.
+func (ledger *Ledger) ImportStateSnapshot(in io.ReadSeeker, blockHash []byte) (*protos.StateSnapshotHeader, error) {
+	if size := ledger.GetBlockchainSize(); size != 0 {
+		return nil, fmt.Errorf("Cannot import a state snapshot, the blockchain already has %d blocks", size)
+	}
+	if err := verifyStateSnapshotChecksum(in); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(in)
+	magic := make([]byte, len(stateSnapshotMagic))
+	if _, err := io.ReadFull(reader, magic); err != nil || string(magic) != stateSnapshotMagic {
+		return nil, fmt.Errorf("Not a state snapshot file")
+	}
+	headerBytes, err := readRecord(reader)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading the state snapshot: %s", err)
+	}
+	header := &protos.StateSnapshotHeader{}
+	if err = proto.Unmarshal(headerBytes, header); err != nil {
+		return nil, fmt.Errorf("Error unmarshalling the state snapshot header: %s", err)
+	}
+	if header.Version != stateSnapshotVersion {
+		return nil, fmt.Errorf("Unsupported state snapshot version %d, expected %d", header.Version, stateSnapshotVersion)
+	}
+	if header.Block == nil {
+		return nil, fmt.Errorf("The state snapshot has no block")
+	}
+	snapshotBlockHash, err := header.Block.GetHash()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(snapshotBlockHash, blockHash) {
+		return nil, fmt.Errorf("The hash of block %d of the state snapshot [%x] does not match the expected block hash [%x]",
+			header.BlockNumber, snapshotBlockHash, blockHash)
+	}
+
+	if err = ledger.DeleteALLStateKeysAndValues(); err != nil {
+		return nil, err
+	}
+	numKeys := 0
+	for {
+		chunk, err := readRecord(reader)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading the state snapshot: %s", err)
+		}
+		if len(chunk) == 0 {
+			break
+		}
+		delta := statemgmt.NewStateDelta()
+		if err = delta.Unmarshal(chunk); err != nil {
+			return nil, fmt.Errorf("Error unmarshalling a state snapshot chunk: %s", err)
+		}
+		if err = ledger.ApplyStateDelta(header, delta); err != nil {
+			return nil, err
+		}
+		if err = ledger.CommitStateDelta(header); err != nil {
+			return nil, err
+		}
+		for _, chaincodeID := range delta.GetUpdatedChaincodeIds(false) {
+			numKeys += len(delta.GetUpdates(chaincodeID))
+		}
+	}
+
+	stateHash, err := ledger.GetTempStateHash()
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(stateHash, header.Block.StateHash) {
+		ledger.DeleteALLStateKeysAndValues()
+		return nil, fmt.Errorf("The hash of the imported state [%x] does not match the state hash of block %d [%x]",
+			stateHash, header.BlockNumber, header.Block.StateHash)
+	}
+	if err = ledger.PutRawBlock(header.Block, header.BlockNumber); err != nil {
+		return nil, err
+	}
+	ledgerLogger.Infof("Imported the state snapshot of block %d with %d keys", header.BlockNumber, numKeys)
+	return header, nil
+}
+
+// verifyStateSnapshotChecksum compares the checksum at the end of the file
+// with the hash of its contents and rewinds it
+func verifyStateSnapshotChecksum(in io.ReadSeeker) error {
+	size, err := in.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if size < int64(len(stateSnapshotMagic)+sha256.Size) {
+		return fmt.Errorf("Not a state snapshot file, it is only %d bytes long", size)
+	}
+	if _, err = in.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	hash := sha256.New()
+	if _, err = io.CopyN(hash, in, size-sha256.Size); err != nil {
+		return err
+	}
+	checksum := make([]byte, sha256.Size)
+	if _, err = io.ReadFull(in, checksum); err != nil {
+		return err
+	}
+	if !bytes.Equal(checksum, hash.Sum(nil)) {
+		return fmt.Errorf("The checksum of the state snapshot does not match its contents, the file is corrupted")
+	}
+	_, err = in.Seek(0, io.SeekStart)
+	return err
+}
+
+// The state snapshot and block archive files are sequences of records, each
+// prefixed with its length as a varint
+func writeRecord(out io.Writer, record []byte) error {
+	if len(record) > statemgmt.MaxRecordLength {
+		return fmt.Errorf("Record of %d bytes exceeds the maximum of %d bytes", len(record), statemgmt.MaxRecordLength)
+	}
+	var length [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(length[:], uint64(len(record)))
+	if _, err := out.Write(length[:n]); err != nil {
+		return err
+	}
+	_, err := out.Write(record)
+	return err
+}
+
+func readRecord(reader *bufio.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, err
+	}
+	if length > statemgmt.MaxRecordLength {
+		return nil, fmt.Errorf("Record of %d bytes exceeds the maximum of %d bytes", length, statemgmt.MaxRecordLength)
+	}
+	record := make([]byte, length)
+	if _, err = io.ReadFull(reader, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// stateSnapshotWriter writes the records of a state snapshot file, gathering
+// the key-values in chunks, and hashes everything it writes
+type stateSnapshotWriter struct {
+	out     io.Writer
+	hash    hash.Hash
+	chunk   *statemgmt.StateDelta
+	numKeys int
+}
+
+func (writer *stateSnapshotWriter) Write(data []byte) (int, error) {
+	writer.hash.Write(data)
+	return writer.out.Write(data)
+}
+
+func (writer *stateSnapshotWriter) addKeyValue(compositeKey []byte, value []byte) error {
+	if writer.chunk == nil {
+		writer.chunk = statemgmt.NewStateDelta()
+	}
+	chaincodeID, key := statemgmt.DecodeCompositeKey(compositeKey)
+	writer.chunk.Set(chaincodeID, key, value, nil)
+	writer.numKeys++
+	if writer.numKeys%stateSnapshotChunkSize == 0 {
+		return writer.flushChunk()
+	}
+	return nil
+}
+
+func (writer *stateSnapshotWriter) flushChunk() error {
+	if writer.chunk == nil {
+		return nil
+	}
+	chunk := writer.chunk
+	writer.chunk = nil
+	return writeRecord(writer, chunk.Marshal())
+}
+
+// finish writes the last chunk, the empty record which ends the state and
+// the checksum
+func (writer *stateSnapshotWriter) finish() error {
+	if err := writer.flushChunk(); err != nil {
+		return err
+	}
+	if err := writeRecord(writer, nil); err != nil {
+		return err
+	}
+	_, err := writer.out.Write(writer.hash.Sum(nil))
+	return err
+}
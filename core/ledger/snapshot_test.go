@@ -0,0 +1,116 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/hyperledger/fabric/protos"
+)
+
+func TestStateSnapshotExportImport(t *testing.T) {
+	ledgerTestWrapper := createFreshDBAndTestLedgerWrapper(t)
+	ledger := ledgerTestWrapper.ledger
+	commitTestBlock(t, ledger, 0, func() {
+		ledger.SetState("chaincode1", "key1", []byte("value1"))
+		ledger.SetState("chaincode2", "key2", []byte("value2"))
+	})
+	commitTestBlock(t, ledger, 1, func() {
+		ledger.DeleteState("chaincode1", "key1")
+		ledger.SetState("chaincode2", "key2", []byte("value2b"))
+		ledger.SetState("chaincode3", "key3", []byte("value3"))
+	})
+	commitTestBlock(t, ledger, 2, func() {
+		ledger.SetState("chaincode4", "key4", []byte("value4"))
+	})
+
+	var lastSnapshot, firstSnapshot bytes.Buffer
+	header, err := ledger.ExportStateSnapshot(2, &lastSnapshot)
+	testutil.AssertNoError(t, err, "Error exporting the state snapshot of the last block")
+	testutil.AssertEquals(t, header.BlockNumber, uint64(2))
+	_, err = ledger.ExportStateSnapshot(0, &firstSnapshot)
+	testutil.AssertNoError(t, err, "Error exporting the state snapshot of the first block")
+	_, err = ledger.ExportStateSnapshot(3, &bytes.Buffer{})
+	testutil.AssertEquals(t, err, ErrOutOfBounds)
+	firstBlock := ledgerTestWrapper.GetBlockByNumber(0)
+	firstBlockHash, _ := firstBlock.GetHash()
+	lastBlockHash, _ := ledgerTestWrapper.GetBlockByNumber(2).GetHash()
+
+	// the state of block 0 is rolled back from the current state
+	ledgerTestWrapper = createFreshDBAndTestLedgerWrapper(t)
+	ledger = ledgerTestWrapper.ledger
+	header, err = ledger.ImportStateSnapshot(bytes.NewReader(firstSnapshot.Bytes()), firstBlockHash)
+	testutil.AssertNoError(t, err, "Error importing the state snapshot of the first block")
+	testutil.AssertEquals(t, header.BlockNumber, uint64(0))
+	testutil.AssertEquals(t, ledger.GetBlockchainSize(), uint64(1))
+	testutil.AssertEquals(t, ledgerTestWrapper.GetBlockByNumber(0), firstBlock)
+	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode1", "key1", true), []byte("value1"))
+	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode2", "key2", true), []byte("value2"))
+	testutil.AssertNil(t, ledgerTestWrapper.GetState("chaincode3", "key3", true))
+
+	_, err = ledger.ImportStateSnapshot(bytes.NewReader(lastSnapshot.Bytes()), lastBlockHash)
+	testutil.AssertError(t, err, "Expected an error importing a state snapshot into a ledger with blocks")
+
+	ledgerTestWrapper = createFreshDBAndTestLedgerWrapper(t)
+	ledger = ledgerTestWrapper.ledger
+	corrupted := append([]byte{}, lastSnapshot.Bytes()...)
+	corrupted[len(corrupted)/2] ^= 0xff
+	_, err = ledger.ImportStateSnapshot(bytes.NewReader(corrupted), lastBlockHash)
+	testutil.AssertError(t, err, "Expected an error importing a corrupted state snapshot")
+	testutil.AssertEquals(t, ledger.GetBlockchainSize(), uint64(0))
+	_, err = ledger.ImportStateSnapshot(bytes.NewReader(lastSnapshot.Bytes()), firstBlockHash)
+	testutil.AssertError(t, err, "Expected an error importing a state snapshot of another block than expected")
+	testutil.AssertEquals(t, ledger.GetBlockchainSize(), uint64(0))
+
+	_, err = ledger.ImportStateSnapshot(bytes.NewReader(lastSnapshot.Bytes()), lastBlockHash)
+	testutil.AssertNoError(t, err, "Error importing the state snapshot of the last block")
+	testutil.AssertEquals(t, ledger.GetBlockchainSize(), uint64(3))
+	testutil.AssertNil(t, ledgerTestWrapper.GetState("chaincode1", "key1", true))
+	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode2", "key2", true), []byte("value2b"))
+	testutil.AssertEquals(t, ledgerTestWrapper.GetState("chaincode4", "key4", true), []byte("value4"))
+}
+
+func TestReadRecordTooLong(t *testing.T) {
+	var record bytes.Buffer
+	writeRecord(&record, []byte("record"))
+	read, err := readRecord(bufio.NewReader(&record))
+	testutil.AssertNoError(t, err, "Error reading a record")
+	testutil.AssertEquals(t, read, []byte("record"))
+
+	var length [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(length[:], statemgmt.MaxRecordLength+1)
+	_, err = readRecord(bufio.NewReader(bytes.NewReader(length[:n])))
+	testutil.AssertError(t, err, "Expected an error reading a record longer than the maximum")
+}
+
+// commitTestBlock commits a block with a transaction which changes the
+// state with changeState, and returns the ID of the transaction
+func commitTestBlock(t *testing.T, ledger *Ledger, id int, changeState func()) string {
+	tx, uuid := buildTestTx(t)
+	ledger.BeginTxBatch(id)
+	ledger.TxBegin(uuid)
+	changeState()
+	ledger.TxFinished(uuid, true)
+	err := ledger.CommitTxBatch(id, []*protos.Transaction{tx}, nil, []byte("proof"))
+	testutil.AssertNoError(t, err, "Error committing block")
+	return uuid
+}
@@ -63,14 +63,12 @@ func (cache *bucketCache) loadAllBucketNodesFromDB() {
 	cache.lock.Lock()
 	defer cache.lock.Unlock()
 	for ; itr.Valid(); itr.Next() {
-		key := itr.Key().Data()
+		key := itr.Key()
 		if key[0] != byte(0) {
-			itr.Key().Free()
-			itr.Value().Free()
 			break
 		}
-		bKey := decodeBucketKey(statemgmt.Copy(itr.Key().Data()))
-		nodeBytes := statemgmt.Copy(itr.Value().Data())
+		bKey := decodeBucketKey(statemgmt.Copy(itr.Key()))
+		nodeBytes := statemgmt.Copy(itr.Value())
 		bucketNode := unmarshalBucketNode(&bKey, nodeBytes)
 		size := bKey.size() + bucketNode.size()
 		cache.size += size
@@ -79,8 +77,6 @@ func (cache *bucketCache) loadAllBucketNodesFromDB() {
 			break
 		}
 		cache.c[bKey] = bucketNode
-		itr.Key().Free()
-		itr.Value().Free()
 		count++
 	}
 	logger.Infof("Loaded buckets data in cache. Total buckets in DB = [%d]. Total cache size:=%d", count, cache.size)
@@ -68,8 +68,8 @@ func fetchDataNodesFromDBFor(bucketKey *bucketKey) (dataNodes, error) {
 
 		// making a copy of key-value bytes because, underlying key bytes are reused by itr.
 		// no need to free slices as iterator frees memory when closed.
-		keyBytes := statemgmt.Copy(itr.Key().Data())
-		valueBytes := statemgmt.Copy(itr.Value().Data())
+		keyBytes := statemgmt.Copy(itr.Key())
+		valueBytes := statemgmt.Copy(itr.Value())
 
 		dataKey := newDataKeyFromEncodedBytes(keyBytes)
 		logger.Debugf("Retrieved data key [%s] from DB for bucket [%s]", dataKey, bucketKey)
@@ -17,21 +17,48 @@ limitations under the License.
 package buckettree
 
 import (
+	"container/heap"
+
 	"github.com/hyperledger/fabric/core/db"
 	"github.com/hyperledger/fabric/core/ledger/statemgmt"
-	"github.com/tecbot/gorocksdb"
 )
 
-// RangeScanIterator implements the interface 'statemgmt.RangeScanIterator'
+// RangeScanIterator implements the interface 'statemgmt.RangeScanIterator'.
+// The keys are spread over the buckets by their hash, so the iterator keeps
+// the next key in range of each bucket, and serves the smallest of them to
+// return the keys in lexical order without reading the whole range.
 type RangeScanIterator struct {
-	dbItr               *gorocksdb.Iterator
-	chaincodeID         string
-	startKey            string
-	endKey              string
-	currentBucketNumber int
-	currentKey          string
-	currentValue        []byte
-	done                bool
+	dbItr        db.Iterator
+	chaincodeID  string
+	endKey       string
+	buckets      bucketCursors
+	currentKey   string
+	currentValue []byte
+}
+
+// bucketCursor is the next key in range of a bucket
+type bucketCursor struct {
+	bucketNumber int
+	key          string
+	value        []byte
+}
+
+// bucketCursors is a heap of the buckets ordered by their next key
+type bucketCursors []*bucketCursor
+
+func (cursors bucketCursors) Len() int           { return len(cursors) }
+func (cursors bucketCursors) Less(i, j int) bool { return cursors[i].key < cursors[j].key }
+func (cursors bucketCursors) Swap(i, j int)      { cursors[i], cursors[j] = cursors[j], cursors[i] }
+
+func (cursors *bucketCursors) Push(cursor interface{}) {
+	*cursors = append(*cursors, cursor.(*bucketCursor))
+}
+
+func (cursors *bucketCursors) Pop() interface{} {
+	old := *cursors
+	cursor := old[len(old)-1]
+	*cursors = old[:len(old)-1]
+	return cursor
 }
 
 func newRangeScanIterator(chaincodeID string, startKey string, endKey string) (*RangeScanIterator, error) {
@@ -39,57 +66,66 @@ func newRangeScanIterator(chaincodeID string, startKey string, endKey string) (*
 	itr := &RangeScanIterator{
 		dbItr:       dbItr,
 		chaincodeID: chaincodeID,
-		startKey:    startKey,
 		endKey:      endKey,
 	}
-	itr.seekForStartKeyWithinBucket(1)
+	// visit only the buckets which hold data
+	dbItr.Seek(encodeBucketNumber(1))
+	for dbItr.Valid() {
+		bucketNumber, _ := decodeBucketNumber(dbItr.Key())
+		if bucketNumber > conf.getNumBucketsAtLowestLevel() {
+			break
+		}
+		if cursor := itr.seekWithinBucket(bucketNumber, startKey); cursor != nil {
+			itr.buckets = append(itr.buckets, cursor)
+		}
+		dbItr.Seek(encodeBucketNumber(bucketNumber + 1))
+	}
+	heap.Init(&itr.buckets)
 	return itr, nil
 }
 
 // Next - see interface 'statemgmt.RangeScanIterator' for details
 func (itr *RangeScanIterator) Next() bool {
-	if itr.done {
+	if len(itr.buckets) == 0 {
 		return false
 	}
-
-	for itr.dbItr.Valid() {
-
-		// making a copy of key-value bytes because, underlying key bytes are reused by itr.
-		// no need to free slices as iterator frees memory when closed.
-		keyBytes := statemgmt.Copy(itr.dbItr.Key().Data())
-		valueBytes := statemgmt.Copy(itr.dbItr.Value().Data())
-
-		dataNode := unmarshalDataNodeFromBytes(keyBytes, valueBytes)
-		dataKey := dataNode.dataKey
-		chaincodeID, key := statemgmt.DecodeCompositeKey(dataNode.getCompositeKey())
-		value := dataNode.value
-		logger.Debugf("Evaluating data-key = %s", dataKey)
-
-		bucketNumber := dataKey.bucketKey.bucketNumber
-		if bucketNumber > itr.currentBucketNumber {
-			itr.seekForStartKeyWithinBucket(bucketNumber)
-			continue
-		}
-
-		if chaincodeID == itr.chaincodeID && (itr.endKey == "" || key <= itr.endKey) {
-			logger.Debugf("including data-key = %s", dataKey)
-			itr.currentKey = key
-			itr.currentValue = value
-			itr.dbItr.Next()
-			return true
-		}
-
-		itr.seekForStartKeyWithinBucket(bucketNumber + 1)
-		continue
+	cursor := itr.buckets[0]
+	itr.currentKey = cursor.key
+	itr.currentValue = cursor.value
+	logger.Debugf("including key = %s from bucket %d", cursor.key, cursor.bucketNumber)
+
+	// the smallest key greater than the current one
+	if next := itr.seekWithinBucket(cursor.bucketNumber, cursor.key+"\x00"); next != nil {
+		itr.buckets[0] = next
+		heap.Fix(&itr.buckets, 0)
+	} else {
+		heap.Pop(&itr.buckets)
 	}
-	itr.done = true
-	return false
+	return true
 }
 
-func (itr *RangeScanIterator) seekForStartKeyWithinBucket(bucketNumber int) {
-	itr.currentBucketNumber = bucketNumber
-	datakeyBytes := minimumPossibleDataKeyBytes(bucketNumber, itr.chaincodeID, itr.startKey)
-	itr.dbItr.Seek(datakeyBytes)
+// seekWithinBucket returns the first key in range of the bucket from
+// fromKey, or nil if there is none
+func (itr *RangeScanIterator) seekWithinBucket(bucketNumber int, fromKey string) *bucketCursor {
+	itr.dbItr.Seek(minimumPossibleDataKeyBytes(bucketNumber, itr.chaincodeID, fromKey))
+	if !itr.dbItr.Valid() {
+		return nil
+	}
+	// making a copy of key-value bytes because, underlying key bytes are reused by itr.
+	// no need to free slices as iterator frees memory when closed.
+	keyBytes := statemgmt.Copy(itr.dbItr.Key())
+	valueBytes := statemgmt.Copy(itr.dbItr.Value())
+
+	dataNode := unmarshalDataNodeFromBytes(keyBytes, valueBytes)
+	logger.Debugf("Evaluating data-key = %s", dataNode.dataKey)
+	if dataNode.dataKey.bucketKey.bucketNumber != bucketNumber {
+		return nil
+	}
+	chaincodeID, key := statemgmt.DecodeCompositeKey(dataNode.getCompositeKey())
+	if chaincodeID != itr.chaincodeID || (itr.endKey != "" && key > itr.endKey) {
+		return nil
+	}
+	return &bucketCursor{bucketNumber, key, dataNode.value}
 }
 
 // GetKeyValue - see interface 'statemgmt.RangeScanIterator' for details
@@ -17,6 +17,7 @@ limitations under the License.
 package buckettree
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/hyperledger/fabric/core/ledger/statemgmt"
@@ -147,3 +148,28 @@ func TestRangeScanIteratorEmptyArray(t *testing.T) {
 	testutil.AssertEquals(t, results["key3"], []byte{})
 	rangeScanItr.Close()
 }
+
+func TestRangeScanIteratorOrder(t *testing.T) {
+	testDBWrapper.CleanDB(t)
+	stateImplTestWrapper := newStateImplTestWrapper(t)
+	stateDelta := statemgmt.NewStateDelta()
+
+	// the keys hash to buckets in no particular order
+	for i := 0; i < 200; i++ {
+		stateDelta.Set("chaincodeID1", fmt.Sprintf("key%03d", i), []byte(fmt.Sprintf("value%03d", i)), nil)
+		stateDelta.Set("chaincodeID2", fmt.Sprintf("key%03d", i), []byte("other"), nil)
+	}
+	stateImplTestWrapper.prepareWorkingSet(stateDelta)
+	stateImplTestWrapper.persistChangesAndResetInMemoryChanges()
+
+	rangeScanItr := stateImplTestWrapper.getRangeScanIterator("chaincodeID1", "key050", "key149")
+	i := 50
+	for rangeScanItr.Next() {
+		key, value := rangeScanItr.GetKeyValue()
+		testutil.AssertEquals(t, key, fmt.Sprintf("key%03d", i))
+		testutil.AssertEquals(t, value, []byte(fmt.Sprintf("value%03d", i)))
+		i++
+	}
+	testutil.AssertEquals(t, i, 150)
+	rangeScanItr.Close()
+}
@@ -19,15 +19,14 @@ package buckettree
 import (
 	"github.com/hyperledger/fabric/core/db"
 	"github.com/hyperledger/fabric/core/ledger/statemgmt"
-	"github.com/tecbot/gorocksdb"
 )
 
 // StateSnapshotIterator implements the interface 'statemgmt.StateSnapshotIterator'
 type StateSnapshotIterator struct {
-	dbItr *gorocksdb.Iterator
+	dbItr db.Iterator
 }
 
-func newStateSnapshotIterator(snapshot *gorocksdb.Snapshot) (*StateSnapshotIterator, error) {
+func newStateSnapshotIterator(snapshot db.Snapshot) (*StateSnapshotIterator, error) {
 	dbItr := db.GetDBHandle().GetStateCFSnapshotIterator(snapshot)
 	dbItr.Seek([]byte{0x01})
 	dbItr.Prev()
@@ -45,8 +44,8 @@ func (snapshotItr *StateSnapshotIterator) GetRawKeyValue() ([]byte, []byte) {
 
 	// making a copy of key-value bytes because, underlying key bytes are reused by itr.
 	// no need to free slices as iterator frees memory when closed.
-	keyBytes := statemgmt.Copy(snapshotItr.dbItr.Key().Data())
-	valueBytes := statemgmt.Copy(snapshotItr.dbItr.Value().Data())
+	keyBytes := statemgmt.Copy(snapshotItr.dbItr.Key())
+	valueBytes := statemgmt.Copy(snapshotItr.dbItr.Value())
 	dataNode := unmarshalDataNodeFromBytes(keyBytes, valueBytes)
 	return dataNode.getCompositeKey(), dataNode.getValue()
 }
@@ -0,0 +1,140 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buckettree
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+)
+
+// verifyBucketTree recomputes the bucket tree from the data nodes in the
+// snapshot, and compares the children crypto-hashes of the recomputed bucket
+// nodes with those of the bucket nodes in the snapshot, from the lowest level
+// up. It returns the crypto-hash of the recomputed root bucket and a
+// description of the first bucket whose crypto-hash differs.
+func verifyBucketTree(snapshot db.Snapshot) ([]byte, string, error) {
+	itr := db.GetDBHandle().GetStateCFSnapshotIterator(snapshot)
+	defer itr.Close()
+
+	computedNodes := computeBucketNodes(itr)
+	persistedNodes := make(map[bucketKey]*bucketNode)
+	for itr.SeekToFirst(); itr.Valid() && itr.Key()[0] == 0; itr.Next() {
+		bucketKey := decodeBucketKey(statemgmt.Copy(itr.Key()))
+		persistedNodes[bucketKey] = unmarshalBucketNode(&bucketKey, statemgmt.Copy(itr.Value()))
+	}
+
+	divergence := ""
+	for level := conf.getLowestLevel() - 1; level >= 0 && divergence == ""; level-- {
+		divergence = compareBucketNodesAt(level, computedNodes, persistedNodes)
+	}
+
+	var cryptoHash []byte
+	if rootNode := computedNodes[*constructRootBucketKey()]; rootNode != nil {
+		cryptoHash = rootNode.computeCryptoHash()
+	}
+	logger.Debugf("Recomputed crypto-hash of the bucket tree [%x], divergence=[%s]", cryptoHash, divergence)
+	return cryptoHash, divergence, nil
+}
+
+// computeBucketNodes computes the bucket nodes above the lowest level from the
+// data nodes, which the iterator gives in the order of their buckets
+func computeBucketNodes(itr db.Iterator) map[bucketKey]*bucketNode {
+	bucketNodes := make(map[bucketKey]*bucketNode)
+	setChildCryptoHash := func(childKey *bucketKey, cryptoHash []byte) {
+		parentKey := childKey.getParentKey()
+		parentNode := bucketNodes[*parentKey]
+		if parentNode == nil {
+			parentNode = newBucketNode(parentKey)
+			bucketNodes[*parentKey] = parentNode
+		}
+		parentNode.setChildCryptoHash(childKey, cryptoHash)
+	}
+
+	var bucketHashCalculator *bucketHashCalculator
+	for itr.Seek([]byte{0x01}); itr.Valid(); itr.Next() {
+		dataNode := unmarshalDataNodeFromBytes(statemgmt.Copy(itr.Key()), statemgmt.Copy(itr.Value()))
+		if dataNode.isDelete() {
+			continue
+		}
+		bucketKey := dataNode.dataKey.getBucketKey()
+		if bucketHashCalculator == nil || !bucketHashCalculator.bucketKey.equals(bucketKey) {
+			if bucketHashCalculator != nil {
+				setChildCryptoHash(bucketHashCalculator.bucketKey, bucketHashCalculator.computeCryptoHash())
+			}
+			bucketHashCalculator = newBucketHashCalculator(bucketKey)
+		}
+		bucketHashCalculator.addNextNode(dataNode)
+	}
+	if bucketHashCalculator != nil {
+		setChildCryptoHash(bucketHashCalculator.bucketKey, bucketHashCalculator.computeCryptoHash())
+	}
+
+	for level := conf.getLowestLevel() - 1; level > 0; level-- {
+		var levelNodes []*bucketNode
+		for bucketKey, bucketNode := range bucketNodes {
+			if bucketKey.level == level {
+				levelNodes = append(levelNodes, bucketNode)
+			}
+		}
+		for _, bucketNode := range levelNodes {
+			setChildCryptoHash(bucketNode.bucketKey, bucketNode.computeCryptoHash())
+		}
+	}
+	return bucketNodes
+}
+
+// compareBucketNodesAt compares the children crypto-hashes of the computed and
+// persisted bucket nodes at the level, and describes the first child bucket
+// whose crypto-hash differs
+func compareBucketNodesAt(level int, computedNodes map[bucketKey]*bucketNode, persistedNodes map[bucketKey]*bucketNode) string {
+	levelBuckets := make(map[int]bool)
+	for _, bucketNodes := range []map[bucketKey]*bucketNode{computedNodes, persistedNodes} {
+		for bucketKey := range bucketNodes {
+			if bucketKey.level == level {
+				levelBuckets[bucketKey.bucketNumber] = true
+			}
+		}
+	}
+	var bucketNumbers []int
+	for bucketNumber := range levelBuckets {
+		bucketNumbers = append(bucketNumbers, bucketNumber)
+	}
+	sort.Ints(bucketNumbers)
+
+	for _, bucketNumber := range bucketNumbers {
+		bucketKey := newBucketKey(level, bucketNumber)
+		computedNode := computedNodes[*bucketKey]
+		if computedNode == nil {
+			computedNode = newBucketNode(bucketKey)
+		}
+		persistedNode := persistedNodes[*bucketKey]
+		if persistedNode == nil {
+			persistedNode = newBucketNode(bucketKey)
+		}
+		for i := range computedNode.childrenCryptoHash {
+			if !bytes.Equal(computedNode.childrenCryptoHash[i], persistedNode.childrenCryptoHash[i]) {
+				return fmt.Sprintf("Bucket [%s] has crypto-hash [%x] in the DB, but [%x] recomputed from the key-values",
+					bucketKey.getChildKey(i), persistedNode.childrenCryptoHash[i], computedNode.childrenCryptoHash[i])
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buckettree
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+)
+
+func TestStateImpl_VerifyCryptoHash(t *testing.T) {
+	// number of buckets at each level 26,9,3,1
+	testHasher, stateImplTestWrapper, stateDelta := createFreshDBAndInitTestStateImplWithCustomHasher(t, 26, 3)
+	testHasher.populate("chaincodeID1", "key1", 0)
+	testHasher.populate("chaincodeID1", "key2", 0)
+	testHasher.populate("chaincodeID2", "key1", 1)
+	testHasher.populate("chaincodeID3", "key1", 12)
+	testHasher.populate("chaincodeID4", "key1", 25)
+
+	cryptoHash, divergence, err := stateImplTestWrapper.stateImpl.VerifyCryptoHash(db.GetDBHandle().GetSnapshot())
+	testutil.AssertNoError(t, err, "Error verifying the crypto-hash of an empty state")
+	testutil.AssertNil(t, cryptoHash)
+	testutil.AssertEquals(t, divergence, "")
+
+	stateDelta.Set("chaincodeID1", "key1", []byte("value1"), nil)
+	stateDelta.Set("chaincodeID1", "key2", []byte("value2"), nil)
+	stateDelta.Set("chaincodeID2", "key1", []byte("value3"), nil)
+	stateDelta.Set("chaincodeID3", "key1", []byte("value4"), nil)
+	stateImplTestWrapper.prepareWorkingSetAndComputeCryptoHash(stateDelta)
+	stateImplTestWrapper.persistChangesAndResetInMemoryChanges()
+	stateDelta = statemgmt.NewStateDelta()
+	stateDelta.Delete("chaincodeID2", "key1", nil)
+	stateDelta.Set("chaincodeID4", "key1", []byte("value5"), nil)
+	expectedHash := stateImplTestWrapper.prepareWorkingSetAndComputeCryptoHash(stateDelta)
+	stateImplTestWrapper.persistChangesAndResetInMemoryChanges()
+
+	cryptoHash, divergence, err = stateImplTestWrapper.stateImpl.VerifyCryptoHash(db.GetDBHandle().GetSnapshot())
+	testutil.AssertNoError(t, err, "Error verifying the crypto-hash")
+	testutil.AssertEquals(t, cryptoHash, expectedHash)
+	testutil.AssertEquals(t, divergence, "")
+
+	// change a value behind the back of the bucket tree
+	openchainDB := db.GetDBHandle()
+	err = openchainDB.Put(openchainDB.StateCF, newDataKey("chaincodeID3", "key1").getEncodedBytes(), []byte("tampered"))
+	testutil.AssertNoError(t, err, "Error writing to the DB")
+	cryptoHash, divergence, err = stateImplTestWrapper.stateImpl.VerifyCryptoHash(db.GetDBHandle().GetSnapshot())
+	testutil.AssertNoError(t, err, "Error verifying the crypto-hash")
+	testutil.AssertNotEquals(t, cryptoHash, expectedHash)
+	if !strings.Contains(divergence, "Bucket [level=[3], bucketNumber=[13]]") {
+		t.Fatalf("Expected the divergence to be in the lowest-level bucket 13, found [%s]", divergence)
+	}
+}
@@ -26,6 +26,11 @@ var logger = logging.MustGetLogger("statemgmt")
 
 var stateKeyDelimiter = []byte{0x00}
 
+// MaxRecordLength bounds the length-prefixed records read from the files of
+// the ledger, so that a corrupted or crafted length cannot make the peer
+// allocate an arbitrary amount of memory
+const MaxRecordLength = 256 * 1024 * 1024
+
 // ConstructCompositeKey returns a []byte that uniquely represents a given chaincodeID and key.
 // This assumes that chaincodeID does not contain a 0x00 byte, but the key may
 // TODO:enforce this restriction on chaincodeID or use length prefixing here instead of delimiter
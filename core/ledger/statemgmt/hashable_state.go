@@ -17,7 +17,7 @@ limitations under the License.
 package statemgmt
 
 import (
-	"github.com/tecbot/gorocksdb"
+	"github.com/hyperledger/fabric/core/db"
 )
 
 // HashableState - Interface that is be implemented by state management
@@ -42,7 +42,7 @@ type HashableState interface {
 	// to persist for committing the  stateDelta (passed in PrepareWorkingSet method) to DB.
 	// In addition to the information in the StateDelta, the implementation may also want to
 	// persist intermediate results for faster crypto-hash computation
-	AddChangesForPersistence(writeBatch *gorocksdb.WriteBatch) error
+	AddChangesForPersistence(writeBatch *db.WriteBatch) error
 
 	// ClearWorkingSet state implementation may clear any data structures that it may have constructed
 	// for computing cryptoHash and persisting the changes for the stateDelta (passed in PrepareWorkingSet method)
@@ -52,13 +52,21 @@ type HashableState interface {
 	// All the key-value of global state. A particular implementation may need to remove additional information
 	// that the implementation keeps for faster crypto-hash computation. For instance, filter a few of the
 	// key-values or remove some data from particular key-values.
-	GetStateSnapshotIterator(snapshot *gorocksdb.Snapshot) (StateSnapshotIterator, error)
+	GetStateSnapshotIterator(snapshot db.Snapshot) (StateSnapshotIterator, error)
+
+	// VerifyCryptoHash state implementation to recompute the crypto-hash of the state in the snapshot
+	// from the key-values alone, without using or changing the data structures it keeps in memory.
+	// The implementation also compares the intermediate results it has persisted for faster crypto-hash
+	// computation with the recomputed ones, and describes the first one that differs in divergence,
+	// looking at the lowest ones first. divergence is empty when they all match.
+	VerifyCryptoHash(snapshot db.Snapshot) (cryptoHash []byte, divergence string, err error)
 
 	// GetRangeScanIterator - state implementation to provide an iterator that is supposed to give
 	// All the key-values for a given chaincodeID such that a return key should be lexically greater than or
 	// equal to startKey and less than or equal to endKey. If the value for startKey parameter is an empty string
 	// startKey is assumed to be the smallest key available in the db for the chaincodeID. Similarly, an empty string
-	// for endKey parameter assumes the endKey to be the greatest key available in the db for the chaincodeID
+	// for endKey parameter assumes the endKey to be the greatest key available in the db for the chaincodeID.
+	// The keys are returned in lexical order.
 	GetRangeScanIterator(chaincodeID string, startKey string, endKey string) (RangeScanIterator, error)
 
 	// PerfHintKeyChanged state implementation may be provided with some hints before (e.g., during tx execution)
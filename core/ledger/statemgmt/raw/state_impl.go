@@ -19,7 +19,6 @@ package raw
 import (
 	"github.com/hyperledger/fabric/core/db"
 	"github.com/hyperledger/fabric/core/ledger/statemgmt"
-	"github.com/tecbot/gorocksdb"
 )
 
 // StateImpl implements raw state management. This implementation does not support computation of crypto-hash of the state.
@@ -62,7 +61,7 @@ func (impl *StateImpl) ComputeCryptoHash() ([]byte, error) {
 }
 
 // AddChangesForPersistence - method implementation for interface 'statemgmt.HashableState'
-func (impl *StateImpl) AddChangesForPersistence(writeBatch *gorocksdb.WriteBatch) error {
+func (impl *StateImpl) AddChangesForPersistence(writeBatch *db.WriteBatch) error {
 	delta := impl.stateDelta
 	if delta == nil {
 		return nil
@@ -88,11 +87,44 @@ func (impl *StateImpl) PerfHintKeyChanged(chaincodeID string, key string) {
 }
 
 // GetStateSnapshotIterator - method implementation for interface 'statemgmt.HashableState'
-func (impl *StateImpl) GetStateSnapshotIterator(snapshot *gorocksdb.Snapshot) (statemgmt.StateSnapshotIterator, error) {
-	panic("Not a full-fledged state implementation. Implemented only for measuring best-case performance benchmark")
+func (impl *StateImpl) GetStateSnapshotIterator(snapshot db.Snapshot) (statemgmt.StateSnapshotIterator, error) {
+	dbItr := db.GetDBHandle().GetStateCFSnapshotIterator(snapshot)
+	dbItr.SeekToFirst()
+	return &stateSnapshotIterator{dbItr, false}, nil
+}
+
+// VerifyCryptoHash - method implementation for interface 'statemgmt.HashableState'
+func (impl *StateImpl) VerifyCryptoHash(snapshot db.Snapshot) ([]byte, string, error) {
+	// the raw state has no crypto-hash, nor intermediate results to compare
+	return nil, "", nil
 }
 
 // GetRangeScanIterator - method implementation for interface 'statemgmt.HashableState'
 func (impl *StateImpl) GetRangeScanIterator(chaincodeID string, startKey string, endKey string) (statemgmt.RangeScanIterator, error) {
 	panic("Not a full-fledged state implementation. Implemented only for measuring best-case performance benchmark")
 }
+
+// stateSnapshotIterator gives the composite keys and values as they are stored
+type stateSnapshotIterator struct {
+	dbItr   db.Iterator
+	started bool
+}
+
+// Next - see interface 'statemgmt.StateSnapshotIterator' for details
+func (snapshotItr *stateSnapshotIterator) Next() bool {
+	if snapshotItr.started {
+		snapshotItr.dbItr.Next()
+	}
+	snapshotItr.started = true
+	return snapshotItr.dbItr.Valid()
+}
+
+// GetRawKeyValue - see interface 'statemgmt.StateSnapshotIterator' for details
+func (snapshotItr *stateSnapshotIterator) GetRawKeyValue() ([]byte, []byte) {
+	return statemgmt.Copy(snapshotItr.dbItr.Key()), statemgmt.Copy(snapshotItr.dbItr.Value())
+}
+
+// Close - see interface 'statemgmt.StateSnapshotIterator' for details
+func (snapshotItr *stateSnapshotIterator) Close() {
+	snapshotItr.dbItr.Close()
+}
@@ -23,65 +23,89 @@ import (
 // CompositeRangeScanIterator - an implementation of interface 'statemgmt.RangeScanIterator'
 // This provides a wrapper on top of more than one underlying iterators
 type CompositeRangeScanIterator struct {
-	itrs             []statemgmt.RangeScanIterator
-	currentItrNumber int
+	itrs []statemgmt.RangeScanIterator
+	// whether each underlying iterator has a current key-value, which has
+	// not been served yet
+	hasNext    []bool
+	currentItr int
 }
 
 func newCompositeRangeScanIterator(
 	txDeltaItr *statemgmt.StateDeltaIterator,
 	batchDeltaItr *statemgmt.StateDeltaIterator,
 	implItr statemgmt.RangeScanIterator) statemgmt.RangeScanIterator {
-	itrs := make([]statemgmt.RangeScanIterator, 3)
-	itrs[0] = txDeltaItr
-	itrs[1] = batchDeltaItr
-	itrs[2] = implItr
-	return &CompositeRangeScanIterator{itrs, 0}
+	itrs := []statemgmt.RangeScanIterator{txDeltaItr, batchDeltaItr, implItr}
+	hasNext := make([]bool, len(itrs))
+	for i, itr := range itrs {
+		hasNext[i] = itr.Next()
+	}
+	return &CompositeRangeScanIterator{itrs, hasNext, -1}
 }
 
 // Next - see interface 'statemgmt.RangeScanIterator' for details
-// The specific implementation below starts from first underlying iterator and
-// after exhausting the first underlying iterator, move to the second underlying iterator.
-// The implementation repeats this until last underlying iterator has been exhausted
-// In addition, the key-value from an underlying iterator are skipped if the key is found
-// in any of the preceding iterators
+// All the underlying iterators return their keys in lexical order, so the
+// implementation below merges them, serving the smallest of their current keys
+// and moving past it each iterator which has it. For a key in more than one
+// iterator, the key-value of the first one is served. In addition, the
+// key-value from an underlying iterator are skipped if the key is found in any
+// of the preceding iterators, which have deleted it.
 func (itr *CompositeRangeScanIterator) Next() bool {
-	currentItrNumber := itr.currentItrNumber
-	currentItr := itr.itrs[currentItrNumber]
-	logger.Debugf("Operating on iterator number = %d", currentItrNumber)
-	keyAvailable := currentItr.Next()
-	for keyAvailable {
-		key, _ := currentItr.GetKeyValue()
-		logger.Debugf("Retrieved key = %s", key)
-		skipKey := false
-		for i := currentItrNumber - 1; i >= 0; i-- {
-			logger.Debugf("Evaluating key = %s in itr number = %d. currentItrNumber = %d", key, i, currentItrNumber)
-			previousItr := itr.itrs[i]
-			if previousItr.(*statemgmt.StateDeltaIterator).ContainsKey(key) {
-				skipKey = true
-				break
+	if itr.currentItr >= 0 {
+		itr.advancePast(itr.currentKey())
+	}
+	for {
+		itr.currentItr = -1
+		var key string
+		for i := range itr.itrs {
+			if !itr.hasNext[i] {
+				continue
+			}
+			if k, _ := itr.itrs[i].GetKeyValue(); itr.currentItr < 0 || k < key {
+				itr.currentItr, key = i, k
 			}
 		}
-		if skipKey {
-			logger.Debugf("Skipping key = %s", key)
-			keyAvailable = currentItr.Next()
-			continue
+		if itr.currentItr < 0 {
+			return false
 		}
-		break
+		if !itr.deletedBefore(itr.currentItr, key) {
+			return true
+		}
+		logger.Debugf("Skipping key = %s", key)
+		itr.advancePast(key)
 	}
+}
+
+func (itr *CompositeRangeScanIterator) currentKey() string {
+	key, _ := itr.itrs[itr.currentItr].GetKeyValue()
+	return key
+}
 
-	if keyAvailable || currentItrNumber == 2 {
-		logger.Debug("Returning for current key")
-		return keyAvailable
+// advancePast moves each underlying iterator at key to its next key-value
+func (itr *CompositeRangeScanIterator) advancePast(key string) {
+	for i := range itr.itrs {
+		if !itr.hasNext[i] {
+			continue
+		}
+		if k, _ := itr.itrs[i].GetKeyValue(); k == key {
+			itr.hasNext[i] = itr.itrs[i].Next()
+		}
 	}
+}
 
-	logger.Debug("Moving to next iterator")
-	itr.currentItrNumber++
-	return itr.Next()
+// deletedBefore returns true if an iterator preceding itrNumber has the key
+// without returning it, as it is deleted there
+func (itr *CompositeRangeScanIterator) deletedBefore(itrNumber int, key string) bool {
+	for i := itrNumber - 1; i >= 0; i-- {
+		if itr.itrs[i].(*statemgmt.StateDeltaIterator).ContainsKey(key) {
+			return true
+		}
+	}
+	return false
 }
 
 // GetKeyValue - see interface 'statemgmt.RangeScanIterator' for details
 func (itr *CompositeRangeScanIterator) GetKeyValue() (string, []byte) {
-	return itr.itrs[itr.currentItrNumber].GetKeyValue()
+	return itr.itrs[itr.currentItr].GetKeyValue()
 }
 
 // Close - see interface 'statemgmt.RangeScanIterator' for details
@@ -17,9 +17,12 @@ limitations under the License.
 package state
 
 import (
+	"fmt"
+	"sort"
 	"testing"
 
 	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
 )
 
 func TestCompositeRangeScanIterator(t *testing.T) {
@@ -158,3 +161,58 @@ func TestCompositeRangeScanIterator(t *testing.T) {
 		})
 	itr.Close()
 }
+
+func TestCompositeRangeScanIteratorOrder(t *testing.T) {
+	stateTestWrapper, state := createFreshDBAndConstructState(t)
+
+	state.TxBegin("txUuid")
+	for i := 0; i < 50; i += 2 {
+		state.Set("chaincode1", fmt.Sprintf("key%02d", i), []byte("committed"))
+	}
+	state.TxFinish("txUuid", true)
+	stateTestWrapper.persistAndClearInMemoryChanges(0)
+
+	// odd keys in the batch and the on-going tx, overwriting and deleting
+	// some committed ones
+	state.TxBegin("txUuid")
+	for i := 1; i < 50; i += 4 {
+		state.Set("chaincode1", fmt.Sprintf("key%02d", i), []byte("batch"))
+	}
+	state.Delete("chaincode1", "key10")
+	state.TxFinish("txUuid", true)
+	state.TxBegin("txUuid")
+	for i := 3; i < 50; i += 4 {
+		state.Set("chaincode1", fmt.Sprintf("key%02d", i), []byte("tx"))
+	}
+	state.Set("chaincode1", "key20", []byte("tx"))
+	state.Delete("chaincode1", "key21")
+	state.Delete("chaincode1", "key30")
+
+	var expectedKeys []string
+	for i := 5; i <= 40; i++ {
+		if i != 10 && i != 21 && i != 30 {
+			expectedKeys = append(expectedKeys, fmt.Sprintf("key%02d", i))
+		}
+	}
+	itr, _ := state.GetRangeScanIterator("chaincode1", "key05", "key40", false)
+	var keys []string
+	for itr.Next() {
+		key, value := itr.GetKeyValue()
+		if key == "key20" {
+			testutil.AssertEquals(t, value, []byte("tx"))
+		}
+		keys = append(keys, key)
+	}
+	itr.Close()
+	testutil.AssertEquals(t, keys, expectedKeys)
+
+	itr, _ = state.GetRangeScanIterator("chaincode1", "", "", true)
+	keys = nil
+	for itr.Next() {
+		key, _ := itr.GetKeyValue()
+		keys = append(keys, key)
+	}
+	itr.Close()
+	testutil.AssertEquals(t, len(keys), 25)
+	testutil.AssertEquals(t, sort.StringsAreSorted(keys), true)
+}
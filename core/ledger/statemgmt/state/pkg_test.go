@@ -23,7 +23,6 @@ import (
 	"github.com/hyperledger/fabric/core/db"
 	"github.com/hyperledger/fabric/core/ledger/statemgmt"
 	"github.com/hyperledger/fabric/core/ledger/testutil"
-	"github.com/tecbot/gorocksdb"
 )
 
 var testDBWrapper = db.NewTestDBWrapper()
@@ -62,9 +61,9 @@ func (testWrapper *stateTestWrapper) getSnapshot() *StateSnapshot {
 }
 
 func (testWrapper *stateTestWrapper) persistAndClearInMemoryChanges(blockNumber uint64) {
-	writeBatch := gorocksdb.NewWriteBatch()
-	defer writeBatch.Destroy()
-	testWrapper.state.AddChangesForPersistence(blockNumber, writeBatch)
+	writeBatch := db.NewWriteBatch()
+	err := testWrapper.state.AddChangesForPersistence(blockNumber, writeBatch)
+	testutil.AssertNoError(testWrapper.t, err, "Error while adding changes for persistence")
 	testDBWrapper.WriteToDB(testWrapper.t, writeBatch)
 	testWrapper.state.ClearInMemoryChanges(true)
 }
@@ -0,0 +1,273 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim/richquery"
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+)
+
+// Rich query index entries share the indexes column family with the blockchain indexes,
+// which use the key prefixes 1 to 3
+var prefixRichQueryIndexKey = byte(4)
+
+// Query returns an iterator over the key-values of chaincodeID selected by the given rich query,
+// in the order requested by the query. If committed is true, only the committed state is queried.
+// If committed is false, the in-memory changes take precedence over the committed state.
+// When the query constrains a field the chaincode has declared an index on, only the keys found in
+// that index (and the keys changed in memory) are looked up instead of scanning the whole chaincode state.
+func (state *State) Query(chaincodeID string, query string, committed bool) (statemgmt.RangeScanIterator, error) {
+	q, err := richquery.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	indexedFields, err := state.getIndexedFields(chaincodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*richquery.Result
+	if indexRange := q.IndexRange(indexedFields); indexRange != nil {
+		logger.Debugf("Querying state of chaincode [%s] using the index on [%s]", chaincodeID, indexRange.Field)
+		keys := state.getIndexedKeys(chaincodeID, indexRange)
+		if !committed {
+			keys = append(keys, state.getUncommittedKeys(chaincodeID)...)
+		}
+		sort.Strings(keys)
+		for i, key := range keys {
+			if i > 0 && keys[i-1] == key {
+				continue
+			}
+			value, err := state.Get(chaincodeID, key, committed)
+			if err != nil {
+				return nil, err
+			}
+			if result := q.Select(key, value); result != nil {
+				results = append(results, result)
+			}
+		}
+	} else {
+		itr, err := state.GetRangeScanIterator(chaincodeID, "", "", committed)
+		if err != nil {
+			return nil, err
+		}
+		defer itr.Close()
+		for itr.Next() {
+			key, value := itr.GetKeyValue()
+			if result := q.Select(key, value); result != nil {
+				results = append(results, result)
+			}
+		}
+	}
+	return &queryResultsIterator{q.Arrange(results), -1}, nil
+}
+
+// getIndexedFields returns the sorted fields of chaincodeID that have an index declared in the committed state
+func (state *State) getIndexedFields(chaincodeID string) ([]string, error) {
+	itr, err := state.stateImpl.GetRangeScanIterator(chaincodeID, richquery.IndexDeclarationPrefix, richquery.IndexDeclarationPrefix+"\xff")
+	if err != nil {
+		return nil, err
+	}
+	defer itr.Close()
+	var fields []string
+	for itr.Next() {
+		key, _ := itr.GetKeyValue()
+		if field, ok := richquery.IndexedField(key); ok {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+func (state *State) getIndexedKeys(chaincodeID string, indexRange *richquery.IndexRange) []string {
+	prefix := encodeRichQueryIndexPrefix(chaincodeID, indexRange.Field)
+	start := append(append([]byte{}, prefix...), indexRange.Start...)
+	end := append(append([]byte{}, prefix...), indexRange.End...)
+
+	itr := db.GetDBHandle().GetIterator(db.GetDBHandle().IndexesCF)
+	defer itr.Close()
+	var keys []string
+	for itr.Seek(start); itr.Valid(); itr.Next() {
+		if bytes.Compare(itr.Key(), end) >= 0 {
+			break
+		}
+		keys = append(keys, string(itr.Value()))
+	}
+	return keys
+}
+
+func (state *State) getUncommittedKeys(chaincodeID string) []string {
+	var keys []string
+	for _, delta := range []*statemgmt.StateDelta{state.stateDelta, state.currentTxStateDelta} {
+		for key := range delta.GetUpdates(chaincodeID) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// addRichQueryIndexChanges adds to writeBatch the changes to the rich query indexes caused by
+// committing stateDelta. An index declared in stateDelta is built from the committed state and an
+// index removed in stateDelta is dropped.
+func (state *State) addRichQueryIndexChanges(stateDelta *statemgmt.StateDelta, writeBatch *db.WriteBatch) error {
+	if stateImplName == rawType {
+		// The raw state implementation does not support range scans, hence no declared indexes can be found
+		return nil
+	}
+	cf := db.GetDBHandle().IndexesCF
+	for _, chaincodeID := range stateDelta.GetUpdatedChaincodeIds(true) {
+		updates := stateDelta.GetUpdates(chaincodeID)
+		indexedFields, err := state.getIndexedFields(chaincodeID)
+		if err != nil {
+			return err
+		}
+		declared := make(map[string]bool)
+		for _, field := range indexedFields {
+			declared[field] = true
+		}
+
+		for key, updatedValue := range updates {
+			field, ok := richquery.IndexedField(key)
+			if !ok {
+				continue
+			}
+			newValue := getNewValue(stateDelta, updatedValue)
+			if newValue != nil && !declared[field] {
+				logger.Debugf("Building rich query index on [%s] for chaincode [%s]", field, chaincodeID)
+				if err := state.buildRichQueryIndex(chaincodeID, field, updates, writeBatch); err != nil {
+					return err
+				}
+				declared[field] = true
+			} else if newValue == nil && declared[field] {
+				logger.Debugf("Dropping rich query index on [%s] for chaincode [%s]", field, chaincodeID)
+				deleteRichQueryIndexEntries(encodeRichQueryIndexPrefix(chaincodeID, field), writeBatch)
+				delete(declared, field)
+			}
+		}
+		if len(declared) == 0 {
+			continue
+		}
+
+		for key, updatedValue := range updates {
+			if richquery.IsReservedKey(key) {
+				continue
+			}
+			oldValue, err := state.stateImpl.Get(chaincodeID, key)
+			if err != nil {
+				return err
+			}
+			newValue := getNewValue(stateDelta, updatedValue)
+			for field := range declared {
+				if encoded, ok := richquery.IndexValue(field, oldValue); ok {
+					writeBatch.DeleteCF(cf, encodeRichQueryIndexKey(chaincodeID, field, encoded, key))
+				}
+				if encoded, ok := richquery.IndexValue(field, newValue); ok {
+					writeBatch.PutCF(cf, encodeRichQueryIndexKey(chaincodeID, field, encoded, key), []byte(key))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// buildRichQueryIndex adds entries for field for all the committed keys of chaincodeID, except the
+// keys in updates for which the entries are maintained as for any other update
+func (state *State) buildRichQueryIndex(chaincodeID string, field string, updates map[string]*statemgmt.UpdatedValue,
+	writeBatch *db.WriteBatch) error {
+	itr, err := state.stateImpl.GetRangeScanIterator(chaincodeID, "", "")
+	if err != nil {
+		return err
+	}
+	defer itr.Close()
+	cf := db.GetDBHandle().IndexesCF
+	for itr.Next() {
+		key, value := itr.GetKeyValue()
+		if _, ok := updates[key]; ok || richquery.IsReservedKey(key) {
+			continue
+		}
+		if encoded, ok := richquery.IndexValue(field, value); ok {
+			writeBatch.PutCF(cf, encodeRichQueryIndexKey(chaincodeID, field, encoded, key), []byte(key))
+		}
+	}
+	return nil
+}
+
+// deleteRichQueryIndexEntries adds to writeBatch the deletion of all the index entries starting with prefix
+func deleteRichQueryIndexEntries(prefix []byte, writeBatch *db.WriteBatch) {
+	cf := db.GetDBHandle().IndexesCF
+	itr := db.GetDBHandle().GetIterator(cf)
+	defer itr.Close()
+	for itr.Seek(prefix); itr.ValidForPrefix(prefix); itr.Next() {
+		writeBatch.DeleteCF(cf, itr.Key())
+	}
+}
+
+// deleteAllRichQueryIndexEntries deletes the entries of all the rich query indexes from the DB
+func deleteAllRichQueryIndexEntries() error {
+	writeBatch := db.NewWriteBatch()
+	deleteRichQueryIndexEntries([]byte{prefixRichQueryIndexKey}, writeBatch)
+	return db.GetDBHandle().Write(writeBatch)
+}
+
+func getNewValue(stateDelta *statemgmt.StateDelta, updatedValue *statemgmt.UpdatedValue) []byte {
+	if stateDelta.RollBackwards {
+		return updatedValue.GetPreviousValue()
+	}
+	return updatedValue.GetValue()
+}
+
+func encodeRichQueryIndexPrefix(chaincodeID string, field string) []byte {
+	prefix := []byte{prefixRichQueryIndexKey}
+	prefix = append(prefix, chaincodeID...)
+	prefix = append(prefix, 0)
+	prefix = append(prefix, field...)
+	return append(prefix, 0)
+}
+
+func encodeRichQueryIndexKey(chaincodeID string, field string, encodedValue []byte, key string) []byte {
+	indexKey := encodeRichQueryIndexPrefix(chaincodeID, field)
+	indexKey = append(indexKey, encodedValue...)
+	indexKey = append(indexKey, 0)
+	return append(indexKey, key...)
+}
+
+// queryResultsIterator iterates over the results of a rich query
+type queryResultsIterator struct {
+	results []*richquery.Result
+	current int
+}
+
+// Next - see interface 'statemgmt.RangeScanIterator' for details
+func (itr *queryResultsIterator) Next() bool {
+	itr.current++
+	return itr.current < len(itr.results)
+}
+
+// GetKeyValue - see interface 'statemgmt.RangeScanIterator' for details
+func (itr *queryResultsIterator) GetKeyValue() (string, []byte) {
+	result := itr.results[itr.current]
+	return result.Key, result.Value
+}
+
+// Close - see interface 'statemgmt.RangeScanIterator' for details
+func (itr *queryResultsIterator) Close() {
+}
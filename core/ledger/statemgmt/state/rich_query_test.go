@@ -0,0 +1,111 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim/richquery"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+)
+
+func (testWrapper *stateTestWrapper) query(chaincodeID string, query string, committed bool) []string {
+	itr, err := testWrapper.state.Query(chaincodeID, query, committed)
+	testutil.AssertNoError(testWrapper.t, err, "Error while querying state")
+	defer itr.Close()
+	keys := []string{}
+	for itr.Next() {
+		key, _ := itr.GetKeyValue()
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (testWrapper *stateTestWrapper) indexedKeys(chaincodeID string, field string) []string {
+	keys := testWrapper.state.getIndexedKeys(chaincodeID, &richquery.IndexRange{Field: field, Start: []byte{0}, End: []byte{0xff}})
+	if keys == nil {
+		keys = []string{}
+	}
+	return keys
+}
+
+func TestStateQuery(t *testing.T) {
+	stateTestWrapper, state := createFreshDBAndConstructState(t)
+	query := `{"selector":{"Integral":{"$gt":1000}},"sort":[{"Integral":"desc"}]}`
+
+	state.TxBegin("txUuid")
+	state.Set("chaincode1", "alice", []byte(`{"Integral":1500}`))
+	state.Set("chaincode1", "bob", []byte(`{"Integral":800}`))
+	state.Set("chaincode1", "carol", []byte(`{"Integral":2500}`))
+	state.Set("chaincode1", "dave", []byte(`not json`))
+	state.Set("chaincode2", "erin", []byte(`{"Integral":5000}`))
+	state.TxFinish("txUuid", true)
+	testutil.AssertEquals(t, stateTestWrapper.query("chaincode1", query, false), []string{"carol", "alice"})
+	testutil.AssertEquals(t, stateTestWrapper.query("chaincode1", query, true), []string{})
+	stateTestWrapper.persistAndClearInMemoryChanges(0)
+	testutil.AssertEquals(t, stateTestWrapper.query("chaincode1", query, true), []string{"carol", "alice"})
+
+	state.TxBegin("txUuid")
+	state.Set("chaincode1", "bob", []byte(`{"Integral":1200}`))
+	state.Delete("chaincode1", "carol")
+	state.TxFinish("txUuid", true)
+	testutil.AssertEquals(t, stateTestWrapper.query("chaincode1", query, false), []string{"alice", "bob"})
+	testutil.AssertEquals(t, stateTestWrapper.query("chaincode1", query, true), []string{"carol", "alice"})
+
+	_, err := state.Query("chaincode1", `{"selector":{"Integral":{"$regex":"1"}}}`, false)
+	testutil.AssertError(t, err, "Expected an error for an invalid query")
+}
+
+func TestStateQueryWithIndex(t *testing.T) {
+	stateTestWrapper, state := createFreshDBAndConstructState(t)
+	query := `{"selector":{"Integral":{"$gt":1000}}}`
+	declarationKey, _ := richquery.IndexDeclarationKey("Integral")
+
+	state.TxBegin("txUuid")
+	state.Set("chaincode1", "alice", []byte(`{"Integral":1500}`))
+	state.Set("chaincode1", "bob", []byte(`{"Integral":800}`))
+	state.TxFinish("txUuid", true)
+	stateTestWrapper.persistAndClearInMemoryChanges(0)
+	testutil.AssertEquals(t, stateTestWrapper.indexedKeys("chaincode1", "Integral"), []string{})
+
+	// declaring the index builds it from the committed state and the changes committed along with it
+	state.TxBegin("txUuid")
+	state.Set("chaincode1", declarationKey, []byte("Integral"))
+	state.Set("chaincode1", "carol", []byte(`{"Integral":2500}`))
+	state.TxFinish("txUuid", true)
+	stateTestWrapper.persistAndClearInMemoryChanges(1)
+	testutil.AssertEquals(t, stateTestWrapper.indexedKeys("chaincode1", "Integral"), []string{"bob", "alice", "carol"})
+	testutil.AssertEquals(t, stateTestWrapper.query("chaincode1", query, true), []string{"alice", "carol"})
+
+	// the index follows updates and deletions, and the queries see the uncommitted changes
+	state.TxBegin("txUuid")
+	state.Set("chaincode1", "bob", []byte(`{"Integral":3000}`))
+	state.Delete("chaincode1", "alice")
+	state.TxFinish("txUuid", true)
+	testutil.AssertEquals(t, stateTestWrapper.query("chaincode1", query, false), []string{"bob", "carol"})
+	stateTestWrapper.persistAndClearInMemoryChanges(2)
+	testutil.AssertEquals(t, stateTestWrapper.indexedKeys("chaincode1", "Integral"), []string{"carol", "bob"})
+	testutil.AssertEquals(t, stateTestWrapper.query("chaincode1", query, true), []string{"bob", "carol"})
+
+	// removing the declaration drops the index
+	state.TxBegin("txUuid")
+	state.Delete("chaincode1", declarationKey)
+	state.TxFinish("txUuid", true)
+	stateTestWrapper.persistAndClearInMemoryChanges(3)
+	testutil.AssertEquals(t, stateTestWrapper.indexedKeys("chaincode1", "Integral"), []string{})
+	testutil.AssertEquals(t, stateTestWrapper.query("chaincode1", query, true), []string{"bob", "carol"})
+}
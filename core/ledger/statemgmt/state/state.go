@@ -26,7 +26,6 @@ import (
 	"github.com/hyperledger/fabric/core/ledger/statemgmt/raw"
 	"github.com/hyperledger/fabric/core/ledger/statemgmt/trie"
 	"github.com/op/go-logging"
-	"github.com/tecbot/gorocksdb"
 )
 
 var logger = logging.MustGetLogger("state")
@@ -52,6 +51,7 @@ type State struct {
 	currentTxStateDelta   *statemgmt.StateDelta
 	currentTxID           string
 	txStateDeltaHash      map[string][]byte
+	txStateDeltas         map[string]*statemgmt.StateDelta
 	updateStateImpl       bool
 	historyStateDeltaSize uint64
 }
@@ -60,22 +60,36 @@ type State struct {
 func NewState() *State {
 	initConfig()
 	logger.Infof("Initializing state implementation [%s]", stateImplName)
-	switch stateImplName {
+	err := checkStateStructure()
+	if err != nil {
+		panic(fmt.Errorf("Error during initialization of state implementation: %s", err))
+	}
+	stateImpl, err = newStateImpl(stateImplName, stateImplConfigs)
+	if err != nil {
+		panic(fmt.Errorf("Error during initialization of state implementation: %s", err))
+	}
+	return &State{stateImpl, statemgmt.NewStateDelta(), statemgmt.NewStateDelta(), "", make(map[string][]byte),
+		make(map[string]*statemgmt.StateDelta), false, uint64(deltaHistorySize)}
+}
+
+// newStateImpl constructs and initializes the state implementation with the
+// given name and configs
+func newStateImpl(name stateImplType, configs map[string]interface{}) (statemgmt.HashableState, error) {
+	var impl statemgmt.HashableState
+	switch name {
 	case buckettreeType:
-		stateImpl = buckettree.NewStateImpl()
+		impl = buckettree.NewStateImpl()
 	case trieType:
-		stateImpl = trie.NewStateImpl()
+		impl = trie.NewStateImpl()
 	case rawType:
-		stateImpl = raw.NewStateImpl()
+		impl = raw.NewStateImpl()
 	default:
-		panic("Should not reach here. Configs should have checked for the stateImplName being a valid names ")
+		return nil, fmt.Errorf("Unknown state data structure [%s]", name)
 	}
-	err := stateImpl.Initialize(stateImplConfigs)
-	if err != nil {
-		panic(fmt.Errorf("Error during initialization of state implementation: %s", err))
+	if err := impl.Initialize(configs); err != nil {
+		return nil, err
 	}
-	return &State{stateImpl, statemgmt.NewStateDelta(), statemgmt.NewStateDelta(), "", make(map[string][]byte),
-		false, uint64(deltaHistorySize)}
+	return impl, nil
 }
 
 // TxBegin marks begin of a new tx. If a tx is already in progress, this call panics
@@ -98,6 +112,7 @@ func (state *State) TxFinish(txID string, txSuccessful bool) {
 			logger.Debugf("txFinish() for txId [%s] merging state changes", txID)
 			state.stateDelta.ApplyChanges(state.currentTxStateDelta)
 			state.txStateDeltaHash[txID] = state.currentTxStateDelta.ComputeCryptoHash()
+			state.txStateDeltas[txID] = state.currentTxStateDelta
 			state.updateStateImpl = true
 		} else {
 			state.txStateDeltaHash[txID] = nil
@@ -107,6 +122,11 @@ func (state *State) TxFinish(txID string, txSuccessful bool) {
 	state.currentTxID = ""
 }
 
+// GetCurrentTxStateDelta returns the state changes made so far by the on-going tx
+func (state *State) GetCurrentTxStateDelta() *statemgmt.StateDelta {
+	return state.currentTxStateDelta
+}
+
 func (state *State) txInProgress() bool {
 	return state.currentTxID != ""
 }
@@ -255,10 +275,17 @@ func (state *State) GetTxStateDeltaHash() map[string][]byte {
 	return state.txStateDeltaHash
 }
 
+// GetTxStateDeltas returns the changes made by each successful tx since the most
+// recent call to ClearInMemoryChanges, keyed by txID
+func (state *State) GetTxStateDeltas() map[string]*statemgmt.StateDelta {
+	return state.txStateDeltas
+}
+
 // ClearInMemoryChanges remove from memory all the changes to state
 func (state *State) ClearInMemoryChanges(changesPersisted bool) {
 	state.stateDelta = statemgmt.NewStateDelta()
 	state.txStateDeltaHash = make(map[string][]byte)
+	state.txStateDeltas = make(map[string]*statemgmt.StateDelta)
 	state.stateImpl.ClearWorkingSet(changesPersisted)
 }
 
@@ -269,10 +296,16 @@ func (state *State) getStateDelta() *statemgmt.StateDelta {
 
 // GetSnapshot returns a snapshot of the global state for the current block. stateSnapshot.Release()
 // must be called once you are done.
-func (state *State) GetSnapshot(blockNumber uint64, dbSnapshot *gorocksdb.Snapshot) (*StateSnapshot, error) {
+func (state *State) GetSnapshot(blockNumber uint64, dbSnapshot db.Snapshot) (*StateSnapshot, error) {
 	return newStateSnapshot(blockNumber, dbSnapshot)
 }
 
+// VerifyHash recomputes the crypto-hash of the state in dbSnapshot from its key-values, without
+// changing the in-memory state. See the VerifyCryptoHash method of statemgmt.HashableState.
+func (state *State) VerifyHash(dbSnapshot db.Snapshot) ([]byte, string, error) {
+	return state.stateImpl.VerifyCryptoHash(dbSnapshot)
+}
+
 // FetchStateDeltaFromDB fetches the StateDelta corrsponding to given blockNumber
 func (state *State) FetchStateDeltaFromDB(blockNumber uint64) (*statemgmt.StateDelta, error) {
 	stateDeltaBytes, err := db.GetDBHandle().GetFromStateDeltaCF(encodeStateDeltaKey(blockNumber))
@@ -288,13 +321,16 @@ func (state *State) FetchStateDeltaFromDB(blockNumber uint64) (*statemgmt.StateD
 }
 
 // AddChangesForPersistence adds key-value pairs to writeBatch
-func (state *State) AddChangesForPersistence(blockNumber uint64, writeBatch *gorocksdb.WriteBatch) {
+func (state *State) AddChangesForPersistence(blockNumber uint64, writeBatch *db.WriteBatch) error {
 	logger.Debug("state.addChangesForPersistence()...start")
 	if state.updateStateImpl {
 		state.stateImpl.PrepareWorkingSet(state.stateDelta)
 		state.updateStateImpl = false
 	}
 	state.stateImpl.AddChangesForPersistence(writeBatch)
+	if err := state.addRichQueryIndexChanges(state.stateDelta, writeBatch); err != nil {
+		return err
+	}
 
 	serializedStateDelta := state.stateDelta.Marshal()
 	cf := db.GetDBHandle().StateDeltaCF
@@ -309,6 +345,17 @@ func (state *State) AddChangesForPersistence(blockNumber uint64, writeBatch *gor
 			blockNumber, state.historyStateDeltaSize)
 	}
 	logger.Debug("state.addChangesForPersistence()...finished")
+	return nil
+}
+
+// AddStateDeltaPruningForPersistence adds to writeBatch the deletion of the
+// state deltas of the blocks from fromBlockNumber up to, but not including,
+// toBlockNumber
+func (state *State) AddStateDeltaPruningForPersistence(fromBlockNumber uint64, toBlockNumber uint64, writeBatch *db.WriteBatch) {
+	cf := db.GetDBHandle().StateDeltaCF
+	for blockNumber := fromBlockNumber; blockNumber < toBlockNumber; blockNumber++ {
+		writeBatch.DeleteCF(cf, encodeStateDeltaKey(blockNumber))
+	}
 }
 
 // ApplyStateDelta applies already prepared stateDelta to the existing state.
@@ -327,12 +374,12 @@ func (state *State) CommitStateDelta() error {
 		state.updateStateImpl = false
 	}
 
-	writeBatch := gorocksdb.NewWriteBatch()
-	defer writeBatch.Destroy()
+	writeBatch := db.NewWriteBatch()
 	state.stateImpl.AddChangesForPersistence(writeBatch)
-	opt := gorocksdb.NewDefaultWriteOptions()
-	defer opt.Destroy()
-	return db.GetDBHandle().DB.Write(opt, writeBatch)
+	if err := state.addRichQueryIndexChanges(state.stateDelta, writeBatch); err != nil {
+		return err
+	}
+	return db.GetDBHandle().Write(writeBatch)
 }
 
 // DeleteState deletes ALL state keys/values from the DB. This is generally
@@ -343,6 +390,17 @@ func (state *State) DeleteState() error {
 	err := db.GetDBHandle().DeleteState()
 	if err != nil {
 		logger.Errorf("Error deleting state: %s", err)
+		return err
+	}
+	// the state structure is kept with the state deltas
+	err = writeStateStructureToDB(configuredStateStructure())
+	if err != nil {
+		logger.Errorf("Error recording the state data structure: %s", err)
+		return err
+	}
+	err = deleteAllRichQueryIndexEntries()
+	if err != nil {
+		logger.Errorf("Error deleting rich query indexes: %s", err)
 	}
 	return err
 }
@@ -0,0 +1,337 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+	"github.com/hyperledger/fabric/core/ledger/statemgmt/buckettree"
+	"github.com/hyperledger/fabric/protos"
+)
+
+// stateStructureVersion is the version of the way the state data structures
+// are persisted. It is recorded in the DB with the configured data structure,
+// and has to be increased when a data structure changes the way it persists
+// the state, so that the peer refuses to start on a DB in the old format.
+const stateStructureVersion = 1
+
+// The number of key-values rebuilt in each write to the DB by a migration
+const migrationBatchSize = 1000
+
+// The state deltas are keyed by 8 byte block numbers, so this key cannot
+// clash with them
+var stateStructureKey = []byte("stateStructure")
+
+// StructureMigration describes a migration of the state from one data
+// structure to another. Migrated is false if the state was already persisted
+// with the configured data structure.
+type StructureMigration struct {
+	From       *protos.StateStructure
+	To         *protos.StateStructure
+	Migrated   bool
+	NumKeys    int
+	CryptoHash []byte
+}
+
+// configuredStateStructure returns the state structure selected by
+// ledger.state.dataStructure in core.yaml
+func configuredStateStructure() *protos.StateStructure {
+	structure := &protos.StateStructure{Version: stateStructureVersion, Name: string(stateImplName)}
+	if stateImplName == buckettreeType {
+		// the same defaults as the bucket tree
+		numBuckets, ok := stateImplConfigs[buckettree.ConfigNumBuckets].(int)
+		if !ok {
+			numBuckets = buckettree.DefaultNumBuckets
+		}
+		maxGroupingAtEachLevel, ok := stateImplConfigs[buckettree.ConfigMaxGroupingAtEachLevel].(int)
+		if !ok {
+			maxGroupingAtEachLevel = buckettree.DefaultMaxGroupingAtEachLevel
+		}
+		structure.Configs = []string{
+			fmt.Sprintf("%s=%d", buckettree.ConfigMaxGroupingAtEachLevel, maxGroupingAtEachLevel),
+			fmt.Sprintf("%s=%d", buckettree.ConfigNumBuckets, numBuckets),
+		}
+	}
+	return structure
+}
+
+func sameStateStructure(structure *protos.StateStructure, anotherStructure *protos.StateStructure) bool {
+	return structure.Version == anotherStructure.Version && structure.Name == anotherStructure.Name &&
+		strings.Join(structure.Configs, ",") == strings.Join(anotherStructure.Configs, ",")
+}
+
+func describeStateStructure(structure *protos.StateStructure) string {
+	if len(structure.Configs) == 0 {
+		return fmt.Sprintf("%s version %d", structure.Name, structure.Version)
+	}
+	return fmt.Sprintf("%s(%s) version %d", structure.Name, strings.Join(structure.Configs, ", "), structure.Version)
+}
+
+// stateStructureConfigs converts the configs of the state structure back to
+// the configs of the state implementation
+func stateStructureConfigs(structure *protos.StateStructure) map[string]interface{} {
+	configs := make(map[string]interface{})
+	for _, config := range structure.Configs {
+		nameValue := strings.SplitN(config, "=", 2)
+		if len(nameValue) != 2 {
+			continue
+		}
+		if intValue, err := strconv.Atoi(nameValue[1]); err == nil {
+			configs[nameValue[0]] = intValue
+		} else {
+			configs[nameValue[0]] = nameValue[1]
+		}
+	}
+	return configs
+}
+
+// checkStateStructure compares the state structure recorded in the DB with
+// the configured one, and records the configured one if there is none yet
+func checkStateStructure() error {
+	configured := configuredStateStructure()
+	persisted, err := fetchStateStructureFromDB()
+	if err != nil {
+		return err
+	}
+	if persisted == nil {
+		logger.Infof("Recording the state data structure [%s] in the DB", describeStateStructure(configured))
+		return writeStateStructureToDB(configured)
+	}
+	if persisted.Migrating {
+		return fmt.Errorf("The migration of the state from data structure [%s] was interrupted. Run 'peer node migrate-state' again to complete it.",
+			describeStateStructure(persisted))
+	}
+	if !sameStateStructure(persisted, configured) {
+		return fmt.Errorf("The state is persisted with data structure [%s], but [%s] is configured. Restore the configuration, or run 'peer node migrate-state' to rebuild the state for the configured data structure.",
+			describeStateStructure(persisted), describeStateStructure(configured))
+	}
+	return nil
+}
+
+func fetchStateStructureFromDB() (*protos.StateStructure, error) {
+	structureBytes, err := db.GetDBHandle().GetFromStateDeltaCF(stateStructureKey)
+	if err != nil {
+		return nil, err
+	}
+	if structureBytes == nil {
+		return nil, nil
+	}
+	structure := &protos.StateStructure{}
+	if err = proto.Unmarshal(structureBytes, structure); err != nil {
+		return nil, fmt.Errorf("Could not unmarshal the state data structure: %s", err)
+	}
+	return structure, nil
+}
+
+func writeStateStructureToDB(structure *protos.StateStructure) error {
+	structureBytes, err := proto.Marshal(structure)
+	if err != nil {
+		return err
+	}
+	openchainDB := db.GetDBHandle()
+	return openchainDB.Put(openchainDB.StateDeltaCF, stateStructureKey, structureBytes)
+}
+
+// MigrateStructure rebuilds the persisted state for the configured data
+// structure, from the key-values of the state persisted with the data
+// structure recorded in the DB. The key-values are first written to
+// spoolFile, so that an interrupted migration can be completed by calling
+// MigrateStructure again. As the state hash changes with the data structure,
+// the peers of a network have to migrate at the same block. This must not be
+// called while a State is in use.
+func MigrateStructure(spoolFile string) (*StructureMigration, error) {
+	initConfig()
+	to := configuredStateStructure()
+	from, err := fetchStateStructureFromDB()
+	if err != nil {
+		return nil, err
+	}
+	if from == nil {
+		return nil, fmt.Errorf("The DB does not record the data structure of the state. Start the peer once with the configuration the DB was created with.")
+	}
+	migration := &StructureMigration{From: from, To: to}
+	if !from.Migrating {
+		if sameStateStructure(from, to) {
+			return migration, nil
+		}
+		if from.Version > stateStructureVersion {
+			return nil, fmt.Errorf("The state data structure [%s] is newer than this peer supports (version %d)",
+				describeStateStructure(from), stateStructureVersion)
+		}
+		if err = spoolStateKeyValues(from, spoolFile); err != nil {
+			return nil, fmt.Errorf("Error reading the state key-values: %s", err)
+		}
+		from.Migrating = true
+		if err = writeStateStructureToDB(from); err != nil {
+			return nil, err
+		}
+	} else {
+		logger.Infof("Completing the interrupted migration of the state from data structure [%s]", describeStateStructure(from))
+	}
+
+	migration.NumKeys, migration.CryptoHash, err = rebuildState(spoolFile)
+	if err != nil {
+		return nil, fmt.Errorf("Error rebuilding the state: %s", err)
+	}
+	if err = writeStateStructureToDB(to); err != nil {
+		return nil, err
+	}
+	migration.Migrated = true
+	os.Remove(spoolFile)
+	logger.Infof("Migrated %d keys of the state from data structure [%s] to [%s]. The state hash is now [%x]",
+		migration.NumKeys, describeStateStructure(from), describeStateStructure(to), migration.CryptoHash)
+	return migration, nil
+}
+
+// spoolStateKeyValues writes the composite keys and values of the state
+// persisted with the data structure to spoolFile
+func spoolStateKeyValues(structure *protos.StateStructure, spoolFile string) error {
+	impl, err := newStateImpl(stateImplType(structure.Name), stateStructureConfigs(structure))
+	if err != nil {
+		return err
+	}
+	dbSnapshot := db.GetDBHandle().GetSnapshot()
+	defer dbSnapshot.Release()
+	itr, err := impl.GetStateSnapshotIterator(dbSnapshot)
+	if err != nil {
+		return err
+	}
+	defer itr.Close()
+
+	// The spool file only gets its name once it is complete
+	tempFile := spoolFile + ".tmp"
+	file, err := os.Create(tempFile)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile)
+	defer file.Close()
+	out := bufio.NewWriter(file)
+	for itr.Next() {
+		compositeKey, value := itr.GetRawKeyValue()
+		for _, record := range [][]byte{compositeKey, value} {
+			if _, err = out.Write(proto.EncodeVarint(uint64(len(record)))); err != nil {
+				return err
+			}
+			if _, err = out.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	if err = out.Flush(); err != nil {
+		return err
+	}
+	if err = file.Sync(); err != nil {
+		return err
+	}
+	if err = file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempFile, spoolFile)
+}
+
+// rebuildState replaces the persisted state with the key-values in
+// spoolFile, persisted with the configured data structure. It returns the
+// number of keys and the crypto-hash of the rebuilt state.
+func rebuildState(spoolFile string) (int, []byte, error) {
+	file, err := os.Open(spoolFile)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer file.Close()
+	in := bufio.NewReader(file)
+
+	if err = db.GetDBHandle().DeleteStateCF(); err != nil {
+		return 0, nil, err
+	}
+	impl, err := newStateImpl(stateImplName, stateImplConfigs)
+	if err != nil {
+		return 0, nil, err
+	}
+	stateDelta := statemgmt.NewStateDelta()
+	persistStateDelta := func() error {
+		impl.PrepareWorkingSet(stateDelta)
+		if _, err := impl.ComputeCryptoHash(); err != nil {
+			return err
+		}
+		writeBatch := db.NewWriteBatch()
+		if err := impl.AddChangesForPersistence(writeBatch); err != nil {
+			return err
+		}
+		if err := db.GetDBHandle().Write(writeBatch); err != nil {
+			return err
+		}
+		impl.ClearWorkingSet(true)
+		stateDelta = statemgmt.NewStateDelta()
+		return nil
+	}
+
+	numKeys := 0
+	for {
+		compositeKey, err := readSpoolRecord(in)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+		value, err := readSpoolRecord(in)
+		if err != nil {
+			return 0, nil, err
+		}
+		chaincodeID, key := statemgmt.DecodeCompositeKey(compositeKey)
+		stateDelta.Set(chaincodeID, key, value, nil)
+		numKeys++
+		if numKeys%migrationBatchSize == 0 {
+			if err = persistStateDelta(); err != nil {
+				return 0, nil, err
+			}
+		}
+	}
+	if err = persistStateDelta(); err != nil {
+		return 0, nil, err
+	}
+	cryptoHash, err := impl.ComputeCryptoHash()
+	if err != nil {
+		return 0, nil, err
+	}
+	return numKeys, cryptoHash, nil
+}
+
+func readSpoolRecord(in *bufio.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(in)
+	if err != nil {
+		return nil, err
+	}
+	if length > statemgmt.MaxRecordLength {
+		return nil, fmt.Errorf("Record of %d bytes in the spool file exceeds the maximum of %d bytes", length, statemgmt.MaxRecordLength)
+	}
+	record := make([]byte, length)
+	if _, err = io.ReadFull(in, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
@@ -0,0 +1,156 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+)
+
+func TestStateStructureCheck(t *testing.T) {
+	createFreshDBAndConstructState(t)
+	defer configureStateStructure(stateImplName, stateImplConfigs)
+
+	structure, err := fetchStateStructureFromDB()
+	testutil.AssertNoError(t, err, "Error fetching the state structure")
+	testutil.AssertEquals(t, structure, configuredStateStructure())
+	testutil.AssertNoError(t, checkStateStructure(), "Error checking the recorded state structure")
+
+	configureStateStructure(buckettreeType, map[string]interface{}{"numBuckets": 7, "maxGroupingAtEachLevel": 3})
+	testutil.AssertError(t, checkStateStructure(), "Expected an error for a different number of buckets")
+
+	configureStateStructure(trieType, nil)
+	testutil.AssertError(t, checkStateStructure(), "Expected an error for a different data structure")
+}
+
+func TestMigrateStructure(t *testing.T) {
+	stateTestWrapper, state := createFreshDBAndConstructState(t)
+	defer configureStateStructure(stateImplName, stateImplConfigs)
+	spoolFile := createSpoolFilePath(t)
+
+	state.TxBegin("txUuid")
+	for i := 0; i < 2500; i++ {
+		state.Set(fmt.Sprintf("chaincode%d", i%3), fmt.Sprintf("key%d", i), []byte(fmt.Sprintf("value%d", i)))
+	}
+	state.TxFinish("txUuid", true)
+	delta := state.getStateDelta()
+	stateTestWrapper.persistAndClearInMemoryChanges(0)
+
+	targets := []struct {
+		name    stateImplType
+		configs map[string]interface{}
+	}{
+		{buckettreeType, map[string]interface{}{"numBuckets": 7, "maxGroupingAtEachLevel": 3}},
+		{trieType, nil},
+		{rawType, nil},
+		{buckettreeType, nil},
+	}
+	for _, target := range targets {
+		configureStateStructure(target.name, target.configs)
+		migration, err := MigrateStructure(spoolFile)
+		testutil.AssertNoError(t, err, fmt.Sprintf("Error migrating the state to [%s]", target.name))
+		testutil.AssertEquals(t, migration.Migrated, true)
+		testutil.AssertEquals(t, migration.To, configuredStateStructure())
+		testutil.AssertEquals(t, migration.NumKeys, 2500)
+
+		stateTestWrapper = newStateTestWrapper(t)
+		testutil.AssertEquals(t, stateTestWrapper.get("chaincode1", "key1", true), []byte("value1"))
+		testutil.AssertEquals(t, stateTestWrapper.get("chaincode2", "key2498", true), []byte("value2498"))
+		testutil.AssertEquals(t, stateTestWrapper.fetchStateDeltaFromDB(0), delta)
+		assertStateConsistent(t, stateTestWrapper.state, migration.CryptoHash)
+		testutil.AssertNoError(t, checkStateStructure(), "Error checking the migrated state structure")
+	}
+
+	// no migration when the configuration is unchanged
+	migration, err := MigrateStructure(spoolFile)
+	testutil.AssertNoError(t, err, "Error migrating the state")
+	testutil.AssertEquals(t, migration.Migrated, false)
+	testutil.AssertEquals(t, migration.From, migration.To)
+}
+
+func TestMigrateStructureResume(t *testing.T) {
+	stateTestWrapper, state := createFreshDBAndConstructState(t)
+	defer configureStateStructure(stateImplName, stateImplConfigs)
+	spoolFile := createSpoolFilePath(t)
+
+	state.TxBegin("txUuid")
+	state.Set("chaincode1", "key1", []byte("value1"))
+	state.Set("chaincode2", "key2", []byte("value2"))
+	state.TxFinish("txUuid", true)
+	stateTestWrapper.persistAndClearInMemoryChanges(0)
+
+	// interrupt the migration after the state has been dropped
+	from := configuredStateStructure()
+	testutil.AssertNoError(t, spoolStateKeyValues(from, spoolFile), "Error spooling the state")
+	from.Migrating = true
+	testutil.AssertNoError(t, writeStateStructureToDB(from), "Error recording the migration")
+	testutil.AssertNoError(t, db.GetDBHandle().DeleteStateCF(), "Error dropping the state")
+	testutil.AssertError(t, checkStateStructure(), "Expected an error for an interrupted migration")
+
+	configureStateStructure(trieType, nil)
+	migration, err := MigrateStructure(spoolFile)
+	testutil.AssertNoError(t, err, "Error completing the migration")
+	testutil.AssertEquals(t, migration.NumKeys, 2)
+	_, err = os.Stat(spoolFile)
+	testutil.AssertEquals(t, os.IsNotExist(err), true)
+
+	stateTestWrapper = newStateTestWrapper(t)
+	testutil.AssertEquals(t, stateTestWrapper.get("chaincode2", "key2", true), []byte("value2"))
+	assertStateConsistent(t, stateTestWrapper.state, migration.CryptoHash)
+}
+
+func TestReadSpoolRecordTooLong(t *testing.T) {
+	var length [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(length[:], statemgmt.MaxRecordLength+1)
+	_, err := readSpoolRecord(bufio.NewReader(bytes.NewReader(length[:n])))
+	testutil.AssertError(t, err, "Expected an error reading a spool record longer than the maximum")
+}
+
+func configureStateStructure(name stateImplType, configs map[string]interface{}) {
+	stateImplName = name
+	stateImplConfigs = configs
+}
+
+func createSpoolFilePath(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "stateMigration")
+	testutil.AssertNoError(t, err, "Error creating a directory for the spool file")
+	return filepath.Join(dir, "spool")
+}
+
+func assertStateConsistent(t *testing.T, state *State, expectedHash []byte) {
+	hash, err := state.GetHash()
+	testutil.AssertNoError(t, err, "Error computing the state hash")
+	testutil.AssertEquals(t, hash, expectedHash)
+	dbSnapshot := db.GetDBHandle().GetSnapshot()
+	defer dbSnapshot.Release()
+	verifiedHash, divergence, err := state.VerifyHash(dbSnapshot)
+	testutil.AssertNoError(t, err, "Error verifying the state hash")
+	testutil.AssertEquals(t, divergence, "")
+	if verifiedHash != nil {
+		testutil.AssertEquals(t, verifiedHash, expectedHash)
+	}
+}
@@ -16,6 +16,8 @@ limitations under the License.
 
 package statemgmt
 
+import "sort"
+
 // StateDeltaIterator - An iterator implementation over state-delta
 type StateDeltaIterator struct {
 	updates         map[string]*UpdatedValue
@@ -24,7 +26,8 @@ type StateDeltaIterator struct {
 	done            bool
 }
 
-// NewStateDeltaRangeScanIterator - return an iterator for performing a range scan over a state-delta object
+// NewStateDeltaRangeScanIterator - return an iterator for performing a range scan over a state-delta object.
+// The keys are returned in lexical order.
 func NewStateDeltaRangeScanIterator(delta *StateDelta, chaincodeID string, startKey string, endKey string) *StateDeltaIterator {
 	updates := delta.GetUpdates(chaincodeID)
 	return &StateDeltaIterator{updates, retrieveRelevantKeys(updates, startKey, endKey), -1, false}
@@ -40,6 +43,7 @@ func retrieveRelevantKeys(updates map[string]*UpdatedValue, startKey string, end
 			relevantKeys = append(relevantKeys, k)
 		}
 	}
+	sort.Strings(relevantKeys)
 	return relevantKeys
 }
 
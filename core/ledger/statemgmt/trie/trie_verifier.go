@@ -0,0 +1,139 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trie
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+)
+
+// verifiedTrieNode pairs a trie node read from the DB with the node recomputed
+// from the values of its descendants
+type verifiedTrieNode struct {
+	persisted *trieNode
+	computed  *trieNode
+}
+
+// trieVerifier recomputes the trie from the trie nodes, which the DB gives in
+// the order of their keys, i.e., every node before its descendants. It keeps
+// the nodes from the root to the last node read, and finishes a node once the
+// nodes after it are no longer its descendants.
+type trieVerifier struct {
+	path       []*verifiedTrieNode
+	divergence string
+}
+
+// verifyTrie recomputes the trie from the values of the trie nodes in the
+// snapshot, and compares the children crypto-hashes of the recomputed nodes
+// with those of the nodes in the snapshot. It returns the crypto-hash of the
+// recomputed root and a description of the first trie node, in depth first
+// order, whose children crypto-hashes differ.
+func verifyTrie(snapshot db.Snapshot) ([]byte, string, error) {
+	itr := db.GetDBHandle().GetStateCFSnapshotIterator(snapshot)
+	defer itr.Close()
+
+	verifier := &trieVerifier{}
+	verifier.push(rootTrieKey, nil)
+	for itr.SeekToFirst(); itr.Valid(); itr.Next() {
+		trieKey := newTrieKeyFromCompositeKey(decodeTrieKeyBytes(statemgmt.Copy(itr.Key())))
+		persistedNode, err := unmarshalTrieNode(trieKey, statemgmt.Copy(itr.Value()))
+		if err != nil {
+			return nil, "", err
+		}
+		if trieKey.isRootKey() {
+			verifier.path[0].persisted = persistedNode
+			continue
+		}
+		verifier.add(persistedNode)
+	}
+	for len(verifier.path) > 1 {
+		verifier.pop()
+	}
+	cryptoHash := verifier.pop()
+	stateTrieLogger.Debugf("Recomputed crypto-hash of the trie [%x], divergence=[%s]", cryptoHash, verifier.divergence)
+	return cryptoHash, verifier.divergence, nil
+}
+
+func (verifier *trieVerifier) add(persistedNode *trieNode) {
+	key := persistedNode.trieKey
+	for !bytes.HasPrefix(key.getEncodedBytes(), verifier.top().getEncodedBytes()) {
+		verifier.pop()
+	}
+	// the ancestors without a trie node in the DB
+	var ancestorKeys []*trieKey
+	for parentKey := key.getParentTrieKey(); parentKey.getLevel() > verifier.top().getLevel(); parentKey = parentKey.getParentTrieKey() {
+		ancestorKeys = append(ancestorKeys, parentKey)
+	}
+	for i := len(ancestorKeys) - 1; i >= 0; i-- {
+		verifier.push(ancestorKeys[i], nil)
+	}
+	verifier.push(key, persistedNode)
+}
+
+func (verifier *trieVerifier) top() *trieKey {
+	return verifier.path[len(verifier.path)-1].computed.trieKey
+}
+
+func (verifier *trieVerifier) push(trieKey *trieKey, persistedNode *trieNode) {
+	var value []byte
+	if persistedNode != nil {
+		value = persistedNode.value
+	}
+	verifier.path = append(verifier.path, &verifiedTrieNode{persistedNode, newTrieNode(trieKey, value, false)})
+}
+
+// pop finishes the last trie node of the path, and returns its crypto-hash
+func (verifier *trieVerifier) pop() []byte {
+	node := verifier.path[len(verifier.path)-1]
+	verifier.path = verifier.path[:len(verifier.path)-1]
+	if verifier.divergence == "" {
+		verifier.divergence = compareTrieNodes(node.computed, node.persisted)
+	}
+	cryptoHash := node.computed.computeCryptoHash()
+	if len(verifier.path) > 0 {
+		verifier.path[len(verifier.path)-1].computed.setChildCryptoHash(node.computed.getIndexInParent(), cryptoHash)
+	}
+	return cryptoHash
+}
+
+// compareTrieNodes describes the first child whose crypto-hash differs between
+// the computed trie node and the persisted one
+func compareTrieNodes(computedNode *trieNode, persistedNode *trieNode) string {
+	if persistedNode == nil {
+		persistedNode = newTrieNode(computedNode.trieKey, nil, false)
+	}
+	indexes := computedNode.getSortedChildrenIndex()
+	for _, index := range persistedNode.getSortedChildrenIndex() {
+		if _, ok := computedNode.childrenCryptoHashes[index]; !ok {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		computedHash := computedNode.childrenCryptoHashes[index]
+		persistedHash := persistedNode.childrenCryptoHashes[index]
+		if !bytes.Equal(computedHash, persistedHash) {
+			return fmt.Sprintf("Trie node [%q] has crypto-hash [%x] for child [%d] in the DB, but [%x] recomputed from the key-values",
+				computedNode.trieKey.getEncodedBytes(), persistedHash, index, computedHash)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,72 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trie
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger/statemgmt"
+	"github.com/hyperledger/fabric/core/ledger/testutil"
+)
+
+func TestStateTrie_VerifyCryptoHash(t *testing.T) {
+	testDBWrapper.CleanDB(t)
+	stateTrie := NewStateImpl()
+	stateTrieTestWrapper := &stateTrieTestWrapper{stateTrie, t}
+
+	cryptoHash, divergence, err := stateTrie.VerifyCryptoHash(db.GetDBHandle().GetSnapshot())
+	testutil.AssertNoError(t, err, "Error verifying the crypto-hash of an empty state")
+	testutil.AssertNil(t, cryptoHash)
+	testutil.AssertEquals(t, divergence, "")
+
+	stateDelta := statemgmt.NewStateDelta()
+	stateDelta.Set("chaincodeID1", "key1", []byte("value1"), nil)
+	stateDelta.Set("chaincodeID1", "key10", []byte("value10"), nil)
+	stateDelta.Set("chaincodeID1", "key2", []byte("value2"), nil)
+	stateDelta.Set("chaincodeID2", "key3", []byte("value3"), nil)
+	stateDelta.Set("chaincodeID2", "key4", []byte("value4"), nil)
+	stateTrieTestWrapper.PrepareWorkingSetAndComputeCryptoHash(stateDelta)
+	stateTrieTestWrapper.PersistChangesAndResetInMemoryChanges()
+	stateDelta = statemgmt.NewStateDelta()
+	stateDelta.Delete("chaincodeID1", "key2", nil)
+	stateDelta.Set("chaincodeID3", "key5", []byte("value5"), nil)
+	expectedHash := stateTrieTestWrapper.PrepareWorkingSetAndComputeCryptoHash(stateDelta)
+	stateTrieTestWrapper.PersistChangesAndResetInMemoryChanges()
+
+	cryptoHash, divergence, err = stateTrie.VerifyCryptoHash(db.GetDBHandle().GetSnapshot())
+	testutil.AssertNoError(t, err, "Error verifying the crypto-hash")
+	testutil.AssertEquals(t, cryptoHash, expectedHash)
+	testutil.AssertEquals(t, divergence, "")
+
+	// change a value behind the back of the trie
+	tamperedKey := newTrieKey("chaincodeID2", "key3")
+	tamperedNode, err := newTrieNode(tamperedKey, []byte("tampered"), true).marshal()
+	testutil.AssertNoError(t, err, "Error marshalling the trie node")
+	openchainDB := db.GetDBHandle()
+	err = openchainDB.Put(openchainDB.StateCF, tamperedKey.getEncodedBytes(), tamperedNode)
+	testutil.AssertNoError(t, err, "Error writing to the DB")
+	cryptoHash, divergence, err = stateTrie.VerifyCryptoHash(db.GetDBHandle().GetSnapshot())
+	testutil.AssertNoError(t, err, "Error verifying the crypto-hash")
+	testutil.AssertNotEquals(t, cryptoHash, expectedHash)
+	parentNode := fmt.Sprintf("Trie node [%q]", tamperedKey.getParentTrieKey().getEncodedBytes())
+	if !strings.Contains(divergence, parentNode) {
+		t.Fatalf("Expected the divergence to be in the parent of the changed node, found [%s]", divergence)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/looplab/fsm"
 	"github.com/spf13/viper"
 
+	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/statemgmt"
 	pb "github.com/hyperledger/fabric/protos"
 )
@@ -415,12 +416,18 @@ func (d *Handler) sendBlocks(syncBlockRange *pb.SyncBlockRange) {
 	for _, currBlockNum := range blockNums {
 		// Get the Block from
 		block, err := d.Coordinator.GetBlockByNumber(currBlockNum)
-		if err != nil {
+		syncBlocks := &pb.SyncBlocks{Range: &pb.SyncBlockRange{Start: currBlockNum, End: currBlockNum, CorrelationId: syncBlockRange.CorrelationId}}
+		if ledgerErr, ok := err.(*ledger.Error); ok && ledgerErr.Type() == ledger.ErrorTypePruned {
+			// Tell the requesting peer, so that it does not wait for the block
+			peerLogger.Warningf("Cannot send blockNum %d: %s", currBlockNum, err)
+			syncBlocks.Pruned = true
+		} else if err != nil {
 			peerLogger.Errorf("Error sending blockNum %d: %s", currBlockNum, err)
 			break
+		} else {
+			syncBlocks.Blocks = []*pb.Block{block}
 		}
 		// Encode a SyncBlocks into the payload
-		syncBlocks := &pb.SyncBlocks{Range: &pb.SyncBlockRange{Start: currBlockNum, End: currBlockNum, CorrelationId: syncBlockRange.CorrelationId}, Blocks: []*pb.Block{block}}
 		syncBlocksBytes, err := proto.Marshal(syncBlocks)
 		if err != nil {
 			peerLogger.Errorf("Error marshalling syncBlocks for BlockNum = %d: %s", currBlockNum, err)
@@ -430,6 +437,9 @@ func (d *Handler) sendBlocks(syncBlockRange *pb.SyncBlockRange) {
 			peerLogger.Errorf("Error sending blockNum %d: %s", currBlockNum, err)
 			break
 		}
+		if syncBlocks.Pruned {
+			break
+		}
 	}
 }
 
@@ -328,6 +328,10 @@ func (sts *coordinatorImpl) syncBlocks(highBlock, lowBlock uint64, highHash []by
 						return fmt.Errorf("Channel closed before we could finish reading")
 					}
 
+					if syncBlockMessage.Pruned {
+						return fmt.Errorf("Block %d has been pruned by %v, trying another peer", syncBlockMessage.Range.Start, peerID)
+					}
+
 					if syncBlockMessage.Range.Start < syncBlockMessage.Range.End {
 						// If the message is not replying with blocks backwards, we did not ask for it
 						return fmt.Errorf("Received a block with wrong (increasing) order from %v, aborting", peerID)
@@ -44,6 +44,7 @@ const (
 	Corrupt
 	Timeout
 	OutOfOrder
+	Pruned
 )
 
 func (r mockResponse) String() string {
@@ -54,6 +55,8 @@ func (r mockResponse) String() string {
 		return "Corrupt"
 	case Timeout:
 		return "Timeout"
+	case Pruned:
+		return "Pruned"
 	}
 
 	return "ERROR"
@@ -289,6 +292,15 @@ func (mock *MockLedger) GetRemoteBlocks(peerID *protos.PeerID, start, finish uin
 					},
 					Blocks: []*protos.Block{block},
 				}
+			case ft == Pruned:
+				res <- &protos.SyncBlocks{
+					Range: &protos.SyncBlockRange{
+						Start: current,
+						End:   current,
+					},
+					Pruned: true,
+				}
+				return
 			default:
 				mock.t.Fatalf("Unsupported filter result %d", ft)
 			}
@@ -363,6 +363,26 @@ func TestCatchupSyncBlocksErrors(t *testing.T) {
 	}
 }
 
+func TestCatchupSyncBlocksPruned(t *testing.T) {
+	mrls := createRemoteLedgers(1, 3)
+
+	// The peer which has pruned the blocks says so, instead of letting
+	// the request time out
+	filter, result := makeSimpleFilter(SyncBlocks, Pruned)
+	ml := NewMockLedger(mrls, filter, t)
+
+	ml.PutBlock(0, SimpleGetBlock(0))
+	sts := newTestStateTransfer(ml, mrls)
+	defer sts.Stop()
+	sts.BlockRequestTimeout = time.Hour
+	if err := executeStateTransfer(sts, ml, 7, 10, mrls); nil != err {
+		t.Fatalf("SyncBlocksPruned case: %s", err)
+	}
+	if !result.wasTriggered() {
+		t.Fatalf("SyncBlocksPruned case never simulated a pruned block")
+	}
+}
+
 // Added for issue #676, for situations all potential sync targets fail, and sync is re-initiated, causing panic
 func TestCatchupSyncBlocksAllErrors(t *testing.T) {
 	blockNumber := uint64(10)
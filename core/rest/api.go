@@ -88,6 +88,9 @@ func (s *ServerOpenchain) GetBlockchainInfo(ctx context.Context, e *empty.Empty)
 func (s *ServerOpenchain) GetBlockByNumber(ctx context.Context, num *pb.BlockNumber) (*pb.Block, error) {
 	block, err := s.ledger.GetBlockByNumber(num.Number)
 	if err != nil {
+		if ledgerErr, ok := err.(*ledger.Error); ok && ledgerErr.Type() == ledger.ErrorTypePruned {
+			return nil, err
+		}
 		switch err {
 		case ledger.ErrOutOfBounds:
 			return nil, ErrNotFound
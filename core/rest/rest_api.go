@@ -42,6 +42,7 @@ import (
 	"github.com/hyperledger/fabric/core/comm"
 	"github.com/hyperledger/fabric/core/crypto"
 	"github.com/hyperledger/fabric/core/crypto/primitives"
+	"github.com/hyperledger/fabric/core/ledger"
 	pb "github.com/hyperledger/fabric/protos"
 )
 
@@ -136,8 +137,9 @@ type rpcError struct {
 	Message string `json:"message,omitempty"`
 	// A Primitive or Structured value that contains additional information about
 	// the error. This may be omitted. The value of this member is defined by the
-	// Server (e.g. detailed error information, nested errors etc.).
-	Data string `json:"data,omitempty"`
+	// Server (e.g. detailed error information, nested errors etc.). A chaincode
+	// failure reported with shim.Error carries the structured ChaincodeError.
+	Data interface{} `json:"data,omitempty"`
 }
 
 // JSON RPC 2.0 errors and messages.
@@ -678,6 +680,13 @@ func (s *ServerOpenchainREST) GetBlockByNumber(rw web.ResponseWriter, req *web.R
 		return
 	}
 
+	// The block is still on the chain, but without its transactions
+	if ledgerErr, ok := err.(*ledger.Error); ok && ledgerErr.Type() == ledger.ErrorTypePruned {
+		rw.WriteHeader(http.StatusGone)
+		encoder.Encode(restResult{Error: err.Error()})
+		return
+	}
+
 	if err != nil {
 		rw.WriteHeader(http.StatusInternalServerError)
 		encoder.Encode(restResult{Error: err.Error()})
@@ -1131,6 +1140,9 @@ func (s *ServerOpenchainREST) Query(rw web.ResponseWriter, req *web.Request) {
 
 	// Query the chainCode
 	resp, err := s.devops.Query(context.Background(), &spec)
+	if err == nil && resp.ChaincodeError != nil {
+		err = resp.ChaincodeError
+	}
 	if err != nil {
 		// Replace " characters with '
 		errVal := strings.Replace(err.Error(), "\"", "'", -1)
@@ -1612,7 +1624,7 @@ func (s *ServerOpenchainREST) processChaincodeInvokeOrQuery(method string, spec
 
 		if err != nil {
 			// Format the error appropriately for further processing
-			error := formatRPCError(ChaincodeInvokeError.Code, ChaincodeInvokeError.Message, fmt.Sprintf("Error when invoking chaincode: %s", err))
+			error := formatRPCError(ChaincodeInvokeError.Code, ChaincodeInvokeError.Message, chaincodeErrorData("Error when invoking chaincode", err))
 			restLogger.Errorf("Error when invoking chaincode: %s", err)
 
 			return error
@@ -1641,6 +1653,9 @@ func (s *ServerOpenchainREST) processChaincodeInvokeOrQuery(method string, spec
 		//
 
 		resp, err := s.devops.Query(context.Background(), spec)
+		if err == nil && resp.ChaincodeError != nil {
+			err = resp.ChaincodeError
+		}
 
 		//
 		// Query failed
@@ -1648,7 +1663,7 @@ func (s *ServerOpenchainREST) processChaincodeInvokeOrQuery(method string, spec
 
 		if err != nil {
 			// Format the error appropriately for further processing
-			error := formatRPCError(ChaincodeQueryError.Code, ChaincodeQueryError.Message, fmt.Sprintf("Error when querying chaincode: %s", err))
+			error := formatRPCError(ChaincodeQueryError.Code, ChaincodeQueryError.Message, chaincodeErrorData("Error when querying chaincode", err))
 			restLogger.Errorf("Error when querying chaincode: %s", err)
 
 			return error
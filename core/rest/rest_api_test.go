@@ -141,6 +141,9 @@ func (d *mockDevops) Query(c context.Context, cis *protos.ChaincodeInvocationSpe
 	switch string(cis.ChaincodeSpec.CtorMsg.Args[0]) {
 	case "fail":
 		return nil, fmt.Errorf("Query failure with special-\" chars")
+	case "fail_with_code":
+		return &protos.Response{Status: protos.Response_FAILURE,
+			ChaincodeError: &protos.ChaincodeError{Code: 402, Message: "insufficient balance", Details: "balance 5 is less than 10"}}, nil
 	case "get_owner":
 		return &protos.Response{Status: protos.Response_SUCCESS, Msg: []byte("get_owner_query_result")}, nil
 	}
@@ -763,6 +766,20 @@ func TestServerOpenchainREST_API_Chaincode_Query(t *testing.T) {
 		t.Errorf("Expected an error message when chaincode query fails, but got %#v", res.Error.Data)
 	}
 
+	// Test query failing with a structured chaincode error
+	httpResponse, body = performHTTPPost(t, httpServer.URL+"/chaincode", []byte(`{"jsonrpc":"2.0","ID":123,"method":"query","params":{"type":1,"chaincodeID":{"name":"dummy"},"ctorMsg":{"Function":"fail_with_code","args":[]},"secureContext":"myuser"}}`))
+	if httpResponse.StatusCode != http.StatusOK {
+		t.Errorf("Expected an HTTP status code %#v but got %#v", http.StatusOK, httpResponse.StatusCode)
+	}
+	res = parseRPCResponse(t, body)
+	if res.Error == nil || res.Error.Code != ChaincodeQueryError.Code {
+		t.Fatalf("Expected an error when chaincode query fails, but got %#v", res.Error)
+	}
+	data, ok := res.Error.Data.(map[string]interface{})
+	if !ok || data["code"] != float64(402) || data["message"] != "insufficient balance" || data["details"] != "balance 5 is less than 10" {
+		t.Errorf("Expected the structured chaincode error as data, but got %#v", res.Error.Data)
+	}
+
 	// Test query with get_owner function
 	httpResponse, body = performHTTPPost(t, httpServer.URL+"/chaincode", []byte(`{"jsonrpc":"2.0","ID":123,"method":"query","params":{"type":1,"chaincodeID":{"name":"dummy"},"ctorMsg":{"Function":"`+get_owner_func+`","args":[]},"secureContext":"myuser"}}`))
 	if httpResponse.StatusCode != http.StatusOK {
@@ -16,7 +16,12 @@ limitations under the License.
 
 package rest
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+
+	pb "github.com/hyperledger/fabric/protos"
+)
 
 // isJSON is a helper function to determine if a given string is proper JSON.
 func isJSON(s string) bool {
@@ -25,13 +30,23 @@ func isJSON(s string) bool {
 }
 
 // formatRPCError formats the ERROR response to aid in JSON RPC 2.0 implementation
-func formatRPCError(code int64, msg string, data string) rpcResult {
+func formatRPCError(code int64, msg string, data interface{}) rpcResult {
 	err := &rpcError{Code: code, Message: msg, Data: data}
 	error := rpcResult{Status: "Error", Error: err}
 
 	return error
 }
 
+// chaincodeErrorData returns the data of the error response to a failed
+// invoke or query: the error itself if the chaincode returned a structured
+// one, so that clients can act on its code, or else its message after prefix
+func chaincodeErrorData(prefix string, err error) interface{} {
+	if chaincodeError, ok := err.(*pb.ChaincodeError); ok {
+		return chaincodeError
+	}
+	return fmt.Sprintf("%s: %s", prefix, err)
+}
+
 // formatRPCOK formats the OK response to aid in JSON RPC 2.0 implementation
 func formatRPCOK(msg string) rpcResult {
 	result := rpcResult{Status: "OK", Message: msg}
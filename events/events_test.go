@@ -35,8 +35,9 @@ import (
 
 type Adapter struct {
 	sync.RWMutex
-	notfy chan struct{}
-	count int
+	notfy     chan struct{}
+	count     int
+	rejection *ehpb.Rejection
 }
 
 var peerAddress string
@@ -46,6 +47,7 @@ var obcEHClient *consumer.EventsClient
 func (a *Adapter) GetInterestedEvents() ([]*ehpb.Interest, error) {
 	return []*ehpb.Interest{
 		&ehpb.Interest{EventType: ehpb.EventType_BLOCK},
+		&ehpb.Interest{EventType: ehpb.EventType_REJECTION},
 		&ehpb.Interest{EventType: ehpb.EventType_CHAINCODE, RegInfo: &ehpb.Interest_ChaincodeRegInfo{ChaincodeRegInfo: &ehpb.ChaincodeReg{ChaincodeID: "0xffffffff", EventName: "event1"}}},
 		&ehpb.Interest{EventType: ehpb.EventType_CHAINCODE, RegInfo: &ehpb.Interest_ChaincodeRegInfo{ChaincodeRegInfo: &ehpb.ChaincodeReg{ChaincodeID: "0xffffffff", EventName: "event2"}}},
 	}, nil
@@ -65,6 +67,11 @@ func (a *Adapter) Recv(msg *ehpb.Event) (bool, error) {
 	switch x := msg.Event.(type) {
 	case *ehpb.Event_Block, *ehpb.Event_ChaincodeEvent, *ehpb.Event_Register, *ehpb.Event_Unregister:
 		a.updateCountNotify()
+	case *ehpb.Event_Rejection:
+		a.Lock()
+		a.rejection = x.Rejection
+		a.Unlock()
+		a.updateCountNotify()
 	case nil:
 		// The field is not set.
 		return false, fmt.Errorf("event not set")
@@ -177,6 +184,30 @@ func TestReceiveCCWildcard(t *testing.T) {
 	}
 }
 
+func TestReceiveRejection(t *testing.T) {
+	adapter.count = 1
+	tx := &ehpb.Transaction{Txid: "rejectedTx"}
+	chaincodeError := &ehpb.ChaincodeError{Code: 402, Message: "insufficient balance", Details: "balance 5 is less than 10"}
+	if err := producer.Send(producer.CreateRejectionEvent(tx, chaincodeError)); err != nil {
+		t.Fatalf("Error sending message %s", err)
+	}
+
+	select {
+	case <-adapter.notfy:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out on rejection")
+	}
+	adapter.RLock()
+	rejection := adapter.rejection
+	adapter.RUnlock()
+	if rejection.Tx.Txid != "rejectedTx" || rejection.ErrorMsg != chaincodeError.Error() {
+		t.Fatalf("Unexpected rejection %v", rejection)
+	}
+	if rejection.ChaincodeError == nil || rejection.ChaincodeError.Code != 402 || rejection.ChaincodeError.Details != chaincodeError.Details {
+		t.Fatalf("Expected the structured chaincode error in the rejection, got %v", rejection.ChaincodeError)
+	}
+}
+
 func TestFailReceive(t *testing.T) {
 	var err error
 
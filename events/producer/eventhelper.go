@@ -30,7 +30,8 @@ func CreateChaincodeEvent(te *ehpb.ChaincodeEvent) *ehpb.Event {
 	return &ehpb.Event{Event: &ehpb.Event_ChaincodeEvent{ChaincodeEvent: te}}
 }
 
-//CreateRejectionEvent creates an Event from TxResults
-func CreateRejectionEvent(tx *ehpb.Transaction, errorMsg string) *ehpb.Event {
-	return &ehpb.Event{Event: &ehpb.Event_Rejection{Rejection: &ehpb.Rejection{Tx: tx, ErrorMsg: errorMsg}}}
+//CreateRejectionEvent creates an Event from the error a transaction failed
+//with, keeping the structure of a ChaincodeError
+func CreateRejectionEvent(tx *ehpb.Transaction, err error) *ehpb.Event {
+	return &ehpb.Event{Event: &ehpb.Event_Rejection{Rejection: &ehpb.Rejection{Tx: tx, ErrorMsg: err.Error(), ChaincodeError: ehpb.ChaincodeErrorOf(err)}}}
 }
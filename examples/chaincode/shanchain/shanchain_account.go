@@ -0,0 +1,363 @@
+/*
+	author:krew
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Account status. An issuer may freeze a user (no exchange or transfer in or
+// out until unfrozen) or close the account for good; closing moves what is
+// left of the balance back to the pool the user belongs to. Every change is
+// kept as an AuditRecord naming the caller, the reason code and the time.
+const (
+	statusKeyType = "status" //账户状态
+	auditKeyType  = "audit"  //账户审计记录
+
+	statusActive = "active" //正常
+	statusFrozen = "frozen" //冻结
+	statusClosed = "closed" //注销
+)
+
+// reasonCodes are the reasons an account may be frozen, unfrozen or closed for.
+var reasonCodes = []string{"fraud", "dispute", "compliance", "customer_request", "dormant", "resolved", "other"}
+
+// AccountStatus is the current status of a user's account.
+type AccountStatus struct {
+	ID     string //用户id
+	Status string //状态: active, frozen, closed
+	Reason string //原因代码
+	By     string //操作人id
+	Time   int64  //操作时间戳
+}
+
+// AuditRecord is one status change of a user's account.
+type AuditRecord struct {
+	TxID      string //交易流水号
+	UserID    string //用户id
+	Action    string //操作: freezeUser, unfreezeUser, closeUser
+	Reason    string //原因代码
+	By        string //操作人id
+	Time      int64  //操作时间戳
+	Reclaimed int    //注销时回收积分
+}
+
+// UserHistory is the committed changes of a user, oldest first. The peer keeps
+// no history for the blocks it got through state transfer or a state snapshot
+// import, so the records are complete only from block CompleteFromBlock on.
+type UserHistory struct {
+	CompleteFromBlock uint64              //完整记录的起始区块号
+	Records           []UserHistoryRecord //变更记录
+}
+
+// UserHistoryRecord is one committed change of a user, e.g. of its balance.
+type UserHistoryRecord struct {
+	TxID        string //交易流水号
+	BlockNumber uint64 //区块号
+	Time        int64  //交易时间戳
+	Deleted     bool   //是否删除
+	User        *User  //变更后的用户
+}
+
+func statusKey(userID string) string {
+	return stateKey(statusKeyType, userID)
+}
+
+func auditKey(userID string, time int64, tsID string) string {
+	return stateKey(auditKeyType, userID, fmt.Sprintf(timeKeyFormat, time), tsID)
+}
+
+/**
+ * [getAccountStatus returns a user's account status, active when none was ever set]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, userID string) (AccountStatus, error [description]
+ * @return {[type]}      [description]
+ */
+func getAccountStatus(stub shim.ChaincodeStubInterface, userID string) (AccountStatus, error) {
+	status := AccountStatus{ID: userID, Status: statusActive}
+	statusBytes, err := stub.GetState(statusKey(userID))
+	if err != nil {
+		return status, errors.New("GetState Error" + err.Error())
+	}
+	if statusBytes == nil {
+		return status, nil
+	}
+	err = json.Unmarshal(statusBytes, &status)
+	if err != nil {
+		return status, errors.New("Error unmarshalling status")
+	}
+	return status, nil
+}
+
+/**
+ * [checkActive fails when a user's account is frozen or closed]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, userID string) (error [description]
+ * @return {[type]}      [description]
+ */
+func checkActive(stub shim.ChaincodeStubInterface, userID string) error {
+	status, err := getAccountStatus(stub, userID)
+	if err != nil {
+		return err
+	}
+	if status.Status != statusActive {
+		return errors.New("user " + userID + " is " + status.Status)
+	}
+	return nil
+}
+
+/**
+ * [setAccountStatus moves a user's account from status from (any but closed when empty) to status to]
+ * args: userID, reason code; returns the audit record for the caller to complete and write
+ * @param  {[type]} stub shim.ChaincodeStubInterface, action string, args []string, from string, to string) (User, AuditRecord, error [description]
+ * @return {[type]}      [description]
+ */
+func setAccountStatus(stub shim.ChaincodeStubInterface, action string, args []string, from string, to string) (User, AuditRecord, error) {
+	var (
+		user   User
+		record AuditRecord
+	)
+	if len(args) != 2 {
+		return user, record, errors.New("Incorrect number of arguments. Expecting 2")
+	}
+	err := checkIssuer(stub)
+	if err != nil {
+		return user, record, err
+	}
+	userID, reason := args[0], args[1]
+	if !containsString(reasonCodes, reason) {
+		return user, record, errors.New("unknown reason code " + reason)
+	}
+	user, userBytes, err := getUser(stub, userID)
+	if err != nil {
+		return user, record, errors.New("get user errors")
+	}
+	if userBytes == nil {
+		return user, record, errors.New("user " + userID + " does not exist")
+	}
+	status, err := getAccountStatus(stub, userID)
+	if err != nil {
+		return user, record, err
+	}
+	if status.Status == statusClosed || (from != "" && status.Status != from) {
+		return user, record, errors.New("user " + userID + " is " + status.Status)
+	}
+	by, err := stub.ReadCertAttribute(userIDAttribute)
+	if err != nil {
+		return user, record, errors.New("Failed fetching caller userid" + err.Error())
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return user, record, err
+	}
+
+	status = AccountStatus{ID: userID, Status: to, Reason: reason, By: string(by), Time: now}
+	statusBytes, err := json.Marshal(&status)
+	if err != nil {
+		return user, record, errors.New("Error retrieving statusBytes")
+	}
+	err = stub.PutState(statusKey(userID), statusBytes)
+	if err != nil {
+		return user, record, errors.New("PutState Error" + err.Error())
+	}
+	record = AuditRecord{TxID: stub.GetTxID(), UserID: userID, Action: action, Reason: reason, By: string(by), Time: now}
+	return user, record, nil
+}
+
+/**
+ * [writeAuditRecord stores an audit record under its user]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, record AuditRecord) ([]byte, error [description]
+ * @return {[type]}      [description]
+ */
+func writeAuditRecord(stub shim.ChaincodeStubInterface, record AuditRecord) ([]byte, error) {
+	recordBytes, err := json.Marshal(&record)
+	if err != nil {
+		return nil, errors.New("Error retrieving recordBytes")
+	}
+	err = stub.PutState(auditKey(record.UserID, record.Time, record.TxID), recordBytes)
+	if err != nil {
+		return nil, errors.New("PutState Error" + err.Error())
+	}
+	return recordBytes, nil
+}
+
+/**
+ * [freezeUser stops a user from exchanging and from sending or receiving transfers]
+ * args: userID, reason code
+ * @param  {[type]} t *ShanChainAPI) freezeUser(stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}   [description]
+ */
+func (t *ShanChainAPI) freezeUser(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	_, record, err := setAccountStatus(stub, "freezeUser", args, statusActive, statusFrozen)
+	if err != nil {
+		return nil, err
+	}
+	return writeAuditRecord(stub, record)
+}
+
+/**
+ * [unfreezeUser lifts the freeze of a user]
+ * args: userID, reason code
+ * @param  {[type]} t *ShanChainAPI) unfreezeUser(stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}   [description]
+ */
+func (t *ShanChainAPI) unfreezeUser(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	_, record, err := setAccountStatus(stub, "unfreezeUser", args, statusFrozen, statusActive)
+	if err != nil {
+		return nil, err
+	}
+	return writeAuditRecord(stub, record)
+}
+
+/**
+ * [closeUser closes a user's account for good and reclaims its balance into the user's pool]
+ * args: userID, reason code; frozen accounts may be closed too
+ * @param  {[type]} t *ShanChainAPI) closeUser(stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}   [description]
+ */
+func (t *ShanChainAPI) closeUser(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	user, record, err := setAccountStatus(stub, "closeUser", args, "", statusClosed)
+	if err != nil {
+		return nil, err
+	}
+	pool, poolType, err := userPool(stub, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	record.Reclaimed = user.Integral
+	pool.RestIntegral = pool.RestIntegral + user.Integral
+	user.Integral = 0
+	err = writePool(stub, pool, poolType)
+	if err != nil {
+		return nil, errors.New("write root errors" + err.Error())
+	}
+	err = writeUser(stub, user)
+	if err == nil {
+		err = writeLots(stub, user.ID, nil)
+	}
+	if err != nil {
+		return nil, errors.New("writeUser Error" + err.Error())
+	}
+	if record.Reclaimed > 0 {
+		transaction := Transaction{ID: record.TxID, Integral: record.Reclaimed, FromType: 1, FromID: user.ID,
+			ToType: poolType, ToID: pool.ID, Time: record.Time}
+		err = writeTransaction(stub, transaction)
+		if err == nil {
+			err = writeTransactionIndexes(stub, transaction)
+		}
+		if err != nil {
+			return nil, errors.New("write transaction Error" + err.Error())
+		}
+	}
+	err = emitBalanceEvent(stub, BalanceEvent{Type: "closeUser", Delta: record.Reclaimed, FromID: user.ID, ToID: pool.ID,
+		Time: record.Time, Balances: []PartyBalance{{ID: user.ID, Integral: 0}, {ID: pool.ID, Integral: pool.RestIntegral}}})
+	if err != nil {
+		return nil, err
+	}
+	return writeAuditRecord(stub, record)
+}
+
+/**
+ * [getUserStatus returns a user's account status]
+ * args: userID
+ * @param  {[type]} stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}      [description]
+ */
+func getUserStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 1")
+	}
+	_, userBytes, err := getUser(stub, args[0])
+	if err != nil {
+		return nil, errors.New("get user errors")
+	}
+	if userBytes == nil {
+		return nil, errors.New("user " + args[0] + " does not exist")
+	}
+	status, err := getAccountStatus(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	statusBytes, err := json.Marshal(&status)
+	if err != nil {
+		return nil, errors.New("Error retrieving statusBytes")
+	}
+	return statusBytes, nil
+}
+
+/**
+ * [getUserAudit returns the status changes of a user's account, oldest first]
+ * args: userID; audit keys carry the time so the range comes back in order
+ * @param  {[type]} stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}      [description]
+ */
+func getUserAudit(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 1")
+	}
+	iter, err := stub.RangeQueryState(stateKey(auditKeyType, args[0], ""), stateKey(auditKeyType, args[0], indexMaxRune))
+	if err != nil {
+		return nil, errors.New("RangeQueryState Error" + err.Error())
+	}
+	defer iter.Close()
+	records := []AuditRecord{}
+	for iter.HasNext() {
+		_, recordBytes, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("RangeQueryState Error" + err.Error())
+		}
+		var record AuditRecord
+		err = json.Unmarshal(recordBytes, &record)
+		if err != nil {
+			return nil, errors.New("Error unmarshalling audit record")
+		}
+		records = append(records, record)
+	}
+	recordsBytes, err := json.Marshal(records)
+	if err != nil {
+		return nil, errors.New("Error retrieving recordsBytes")
+	}
+	return recordsBytes, nil
+}
+
+/**
+ * [getUserHistory returns the committed changes of a user, oldest first]
+ * args: userID; built from the peer's key history, so it also covers changes
+ * made before the state deltas were pruned, but only from CompleteFromBlock on
+ * @param  {[type]} stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}      [description]
+ */
+func getUserHistory(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 1")
+	}
+	modifications, completeFromBlock, err := stub.GetHistoryForKey(userKey(args[0]))
+	if err != nil {
+		return nil, errors.New("GetHistoryForKey Error" + err.Error())
+	}
+	history := UserHistory{CompleteFromBlock: completeFromBlock, Records: []UserHistoryRecord{}}
+	for _, modification := range modifications {
+		record := UserHistoryRecord{TxID: modification.TxID, BlockNumber: modification.BlockNumber, Deleted: modification.IsDelete}
+		if modification.Timestamp != nil {
+			record.Time = modification.Timestamp.Seconds
+		}
+		if !modification.IsDelete {
+			var user User
+			err = json.Unmarshal(modification.Value, &user)
+			if err != nil {
+				return nil, errors.New("Error unmarshalling user")
+			}
+			record.User = &user
+		}
+		history.Records = append(history.Records, record)
+	}
+	historyBytes, err := json.Marshal(history)
+	if err != nil {
+		return nil, errors.New("Error retrieving historyBytes")
+	}
+	return historyBytes, nil
+}
@@ -9,7 +9,6 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
-	"time"
 
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 )
@@ -45,6 +44,13 @@ type Transaction struct {
 	Time int64  //发起交易时间戳
 }
 
+// codes of the errors returned with shim.Error, which the gateway maps to
+// HTTP responses
+const (
+	errCodeInsufficientBalance int32 = 1001 // the user has too few points
+	errCodeInsufficientPool int32 = 1002 // the merchant or the root has too few points
+)
+
 /**
  * [main description]
  * @return {[type]} [description]
@@ -83,6 +89,10 @@ func (t *ShanChainAPI) Init(stub shim.ChaincodeStubInterface, function string, a
 	if err != nil {
 		return nil, errors.New("writeRoot Error" + err.Error())
 	}
+	err = writeLayoutVersion(stub)
+	if err != nil {
+		return nil, errors.New("write layout version Error" + err.Error())
+	}
 	rootBytes, err  = json.Marshal(&root)
 	if err != nil {
 		return nil,errors.New("Error retrieving rootBytes")
@@ -101,9 +111,33 @@ func (t *ShanChainAPI) Invoke(stub shim.ChaincodeStubInterface, function string,
 	} else if function == "createUser" {
 		return t.createUser(stub, args)
 	} else if function == "exchange" {
+		if len(args) == 3 {
+			return idempotent(stub, function, args[:2], args[2], t.exchange)
+		}
 		return t.exchange(stub, args)
 	} else if function == "transfer" {
+		if len(args) == 4 {
+			return idempotent(stub, function, args[:3], args[3], t.transfer)
+		}
 		return t.transfer(stub, args)
+	} else if function == "migrate" {
+		return t.migrate(stub, args)
+	} else if function == "setPointExpiry" {
+		return t.setPointExpiry(stub, args)
+	} else if function == "expire" {
+		return t.expire(stub, args)
+	} else if function == "setExchangePolicy" {
+		return t.setExchangePolicy(stub, args)
+	} else if function == "createMerchant" {
+		return t.createMerchant(stub, args)
+	} else if function == "setTransferRule" {
+		return t.setTransferRule(stub, args)
+	} else if function == "freezeUser" {
+		return t.freezeUser(stub, args)
+	} else if function == "unfreezeUser" {
+		return t.unfreezeUser(stub, args)
+	} else if function == "closeUser" {
+		return t.closeUser(stub, args)
 	}
 	return nil, errors.New("Received unknown function invocation")
 }
@@ -144,6 +178,39 @@ func (t *ShanChainAPI) Query(stub shim.ChaincodeStubInterface, function string,
 			return nil, err
 		}
 		return tsBytes, nil
+	} else if function == "getUserTransactions" {
+		return getUserTransactions(stub, args)
+	} else if function == "getTransactionsByTime" {
+		return getTransactionsByTime(stub, args)
+	} else if function == "getUserBalance" {
+		return getUserBalance(stub, args)
+	} else if function == "getExchangePolicy" {
+		return getExchangePolicy(stub, args)
+	} else if function == "getExchangePolicyHistory" {
+		return getExchangePolicyHistory(stub, args)
+	} else if function == "getRequestStatus" {
+		return getRequestStatus(stub, args)
+	} else if function == "getMerchant" {
+		return getMerchantQuery(stub, args)
+	} else if function == "getTransferRule" {
+		if argsLength != 2 {
+			return nil, errors.New("Incorrect number of arguments. Expecting 2")
+		}
+		_, ruleBytes, err := getTransferRule(stub, args[0], args[1])
+		if err != nil {
+			return nil, err
+		}
+		return ruleBytes, nil
+	} else if function == "getSettlement" {
+		return getSettlement(stub, args)
+	} else if function == "getSettlements" {
+		return getSettlements(stub, args)
+	} else if function == "getUserStatus" {
+		return getUserStatus(stub, args)
+	} else if function == "getUserAudit" {
+		return getUserAudit(stub, args)
+	} else if function == "getUserHistory" {
+		return getUserHistory(stub, args)
 	}
 	return nil, nil
 }
@@ -155,11 +222,18 @@ func (t *ShanChainAPI) Query(stub shim.ChaincodeStubInterface, function string,
  * @return {[type]}   [description]
  */
 func (t *ShanChainAPI) additional(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 1 or 2")
+	}
+	err := checkIssuer(stub)
+	if err != nil {
+		return nil, err
+	}
 	var (
-		err error
 		number int
 		root Root
 		rootBytes []byte
+		now int64
 	)
 	number, err = strconv.Atoi(args[0])
 	if err != nil {
@@ -172,14 +246,36 @@ func (t *ShanChainAPI) additional(stub shim.ChaincodeStubInterface, args []strin
 	if err != nil {
 		return nil,errors.New("get root errors")
 	}
+	// with a merchant ID the points raise that merchant's budget instead
+	poolType := rootType
+	if len(args) == 2 && args[1] != root.ID {
+		poolType = merchantType
+		var merchantBytes []byte
+		root, merchantBytes, err = getMerchant(stub, args[1])
+		if err != nil {
+			return nil, err
+		}
+		if merchantBytes == nil {
+			return nil, errors.New("merchant " + args[1] + " does not exist")
+		}
+	}
 	root.TotalIntegral = root.TotalIntegral + number
 	root.RestIntegral = root.RestIntegral + number
-	err = writeRoot(stub, root)
+	err = writePool(stub, root, poolType)
 	if err != nil {
 		root.TotalIntegral = root.TotalIntegral - number
 		root.RestIntegral = root.RestIntegral - number
 		return nil, errors.New("writeRoot Error" + err.Error())
 	}
+	now, err = txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	err = emitBalanceEvent(stub, BalanceEvent{Type : "additional", Delta : number, ToID : root.ID, Time : now,
+		Balances : []PartyBalance{{ID : root.ID, Integral : root.RestIntegral}}})
+	if err != nil {
+		return nil, err
+	}
 	rootBytes, err  = json.Marshal(&root)
 	if err != nil {
 		return nil,errors.New("Error retrieving rootBytes")
@@ -194,26 +290,101 @@ func (t *ShanChainAPI) additional(stub shim.ChaincodeStubInterface, args []strin
  * @return {[type]}   [description]
  */
 func (t *ShanChainAPI) createUser(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	if len(args) != 3 {
-		return nil, errors.New("Incorrect number of arguments. Expecting 3")
+	if len(args) != 3 && len(args) != 4 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 3 or 4")
+	}
+	var err error
+	if len(args) == 4 {
+		err = checkMerchantAdmin(stub, args[3])
+	} else {
+		err = checkIssuer(stub)
+	}
+	if err != nil {
+		return nil, err
 	}
 	var user User
 	var id string
 	var name string
 	var integral int
 	var userBytes []byte
+	var lots []Lot
+	var now int64
+	var pool Root
+	var poolType int
 
 	id = args[0]
 	name = args[1]
-	integral, err := strconv.Atoi(args[2])
+	integral, err = strconv.Atoi(args[2])
 	if err != nil {
 		return nil, errors.New("Expecting integer value for asset holding")
 	}
+	_, userBytes, err = getUser(stub, id)
+	if err != nil {
+		return nil, errors.New("get user errors")
+	}
+	if userBytes != nil {
+		return nil, errors.New("user " + id + " already exists")
+	}
+	if integral < 0 {
+		return nil, errors.New("want positive Integer number")
+	}
+	// the opening balance comes out of the pool of the user's merchant, or the root
+	if len(args) == 4 {
+		var poolBytes []byte
+		pool, poolBytes, err = getMerchant(stub, args[3])
+		if err != nil {
+			return nil, err
+		}
+		if poolBytes == nil {
+			return nil, errors.New("merchant " + args[3] + " does not exist")
+		}
+		poolType = merchantType
+	} else {
+		pool, _, err = getRoot(stub)
+		if err != nil {
+			return nil, errors.New("get root errors")
+		}
+		poolType = rootType
+	}
+	if pool.RestIntegral < integral {
+		if poolType == merchantType {
+			return nil, shim.Error(errCodeInsufficientPool, "Merchant " + pool.ID + " 剩余善圆不足", "")
+		}
+		return nil, shim.Error(errCodeInsufficientPool, "Root 剩余善圆不足", "")
+	}
+	now, err = txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	lots, err = issueLot(stub, lots, integral, now)
+	if err != nil {
+		return nil, err
+	}
+	pool.RestIntegral = pool.RestIntegral - integral
+	err = writePool(stub, pool, poolType)
+	if err != nil {
+		return nil, errors.New("write root errors" + err.Error())
+	}
 	user = User{ID : id, Name : name, Integral : integral}
 	err = writeUser(stub, user)
 	if err != nil {
 		return nil, errors.New("writeUser Error" + err.Error())
 	}
+	err = writeLots(stub, id, lots)
+	if err != nil {
+		return nil, errors.New("write lots Error" + err.Error())
+	}
+	if len(args) == 4 {
+		err = joinMerchant(stub, id, args[3])
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = emitBalanceEvent(stub, BalanceEvent{Type : "createUser", Delta : integral, FromID : pool.ID, ToID : id, Time : now,
+		Balances : []PartyBalance{{ID : pool.ID, Integral : pool.RestIntegral}, {ID : id, Integral : user.Integral}}})
+	if err != nil {
+		return nil, err
+	}
 	userBytes, err  = json.Marshal(&user)
 	if err != nil {
 		return nil, errors.New("Error retrieving userBytes")
@@ -229,7 +400,7 @@ func (t *ShanChainAPI) createUser(stub shim.ChaincodeStubInterface, args []strin
  */
 func (t * ShanChainAPI) exchange(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 2 {
-		return nil, errors.New("Incorrect number of arguments. Expecting 2")
+		return nil, errors.New("Incorrect number of arguments. Expecting 2 or 3")
 	}
 	var (
 		root Root
@@ -239,52 +410,112 @@ func (t * ShanChainAPI) exchange(stub shim.ChaincodeStubInterface, args []string
 		err error
 		transaction Transaction
 		tsBytes []byte
+		userBytes []byte
 		id string
+		lots []Lot
+		now int64
+		steps int
+		fromType int
 	)
 	receiverID = args[0]
-	number, err = strconv.Atoi(args[1])
+	err = checkOwner(stub, receiverID)
+	if err != nil {
+		return nil, err
+	}
+	steps, err = strconv.Atoi(args[1])
 	if err != nil {
 		return nil,errors.New("want Integer number")
 	}
-	root, _, err = getRoot(stub)
+	if steps <= 0 {
+		return nil,errors.New("want positive Integer number")
+	}
+	now, err = txTime(stub)
 	if err != nil {
-		return nil,errors.New("get root errors")
+		return nil, err
 	}
-	if root.RestIntegral < number {
-		return nil,errors.New("Root 剩余善圆不足")
+	number, err = exchangePoints(stub, receiverID, steps, now)
+	if err != nil {
+		return nil, err
 	}
-	user, _, err = getUser(stub, receiverID)
+	user, userBytes, err = getUser(stub, receiverID)
 	if err != nil {
 		return nil,errors.New("get user errors")
 	}
+	if userBytes == nil {
+		return nil, errors.New("user " + receiverID + " does not exist")
+	}
+	err = checkActive(stub, receiverID)
+	if err != nil {
+		return nil, err
+	}
+	// the points come out of the pool of the user's merchant, or the root
+	root, fromType, err = userPool(stub, receiverID)
+	if err != nil {
+		return nil, err
+	}
+	if root.RestIntegral < number {
+		if fromType == merchantType {
+			return nil, shim.Error(errCodeInsufficientPool, "Merchant " + root.ID + " 剩余善圆不足", "")
+		}
+		return nil, shim.Error(errCodeInsufficientPool, "Root 剩余善圆不足", "")
+	}
+	id = stub.GetTxID()
+	_, tsBytes, err = getTransaction(stub, id)
+	if err != nil {
+		return nil, errors.New("get transaction errors")
+	}
+	if tsBytes != nil {
+		return nil, errors.New("transaction " + id + " already exists")
+	}
+	lots, err = getLots(stub, user)
+	if err != nil {
+		return nil, err
+	}
+	lots, err = issueLot(stub, lots, number, now)
+	if err != nil {
+		return nil, err
+	}
 
 	root.RestIntegral = root.RestIntegral - number
 	user.Integral = user.Integral + number
 
-	err = writeRoot(stub, root)
+	err = writePool(stub, root, fromType)
 	if err != nil {
-		root.RestIntegral = root.RestIntegral - number
-		user.Integral = user.Integral - number
 		return nil, errors.New("write root errors" + err.Error())
 	}
 	err = writeUser(stub, user)
 	if err != nil {
-		root.RestIntegral = root.RestIntegral - number
-		user.Integral = user.Integral - number
-		err = writeRoot(stub, root)
-		if err != nil {
-			return nil, errors.New("roll down errors" + err.Error())
-		}
-		return nil, err
+		return nil, errors.New("writeUser Error" + err.Error())
+	}
+	err = writeLots(stub, receiverID, lots)
+	if err != nil {
+		return nil, errors.New("write lots Error" + err.Error())
+	}
+	err = addDailyExchanged(stub, receiverID, now, number)
+	if err != nil {
+		return nil, errors.New("write daily exchange Error" + err.Error())
 	}
 	
-	id = stub.GetTxID()
-	transaction = Transaction{ID : id, Integral : number, FromType : 0, FromID : "0001", ToType : 1, ToID : receiverID, Time : time.Now().Unix()}
+	transaction = Transaction{ID : id, Step : steps, Integral : number, FromType : fromType, FromID : root.ID, ToType : 1, ToID : receiverID, Time : now}
 	
 	err = writeTransaction(stub, transaction)
 	if err != nil {
 		return nil, errors.New("write transaction Error" + err.Error())
 	}
+	err = writeTransactionIndexes(stub, transaction)
+	if err != nil {
+		return nil, errors.New("write transaction index Error" + err.Error())
+	}
+	err = writeFlow(stub, Flow{TxID : id, Type : "exchange", FromMerchant : root.ID, ToMerchant : root.ID, Integral : number, Time : now})
+	if err != nil {
+		return nil, errors.New("write flow Error" + err.Error())
+	}
+	err = emitBalanceEvent(stub, BalanceEvent{Type : "exchange", Delta : number, FromID : root.ID, ToID : receiverID,
+		Step : transaction.Step, Time : now,
+		Balances : []PartyBalance{{ID : root.ID, Integral : root.RestIntegral}, {ID : receiverID, Integral : user.Integral}}})
+	if err != nil {
+		return nil, err
+	}
 	tsBytes, err = json.Marshal(&transaction)
 	if err != nil {
 		return nil, err
@@ -300,7 +531,7 @@ func (t * ShanChainAPI) exchange(stub shim.ChaincodeStubInterface, args []string
  */
 func (t *ShanChainAPI) transfer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error){
 	if len(args) != 3 {
-		return nil, errors.New("Incorrect number of arguments. Expecting 3")
+		return nil, errors.New("Incorrect number of arguments. Expecting 3 or 4")
 	}
 	var (
 		sender User
@@ -311,49 +542,141 @@ func (t *ShanChainAPI) transfer(stub shim.ChaincodeStubInterface, args []string)
 		err error
 		transaction Transaction
 		tsBytes []byte
+		userBytes []byte
 		id string
+		root Root
+		receiverPool Root
+		poolType int
+		senderLots []Lot
+		receiverLots []Lot
+		taken []Lot
+		expired int
+		now int64
 	)
 	senderID = args[0]
 	receiverID = args[1]
+	err = checkOwner(stub, senderID)
+	if err != nil {
+		return nil, err
+	}
 	number, err = strconv.Atoi(args[2])
 	if err != nil {
 		return nil,errors.New("want Integer number")
 	}
-	sender, _, err = getUser(stub, senderID)
+	if number <= 0 {
+		return nil,errors.New("want positive Integer number")
+	}
+	if senderID == receiverID {
+		return nil, errors.New("cannot transfer to the same user")
+	}
+	now, err = txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	sender, userBytes, err = getUser(stub, senderID)
 	if err != nil {
 		return nil,errors.New("get sender errors")
 	}
+	if userBytes == nil {
+		return nil, errors.New("user " + senderID + " does not exist")
+	}
+	err = checkActive(stub, senderID)
+	if err != nil {
+		return nil, err
+	}
+	// the sender's expired points go back to the sender's pool
+	root, poolType, err = userPool(stub, senderID)
+	if err != nil {
+		return nil, err
+	}
+	senderLots, err = getLots(stub, sender)
+	if err != nil {
+		return nil, err
+	}
+	// points that expired before this transfer can no longer be spent
+	expired, senderLots = expireUser(&sender, &root, senderLots, now)
 	if sender.Integral < number {
-		return nil,errors.New("用户 " + sender.Name +" 剩余善圆不足本次交易")
+		return nil, shim.Error(errCodeInsufficientBalance, "用户 " + sender.Name +" 剩余善圆不足本次交易", "")
 	}
-	receiver, _, err = getUser(stub, receiverID)
+	receiver, userBytes, err = getUser(stub, receiverID)
 	if err != nil {
 		return nil,errors.New("get receiver errors")
 	}
+	if userBytes == nil {
+		return nil, errors.New("user " + receiverID + " does not exist")
+	}
+	err = checkActive(stub, receiverID)
+	if err != nil {
+		return nil, err
+	}
+	receiverPool, _, err = userPool(stub, receiverID)
+	if err != nil {
+		return nil, err
+	}
+	err = checkTransferRule(stub, root.ID, receiverPool.ID)
+	if err != nil {
+		return nil, err
+	}
+	id = stub.GetTxID()
+	_, tsBytes, err = getTransaction(stub, id)
+	if err != nil {
+		return nil, errors.New("get transaction errors")
+	}
+	if tsBytes != nil {
+		return nil, errors.New("transaction " + id + " already exists")
+	}
+	receiverLots, err = getLots(stub, receiver)
+	if err != nil {
+		return nil, err
+	}
+	taken, senderLots = takeLots(senderLots, number)
+	receiverLots = mergeLots(receiverLots, taken)
 	sender.Integral = sender.Integral - number
 	receiver.Integral = receiver.Integral + number
+	if expired > 0 {
+		err = writePool(stub, root, poolType)
+		if err != nil {
+			return nil, errors.New("write root errors" + err.Error())
+		}
+	}
 	err = writeUser(stub, sender)
+	if err == nil {
+		err = writeLots(stub, senderID, senderLots)
+	}
 	if err != nil {
-		sender.Integral = sender.Integral + number
-		receiver.Integral = receiver.Integral - number
-		return nil, errors.New("write root errors" + err.Error())
+		return nil, errors.New("write sender errors" + err.Error())
 	}
 	err = writeUser(stub, receiver)
+	if err == nil {
+		err = writeLots(stub, receiverID, receiverLots)
+	}
 	if err != nil {
-		sender.Integral = sender.Integral + number
-		receiver.Integral = receiver.Integral - number
-		err = writeUser(stub, sender)
-		if err != nil {
-			return nil, errors.New("roll down errors" + err.Error())
-		}
-		return nil, err
+		return nil, errors.New("write receiver errors" + err.Error())
 	}
-	id = stub.GetTxID()
-	transaction = Transaction{ID : id, Integral : number, FromType : 1, FromID : senderID, ToType : 1, ToID : receiverID, Time : time.Now().Unix()}
+	transaction = Transaction{ID : id, Integral : number, FromType : 1, FromID : senderID, ToType : 1, ToID : receiverID, Time : now}
 	err = writeTransaction(stub, transaction)
 	if err != nil {
 		return nil, errors.New("write transaction Error" + err.Error())
 	}
+	err = writeTransactionIndexes(stub, transaction)
+	if err != nil {
+		return nil, errors.New("write transaction index Error" + err.Error())
+	}
+	if root.ID != receiverPool.ID {
+		err = writeFlow(stub, Flow{TxID : id, Type : "transfer", FromMerchant : root.ID, ToMerchant : receiverPool.ID, Integral : number, Time : now})
+		if err != nil {
+			return nil, errors.New("write flow Error" + err.Error())
+		}
+	}
+	event := BalanceEvent{Type : "transfer", Delta : number, Expired : expired, FromID : senderID, ToID : receiverID,
+		Time : now, Balances : []PartyBalance{{ID : senderID, Integral : sender.Integral}, {ID : receiverID, Integral : receiver.Integral}}}
+	if expired > 0 {
+		event.Balances = append(event.Balances, PartyBalance{ID : root.ID, Integral : root.RestIntegral})
+	}
+	err = emitBalanceEvent(stub, event)
+	if err != nil {
+		return nil, err
+	}
 	tsBytes, err = json.Marshal(&transaction)
 	if err != nil {
 		return nil, err
@@ -374,7 +697,7 @@ func writeRoot(stub shim.ChaincodeStubInterface, root Root) (error) {
 	if err != nil {
 		return err
 	}
-	err = stub.PutState("root", rootBytes)
+	err = stub.PutState(rootKey(), rootBytes)
 	if err != nil {
 		return errors.New("PutState Error" + err.Error())
 	}
@@ -389,7 +712,7 @@ func writeRoot(stub shim.ChaincodeStubInterface, root Root) (error) {
  */
 func getRoot(stub shim.ChaincodeStubInterface) (Root, []byte, error) {
 	var root Root
-	rootBytyes, err := stub.GetState("root")
+	rootBytyes, err := stub.GetState(rootKey())
 	if err != nil {
 		fmt.Println("Error retrieving rootBytyes")
 	}
@@ -413,7 +736,7 @@ func writeUser(stub shim.ChaincodeStubInterface, user User) (error) {
 	if err != nil {
 		return err
 	}
-	err = stub.PutState(id, userBytes)
+	err = stub.PutState(userKey(id), userBytes)
 	if err != nil {
 		return errors.New("PutState Error" + err.Error())
 	}
@@ -428,7 +751,7 @@ func writeUser(stub shim.ChaincodeStubInterface, user User) (error) {
  */
 func getUser(stub shim.ChaincodeStubInterface, id string) (User, []byte, error) {
 	var user User
-	userBytes, err := stub.GetState(id)
+	userBytes, err := stub.GetState(userKey(id))
 	if err != nil {
 		fmt.Println("Error retrieving userBytes")
 	}
@@ -453,7 +776,7 @@ func writeTransaction(stub shim.ChaincodeStubInterface,transaction Transaction)
 		return err
 	}
 	tsID = transaction.ID
-	err = stub.PutState(tsID, tsBytes)
+	err = stub.PutState(transactionKey(tsID), tsBytes)
 	if err != nil {
 		return errors.New("PutState Error" + err.Error())
 	}
@@ -468,7 +791,7 @@ func writeTransaction(stub shim.ChaincodeStubInterface,transaction Transaction)
  */
 func getTransaction(stub shim.ChaincodeStubInterface, tsID string) (Transaction, []byte, error) {
 	var transaction Transaction
-	tsBytes, err := stub.GetState(tsID)
+	tsBytes, err := stub.GetState(transactionKey(tsID))
 	if err != nil {
 		fmt.Println("Error retrieving tsBytes")
 	}
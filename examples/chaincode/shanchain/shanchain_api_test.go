@@ -3,9 +3,12 @@ package main
 import (
   "encoding/json"
   "fmt"
+  "strings"
   "testing"
 
+  "github.com/golang/protobuf/ptypes/timestamp"
   "github.com/hyperledger/fabric/core/chaincode/shim"
+  pb "github.com/hyperledger/fabric/protos"
 )
 
 type Transaction2 struct {
@@ -18,6 +21,44 @@ type Transaction2 struct {
   ToID string //交易接收方id
 }
 
+// testNow is the transaction time used unless a test picks its own.
+const testNow = 1477000000
+
+// lastEvent is the chaincode event set by the latest invoke.
+var lastEvent struct {
+  name string
+  payload []byte
+}
+
+var issuer = map[string]string{"role": "issuer", "userid": "0001"}
+
+func client(id string) map[string]string {
+  return map[string]string{"role": "client", "userid": id}
+}
+
+func invokeAs(stub *shim.MockStub, attrs map[string]string, uuid string, function string, args []string) ([]byte, error) {
+  return invokeAt(stub, attrs, testNow, uuid, function, args)
+}
+
+// invokeAt invokes as an enrolled caller with attributes attrs at time now.
+func invokeAt(stub *shim.MockStub, attrs map[string]string, now int64, uuid string, function string, args []string) ([]byte, error) {
+  attributes := map[string][]byte{}
+  for name, value := range attrs {
+    attributes[name] = []byte(value)
+  }
+  stub.MockCaller(shim.MockIdentity{Attributes: attributes})
+  stub.MockTxTimestamp(&timestamp.Timestamp{Seconds: now})
+  events := len(stub.Events)
+  bytes, err := stub.MockInvoke(uuid, function, args)
+  lastEvent.name = ""
+  lastEvent.payload = nil
+  if len(stub.Events) > events {
+    lastEvent.name = stub.Events[events].EventName
+    lastEvent.payload = stub.Events[events].Payload
+  }
+  return bytes, err
+}
+
 func checkInit(t *testing.T, stub *shim.MockStub, args []string) (error) {
   _, err := stub.MockInit("1", "init", args)
   return err
@@ -41,7 +82,7 @@ func checkState(t *testing.T, stub *shim.MockStub, name string, value string) {
 }
 
 func checkCreateUser(t *testing.T, stub *shim.MockStub, args []string) (error) {
-  _, err := stub.MockInvoke("1", "createUser", args)
+  _, err := invokeAs(stub, issuer, "1", "createUser", args)
   return err
 }
 
@@ -56,17 +97,17 @@ func checkGetRoot(t *testing.T, stub *shim.MockStub, args []string) ([]byte, err
 }
 
 func checkAdditional(t *testing.T, stub *shim.MockStub, args []string) ([]byte, error) {
-  ts, err := stub.MockInvoke("1", "additional", args)
+  ts, err := invokeAs(stub, issuer, "1", "additional", args)
   return ts, err
 }
 
 func checkExchange(t *testing.T, stub *shim.MockStub, args []string) ([]byte, error) {
-  ts, err := stub.MockInvoke("1", "exchange", args)
+  ts, err := invokeAs(stub, client(args[0]), "1", "exchange", args)
   return ts, err
 }
 
 func checkTransfer(t *testing.T, stub *shim.MockStub, args []string) ([]byte, error) {
-  ts, err := stub.MockInvoke("1", "transfer", args)
+  ts, err := invokeAs(stub, client(args[0]), "1", "transfer", args)
   return ts, err
 }
 
@@ -101,7 +142,7 @@ func TestShanchain_Init(t *testing.T) {
   stub := shim.NewMockStub("shanchain_api", scc)
   // Init RootName=shanchain , InitIntegral=50000
   checkInit(t, stub, []string{"shanchain", "50000"})
-  checkState(t, stub, "root", "{\"ID\":\"0001\",\"Name\":\"shanchain\",\"TotalIntegral\":50000,\"RestIntegral\":50000}")
+  checkState(t, stub, rootKey(), "{\"ID\":\"0001\",\"Name\":\"shanchain\",\"TotalIntegral\":50000,\"RestIntegral\":50000}")
 }
 
 func TestShanchain_Init_Incorrect_arguments(t *testing.T) {
@@ -177,6 +218,7 @@ func TestShanchain_GetRoot_Incorrect_arguments(t *testing.T) {
 func TestShanchain_CreateUser(t *testing.T) {
   scc := new(ShanChainAPI)
   stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
   err := checkCreateUser(t, stub, []string{"10086", "china mobile", "100"})
   if err != nil {
     t.FailNow()
@@ -186,9 +228,9 @@ func TestShanchain_CreateUser(t *testing.T) {
 func TestShanchain_CreateUser_Incorrect_arguments(t *testing.T) {
   scc := new(ShanChainAPI)
   stub := shim.NewMockStub("shanchain_api", scc)
-  err := checkCreateUser(t, stub, []string{"10086", "china mobile", "100", "name"})
+  err := checkCreateUser(t, stub, []string{"10086", "china mobile", "100", "0002", "name"})
   if err != nil {
-    if err.Error() == "Incorrect number of arguments. Expecting 3" {
+    if err.Error() == "Incorrect number of arguments. Expecting 3 or 4" {
       t.SkipNow()
     }
   }
@@ -210,6 +252,7 @@ func TestShanchain_CreateUser_Invalid_argument(t *testing.T) {
 func TestShanchain_GetUser(t *testing.T) {
   scc := new(ShanChainAPI)
   stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
 
   checkCreateUser(t, stub, []string{"10086", "china mobile", "100"})
   bytes, err := checkGetUser(t, stub, []string{"10086"})
@@ -227,6 +270,7 @@ func TestShanchain_GetUser(t *testing.T) {
 func TestShanchain_GetUser_Incorrect_arguments(t *testing.T) {
   scc := new(ShanChainAPI)
   stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
 
   checkCreateUser(t, stub, []string{"10086", "china mobile", "100"})
   _, err := checkGetUser(t, stub, []string{"10086", "hello"})
@@ -308,9 +352,9 @@ func TestShanchain_Exchange_Incorrect_arguments(t *testing.T) {
   stub := shim.NewMockStub("shanchain_api", scc)
   checkInit(t, stub, []string{"shanchain", "50000"})
   checkCreateUser(t, stub, []string{"10086", "china mobile", "100"})
-  _, err := checkExchange(t, stub, []string{"10086", "900", "more"})
+  _, err := checkExchange(t, stub, []string{"10086", "900", "req1", "more"})
   if err != nil {
-    if err.Error() == "Incorrect number of arguments. Expecting 2" {
+    if err.Error() == "Incorrect number of arguments. Expecting 2 or 3" {
       t.SkipNow()
     }
   }
@@ -395,6 +439,20 @@ func TestShanchain_Transfer_Invalid_argument2(t *testing.T) {
   t.FailNow()
 }
 
+func TestShanchain_Transfer_ErrorCode(t *testing.T) {
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  checkCreateUser(t, stub, []string{"10086", "china mobile", "1000"})
+  checkCreateUser(t, stub, []string{"10000", "china unicom", "1000"})
+  _, err := checkTransfer(t, stub, []string{"10086", "10000", "3000"})
+  chaincodeError, ok := err.(*pb.ChaincodeError)
+  if !ok || chaincodeError.Code != errCodeInsufficientBalance {
+    fmt.Println("expected an insufficient balance error, got", err)
+    t.FailNow()
+  }
+}
+
 
 func TestShanchain_Transaction(t *testing.T) {
   scc := new(ShanChainAPI)
@@ -405,3 +463,752 @@ func TestShanchain_Transaction(t *testing.T) {
   checkTransfer(t, stub, []string{"10086", "10000", "200"})
   checkTransaction(t, stub, []string{"1"},"{\"ID\":\"1\",\"Step\":0,\"Integral\":200,\"FromType\":1,\"FromID\":\"10086\",\"ToType\":1,\"ToID\":\"10000\"}")
 }
+
+func checkUserTransactions(t *testing.T, stub *shim.MockStub, args []string) (TransactionPage) {
+  var page TransactionPage
+  bytes, err := stub.MockQuery("getUserTransactions", args)
+  if err != nil {
+    fmt.Println("Query", args, "failed", err)
+    t.FailNow()
+  }
+  err = json.Unmarshal(bytes, &page)
+  if err != nil {
+    fmt.Println("Error unmarshalling transaction page")
+    t.FailNow()
+  }
+  return page
+}
+
+func TestShanchain_UserTransactions(t *testing.T) {
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  checkCreateUser(t, stub, []string{"10086", "china mobile", "1000"})
+  checkCreateUser(t, stub, []string{"10000", "china unicom", "1000"})
+  checkCreateUser(t, stub, []string{"10010", "china telecom", "1000"})
+  invokeAs(stub, client("10086"), "tx1", "transfer", []string{"10086", "10000", "100"})
+  invokeAs(stub, client("10086"), "tx2", "exchange", []string{"10086", "200"})
+  invokeAs(stub, client("10000"), "tx3", "transfer", []string{"10000", "10010", "300"})
+  invokeAs(stub, client("10010"), "tx4", "transfer", []string{"10010", "10086", "400"})
+
+  page := checkUserTransactions(t, stub, []string{"10086", "0", "9999999999", "2"})
+  if len(page.Transactions) != 2 || page.Cursor == "" {
+    fmt.Println("Expected 2 transactions and a cursor, got", page)
+    t.FailNow()
+  }
+  ids := page.Transactions[0].ID + page.Transactions[1].ID
+  page = checkUserTransactions(t, stub, []string{"10086", "0", "9999999999", "2", page.Cursor})
+  if len(page.Transactions) != 1 || page.Cursor != "" {
+    fmt.Println("Expected 1 transaction and no cursor, got", page)
+    t.FailNow()
+  }
+  ids = ids + page.Transactions[0].ID
+  if ids != "tx1tx2tx4" {
+    fmt.Println("Expected transactions tx1tx2tx4, got", ids)
+    t.FailNow()
+  }
+
+  page = checkUserTransactions(t, stub, []string{"10010", "0", "9999999999", "10"})
+  if len(page.Transactions) != 2 || page.Transactions[0].ID != "tx3" || page.Transactions[1].ID != "tx4" {
+    fmt.Println("Expected transactions tx3 and tx4, got", page)
+    t.FailNow()
+  }
+
+  page = checkUserTransactions(t, stub, []string{"10010", "0", "1", "10"})
+  if len(page.Transactions) != 0 {
+    fmt.Println("Expected no transactions before time 1, got", page)
+    t.FailNow()
+  }
+}
+
+func TestShanchain_UserTransactions_Invalid_argument(t *testing.T) {
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  _, err := stub.MockQuery("getUserTransactions", []string{"10086", "0", "9999999999", "0"})
+  if err == nil || err.Error() != "want positive Integer pageSize" {
+    t.FailNow()
+  }
+  _, err = stub.MockQuery("getUserTransactions", []string{"10086", "0", "9999999999", "1", "cursor"})
+  if err == nil || err.Error() != "invalid cursor" {
+    t.FailNow()
+  }
+}
+
+// countingStub counts the transactions a query loads.
+type countingStub struct {
+  *shim.MockStub
+  loaded int
+}
+
+func (stub *countingStub) GetState(key string) ([]byte, error) {
+  if strings.HasPrefix(key, transactionKey("")) {
+    stub.loaded++
+  }
+  return stub.MockStub.GetState(key)
+}
+
+func TestShanchain_UserTransactions_PageSizeBoundsScan(t *testing.T) {
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  checkCreateUser(t, stub, []string{"10086", "china mobile", "1000"})
+  checkCreateUser(t, stub, []string{"10000", "china unicom", "1000"})
+  for i := 0; i < 20; i++ {
+    invokeAt(stub, client("10086"), testNow+int64(i), fmt.Sprintf("tx%02d", i), "transfer", []string{"10086", "10000", "1"})
+    invokeAt(stub, client("10000"), testNow+int64(i), fmt.Sprintf("rx%02d", i), "transfer", []string{"10000", "10086", "1"})
+  }
+
+  counting := &countingStub{MockStub: stub}
+  var ids []string
+  cursor := ""
+  for {
+    counting.loaded = 0
+    args := []string{"10086", "0", "9999999999", "3"}
+    if cursor != "" {
+      args = append(args, cursor)
+    }
+    bytes, err := getUserTransactions(counting, args)
+    if err != nil {
+      fmt.Println("Query", args, "failed", err)
+      t.FailNow()
+    }
+    if counting.loaded > 2*(3+1) {
+      fmt.Println("Expected at most 8 transactions loaded for a page of 3, got", counting.loaded)
+      t.FailNow()
+    }
+    var page TransactionPage
+    json.Unmarshal(bytes, &page)
+    for _, transaction := range page.Transactions {
+      ids = append(ids, transaction.ID)
+    }
+    if page.Cursor == "" {
+      break
+    }
+    cursor = page.Cursor
+  }
+  if len(ids) != 40 || ids[0] != "rx00" || ids[1] != "tx00" || ids[39] != "tx19" {
+    fmt.Println("Expected the 40 transactions in time order, got", ids)
+    t.FailNow()
+  }
+}
+
+func TestShanchain_CreateUser_Exists(t *testing.T) {
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  checkCreateUser(t, stub, []string{"10086", "china mobile", "100"})
+  err := checkCreateUser(t, stub, []string{"10086", "china unicom", "200"})
+  if err == nil || err.Error() != "user 10086 already exists" {
+    t.FailNow()
+  }
+}
+
+func TestShanchain_Key_Collision(t *testing.T) {
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  checkCreateUser(t, stub, []string{"root", "china mobile", "100"})
+  checkCreateUser(t, stub, []string{"10000", "china unicom", "100"})
+  _, err := invokeAs(stub, client("root"), "10000", "exchange", []string{"root", "900"})
+  if err != nil {
+    t.FailNow()
+  }
+  bytes, _ := checkGetRoot(t, stub, []string{})
+  if string(bytes) != "{\"ID\":\"0001\",\"Name\":\"shanchain\",\"TotalIntegral\":50000,\"RestIntegral\":48900}" {
+    t.FailNow()
+  }
+  bytes, _ = checkGetUser(t, stub, []string{"10000"})
+  if string(bytes) != "{\"ID\":\"10000\",\"Name\":\"china unicom\",\"Integral\":100}" {
+    t.FailNow()
+  }
+}
+
+func TestShanchain_Exchange_Unknown_user(t *testing.T) {
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  _, err := checkExchange(t, stub, []string{"10086", "900"})
+  if err == nil || err.Error() != "user 10086 does not exist" {
+    t.FailNow()
+  }
+}
+
+func TestShanchain_Transfer_Duplicate_transaction(t *testing.T) {
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  checkCreateUser(t, stub, []string{"10086", "china mobile", "1000"})
+  checkCreateUser(t, stub, []string{"10000", "china unicom", "1000"})
+  checkTransfer(t, stub, []string{"10086", "10000", "200"})
+  _, err := checkTransfer(t, stub, []string{"10086", "10000", "200"})
+  if err == nil || err.Error() != "transaction 1 already exists" {
+    t.FailNow()
+  }
+}
+
+func TestShanchain_Migrate(t *testing.T) {
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  // ledger written by the flat key layout
+  stub.MockTransactionStart("legacy")
+  stub.PutState("root", []byte("{\"ID\":\"0001\",\"Name\":\"shanchain\",\"TotalIntegral\":50000,\"RestIntegral\":49800}"))
+  stub.PutState("10086", []byte("{\"ID\":\"10086\",\"Name\":\"china mobile\",\"Integral\":200}"))
+  stub.PutState("tx1", []byte("{\"ID\":\"tx1\",\"Step\":0,\"Integral\":200,\"FromType\":0,\"FromID\":\"0001\",\"ToType\":1,\"ToID\":\"10086\",\"Time\":100}"))
+  stub.MockTransactionEnd("legacy")
+
+  _, err := invokeAs(stub, issuer, "2", "migrate", []string{})
+  if err != nil {
+    fmt.Println("migrate failed", err)
+    t.FailNow()
+  }
+  for _, key := range []string{"root", "10086", "tx1"} {
+    if stub.State[key] != nil {
+      fmt.Println("legacy key", key, "was not removed")
+      t.FailNow()
+    }
+  }
+  bytes, _ := checkGetRoot(t, stub, []string{})
+  if string(bytes) != "{\"ID\":\"0001\",\"Name\":\"shanchain\",\"TotalIntegral\":50000,\"RestIntegral\":49800}" {
+    t.FailNow()
+  }
+  bytes, _ = checkGetUser(t, stub, []string{"10086"})
+  if string(bytes) != "{\"ID\":\"10086\",\"Name\":\"china mobile\",\"Integral\":200}" {
+    t.FailNow()
+  }
+  page := checkUserTransactions(t, stub, []string{"10086", "0", "1000", "10"})
+  if len(page.Transactions) != 1 || page.Transactions[0].ID != "tx1" {
+    fmt.Println("Expected migrated transaction tx1, got", page)
+    t.FailNow()
+  }
+
+  _, err = invokeAs(stub, issuer, "3", "migrate", []string{})
+  if err == nil || err.Error() != "storage layout v1 already in use" {
+    t.FailNow()
+  }
+}
+
+func TestShanchain_Additional_Unauthorized(t *testing.T) {
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  _, err := invokeAs(stub, client("10086"), "1", "additional", []string{"10000"})
+  if err == nil || err.Error() != "caller is not issuer" {
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, client("10086"), "1", "createUser", []string{"10086", "china mobile", "100"})
+  if err == nil || err.Error() != "caller is not issuer" {
+    t.FailNow()
+  }
+}
+
+func TestShanchain_Transfer_Unauthorized(t *testing.T) {
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  checkCreateUser(t, stub, []string{"10086", "china mobile", "1000"})
+  checkCreateUser(t, stub, []string{"10000", "china unicom", "1000"})
+  _, err := invokeAs(stub, client("10000"), "1", "transfer", []string{"10086", "10000", "200"})
+  if err == nil || err.Error() != "caller is not the owner of user 10086" {
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, client("10000"), "2", "exchange", []string{"10086", "200"})
+  if err == nil || err.Error() != "caller is not the owner of user 10086" {
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, map[string]string{"role": "client"}, "3", "transfer", []string{"10086", "10000", "200"})
+  if err == nil {
+    t.FailNow()
+  }
+  bytes, _ := checkGetUser(t, stub, []string{"10086"})
+  if string(bytes) != "{\"ID\":\"10086\",\"Name\":\"china mobile\",\"Integral\":1000}" {
+    t.FailNow()
+  }
+}
+
+func checkBalance(t *testing.T, stub *shim.MockStub, id string, now int64, expect string) {
+  bytes, err := stub.MockQuery("getUserBalance", []string{id, fmt.Sprint(now)})
+  if err != nil {
+    fmt.Println("Query balance of", id, "failed", err)
+    t.FailNow()
+  }
+  if string(bytes) != expect {
+    fmt.Println("Balance", string(bytes), "was not", expect, "as expected")
+    t.FailNow()
+  }
+}
+
+func TestShanchain_Expiry(t *testing.T) {
+  day := int64(24 * 60 * 60)
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  checkCreateUser(t, stub, []string{"10086", "china mobile", "0"})
+  checkCreateUser(t, stub, []string{"10000", "china unicom", "0"})
+  _, err := invokeAs(stub, issuer, "1", "setPointExpiry", []string{"30"})
+  if err != nil {
+    t.FailNow()
+  }
+  // 100 points on day 0 and 200 points on day 10, each valid for 30 days
+  invokeAt(stub, client("10086"), testNow, "tx1", "exchange", []string{"10086", "100"})
+  invokeAt(stub, client("10086"), testNow+10*day, "tx2", "exchange", []string{"10086", "200"})
+  checkBalance(t, stub, "10086", testNow+10*day, "{\"ID\":\"10086\",\"Integral\":300,\"Expired\":0,\"Buckets\":[{\"Date\":\"2016-11-19\",\"ExpireAt\":1479592000,\"Integral\":100},{\"Date\":\"2016-11-29\",\"ExpireAt\":1480456000,\"Integral\":200}]}")
+
+  // the transfer spends the oldest lot first and the lots keep their expiry
+  _, err = invokeAt(stub, client("10086"), testNow+20*day, "tx3", "transfer", []string{"10086", "10000", "150"})
+  if err != nil {
+    fmt.Println("transfer failed", err)
+    t.FailNow()
+  }
+  checkBalance(t, stub, "10086", testNow+20*day, "{\"ID\":\"10086\",\"Integral\":150,\"Expired\":0,\"Buckets\":[{\"Date\":\"2016-11-29\",\"ExpireAt\":1480456000,\"Integral\":150}]}")
+  checkBalance(t, stub, "10000", testNow+20*day, "{\"ID\":\"10000\",\"Integral\":150,\"Expired\":0,\"Buckets\":[{\"Date\":\"2016-11-19\",\"ExpireAt\":1479592000,\"Integral\":100},{\"Date\":\"2016-11-29\",\"ExpireAt\":1480456000,\"Integral\":50}]}")
+
+  // on day 35 the first lot has expired and can no longer be spent
+  checkBalance(t, stub, "10000", testNow+35*day, "{\"ID\":\"10000\",\"Integral\":50,\"Expired\":100,\"Buckets\":[{\"Date\":\"2016-11-29\",\"ExpireAt\":1480456000,\"Integral\":50}]}")
+  _, err = invokeAt(stub, client("10000"), testNow+35*day, "tx4", "transfer", []string{"10000", "10086", "60"})
+  if err == nil || err.Error() != "用户 china unicom 剩余善圆不足本次交易" {
+    t.FailNow()
+  }
+
+  bytes, err := invokeAt(stub, issuer, testNow+35*day, "tx5", "expire", []string{})
+  if err != nil || string(bytes) != "100" {
+    fmt.Println("expire returned", string(bytes), err)
+    t.FailNow()
+  }
+  checkEvent(t, "{\"Type\":\"expire\",\"TxID\":\"tx5\",\"Delta\":0,\"Expired\":100,\"FromID\":\"\",\"ToID\":\"0001\",\"Step\":0,\"Time\":1480024000,\"Balances\":[{\"ID\":\"10000\",\"Integral\":50},{\"ID\":\"0001\",\"Integral\":49800}]}")
+  bytes, _ = checkGetRoot(t, stub, []string{})
+  if string(bytes) != "{\"ID\":\"0001\",\"Name\":\"shanchain\",\"TotalIntegral\":50000,\"RestIntegral\":49800}" {
+    fmt.Println("Unexpected root", string(bytes))
+    t.FailNow()
+  }
+  bytes, _ = checkGetUser(t, stub, []string{"10000"})
+  if string(bytes) != "{\"ID\":\"10000\",\"Name\":\"china unicom\",\"Integral\":50}" {
+    t.FailNow()
+  }
+
+  // by day 45 every point 10086 holds has expired
+  _, err = invokeAt(stub, client("10086"), testNow+45*day, "tx6", "transfer", []string{"10086", "10000", "1"})
+  if err == nil {
+    t.FailNow()
+  }
+}
+
+func TestShanchain_Transfer_Invalid_argument3(t *testing.T) {
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  checkCreateUser(t, stub, []string{"10086", "china mobile", "1000"})
+  checkCreateUser(t, stub, []string{"10000", "china unicom", "1000"})
+  _, err := checkTransfer(t, stub, []string{"10086", "10000", "-200"})
+  if err == nil || err.Error() != "want positive Integer number" {
+    t.FailNow()
+  }
+  _, err = checkTransfer(t, stub, []string{"10086", "10086", "200"})
+  if err == nil || err.Error() != "cannot transfer to the same user" {
+    t.FailNow()
+  }
+}
+
+func checkEvent(t *testing.T, expect string) {
+  if lastEvent.name != "balanceChange" {
+    fmt.Println("Expected a balanceChange event, got", lastEvent.name)
+    t.FailNow()
+  }
+  if string(lastEvent.payload) != expect {
+    fmt.Println("Event", string(lastEvent.payload), "was not", expect, "as expected")
+    t.FailNow()
+  }
+}
+
+func TestShanchain_Events(t *testing.T) {
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+
+  checkAdditional(t, stub, []string{"10000"})
+  checkEvent(t, "{\"Type\":\"additional\",\"TxID\":\"1\",\"Delta\":10000,\"Expired\":0,\"FromID\":\"\",\"ToID\":\"0001\",\"Step\":0,\"Time\":1477000000,\"Balances\":[{\"ID\":\"0001\",\"Integral\":60000}]}")
+
+  checkCreateUser(t, stub, []string{"10086", "china mobile", "100"})
+  checkEvent(t, "{\"Type\":\"createUser\",\"TxID\":\"1\",\"Delta\":100,\"Expired\":0,\"FromID\":\"0001\",\"ToID\":\"10086\",\"Step\":0,\"Time\":1477000000,\"Balances\":[{\"ID\":\"0001\",\"Integral\":59900},{\"ID\":\"10086\",\"Integral\":100}]}")
+  checkCreateUser(t, stub, []string{"10000", "china unicom", "0"})
+
+  invokeAs(stub, client("10086"), "tx1", "exchange", []string{"10086", "900"})
+  checkEvent(t, "{\"Type\":\"exchange\",\"TxID\":\"tx1\",\"Delta\":900,\"Expired\":0,\"FromID\":\"0001\",\"ToID\":\"10086\",\"Step\":900,\"Time\":1477000000,\"Balances\":[{\"ID\":\"0001\",\"Integral\":59000},{\"ID\":\"10086\",\"Integral\":1000}]}")
+
+  invokeAs(stub, client("10086"), "tx2", "transfer", []string{"10086", "10000", "300"})
+  checkEvent(t, "{\"Type\":\"transfer\",\"TxID\":\"tx2\",\"Delta\":300,\"Expired\":0,\"FromID\":\"10086\",\"ToID\":\"10000\",\"Step\":0,\"Time\":1477000000,\"Balances\":[{\"ID\":\"10086\",\"Integral\":700},{\"ID\":\"10000\",\"Integral\":300}]}")
+
+  _, err := invokeAs(stub, client("10086"), "tx3", "transfer", []string{"10086", "10000", "3000"})
+  if err == nil || lastEvent.name != "" {
+    fmt.Println("A failed transfer must not set an event")
+    t.FailNow()
+  }
+}
+
+func TestShanchain_ExchangePolicy(t *testing.T) {
+  day := int64(24 * 60 * 60)
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  checkCreateUser(t, stub, []string{"10086", "china mobile", "0"})
+
+  // 10 steps per point up to 1000 steps, 5 per point above, capped at 150 a day,
+  // doubled during a one day promotion starting on day 2
+  policy := "{\"Tiers\":[{\"FromSteps\":0,\"StepsPerPoint\":10},{\"FromSteps\":1000,\"StepsPerPoint\":5}],\"DailyCap\":150,\"Promotions\":[{\"Name\":\"double\",\"Percent\":200,\"Start\":1477172800,\"End\":1477259200}]}"
+  _, err := invokeAs(stub, client("10086"), "p0", "setExchangePolicy", []string{policy})
+  if err == nil || err.Error() != "caller is not issuer" {
+    fmt.Println("Only the issuer may set the exchange policy")
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, issuer, "p1", "setExchangePolicy", []string{"{\"Tiers\":[{\"FromSteps\":10,\"StepsPerPoint\":10}]}"})
+  if err == nil || err.Error() != "policy needs a first tier starting at 0 steps" {
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, issuer, "p1", "setExchangePolicy", []string{policy})
+  if err != nil {
+    fmt.Println("setExchangePolicy failed", err)
+    t.FailNow()
+  }
+
+  // 1000 steps earn 100 points, 500 more earn 100: only 150 fit in the cap
+  _, err = invokeAt(stub, client("10086"), testNow, "tx1", "exchange", []string{"10086", "1500"})
+  if err != nil {
+    fmt.Println("exchange failed", err)
+    t.FailNow()
+  }
+  checkTransaction(t, stub, []string{"tx1"}, "{\"ID\":\"tx1\",\"Step\":1500,\"Integral\":150,\"FromType\":0,\"FromID\":\"0001\",\"ToType\":1,\"ToID\":\"10086\"}")
+  _, err = invokeAt(stub, client("10086"), testNow, "tx2", "exchange", []string{"10086", "100"})
+  if err == nil || err.Error() != "daily exchange cap reached" {
+    fmt.Println("Cap should be reached", err)
+    t.FailNow()
+  }
+  _, err = invokeAt(stub, client("10086"), testNow+day, "tx2", "exchange", []string{"10086", "9"})
+  if err == nil || err.Error() != "steps are worth no points" {
+    t.FailNow()
+  }
+
+  // the cap resets the next day and the promotion doubles the points
+  _, err = invokeAt(stub, client("10086"), testNow+day, "tx2", "exchange", []string{"10086", "100"})
+  if err != nil {
+    t.FailNow()
+  }
+  _, err = invokeAt(stub, client("10086"), testNow+2*day, "tx3", "exchange", []string{"10086", "100"})
+  if err != nil {
+    t.FailNow()
+  }
+  checkTransaction(t, stub, []string{"tx2"}, "{\"ID\":\"tx2\",\"Step\":100,\"Integral\":10,\"FromType\":0,\"FromID\":\"0001\",\"ToType\":1,\"ToID\":\"10086\"}")
+  checkTransaction(t, stub, []string{"tx3"}, "{\"ID\":\"tx3\",\"Step\":100,\"Integral\":20,\"FromType\":0,\"FromID\":\"0001\",\"ToType\":1,\"ToID\":\"10086\"}")
+
+  // a second version replaces the first, which stays queryable
+  _, err = invokeAt(stub, issuer, testNow+3*day, "p2", "setExchangePolicy", []string{"{\"Tiers\":[{\"FromSteps\":0,\"StepsPerPoint\":2}]}"})
+  if err != nil {
+    t.FailNow()
+  }
+  bytes, err := stub.MockQuery("getExchangePolicy", []string{})
+  if err != nil || string(bytes) != "{\"Version\":2,\"Tiers\":[{\"FromSteps\":0,\"StepsPerPoint\":2}],\"DailyCap\":0,\"Promotions\":null,\"UpdatedAt\":1477259200}" {
+    fmt.Println("Unexpected policy", string(bytes), err)
+    t.FailNow()
+  }
+  bytes, err = stub.MockQuery("getExchangePolicy", []string{"1"})
+  if err != nil || string(bytes) != "{\"Version\":1,"+policy[1:len(policy)-1]+",\"UpdatedAt\":1477000000}" {
+    fmt.Println("Unexpected policy", string(bytes), err)
+    t.FailNow()
+  }
+  var history []ExchangePolicy
+  bytes, err = stub.MockQuery("getExchangePolicyHistory", []string{})
+  if err != nil || json.Unmarshal(bytes, &history) != nil || len(history) != 2 || history[0].Version != 1 || history[1].Version != 2 {
+    fmt.Println("Unexpected policy history", string(bytes), err)
+    t.FailNow()
+  }
+  _, err = stub.MockQuery("getExchangePolicy", []string{"3"})
+  if err == nil {
+    t.FailNow()
+  }
+}
+
+func TestShanchain_Idempotent(t *testing.T) {
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  checkCreateUser(t, stub, []string{"10086", "china mobile", "0"})
+  checkCreateUser(t, stub, []string{"10000", "china unicom", "0"})
+
+  bytes, err := stub.MockQuery("getRequestStatus", []string{"10086", "req1"})
+  if err != nil || string(bytes) != "{\"RequestID\":\"req1\",\"UserID\":\"10086\",\"Status\":\"unknown\",\"Function\":\"\",\"Args\":null,\"TxID\":\"\",\"Time\":0,\"Result\":null}" {
+    fmt.Println("Unexpected request status", string(bytes), err)
+    t.FailNow()
+  }
+
+  // the gateway retries the exchange under a new txID: it is applied once
+  first, err := invokeAs(stub, client("10086"), "tx1", "exchange", []string{"10086", "500", "req1"})
+  if err != nil {
+    fmt.Println("exchange failed", err)
+    t.FailNow()
+  }
+  retry, err := invokeAs(stub, client("10086"), "tx2", "exchange", []string{"10086", "500", "req1"})
+  if err != nil || string(retry) != string(first) || lastEvent.name != "" {
+    fmt.Println("Retry should replay the first result", string(retry), err)
+    t.FailNow()
+  }
+  checkState(t, stub, userKey("10086"), "{\"ID\":\"10086\",\"Name\":\"china mobile\",\"Integral\":500}")
+  checkState(t, stub, rootKey(), "{\"ID\":\"0001\",\"Name\":\"shanchain\",\"TotalIntegral\":50000,\"RestIntegral\":49500}")
+  _, tsBytes, _ := getTransaction(stub, "tx2")
+  if tsBytes != nil {
+    t.FailNow()
+  }
+
+  bytes, err = stub.MockQuery("getRequestStatus", []string{"10086", "req1"})
+  if err != nil || string(bytes) != "{\"RequestID\":\"req1\",\"UserID\":\"10086\",\"Status\":\"committed\",\"Function\":\"exchange\",\"Args\":[\"10086\",\"500\"],\"TxID\":\"tx1\",\"Time\":1477000000,\"Result\":"+string(first)+"}" {
+    fmt.Println("Unexpected request status", string(bytes), err)
+    t.FailNow()
+  }
+
+  // a request ID cannot be reused for another invoke, nor replayed by someone else
+  _, err = invokeAs(stub, client("10086"), "tx3", "exchange", []string{"10086", "600", "req1"})
+  if err == nil || err.Error() != "request req1 was already used for a different invoke" {
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, client("10000"), "tx3", "exchange", []string{"10086", "500", "req1"})
+  if err == nil || err.Error() != "caller is not the owner of user 10086" {
+    t.FailNow()
+  }
+
+  // request IDs belong to the account: another user may use the same one
+  _, err = invokeAs(stub, client("10000"), "tx3", "exchange", []string{"10000", "100", "req1"})
+  if err != nil {
+    t.FailNow()
+  }
+
+  // a failed invoke is not recorded and can be retried
+  _, err = invokeAs(stub, client("10086"), "tx4", "transfer", []string{"10086", "10000", "5000", "req2"})
+  if err == nil {
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, client("10086"), "tx4", "transfer", []string{"10086", "10000", "200", "req2"})
+  if err != nil {
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, client("10086"), "tx5", "transfer", []string{"10086", "10000", "200", "req2"})
+  if err != nil {
+    t.FailNow()
+  }
+  checkState(t, stub, userKey("10086"), "{\"ID\":\"10086\",\"Name\":\"china mobile\",\"Integral\":300}")
+  checkState(t, stub, userKey("10000"), "{\"ID\":\"10000\",\"Name\":\"china unicom\",\"Integral\":300}")
+}
+
+func TestShanchain_Merchants(t *testing.T) {
+  day := int64(24 * 60 * 60)
+  merchant := map[string]string{"role": "merchant", "userid": "m1"}
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  invokeAs(stub, issuer, "1", "setPointExpiry", []string{"30"})
+
+  _, err := invokeAs(stub, merchant, "1", "createMerchant", []string{"m1", "merchant one", "1000"})
+  if err == nil || err.Error() != "caller is not issuer" {
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, issuer, "1", "createMerchant", []string{"0001", "shanchain", "1000"})
+  if err == nil || err.Error() != "merchant 0001 already exists" {
+    t.FailNow()
+  }
+  invokeAs(stub, issuer, "1", "createMerchant", []string{"m1", "merchant one", "1000"})
+  checkEvent(t, "{\"Type\":\"createMerchant\",\"TxID\":\"1\",\"Delta\":1000,\"Expired\":0,\"FromID\":\"\",\"ToID\":\"m1\",\"Step\":0,\"Time\":1477000000,\"Balances\":[{\"ID\":\"m1\",\"Integral\":1000}]}")
+  invokeAs(stub, issuer, "1", "createMerchant", []string{"m2", "merchant two", "500"})
+
+  // a merchant opens accounts for its own users only
+  _, err = invokeAs(stub, merchant, "1", "createUser", []string{"u1", "user one", "0", "m1"})
+  if err != nil {
+    fmt.Println("createUser failed", err)
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, merchant, "1", "createUser", []string{"u2", "user two", "0", "m2"})
+  if err == nil || err.Error() != "caller is not merchant m2" {
+    t.FailNow()
+  }
+  invokeAs(stub, issuer, "1", "createUser", []string{"u2", "user two", "0", "m2"})
+  checkCreateUser(t, stub, []string{"u0", "user zero", "0"})
+
+  // exchanges are paid from the user's merchant pool
+  invokeAs(stub, client("u1"), "tx1", "exchange", []string{"u1", "300"})
+  checkTransaction(t, stub, []string{"tx1"}, "{\"ID\":\"tx1\",\"Step\":300,\"Integral\":300,\"FromType\":2,\"FromID\":\"m1\",\"ToType\":1,\"ToID\":\"u1\"}")
+  checkState(t, stub, merchantKey("m1"), "{\"ID\":\"m1\",\"Name\":\"merchant one\",\"TotalIntegral\":1000,\"RestIntegral\":700}")
+  checkState(t, stub, rootKey(), "{\"ID\":\"0001\",\"Name\":\"shanchain\",\"TotalIntegral\":50000,\"RestIntegral\":50000}")
+  _, err = invokeAs(stub, client("u1"), "tx2", "exchange", []string{"u1", "800"})
+  if err == nil || err.Error() != "Merchant m1 剩余善圆不足" {
+    t.FailNow()
+  }
+  invokeAs(stub, issuer, "1", "additional", []string{"500", "m1"})
+  checkState(t, stub, merchantKey("m1"), "{\"ID\":\"m1\",\"Name\":\"merchant one\",\"TotalIntegral\":1500,\"RestIntegral\":1200}")
+
+  // transfers across pools need a rule, in each direction
+  _, err = invokeAs(stub, client("u1"), "tx2", "transfer", []string{"u1", "u2", "100"})
+  if err == nil || err.Error() != "transfer from merchant m1 to merchant m2 is not allowed" {
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, issuer, "1", "setTransferRule", []string{"m1", "m2", "true"})
+  if err != nil {
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, client("u1"), "tx2", "transfer", []string{"u1", "u2", "100"})
+  if err != nil {
+    fmt.Println("transfer failed", err)
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, client("u2"), "tx3", "transfer", []string{"u2", "u1", "10"})
+  if err == nil || err.Error() != "transfer from merchant m2 to merchant m1 is not allowed" {
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, client("u1"), "tx3", "transfer", []string{"u1", "u0", "10"})
+  if err == nil || err.Error() != "transfer from merchant m1 to merchant 0001 is not allowed" {
+    t.FailNow()
+  }
+
+  bytes, err := stub.MockQuery("getSettlement", []string{"m1", "1477000000", "1477000000"})
+  if err != nil || string(bytes) != "{\"MerchantID\":\"m1\",\"StartTime\":1477000000,\"EndTime\":1477000000,\"Exchanged\":300,\"In\":0,\"Out\":100,\"Net\":-100,\"Counterparties\":[{\"MerchantID\":\"m2\",\"In\":0,\"Out\":100,\"Net\":-100}]}" {
+    fmt.Println("Unexpected settlement", string(bytes), err)
+    t.FailNow()
+  }
+  bytes, err = stub.MockQuery("getSettlement", []string{"m1", "0", "1476999999"})
+  if err != nil || string(bytes) != "{\"MerchantID\":\"m1\",\"StartTime\":0,\"EndTime\":1476999999,\"Exchanged\":0,\"In\":0,\"Out\":0,\"Net\":0,\"Counterparties\":[]}" {
+    fmt.Println("Unexpected settlement", string(bytes), err)
+    t.FailNow()
+  }
+  var settlements []Settlement
+  bytes, err = stub.MockQuery("getSettlements", []string{"0", "1477000000"})
+  if err != nil || json.Unmarshal(bytes, &settlements) != nil || len(settlements) != 3 ||
+    settlements[0].MerchantID != "0001" || settlements[2].MerchantID != "m2" || settlements[2].In != 100 {
+    fmt.Println("Unexpected settlements", string(bytes), err)
+    t.FailNow()
+  }
+
+  // expired points go back to the pool each user belongs to
+  _, err = invokeAt(stub, issuer, testNow+31*day, "1", "expire", []string{})
+  if err != nil {
+    t.FailNow()
+  }
+  checkEvent(t, "{\"Type\":\"expire\",\"TxID\":\"1\",\"Delta\":0,\"Expired\":300,\"FromID\":\"\",\"ToID\":\"\",\"Step\":0,\"Time\":1479678400,\"Balances\":[{\"ID\":\"u1\",\"Integral\":0},{\"ID\":\"u2\",\"Integral\":0},{\"ID\":\"m1\",\"Integral\":1400},{\"ID\":\"m2\",\"Integral\":600}]}")
+}
+
+func TestShanchain_FreezeAndClose(t *testing.T) {
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  checkCreateUser(t, stub, []string{"10086", "china mobile", "100"})
+  checkCreateUser(t, stub, []string{"10000", "china unicom", "50"})
+
+  // a frozen user can neither exchange nor send or receive transfers
+  _, err := invokeAt(stub, issuer, testNow, "f1", "freezeUser", []string{"10086", "fraud"})
+  if err != nil {
+    fmt.Println("freezeUser failed", err)
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, client("10086"), "tx1", "exchange", []string{"10086", "10"})
+  if err == nil || err.Error() != "user 10086 is frozen" {
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, client("10086"), "tx1", "transfer", []string{"10086", "10000", "10"})
+  if err == nil || err.Error() != "user 10086 is frozen" {
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, client("10000"), "tx1", "transfer", []string{"10000", "10086", "10"})
+  if err == nil || err.Error() != "user 10086 is frozen" {
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, issuer, "f2", "freezeUser", []string{"10086", "fraud"})
+  if err == nil || err.Error() != "user 10086 is frozen" {
+    t.FailNow()
+  }
+
+  _, err = invokeAt(stub, issuer, testNow+60, "f2", "unfreezeUser", []string{"10086", "resolved"})
+  if err != nil {
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, client("10086"), "tx1", "transfer", []string{"10086", "10000", "10"})
+  if err != nil {
+    fmt.Println("transfer after unfreeze failed", err)
+    t.FailNow()
+  }
+
+  // closing reclaims the balance into the root pool and is final
+  _, err = invokeAt(stub, issuer, testNow+120, "f3", "closeUser", []string{"10086", "customer_request"})
+  if err != nil {
+    fmt.Println("closeUser failed", err)
+    t.FailNow()
+  }
+  checkEvent(t, "{\"Type\":\"closeUser\",\"TxID\":\"f3\",\"Delta\":90,\"Expired\":0,\"FromID\":\"10086\",\"ToID\":\"0001\",\"Step\":0,\"Time\":1477000120,\"Balances\":[{\"ID\":\"10086\",\"Integral\":0},{\"ID\":\"0001\",\"Integral\":49940}]}")
+  checkState(t, stub, userKey("10086"), "{\"ID\":\"10086\",\"Name\":\"china mobile\",\"Integral\":0}")
+  checkState(t, stub, rootKey(), "{\"ID\":\"0001\",\"Name\":\"shanchain\",\"TotalIntegral\":50000,\"RestIntegral\":49940}")
+  checkTransaction(t, stub, []string{"f3"}, "{\"ID\":\"f3\",\"Step\":0,\"Integral\":90,\"FromType\":1,\"FromID\":\"10086\",\"ToType\":0,\"ToID\":\"0001\"}")
+  _, err = invokeAs(stub, issuer, "f4", "unfreezeUser", []string{"10086", "resolved"})
+  if err == nil || err.Error() != "user 10086 is closed" {
+    t.FailNow()
+  }
+  _, err = invokeAs(stub, client("10000"), "tx2", "transfer", []string{"10000", "10086", "10"})
+  if err == nil || err.Error() != "user 10086 is closed" {
+    t.FailNow()
+  }
+
+  bytes, err := stub.MockQuery("getUserStatus", []string{"10086"})
+  if err != nil || string(bytes) != "{\"ID\":\"10086\",\"Status\":\"closed\",\"Reason\":\"customer_request\",\"By\":\"0001\",\"Time\":1477000120}" {
+    fmt.Println("Unexpected status", string(bytes), err)
+    t.FailNow()
+  }
+  bytes, err = stub.MockQuery("getUserAudit", []string{"10086"})
+  if err != nil || string(bytes) != "[{\"TxID\":\"f1\",\"UserID\":\"10086\",\"Action\":\"freezeUser\",\"Reason\":\"fraud\",\"By\":\"0001\",\"Time\":1477000000,\"Reclaimed\":0},"+
+    "{\"TxID\":\"f2\",\"UserID\":\"10086\",\"Action\":\"unfreezeUser\",\"Reason\":\"resolved\",\"By\":\"0001\",\"Time\":1477000060,\"Reclaimed\":0},"+
+    "{\"TxID\":\"f3\",\"UserID\":\"10086\",\"Action\":\"closeUser\",\"Reason\":\"customer_request\",\"By\":\"0001\",\"Time\":1477000120,\"Reclaimed\":90}]" {
+    fmt.Println("Unexpected audit", string(bytes), err)
+    t.FailNow()
+  }
+}
+
+func TestShanchain_UserHistory(t *testing.T) {
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  _, err := invokeAt(stub, issuer, testNow, "h1", "createUser", []string{"10086", "china mobile", "1000"})
+  if err != nil {
+    t.FailNow()
+  }
+  checkCreateUser(t, stub, []string{"10000", "china unicom", "1000"})
+  _, err = invokeAt(stub, client("10086"), testNow+60, "h2", "transfer", []string{"10086", "10000", "200"})
+  if err != nil {
+    t.FailNow()
+  }
+  // a failed transfer leaves no trace in the history
+  _, err = invokeAt(stub, client("10086"), testNow+90, "h3", "transfer", []string{"10086", "10000", "5000"})
+  if err == nil {
+    t.FailNow()
+  }
+
+  bytes, err := stub.MockQuery("getUserHistory", []string{"10086"})
+  if err != nil || string(bytes) != "{\"CompleteFromBlock\":0,\"Records\":[{\"TxID\":\"h1\",\"BlockNumber\":2,\"Time\":1477000000,\"Deleted\":false,\"User\":{\"ID\":\"10086\",\"Name\":\"china mobile\",\"Integral\":1000}},"+
+    "{\"TxID\":\"h2\",\"BlockNumber\":4,\"Time\":1477000060,\"Deleted\":false,\"User\":{\"ID\":\"10086\",\"Name\":\"china mobile\",\"Integral\":800}}]}" {
+    fmt.Println("Unexpected history", string(bytes), err)
+    t.FailNow()
+  }
+  _, err = stub.MockQuery("getUserHistory", []string{})
+  if err == nil {
+    t.FailNow()
+  }
+}
+
+func TestShanchain_Deterministic(t *testing.T) {
+  scc := new(ShanChainAPI)
+  stub := shim.NewMockStub("shanchain_api", scc)
+  // every transaction is executed twice and fails if the executions differ
+  stub.MockDeterminismCheck(true)
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  checkCreateUser(t, stub, []string{"10086", "china mobile", "100"})
+  checkCreateUser(t, stub, []string{"10000", "china unicom", "100"})
+  _, err := checkExchange(t, stub, []string{"10086", "900"})
+  if err != nil {
+    fmt.Println("exchange is not deterministic", err)
+    t.FailNow()
+  }
+  _, err = invokeAt(stub, client("10086"), testNow, "d1", "transfer", []string{"10086", "10000", "200"})
+  if err != nil {
+    fmt.Println("transfer is not deterministic", err)
+    t.FailNow()
+  }
+}
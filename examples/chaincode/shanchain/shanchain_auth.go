@@ -0,0 +1,87 @@
+/*
+	author:krew
+*/
+
+package main
+
+import (
+	"errors"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Caller authorization. The ACA issues every shanchain client two attributes
+// (see the "aca" section of membersrvc.yaml):
+//
+//	role:   "issuer" for the back office, "merchant" for a partner merchant's
+//	        back office, "client" for everybody else
+//	userid: the User.ID (or merchant ID) the enrollment owns
+//
+// Only issuers may mint points or administer accounts; a merchant may open
+// accounts for its own users. Transfer and exchange must be signed by the
+// owner of the account being debited or credited. The ACA has to be enabled
+// (aca.enabled) for the attributes to reach the TCert.
+const (
+	roleAttribute   = "role"     //角色属性
+	userIDAttribute = "userid"   //用户id属性
+	issuerRole      = "issuer"   //发行方角色
+	merchantRole    = "merchant" //商户角色
+)
+
+/**
+ * [checkIssuer fails unless the caller's certificate carries role=issuer]
+ * @param  {[type]} stub shim.ChaincodeStubInterface) (error [description]
+ * @return {[type]}      [description]
+ */
+func checkIssuer(stub shim.ChaincodeStubInterface) error {
+	ok, err := stub.VerifyAttribute(roleAttribute, []byte(issuerRole))
+	if err != nil {
+		return errors.New("Failed verifying caller role" + err.Error())
+	}
+	if !ok {
+		return errors.New("caller is not " + issuerRole)
+	}
+	return nil
+}
+
+/**
+ * [checkOwner fails unless the caller's certificate carries userid=userID]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, userID string) (error [description]
+ * @return {[type]}      [description]
+ */
+func checkOwner(stub shim.ChaincodeStubInterface, userID string) error {
+	callerID, err := stub.ReadCertAttribute(userIDAttribute)
+	if err != nil {
+		return errors.New("Failed fetching caller userid" + err.Error())
+	}
+	if string(callerID) != userID {
+		return errors.New("caller is not the owner of user " + userID)
+	}
+	return nil
+}
+
+/**
+ * [checkMerchantAdmin fails unless the caller is an issuer or the merchant merchantID itself]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, merchantID string) (error [description]
+ * @return {[type]}      [description]
+ */
+func checkMerchantAdmin(stub shim.ChaincodeStubInterface, merchantID string) error {
+	if checkIssuer(stub) == nil {
+		return nil
+	}
+	ok, err := stub.VerifyAttribute(roleAttribute, []byte(merchantRole))
+	if err != nil {
+		return errors.New("Failed verifying caller role" + err.Error())
+	}
+	if !ok {
+		return errors.New("caller is not " + issuerRole + " or " + merchantRole)
+	}
+	callerID, err := stub.ReadCertAttribute(userIDAttribute)
+	if err != nil {
+		return errors.New("Failed fetching caller userid" + err.Error())
+	}
+	if string(callerID) != merchantID {
+		return errors.New("caller is not merchant " + merchantID)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+/*
+	author:krew
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Every invoke that moves points emits one chaincode event named
+// balanceEventName whose payload is a JSON BalanceEvent, so a consumer
+// registers a single ChaincodeReg{ChaincodeID, EventName: "balanceChange"}
+// and switches on BalanceEvent.Type.
+const balanceEventName = "balanceChange"
+
+// PartyBalance is the balance of one party after the transaction. For the
+// root pool Integral is Root.RestIntegral.
+type PartyBalance struct {
+	ID       string //账户id
+	Integral int    //变动后积分
+}
+
+// BalanceEvent describes the balance changes of one transaction.
+type BalanceEvent struct {
+	Type     string         //变动类型: additional, createUser, createMerchant, exchange, transfer, expire, closeUser
+	TxID     string         //交易流水号
+	Delta    int            //变动积分
+	Expired  int            //同时回收的过期积分
+	FromID   string         //转出方id
+	ToID     string         //转入方id
+	Step     int            //转化步数
+	Time     int64          //交易时间戳
+	Balances []PartyBalance //变动后各方积分
+}
+
+/**
+ * [emitBalanceEvent sets the balance change event of the current transaction]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, event BalanceEvent) (error [description]
+ * @return {[type]}      [description]
+ */
+func emitBalanceEvent(stub shim.ChaincodeStubInterface, event BalanceEvent) error {
+	event.TxID = stub.GetTxID()
+	eventBytes, err := json.Marshal(&event)
+	if err != nil {
+		return errors.New("Error retrieving eventBytes")
+	}
+	err = stub.SetEvent(balanceEventName, eventBytes)
+	if err != nil {
+		return errors.New("SetEvent Error" + err.Error())
+	}
+	return nil
+}
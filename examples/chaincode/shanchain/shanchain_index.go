@@ -0,0 +1,241 @@
+/*
+	author:krew
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+const (
+	indexMaxRune    = "\U0010FFFF" //范围查询上界
+	fromIndexPrefix = "txFrom"     //按发起方索引
+	toIndexPrefix   = "txTo"       //按接收方索引
+	timeIndexPrefix = "txTime"     //按时间索引
+	timeKeyFormat   = "%020d"      //时间戳定长格式, 保证字典序与数值序一致
+)
+
+// TransactionPage is one page of a transaction history query. Cursor is
+// empty when there are no more transactions in the requested range.
+type TransactionPage struct {
+	Transactions []Transaction //交易列表
+	Cursor       string        //下一页游标
+}
+
+type transactionsByTime []Transaction
+
+func (s transactionsByTime) Len() int      { return len(s) }
+func (s transactionsByTime) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s transactionsByTime) Less(i, j int) bool {
+	if s[i].Time != s[j].Time {
+		return s[i].Time < s[j].Time
+	}
+	return s[i].ID < s[j].ID
+}
+
+/**
+ * [transactionIndexKey builds the index key prefix|owner|time|txID]
+ * @param  {[type]} prefix string, owner string, time int64, tsID string [description]
+ * @return {[type]} string
+ */
+func transactionIndexKey(prefix string, owner string, time int64, tsID string) string {
+	return stateKey(indexKeyType, prefix, owner, fmt.Sprintf(timeKeyFormat, time), tsID)
+}
+
+/**
+ * [writeTransactionIndexes maintains the FromID, ToID and time indexes of a transaction]
+ * @param  {[type]} stub        shim.ChaincodeStubInterface [description]
+ * @param  {[type]} transaction Transaction)                (error [description]
+ * @return {[type]}             [description]
+ */
+func writeTransactionIndexes(stub shim.ChaincodeStubInterface, transaction Transaction) error {
+	keys := []string{
+		transactionIndexKey(fromIndexPrefix, transaction.FromID, transaction.Time, transaction.ID),
+		transactionIndexKey(toIndexPrefix, transaction.ToID, transaction.Time, transaction.ID),
+		transactionIndexKey(timeIndexPrefix, "", transaction.Time, transaction.ID),
+	}
+	for _, key := range keys {
+		err := stub.PutState(key, []byte(transaction.ID))
+		if err != nil {
+			return errors.New("PutState Error" + err.Error())
+		}
+	}
+	return nil
+}
+
+/**
+ * [rangeTransactions loads, in time order, at most limit transactions referenced by one index after the cursor and up to endTime]
+ * the index keys are ordered by time and txID, so the scan stops after limit entries
+ * @param  {[type]} stub shim.ChaincodeStubInterface, prefix string, owner string, cursorTime int64, cursorID string, endTime int64, limit int [description]
+ * @return {[type]}      ([]Transaction, error)
+ */
+func rangeTransactions(stub shim.ChaincodeStubInterface, prefix string, owner string, cursorTime int64, cursorID string, endTime int64, limit int) ([]Transaction, error) {
+	startKey := transactionIndexKey(prefix, owner, cursorTime, cursorID)
+	endKey := stateKey(indexKeyType, prefix, owner, fmt.Sprintf(timeKeyFormat, endTime), indexMaxRune)
+	iter, err := stub.RangeQueryState(startKey, endKey)
+	if err != nil {
+		return nil, errors.New("RangeQueryState Error" + err.Error())
+	}
+	defer iter.Close()
+
+	var transactions []Transaction
+	for len(transactions) < limit && iter.HasNext() {
+		key, tsIDBytes, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("RangeQueryState Error" + err.Error())
+		}
+		if cursorID != "" && key == startKey {
+			continue
+		}
+		transaction, tsBytes, err := getTransaction(stub, string(tsIDBytes))
+		if err != nil {
+			return nil, err
+		}
+		if tsBytes == nil {
+			return nil, errors.New("transaction " + string(tsIDBytes) + " not found")
+		}
+		transactions = append(transactions, transaction)
+	}
+	return transactions, nil
+}
+
+/**
+ * [parseHistoryArgs parses startTime, endTime, pageSize and the optional cursor]
+ * the returned cursor never points before startTime
+ * @param  {[type]} args []string [description]
+ * @return {[type]}      (endTime int64, pageSize int, cursorTime int64, cursorID string, err error)
+ */
+func parseHistoryArgs(args []string) (int64, int, int64, string, error) {
+	var (
+		startTime  int64
+		endTime    int64
+		pageSize   int
+		cursorTime int64
+		cursorID   string
+		err        error
+	)
+	startTime, err = strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, 0, 0, "", errors.New("want Integer startTime")
+	}
+	endTime, err = strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return 0, 0, 0, "", errors.New("want Integer endTime")
+	}
+	if startTime < 0 || endTime < startTime {
+		return 0, 0, 0, "", errors.New("want 0 <= startTime <= endTime")
+	}
+	pageSize, err = strconv.Atoi(args[2])
+	if err != nil || pageSize <= 0 {
+		return 0, 0, 0, "", errors.New("want positive Integer pageSize")
+	}
+	cursorTime = startTime
+	if len(args) == 4 && args[3] != "" {
+		parts := strings.SplitN(args[3], ":", 2)
+		if len(parts) != 2 {
+			return 0, 0, 0, "", errors.New("invalid cursor")
+		}
+		cursorTime, err = strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return 0, 0, 0, "", errors.New("invalid cursor")
+		}
+		cursorID = parts[1]
+	}
+	if cursorTime < startTime {
+		cursorTime = startTime
+		cursorID = ""
+	}
+	return endTime, pageSize, cursorTime, cursorID, nil
+}
+
+/**
+ * [pageTransactions sorts transactions by time and returns the first pageSize of them]
+ * there is a next page when more than pageSize transactions are given
+ * @param  {[type]} transactions []Transaction, pageSize int [description]
+ * @return {[type]}              TransactionPage
+ */
+func pageTransactions(transactions []Transaction, pageSize int) TransactionPage {
+	sort.Sort(transactionsByTime(transactions))
+	page := TransactionPage{Transactions: []Transaction{}}
+	if len(transactions) > pageSize {
+		last := transactions[pageSize-1]
+		page.Cursor = strconv.FormatInt(last.Time, 10) + ":" + last.ID
+		transactions = transactions[:pageSize]
+	}
+	page.Transactions = append(page.Transactions, transactions...)
+	return page
+}
+
+/**
+ * [getUserTransactions lists the transactions a user sent or received]
+ * args: userID, startTime, endTime, pageSize[, cursor]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, args []string [description]
+ * @return {[type]}      ([]byte, error)
+ */
+func getUserTransactions(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 4 && len(args) != 5 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 4 or 5")
+	}
+	userID := args[0]
+	endTime, pageSize, cursorTime, cursorID, err := parseHistoryArgs(args[1:])
+	if err != nil {
+		return nil, err
+	}
+	// the first pageSize+1 transactions of the merged streams are among the
+	// first pageSize+1 of each stream
+	sent, err := rangeTransactions(stub, fromIndexPrefix, userID, cursorTime, cursorID, endTime, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+	received, err := rangeTransactions(stub, toIndexPrefix, userID, cursorTime, cursorID, endTime, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var transactions []Transaction
+	for _, transaction := range append(sent, received...) {
+		if seen[transaction.ID] {
+			continue
+		}
+		seen[transaction.ID] = true
+		transactions = append(transactions, transaction)
+	}
+	pageBytes, err := json.Marshal(pageTransactions(transactions, pageSize))
+	if err != nil {
+		return nil, errors.New("Error retrieving pageBytes")
+	}
+	return pageBytes, nil
+}
+
+/**
+ * [getTransactionsByTime lists all transactions between startTime and endTime]
+ * args: startTime, endTime, pageSize[, cursor]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, args []string [description]
+ * @return {[type]}      ([]byte, error)
+ */
+func getTransactionsByTime(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 && len(args) != 4 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 3 or 4")
+	}
+	endTime, pageSize, cursorTime, cursorID, err := parseHistoryArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := rangeTransactions(stub, timeIndexPrefix, "", cursorTime, cursorID, endTime, pageSize+1)
+	if err != nil {
+		return nil, err
+	}
+	pageBytes, err := json.Marshal(pageTransactions(transactions, pageSize))
+	if err != nil {
+		return nil, errors.New("Error retrieving pageBytes")
+	}
+	return pageBytes, nil
+}
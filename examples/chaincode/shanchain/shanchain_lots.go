@@ -0,0 +1,449 @@
+/*
+	author:krew
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Point lots. A user's balance is kept as lots, each remembering when its
+// points were issued and when they expire; User.Integral is always the sum
+// of the user's lots. Lots are spent oldest first and keep their issuance
+// and expiry when they move between users. Expired lots go back to the
+// RestIntegral of the owner's pool (its merchant's, or the root's), either
+// through the `expire` sweep or when their owner next spends points.
+const (
+	lotsKeyType   = "lots" //积分批次
+	secondsPerDay = 24 * 60 * 60
+	dateFormat    = "2006-01-02"
+)
+
+// Lot is a batch of points issued to a user at one time.
+type Lot struct {
+	Integral int   //积分
+	IssuedAt int64 //发放时间戳
+	ExpireAt int64 //过期时间戳, 0 为永不过期
+}
+
+// ExpiryBucket is the part of a balance expiring on one day.
+type ExpiryBucket struct {
+	Date     string //过期日期, 空为永不过期
+	ExpireAt int64  //最早过期时间戳
+	Integral int    //积分
+}
+
+// Balance is a user's balance broken down by expiry date.
+type Balance struct {
+	ID       string         //用户id
+	Integral int            //总积分
+	Expired  int            //已过期待回收积分
+	Buckets  []ExpiryBucket //按过期日期分组
+}
+
+type lotsByIssuance []Lot
+
+func (s lotsByIssuance) Len() int      { return len(s) }
+func (s lotsByIssuance) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s lotsByIssuance) Less(i, j int) bool {
+	if s[i].IssuedAt != s[j].IssuedAt {
+		return s[i].IssuedAt < s[j].IssuedAt
+	}
+	return s[i].ExpireAt < s[j].ExpireAt
+}
+
+func lotsKey(userID string) string {
+	return stateKey(lotsKeyType, userID)
+}
+
+func expiryDaysKey() string {
+	return stateKey(metaKeyType, "expiryDays")
+}
+
+/**
+ * [txTime returns the transaction timestamp in unix seconds]
+ * all ledger times come from the transaction so every validator agrees on them
+ * @param  {[type]} stub shim.ChaincodeStubInterface) (int64, error [description]
+ * @return {[type]}      [description]
+ */
+func txTime(stub shim.ChaincodeStubInterface) (int64, error) {
+	timestamp, err := stub.GetTxTimestamp()
+	if err != nil {
+		return 0, errors.New("GetTxTimestamp Error" + err.Error())
+	}
+	if timestamp == nil {
+		return 0, errors.New("transaction timestamp unavailable")
+	}
+	return timestamp.Seconds, nil
+}
+
+/**
+ * [getExpiryDays returns how many days newly issued points stay valid, 0 for ever]
+ * @param  {[type]} stub shim.ChaincodeStubInterface) (int, error [description]
+ * @return {[type]}      [description]
+ */
+func getExpiryDays(stub shim.ChaincodeStubInterface) (int, error) {
+	daysBytes, err := stub.GetState(expiryDaysKey())
+	if err != nil {
+		return 0, errors.New("GetState Error" + err.Error())
+	}
+	if daysBytes == nil {
+		return 0, nil
+	}
+	return strconv.Atoi(string(daysBytes))
+}
+
+/**
+ * [setPointExpiry sets the validity in days of points issued from now on]
+ * args: days (0 disables expiry)
+ * @param  {[type]} t *ShanChainAPI) setPointExpiry(stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}   [description]
+ */
+func (t *ShanChainAPI) setPointExpiry(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 1")
+	}
+	err := checkIssuer(stub)
+	if err != nil {
+		return nil, err
+	}
+	days, err := strconv.Atoi(args[0])
+	if err != nil || days < 0 {
+		return nil, errors.New("want non-negative Integer days")
+	}
+	err = stub.PutState(expiryDaysKey(), []byte(strconv.Itoa(days)))
+	if err != nil {
+		return nil, errors.New("PutState Error" + err.Error())
+	}
+	return []byte(strconv.Itoa(days)), nil
+}
+
+/**
+ * [getLots returns a user's lots ordered by issuance]
+ * users written before lots existed hold their whole balance as one lot that never expires
+ * @param  {[type]} stub shim.ChaincodeStubInterface, user User) ([]Lot, error [description]
+ * @return {[type]}      [description]
+ */
+func getLots(stub shim.ChaincodeStubInterface, user User) ([]Lot, error) {
+	var lots []Lot
+	lotsBytes, err := stub.GetState(lotsKey(user.ID))
+	if err != nil {
+		return nil, errors.New("GetState Error" + err.Error())
+	}
+	if lotsBytes == nil {
+		if user.Integral > 0 {
+			lots = append(lots, Lot{Integral: user.Integral})
+		}
+		return lots, nil
+	}
+	err = json.Unmarshal(lotsBytes, &lots)
+	if err != nil {
+		return nil, errors.New("Error unmarshalling lots")
+	}
+	return lots, nil
+}
+
+/**
+ * [writeLots stores a user's lots]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, userID string, lots []Lot) (error [description]
+ * @return {[type]}      [description]
+ */
+func writeLots(stub shim.ChaincodeStubInterface, userID string, lots []Lot) error {
+	if lots == nil {
+		lots = []Lot{}
+	}
+	lotsBytes, err := json.Marshal(lots)
+	if err != nil {
+		return err
+	}
+	err = stub.PutState(lotsKey(userID), lotsBytes)
+	if err != nil {
+		return errors.New("PutState Error" + err.Error())
+	}
+	return nil
+}
+
+/**
+ * [issueLot appends a lot of freshly issued points valid for the configured number of days]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, lots []Lot, number int, now int64) ([]Lot, error [description]
+ * @return {[type]}      [description]
+ */
+func issueLot(stub shim.ChaincodeStubInterface, lots []Lot, number int, now int64) ([]Lot, error) {
+	if number == 0 {
+		return lots, nil
+	}
+	days, err := getExpiryDays(stub)
+	if err != nil {
+		return nil, err
+	}
+	lot := Lot{Integral: number, IssuedAt: now}
+	if days > 0 {
+		lot.ExpireAt = now + int64(days)*secondsPerDay
+	}
+	return mergeLots(lots, []Lot{lot}), nil
+}
+
+/**
+ * [mergeLots adds lots to a user's lots keeping them ordered by issuance]
+ * @param  {[type]} lots []Lot, more []Lot) ([]Lot [description]
+ * @return {[type]}      [description]
+ */
+func mergeLots(lots []Lot, more []Lot) []Lot {
+	merged := append(append([]Lot{}, lots...), more...)
+	sort.Stable(lotsByIssuance(merged))
+	return merged
+}
+
+/**
+ * [takeLots removes number points from lots oldest first]
+ * the caller has checked that lots hold at least number points
+ * @param  {[type]} lots []Lot, number int) (taken []Lot, rest []Lot [description]
+ * @return {[type]}      [description]
+ */
+func takeLots(lots []Lot, number int) ([]Lot, []Lot) {
+	var taken, rest []Lot
+	for _, lot := range lots {
+		if number == 0 {
+			rest = append(rest, lot)
+			continue
+		}
+		if lot.Integral <= number {
+			taken = append(taken, lot)
+			number = number - lot.Integral
+			continue
+		}
+		part := lot
+		part.Integral = number
+		taken = append(taken, part)
+		lot.Integral = lot.Integral - number
+		number = 0
+		rest = append(rest, lot)
+	}
+	return taken, rest
+}
+
+/**
+ * [splitExpired separates the lots that have expired at now]
+ * @param  {[type]} lots []Lot, now int64) (expired int, rest []Lot [description]
+ * @return {[type]}      [description]
+ */
+func splitExpired(lots []Lot, now int64) (int, []Lot) {
+	var (
+		expired int
+		rest    []Lot
+	)
+	for _, lot := range lots {
+		if lot.ExpireAt != 0 && lot.ExpireAt <= now {
+			expired = expired + lot.Integral
+		} else {
+			rest = append(rest, lot)
+		}
+	}
+	return expired, rest
+}
+
+/**
+ * [expireUser returns the user's expired points to the user's pool]
+ * the caller writes user, lots and pool
+ * @param  {[type]} user *User, root *Root, lots []Lot, now int64) (expired int, rest []Lot [description]
+ * @return {[type]}      [description]
+ */
+func expireUser(user *User, root *Root, lots []Lot, now int64) (int, []Lot) {
+	expired, rest := splitExpired(lots, now)
+	user.Integral = user.Integral - expired
+	root.RestIntegral = root.RestIntegral + expired
+	return expired, rest
+}
+
+/**
+ * [expire sweeps expired points of every user back to the users' pools]
+ * args: none, or the IDs of the users to sweep
+ * @param  {[type]} t *ShanChainAPI) expire(stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}   [description]
+ */
+func (t *ShanChainAPI) expire(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	err := checkIssuer(stub)
+	if err != nil {
+		return nil, err
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := args
+	if len(userIDs) == 0 {
+		userIDs, err = lotOwners(stub)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// pools are written in the order they were first reclaimed into
+	pools := make(map[string]*Root)
+	poolTypes := make(map[string]int)
+	var poolIDs []string
+
+	total := 0
+	var balances []PartyBalance
+	for _, userID := range userIDs {
+		user, userBytes, err := getUser(stub, userID)
+		if err != nil {
+			return nil, errors.New("get user errors")
+		}
+		if userBytes == nil {
+			return nil, errors.New("user " + userID + " does not exist")
+		}
+		lots, err := getLots(stub, user)
+		if err != nil {
+			return nil, err
+		}
+		pool, poolType, err := userPool(stub, userID)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := pools[pool.ID]; !ok {
+			pools[pool.ID] = &pool
+			poolTypes[pool.ID] = poolType
+		}
+		expired, lots := expireUser(&user, pools[pool.ID], lots, now)
+		if expired == 0 {
+			continue
+		}
+		if !containsString(poolIDs, pool.ID) {
+			poolIDs = append(poolIDs, pool.ID)
+		}
+		err = writeUser(stub, user)
+		if err != nil {
+			return nil, errors.New("writeUser Error" + err.Error())
+		}
+		err = writeLots(stub, userID, lots)
+		if err != nil {
+			return nil, errors.New("write lots Error" + err.Error())
+		}
+		total = total + expired
+		balances = append(balances, PartyBalance{ID: userID, Integral: user.Integral})
+	}
+	if total > 0 {
+		for _, poolID := range poolIDs {
+			err = writePool(stub, *pools[poolID], poolTypes[poolID])
+			if err != nil {
+				return nil, errors.New("writeRoot Error" + err.Error())
+			}
+			balances = append(balances, PartyBalance{ID: poolID, Integral: pools[poolID].RestIntegral})
+		}
+		// ToID names the pool only when every expired point went to the same one
+		toID := ""
+		if len(poolIDs) == 1 {
+			toID = poolIDs[0]
+		}
+		err = emitBalanceEvent(stub, BalanceEvent{Type: "expire", Expired: total, ToID: toID, Time: now,
+			Balances: balances})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return []byte(strconv.Itoa(total)), nil
+}
+
+/**
+ * [lotOwners lists the users that hold lots, in key order]
+ * @param  {[type]} stub shim.ChaincodeStubInterface) ([]string, error [description]
+ * @return {[type]}      [description]
+ */
+func lotOwners(stub shim.ChaincodeStubInterface) ([]string, error) {
+	prefix := stateKey(lotsKeyType, "")
+	iter, err := stub.RangeQueryState(prefix, stateKey(lotsKeyType, indexMaxRune))
+	if err != nil {
+		return nil, errors.New("RangeQueryState Error" + err.Error())
+	}
+	defer iter.Close()
+	var userIDs []string
+	for iter.HasNext() {
+		key, _, err := iter.Next()
+		if err != nil {
+			return nil, errors.New("RangeQueryState Error" + err.Error())
+		}
+		userIDs = append(userIDs, key[len(prefix):])
+	}
+	sort.Strings(userIDs)
+	return userIDs, nil
+}
+
+/**
+ * [getUserBalance reports a user's balance broken down by expiry date]
+ * args: userID, time where time decides which lots have expired
+ * @param  {[type]} stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}      [description]
+ */
+func getUserBalance(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 2")
+	}
+	now, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return nil, errors.New("want Integer time")
+	}
+	user, userBytes, err := getUser(stub, args[0])
+	if err != nil {
+		return nil, errors.New("get user errors")
+	}
+	if userBytes == nil {
+		return nil, errors.New("user " + args[0] + " does not exist")
+	}
+	lots, err := getLots(stub, user)
+	if err != nil {
+		return nil, err
+	}
+	expired, lots := splitExpired(lots, now)
+	balance := Balance{ID: user.ID, Integral: user.Integral - expired, Expired: expired, Buckets: []ExpiryBucket{}}
+	buckets := make(map[string]int)
+	for _, lot := range lots {
+		date := ""
+		if lot.ExpireAt != 0 {
+			date = time.Unix(lot.ExpireAt, 0).UTC().Format(dateFormat)
+		}
+		i, ok := buckets[date]
+		if !ok {
+			buckets[date] = len(balance.Buckets)
+			balance.Buckets = append(balance.Buckets, ExpiryBucket{Date: date, ExpireAt: lot.ExpireAt, Integral: lot.Integral})
+			continue
+		}
+		if lot.ExpireAt < balance.Buckets[i].ExpireAt {
+			balance.Buckets[i].ExpireAt = lot.ExpireAt
+		}
+		balance.Buckets[i].Integral = balance.Buckets[i].Integral + lot.Integral
+	}
+	sort.Sort(bucketsByExpiry(balance.Buckets))
+	balanceBytes, err := json.Marshal(&balance)
+	if err != nil {
+		return nil, errors.New("Error retrieving balanceBytes")
+	}
+	return balanceBytes, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// bucketsByExpiry orders the earliest expiry first and never-expiring points last.
+type bucketsByExpiry []ExpiryBucket
+
+func (s bucketsByExpiry) Len() int      { return len(s) }
+func (s bucketsByExpiry) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s bucketsByExpiry) Less(i, j int) bool {
+	if s[i].ExpireAt == 0 || s[j].ExpireAt == 0 {
+		return s[j].ExpireAt == 0 && s[i].ExpireAt != 0
+	}
+	return s[i].ExpireAt < s[j].ExpireAt
+}
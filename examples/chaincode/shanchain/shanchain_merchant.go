@@ -0,0 +1,534 @@
+/*
+	author:krew
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Merchants. Every partner merchant has its own point pool, stored with the
+// same shape as the root pool: TotalIntegral is the budget it was granted and
+// RestIntegral what it has not paid out yet. A user opened for a merchant
+// exchanges steps against that merchant's pool and its expired points go back
+// there; users without a merchant belong to the root pool.
+//
+// Transfers between users of the same pool are always allowed. A transfer
+// across pools needs a TransferRule allowing it, and is recorded as a Flow
+// under both pools so settlement can be reported per period.
+const (
+	merchantKeyType = "merchant" //商户积分池
+	memberKeyType   = "member"   //用户所属商户
+	ruleKeyType     = "xrule"    //跨商户转账规则
+	flowKeyType     = "flow"     //商户结算流水
+	rootType        = 0          //交易方类型: 根账户
+	merchantType    = 2          //交易方类型: 商户
+)
+
+// TransferRule decides whether users of FromMerchant may pay users of ToMerchant.
+type TransferRule struct {
+	FromMerchant string //转出方商户id
+	ToMerchant   string //转入方商户id
+	Allowed      bool   //是否允许
+}
+
+// Flow is one movement of points paid out of, or between, pools.
+type Flow struct {
+	TxID         string //交易流水号
+	Type         string //类型: exchange, transfer
+	FromMerchant string //转出方积分池id
+	ToMerchant   string //转入方积分池id
+	Integral     int    //积分
+	Time         int64  //交易时间戳
+}
+
+// MerchantFlow sums the transfers between a pool and one counterparty.
+type MerchantFlow struct {
+	MerchantID string //对方积分池id
+	In         int    //转入积分
+	Out        int    //转出积分
+	Net        int    //净转入积分
+}
+
+// Settlement is a pool's account for [StartTime, EndTime].
+type Settlement struct {
+	MerchantID     string         //积分池id
+	StartTime      int64          //开始时间戳
+	EndTime        int64          //结束时间戳
+	Exchanged      int            //用户兑换积分
+	In             int            //跨商户转入积分
+	Out            int            //跨商户转出积分
+	Net            int            //跨商户净转入积分
+	Counterparties []MerchantFlow //按对方积分池汇总
+}
+
+func merchantKey(id string) string {
+	return stateKey(merchantKeyType, id)
+}
+
+func memberKey(userID string) string {
+	return stateKey(memberKeyType, userID)
+}
+
+func ruleKey(fromMerchant string, toMerchant string) string {
+	return stateKey(ruleKeyType, fromMerchant, toMerchant)
+}
+
+func flowKey(merchantID string, time int64, tsID string) string {
+	return stateKey(flowKeyType, merchantID, fmt.Sprintf(timeKeyFormat, time), tsID)
+}
+
+/**
+ * [getMerchant returns a merchant's pool, nil bytes when it does not exist]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, id string) (Root, []byte, error [description]
+ * @return {[type]}      [description]
+ */
+func getMerchant(stub shim.ChaincodeStubInterface, id string) (Root, []byte, error) {
+	var merchant Root
+	merchantBytes, err := stub.GetState(merchantKey(id))
+	if err != nil {
+		return merchant, nil, errors.New("GetState Error" + err.Error())
+	}
+	if merchantBytes == nil {
+		return merchant, nil, nil
+	}
+	err = json.Unmarshal(merchantBytes, &merchant)
+	if err != nil {
+		return merchant, nil, errors.New("Error unmarshalling merchant")
+	}
+	return merchant, merchantBytes, nil
+}
+
+/**
+ * [writeMerchant stores a merchant's pool]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, merchant Root) (error [description]
+ * @return {[type]}      [description]
+ */
+func writeMerchant(stub shim.ChaincodeStubInterface, merchant Root) error {
+	merchantBytes, err := json.Marshal(&merchant)
+	if err != nil {
+		return err
+	}
+	err = stub.PutState(merchantKey(merchant.ID), merchantBytes)
+	if err != nil {
+		return errors.New("PutState Error" + err.Error())
+	}
+	return nil
+}
+
+/**
+ * [userPool returns the pool a user exchanges against and its party type: the user's merchant, or the root]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, userID string) (Root, int, error [description]
+ * @return {[type]}      [description]
+ */
+func userPool(stub shim.ChaincodeStubInterface, userID string) (Root, int, error) {
+	merchantIDBytes, err := stub.GetState(memberKey(userID))
+	if err != nil {
+		return Root{}, 0, errors.New("GetState Error" + err.Error())
+	}
+	if merchantIDBytes == nil {
+		root, _, err := getRoot(stub)
+		if err != nil {
+			return Root{}, 0, errors.New("get root errors")
+		}
+		return root, rootType, nil
+	}
+	merchant, merchantBytes, err := getMerchant(stub, string(merchantIDBytes))
+	if err != nil {
+		return Root{}, 0, err
+	}
+	if merchantBytes == nil {
+		return Root{}, 0, errors.New("merchant " + string(merchantIDBytes) + " does not exist")
+	}
+	return merchant, merchantType, nil
+}
+
+/**
+ * [writePool stores the root pool or a merchant's pool]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, pool Root, poolType int) (error [description]
+ * @return {[type]}      [description]
+ */
+func writePool(stub shim.ChaincodeStubInterface, pool Root, poolType int) error {
+	if poolType == merchantType {
+		return writeMerchant(stub, pool)
+	}
+	return writeRoot(stub, pool)
+}
+
+/**
+ * [createMerchant opens a merchant with its own pool of budget points]
+ * args: merchantID, name, budget
+ * @param  {[type]} t *ShanChainAPI) createMerchant(stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}   [description]
+ */
+func (t *ShanChainAPI) createMerchant(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 3")
+	}
+	err := checkIssuer(stub)
+	if err != nil {
+		return nil, err
+	}
+	id := args[0]
+	budget, err := strconv.Atoi(args[2])
+	if err != nil || budget < 0 {
+		return nil, errors.New("want non-negative Integer budget")
+	}
+	root, _, err := getRoot(stub)
+	if err != nil {
+		return nil, errors.New("get root errors")
+	}
+	_, merchantBytes, err := getMerchant(stub, id)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" || id == root.ID || merchantBytes != nil {
+		return nil, errors.New("merchant " + id + " already exists")
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	merchant := Root{ID: id, Name: args[1], TotalIntegral: budget, RestIntegral: budget}
+	err = writeMerchant(stub, merchant)
+	if err != nil {
+		return nil, errors.New("writeMerchant Error" + err.Error())
+	}
+	err = emitBalanceEvent(stub, BalanceEvent{Type: "createMerchant", Delta: budget, ToID: id, Time: now,
+		Balances: []PartyBalance{{ID: id, Integral: merchant.RestIntegral}}})
+	if err != nil {
+		return nil, err
+	}
+	merchantBytes, err = json.Marshal(&merchant)
+	if err != nil {
+		return nil, errors.New("Error retrieving merchantBytes")
+	}
+	return merchantBytes, nil
+}
+
+/**
+ * [joinMerchant makes a new user a member of merchantID]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, userID string, merchantID string) (error [description]
+ * @return {[type]}      [description]
+ */
+func joinMerchant(stub shim.ChaincodeStubInterface, userID string, merchantID string) error {
+	_, merchantBytes, err := getMerchant(stub, merchantID)
+	if err != nil {
+		return err
+	}
+	if merchantBytes == nil {
+		return errors.New("merchant " + merchantID + " does not exist")
+	}
+	err = stub.PutState(memberKey(userID), []byte(merchantID))
+	if err != nil {
+		return errors.New("PutState Error" + err.Error())
+	}
+	return nil
+}
+
+/**
+ * [getTransferRule returns the rule for transfers from one pool to another, denied when unset]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, fromMerchant string, toMerchant string) (TransferRule, []byte, error [description]
+ * @return {[type]}      [description]
+ */
+func getTransferRule(stub shim.ChaincodeStubInterface, fromMerchant string, toMerchant string) (TransferRule, []byte, error) {
+	rule := TransferRule{FromMerchant: fromMerchant, ToMerchant: toMerchant}
+	ruleBytes, err := stub.GetState(ruleKey(fromMerchant, toMerchant))
+	if err != nil {
+		return rule, nil, errors.New("GetState Error" + err.Error())
+	}
+	if ruleBytes == nil {
+		ruleBytes, err = json.Marshal(&rule)
+		if err != nil {
+			return rule, nil, errors.New("Error retrieving ruleBytes")
+		}
+		return rule, ruleBytes, nil
+	}
+	err = json.Unmarshal(ruleBytes, &rule)
+	if err != nil {
+		return rule, nil, errors.New("Error unmarshalling rule")
+	}
+	return rule, ruleBytes, nil
+}
+
+/**
+ * [setTransferRule allows or denies transfers from users of one pool to users of another]
+ * args: fromMerchantID, toMerchantID, allowed (the root pool is addressed by the root ID)
+ * @param  {[type]} t *ShanChainAPI) setTransferRule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}   [description]
+ */
+func (t *ShanChainAPI) setTransferRule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 3")
+	}
+	err := checkIssuer(stub)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := strconv.ParseBool(args[2])
+	if err != nil {
+		return nil, errors.New("want Boolean allowed")
+	}
+	if args[0] == args[1] {
+		return nil, errors.New("transfers within a merchant are always allowed")
+	}
+	root, _, err := getRoot(stub)
+	if err != nil {
+		return nil, errors.New("get root errors")
+	}
+	for _, id := range args[:2] {
+		_, merchantBytes, err := getMerchant(stub, id)
+		if err != nil {
+			return nil, err
+		}
+		if id != root.ID && merchantBytes == nil {
+			return nil, errors.New("merchant " + id + " does not exist")
+		}
+	}
+	rule := TransferRule{FromMerchant: args[0], ToMerchant: args[1], Allowed: allowed}
+	ruleBytes, err := json.Marshal(&rule)
+	if err != nil {
+		return nil, errors.New("Error retrieving ruleBytes")
+	}
+	err = stub.PutState(ruleKey(rule.FromMerchant, rule.ToMerchant), ruleBytes)
+	if err != nil {
+		return nil, errors.New("PutState Error" + err.Error())
+	}
+	return ruleBytes, nil
+}
+
+/**
+ * [checkTransferRule fails unless users of fromMerchant may pay users of toMerchant]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, fromMerchant string, toMerchant string) (error [description]
+ * @return {[type]}      [description]
+ */
+func checkTransferRule(stub shim.ChaincodeStubInterface, fromMerchant string, toMerchant string) error {
+	if fromMerchant == toMerchant {
+		return nil
+	}
+	rule, _, err := getTransferRule(stub, fromMerchant, toMerchant)
+	if err != nil {
+		return err
+	}
+	if !rule.Allowed {
+		return errors.New("transfer from merchant " + fromMerchant + " to merchant " + toMerchant + " is not allowed")
+	}
+	return nil
+}
+
+/**
+ * [writeFlow records a flow under every pool it involves]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, flow Flow) (error [description]
+ * @return {[type]}      [description]
+ */
+func writeFlow(stub shim.ChaincodeStubInterface, flow Flow) error {
+	flowBytes, err := json.Marshal(&flow)
+	if err != nil {
+		return err
+	}
+	merchantIDs := []string{flow.FromMerchant}
+	if flow.ToMerchant != flow.FromMerchant {
+		merchantIDs = append(merchantIDs, flow.ToMerchant)
+	}
+	for _, merchantID := range merchantIDs {
+		err = stub.PutState(flowKey(merchantID, flow.Time, flow.TxID), flowBytes)
+		if err != nil {
+			return errors.New("PutState Error" + err.Error())
+		}
+	}
+	return nil
+}
+
+/**
+ * [settle adds up the flows of a pool between startTime and endTime]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, merchantID string, startTime int64, endTime int64) (Settlement, error [description]
+ * @return {[type]}      [description]
+ */
+func settle(stub shim.ChaincodeStubInterface, merchantID string, startTime int64, endTime int64) (Settlement, error) {
+	settlement := Settlement{MerchantID: merchantID, StartTime: startTime, EndTime: endTime, Counterparties: []MerchantFlow{}}
+	startKey := stateKey(flowKeyType, merchantID, fmt.Sprintf(timeKeyFormat, startTime))
+	endKey := stateKey(flowKeyType, merchantID, fmt.Sprintf(timeKeyFormat, endTime), indexMaxRune)
+	iter, err := stub.RangeQueryState(startKey, endKey)
+	if err != nil {
+		return settlement, errors.New("RangeQueryState Error" + err.Error())
+	}
+	defer iter.Close()
+
+	counterparties := make(map[string]int)
+	for iter.HasNext() {
+		_, flowBytes, err := iter.Next()
+		if err != nil {
+			return settlement, errors.New("RangeQueryState Error" + err.Error())
+		}
+		var flow Flow
+		err = json.Unmarshal(flowBytes, &flow)
+		if err != nil {
+			return settlement, errors.New("Error unmarshalling flow")
+		}
+		if flow.FromMerchant == flow.ToMerchant {
+			settlement.Exchanged = settlement.Exchanged + flow.Integral
+			continue
+		}
+		counterparty := flow.FromMerchant
+		if counterparty == merchantID {
+			counterparty = flow.ToMerchant
+		}
+		i, ok := counterparties[counterparty]
+		if !ok {
+			i = len(settlement.Counterparties)
+			counterparties[counterparty] = i
+			settlement.Counterparties = append(settlement.Counterparties, MerchantFlow{MerchantID: counterparty})
+		}
+		if flow.FromMerchant == merchantID {
+			settlement.Out = settlement.Out + flow.Integral
+			settlement.Counterparties[i].Out = settlement.Counterparties[i].Out + flow.Integral
+		} else {
+			settlement.In = settlement.In + flow.Integral
+			settlement.Counterparties[i].In = settlement.Counterparties[i].In + flow.Integral
+		}
+	}
+	settlement.Net = settlement.In - settlement.Out
+	for i := range settlement.Counterparties {
+		settlement.Counterparties[i].Net = settlement.Counterparties[i].In - settlement.Counterparties[i].Out
+	}
+	sort.Sort(flowsByMerchant(settlement.Counterparties))
+	return settlement, nil
+}
+
+type flowsByMerchant []MerchantFlow
+
+func (s flowsByMerchant) Len() int           { return len(s) }
+func (s flowsByMerchant) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s flowsByMerchant) Less(i, j int) bool { return s[i].MerchantID < s[j].MerchantID }
+
+/**
+ * [parseSettlementPeriod parses startTime and endTime]
+ * @param  {[type]} args []string) (int64, int64, error [description]
+ * @return {[type]}      [description]
+ */
+func parseSettlementPeriod(args []string) (int64, int64, error) {
+	startTime, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("want Integer startTime")
+	}
+	endTime, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("want Integer endTime")
+	}
+	if startTime < 0 || endTime < startTime {
+		return 0, 0, errors.New("want 0 <= startTime <= endTime")
+	}
+	return startTime, endTime, nil
+}
+
+/**
+ * [getMerchantQuery returns a merchant's pool]
+ * args: merchantID
+ * @param  {[type]} stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}      [description]
+ */
+func getMerchantQuery(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 1")
+	}
+	_, merchantBytes, err := getMerchant(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if merchantBytes == nil {
+		return nil, errors.New("merchant " + args[0] + " does not exist")
+	}
+	return merchantBytes, nil
+}
+
+/**
+ * [getSettlement reports a pool's exchanges and cross-merchant transfers over a period]
+ * args: merchantID (or the root ID), startTime, endTime
+ * @param  {[type]} stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}      [description]
+ */
+func getSettlement(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 3")
+	}
+	startTime, endTime, err := parseSettlementPeriod(args[1:])
+	if err != nil {
+		return nil, err
+	}
+	root, _, err := getRoot(stub)
+	if err != nil {
+		return nil, errors.New("get root errors")
+	}
+	_, merchantBytes, err := getMerchant(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if args[0] != root.ID && merchantBytes == nil {
+		return nil, errors.New("merchant " + args[0] + " does not exist")
+	}
+	settlement, err := settle(stub, args[0], startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	settlementBytes, err := json.Marshal(&settlement)
+	if err != nil {
+		return nil, errors.New("Error retrieving settlementBytes")
+	}
+	return settlementBytes, nil
+}
+
+/**
+ * [getSettlements reports every pool over a period: the root first, then merchants by ID]
+ * args: startTime, endTime
+ * @param  {[type]} stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}      [description]
+ */
+func getSettlements(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 2")
+	}
+	startTime, endTime, err := parseSettlementPeriod(args)
+	if err != nil {
+		return nil, err
+	}
+	root, _, err := getRoot(stub)
+	if err != nil {
+		return nil, errors.New("get root errors")
+	}
+	merchantIDs := []string{root.ID}
+	prefix := stateKey(merchantKeyType, "")
+	iter, err := stub.RangeQueryState(prefix, stateKey(merchantKeyType, indexMaxRune))
+	if err != nil {
+		return nil, errors.New("RangeQueryState Error" + err.Error())
+	}
+	for iter.HasNext() {
+		key, _, err := iter.Next()
+		if err != nil {
+			iter.Close()
+			return nil, errors.New("RangeQueryState Error" + err.Error())
+		}
+		merchantIDs = append(merchantIDs, key[len(prefix):])
+	}
+	iter.Close()
+
+	settlements := []Settlement{}
+	for _, merchantID := range merchantIDs {
+		settlement, err := settle(stub, merchantID, startTime, endTime)
+		if err != nil {
+			return nil, err
+		}
+		settlements = append(settlements, settlement)
+	}
+	settlementsBytes, err := json.Marshal(settlements)
+	if err != nil {
+		return nil, errors.New("Error retrieving settlementsBytes")
+	}
+	return settlementsBytes, nil
+}
@@ -0,0 +1,317 @@
+/*
+	author:krew
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Exchange policy. `exchange` converts a step count into points with the
+// policy in force: steps are paid tier by tier (steps above a tier's
+// FromSteps earn that tier's rate), the result is scaled by the best active
+// promotion and capped by what the user may still earn that day (UTC).
+// Every `setExchangePolicy` stores a new version; old versions are kept.
+const (
+	policyKeyType   = "policy" //兑换规则
+	dailyKeyType    = "daily"  //每日兑换额度
+	policyKeyFormat = "%010d"  //版本号定长格式
+	percentBase     = 100
+)
+
+// RateTier pays one point per StepsPerPoint steps for the steps above FromSteps.
+type RateTier struct {
+	FromSteps     int //起始步数
+	StepsPerPoint int //每积分步数
+}
+
+// Promotion multiplies the points of exchanges made in [Start, End) by Percent/100.
+type Promotion struct {
+	Name    string //活动名称
+	Percent int    //倍率百分比
+	Start   int64  //开始时间戳
+	End     int64  //结束时间戳
+}
+
+// ExchangePolicy is one version of the step-to-point conversion rules.
+type ExchangePolicy struct {
+	Version    int         //版本号
+	Tiers      []RateTier  //阶梯兑换率
+	DailyCap   int         //每用户每日积分上限, 0 为不限
+	Promotions []Promotion //促销活动
+	UpdatedAt  int64       //生效时间戳
+}
+
+// defaultExchangePolicy is in force until an issuer sets one: one point per step.
+var defaultExchangePolicy = ExchangePolicy{Tiers: []RateTier{{FromSteps: 0, StepsPerPoint: 1}}}
+
+func policyKey(version int) string {
+	return stateKey(policyKeyType, fmt.Sprintf(policyKeyFormat, version))
+}
+
+func currentPolicyKey() string {
+	return stateKey(policyKeyType, "current")
+}
+
+func dailyKey(userID string, now int64) string {
+	return stateKey(dailyKeyType, userID, time.Unix(now, 0).UTC().Format(dateFormat))
+}
+
+/**
+ * [validatePolicy checks that a policy can be applied]
+ * @param  {[type]} policy ExchangePolicy) (error [description]
+ * @return {[type]}        [description]
+ */
+func validatePolicy(policy ExchangePolicy) error {
+	if len(policy.Tiers) == 0 || policy.Tiers[0].FromSteps != 0 {
+		return errors.New("policy needs a first tier starting at 0 steps")
+	}
+	for i, tier := range policy.Tiers {
+		if tier.StepsPerPoint <= 0 {
+			return errors.New("policy tier StepsPerPoint must be positive")
+		}
+		if i > 0 && tier.FromSteps <= policy.Tiers[i-1].FromSteps {
+			return errors.New("policy tiers must have increasing FromSteps")
+		}
+	}
+	if policy.DailyCap < 0 {
+		return errors.New("policy DailyCap must not be negative")
+	}
+	for _, promotion := range policy.Promotions {
+		if promotion.Percent <= 0 {
+			return errors.New("promotion Percent must be positive")
+		}
+		if promotion.End <= promotion.Start {
+			return errors.New("promotion must end after it starts")
+		}
+	}
+	return nil
+}
+
+/**
+ * [getPolicy returns the policy in force, or version when it is not 0]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, version int) (ExchangePolicy, []byte, error [description]
+ * @return {[type]}      [description]
+ */
+func getPolicy(stub shim.ChaincodeStubInterface, version int) (ExchangePolicy, []byte, error) {
+	var policy ExchangePolicy
+	key := currentPolicyKey()
+	if version != 0 {
+		key = policyKey(version)
+	}
+	policyBytes, err := stub.GetState(key)
+	if err != nil {
+		return policy, nil, errors.New("GetState Error" + err.Error())
+	}
+	if policyBytes == nil {
+		if version != 0 {
+			return policy, nil, errors.New("exchange policy version " + strconv.Itoa(version) + " does not exist")
+		}
+		policy = defaultExchangePolicy
+		policyBytes, err = json.Marshal(&policy)
+		if err != nil {
+			return policy, nil, errors.New("Error retrieving policyBytes")
+		}
+		return policy, policyBytes, nil
+	}
+	err = json.Unmarshal(policyBytes, &policy)
+	if err != nil {
+		return policy, nil, errors.New("Error unmarshalling policy")
+	}
+	return policy, policyBytes, nil
+}
+
+/**
+ * [setExchangePolicy stores a new version of the exchange policy]
+ * args: policy JSON (Tiers, DailyCap, Promotions); Version and UpdatedAt are assigned here
+ * @param  {[type]} t *ShanChainAPI) setExchangePolicy(stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}   [description]
+ */
+func (t *ShanChainAPI) setExchangePolicy(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 1")
+	}
+	err := checkIssuer(stub)
+	if err != nil {
+		return nil, err
+	}
+	var policy ExchangePolicy
+	err = json.Unmarshal([]byte(args[0]), &policy)
+	if err != nil {
+		return nil, errors.New("Error unmarshalling policy")
+	}
+	err = validatePolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	current, _, err := getPolicy(stub, 0)
+	if err != nil {
+		return nil, err
+	}
+	policy.Version = current.Version + 1
+	policy.UpdatedAt, err = txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	policyBytes, err := json.Marshal(&policy)
+	if err != nil {
+		return nil, errors.New("Error retrieving policyBytes")
+	}
+	err = stub.PutState(policyKey(policy.Version), policyBytes)
+	if err == nil {
+		err = stub.PutState(currentPolicyKey(), policyBytes)
+	}
+	if err != nil {
+		return nil, errors.New("PutState Error" + err.Error())
+	}
+	return policyBytes, nil
+}
+
+/**
+ * [stepPoints converts steps into points with the policy's tiers and promotions at now]
+ * @param  {[type]} policy ExchangePolicy, steps int, now int64) (int [description]
+ * @return {[type]}        [description]
+ */
+func stepPoints(policy ExchangePolicy, steps int, now int64) int {
+	points := 0
+	for i, tier := range policy.Tiers {
+		if steps <= tier.FromSteps {
+			break
+		}
+		tierSteps := steps - tier.FromSteps
+		if i+1 < len(policy.Tiers) && steps > policy.Tiers[i+1].FromSteps {
+			tierSteps = policy.Tiers[i+1].FromSteps - tier.FromSteps
+		}
+		points = points + tierSteps/tier.StepsPerPoint
+	}
+	percent := percentBase
+	for _, promotion := range policy.Promotions {
+		if promotion.Start <= now && now < promotion.End && promotion.Percent > percent {
+			percent = promotion.Percent
+		}
+	}
+	return points * percent / percentBase
+}
+
+/**
+ * [getDailyExchanged returns the points a user has earned through exchange on the day of now]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, userID string, now int64) (int, error [description]
+ * @return {[type]}      [description]
+ */
+func getDailyExchanged(stub shim.ChaincodeStubInterface, userID string, now int64) (int, error) {
+	dailyBytes, err := stub.GetState(dailyKey(userID, now))
+	if err != nil {
+		return 0, errors.New("GetState Error" + err.Error())
+	}
+	if dailyBytes == nil {
+		return 0, nil
+	}
+	return strconv.Atoi(string(dailyBytes))
+}
+
+/**
+ * [exchangePoints works out the points steps earn for a user now, within the daily cap]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, userID string, steps int, now int64) (int, error [description]
+ * @return {[type]}      [description]
+ */
+func exchangePoints(stub shim.ChaincodeStubInterface, userID string, steps int, now int64) (int, error) {
+	policy, _, err := getPolicy(stub, 0)
+	if err != nil {
+		return 0, err
+	}
+	points := stepPoints(policy, steps, now)
+	if points == 0 {
+		return 0, errors.New("steps are worth no points")
+	}
+	if policy.DailyCap == 0 {
+		return points, nil
+	}
+	exchanged, err := getDailyExchanged(stub, userID, now)
+	if err != nil {
+		return 0, err
+	}
+	if exchanged >= policy.DailyCap {
+		return 0, errors.New("daily exchange cap reached")
+	}
+	if points > policy.DailyCap-exchanged {
+		points = policy.DailyCap - exchanged
+	}
+	return points, nil
+}
+
+/**
+ * [addDailyExchanged records points a user earned through exchange on the day of now]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, userID string, now int64, points int) (error [description]
+ * @return {[type]}      [description]
+ */
+func addDailyExchanged(stub shim.ChaincodeStubInterface, userID string, now int64, points int) error {
+	exchanged, err := getDailyExchanged(stub, userID, now)
+	if err != nil {
+		return err
+	}
+	err = stub.PutState(dailyKey(userID, now), []byte(strconv.Itoa(exchanged+points)))
+	if err != nil {
+		return errors.New("PutState Error" + err.Error())
+	}
+	return nil
+}
+
+/**
+ * [getExchangePolicy returns the policy in force or the requested version]
+ * args: [version]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}      [description]
+ */
+func getExchangePolicy(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) > 1 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 0 or 1")
+	}
+	version := 0
+	if len(args) == 1 {
+		var err error
+		version, err = strconv.Atoi(args[0])
+		if err != nil || version <= 0 {
+			return nil, errors.New("want positive Integer version")
+		}
+	}
+	_, policyBytes, err := getPolicy(stub, version)
+	if err != nil {
+		return nil, err
+	}
+	return policyBytes, nil
+}
+
+/**
+ * [getExchangePolicyHistory returns every stored policy version, oldest first]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}      [description]
+ */
+func getExchangePolicyHistory(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 0")
+	}
+	current, _, err := getPolicy(stub, 0)
+	if err != nil {
+		return nil, err
+	}
+	policies := []ExchangePolicy{}
+	for version := 1; version <= current.Version; version++ {
+		policy, _, err := getPolicy(stub, version)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+	historyBytes, err := json.Marshal(policies)
+	if err != nil {
+		return nil, errors.New("Error retrieving historyBytes")
+	}
+	return historyBytes, nil
+}
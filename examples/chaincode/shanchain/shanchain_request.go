@@ -0,0 +1,145 @@
+/*
+	author:krew
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Idempotent invokes. `exchange` and `transfer` take an optional trailing
+// client request ID. The first invoke carrying it is applied and recorded,
+// with its result, under the account it debits or credits; a retry with the
+// same request ID returns the recorded result without applying it again.
+// Only committed invokes are recorded: a failed invoke leaves no state, so
+// its request ID may be retried.
+const (
+	requestKeyType   = "req"       //客户端请求
+	requestCommitted = "committed" //已执行
+	requestUnknown   = "unknown"   //未执行
+)
+
+// RequestRecord is the outcome of an invoke keyed by a client request ID.
+type RequestRecord struct {
+	RequestID string          //客户端请求id
+	UserID    string          //请求所属用户id
+	Status    string          //请求状态: committed, unknown
+	Function  string          //调用方法
+	Args      []string        //调用参数
+	TxID      string          //执行交易id
+	Time      int64           //执行时间戳
+	Result    json.RawMessage //执行结果
+}
+
+func requestKey(userID string, requestID string) string {
+	return stateKey(requestKeyType, userID, requestID)
+}
+
+/**
+ * [getRequest returns the record of a client request, nil bytes when it was never applied]
+ * @param  {[type]} stub shim.ChaincodeStubInterface, userID string, requestID string) (RequestRecord, []byte, error [description]
+ * @return {[type]}      [description]
+ */
+func getRequest(stub shim.ChaincodeStubInterface, userID string, requestID string) (RequestRecord, []byte, error) {
+	var record RequestRecord
+	recordBytes, err := stub.GetState(requestKey(userID, requestID))
+	if err != nil {
+		return record, nil, errors.New("GetState Error" + err.Error())
+	}
+	if recordBytes == nil {
+		return record, nil, nil
+	}
+	err = json.Unmarshal(recordBytes, &record)
+	if err != nil {
+		return record, nil, errors.New("Error unmarshalling request")
+	}
+	return record, recordBytes, nil
+}
+
+func sameArgs(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+ * [idempotent applies an invoke once per client request ID and replays its recorded result afterwards]
+ * args[0] is the account the request belongs to; its owner must sign retries as well.
+ * @param  {[type]} stub shim.ChaincodeStubInterface, function string, args []string, requestID string, apply func(shim.ChaincodeStubInterface, []string) ([]byte, error)) ([]byte, error [description]
+ * @return {[type]}      [description]
+ */
+func idempotent(stub shim.ChaincodeStubInterface, function string, args []string, requestID string, apply func(shim.ChaincodeStubInterface, []string) ([]byte, error)) ([]byte, error) {
+	if requestID == "" {
+		return nil, errors.New("want non-empty request ID")
+	}
+	userID := args[0]
+	err := checkOwner(stub, userID)
+	if err != nil {
+		return nil, err
+	}
+	record, recordBytes, err := getRequest(stub, userID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if recordBytes != nil {
+		if record.Function != function || !sameArgs(record.Args, args) {
+			return nil, errors.New("request " + requestID + " was already used for a different invoke")
+		}
+		return record.Result, nil
+	}
+
+	result, err := apply(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	now, err := txTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	record = RequestRecord{RequestID: requestID, UserID: userID, Status: requestCommitted, Function: function,
+		Args: args, TxID: stub.GetTxID(), Time: now, Result: result}
+	recordBytes, err = json.Marshal(&record)
+	if err != nil {
+		return nil, errors.New("Error retrieving recordBytes")
+	}
+	err = stub.PutState(requestKey(userID, requestID), recordBytes)
+	if err != nil {
+		return nil, errors.New("PutState Error" + err.Error())
+	}
+	return result, nil
+}
+
+/**
+ * [getRequestStatus returns the record of a client request, or status unknown if it was never applied]
+ * args: userID, requestID
+ * @param  {[type]} stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}      [description]
+ */
+func getRequestStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 2")
+	}
+	record, recordBytes, err := getRequest(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if recordBytes != nil {
+		return recordBytes, nil
+	}
+	record = RequestRecord{RequestID: args[1], UserID: args[0], Status: requestUnknown}
+	recordBytes, err = json.Marshal(&record)
+	if err != nil {
+		return nil, errors.New("Error retrieving recordBytes")
+	}
+	return recordBytes, nil
+}
@@ -0,0 +1,171 @@
+/*
+	author:krew
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/shim"
+)
+
+// Storage layout. Every record lives under
+//
+//	<layoutVersion> \x00 <keyType> \x00 <id parts...>
+//
+// so records of different types can never overwrite each other. The flat
+// layout used before versioning stored the root under "root" and users and
+// transactions under their bare IDs; `migrate` rewrites such a ledger.
+const (
+	keySeparator       = "\x00" //键分隔符
+	layoutVersion      = "v1"   //存储布局版本
+	metaKeyType        = "meta" //元数据
+	rootKeyType        = "root" //根账户
+	userKeyType        = "user" //用户
+	transactionKeyType = "tx"   //交易
+	indexKeyType       = "idx"  //交易索引
+	legacyRootKey      = "root" //旧布局根账户键
+)
+
+/**
+ * [stateKey builds a namespaced key in the current storage layout]
+ * @param  {[type]} keyType string, parts ...string [description]
+ * @return {[type]} string
+ */
+func stateKey(keyType string, parts ...string) string {
+	return strings.Join(append([]string{layoutVersion, keyType}, parts...), keySeparator)
+}
+
+func layoutKey() string {
+	return stateKey(metaKeyType, "layout")
+}
+
+func rootKey() string {
+	return stateKey(rootKeyType)
+}
+
+func userKey(id string) string {
+	return stateKey(userKeyType, id)
+}
+
+func transactionKey(tsID string) string {
+	return stateKey(transactionKeyType, tsID)
+}
+
+/**
+ * [writeLayoutVersion records the storage layout version the ledger uses]
+ * @param  {[type]} stub shim.ChaincodeStubInterface) (error [description]
+ * @return {[type]}      [description]
+ */
+func writeLayoutVersion(stub shim.ChaincodeStubInterface) error {
+	err := stub.PutState(layoutKey(), []byte(layoutVersion))
+	if err != nil {
+		return errors.New("PutState Error" + err.Error())
+	}
+	return nil
+}
+
+/**
+ * [migrate rewrites a ledger written with the flat key layout into the current layout]
+ * Every key outside the current layout is read once: "root" becomes the root record,
+ * JSON objects carrying a FromID become transactions (with their indexes rebuilt),
+ * other JSON objects become users and anything else is dropped.
+ * @param  {[type]} t *ShanChainAPI) migrate(stub shim.ChaincodeStubInterface, args []string) ([]byte, error [description]
+ * @return {[type]}   [description]
+ */
+func (t *ShanChainAPI) migrate(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, errors.New("Incorrect number of arguments. Expecting 0")
+	}
+	err := checkIssuer(stub)
+	if err != nil {
+		return nil, err
+	}
+	versionBytes, err := stub.GetState(layoutKey())
+	if err != nil {
+		return nil, errors.New("GetState Error" + err.Error())
+	}
+	if versionBytes != nil {
+		return nil, errors.New("storage layout " + string(versionBytes) + " already in use")
+	}
+
+	iter, err := stub.RangeQueryState("", "")
+	if err != nil {
+		return nil, errors.New("RangeQueryState Error" + err.Error())
+	}
+	legacy := make(map[string][]byte)
+	var legacyKeys []string
+	for iter.HasNext() {
+		key, value, err := iter.Next()
+		if err != nil {
+			iter.Close()
+			return nil, errors.New("RangeQueryState Error" + err.Error())
+		}
+		if strings.HasPrefix(key, layoutVersion+keySeparator) {
+			continue
+		}
+		legacy[key] = value
+		legacyKeys = append(legacyKeys, key)
+	}
+	iter.Close()
+
+	var (
+		users        int
+		transactions int
+	)
+	for _, key := range legacyKeys {
+		value := legacy[key]
+		if key == legacyRootKey {
+			var root Root
+			if json.Unmarshal(value, &root) != nil {
+				return nil, errors.New("Error unmarshalling root")
+			}
+			err = writeRoot(stub, root)
+			if err != nil {
+				return nil, errors.New("writeRoot Error" + err.Error())
+			}
+		} else if fields := make(map[string]json.RawMessage); json.Unmarshal(value, &fields) == nil {
+			if _, ok := fields["FromID"]; ok {
+				var transaction Transaction
+				if json.Unmarshal(value, &transaction) != nil {
+					return nil, errors.New("Error unmarshalling transaction " + key)
+				}
+				err = writeTransaction(stub, transaction)
+				if err == nil {
+					err = writeTransactionIndexes(stub, transaction)
+				}
+				if err != nil {
+					return nil, errors.New("write transaction Error" + err.Error())
+				}
+				transactions++
+			} else {
+				var user User
+				if json.Unmarshal(value, &user) != nil {
+					return nil, errors.New("Error unmarshalling user " + key)
+				}
+				err = writeUser(stub, user)
+				if err != nil {
+					return nil, errors.New("writeUser Error" + err.Error())
+				}
+				users++
+			}
+		}
+		err = stub.DelState(key)
+		if err != nil {
+			return nil, errors.New("DelState Error" + err.Error())
+		}
+	}
+
+	err = writeLayoutVersion(stub)
+	if err != nil {
+		return nil, errors.New("write layout version Error" + err.Error())
+	}
+	resultBytes, err := json.Marshal(map[string]interface{}{"Version": layoutVersion, "Users": users, "Transactions": transactions})
+	if err != nil {
+		return nil, errors.New("Error retrieving resultBytes")
+	}
+	return resultBytes, nil
+}
@@ -0,0 +1,408 @@
+package main
+
+import (
+  "encoding/json"
+  "fmt"
+  "math/rand"
+  "strconv"
+  "strings"
+  "testing"
+
+  "github.com/hyperledger/fabric/core/chaincode/shim"
+  "github.com/op/go-logging"
+)
+
+// newFixture returns a ledger with the root pool, two merchants and a user
+// in each pool, so every Invoke and Query path has something to act on. The
+// opening balances and the exchange come out of the pools, so the fixture
+// already passes checkConservation:
+//
+//	root  0001 shanchain      50000 (user 10086 holds 100+900, 10000 holds 0)
+//	m1    merchant one         1000 (user u1)
+//	m2    merchant two          500 (user u2)
+func newFixture(t *testing.T) *shim.MockStub {
+  stub := shim.NewMockStub("shanchain_api", new(ShanChainAPI))
+  checkInit(t, stub, []string{"shanchain", "50000"})
+  steps := [][]string{
+    {"createMerchant", "m1", "merchant one", "1000"},
+    {"createMerchant", "m2", "merchant two", "500"},
+    {"createUser", "10086", "china mobile", "100"},
+    {"createUser", "10000", "china unicom", "0"},
+    {"createUser", "u1", "user one", "0", "m1"},
+    {"createUser", "u2", "user two", "0", "m2"},
+  }
+  for _, step := range steps {
+    _, err := invokeAs(stub, issuer, "setup", step[0], step[1:])
+    if err != nil {
+      fmt.Println("fixture", step, "failed", err)
+      t.FailNow()
+    }
+  }
+  _, err := invokeAs(stub, client("10086"), "tx1", "exchange", []string{"10086", "900"})
+  if err != nil {
+    fmt.Println("fixture exchange failed", err)
+    t.FailNow()
+  }
+  checkConservation(t, stub, "fixture")
+  return stub
+}
+
+type invokeCase struct {
+  attrs    map[string]string
+  function string
+  args     []string
+  expect   string
+}
+
+func TestShanchain_Invoke_Validation(t *testing.T) {
+  merchant := map[string]string{"role": "merchant", "userid": "m1"}
+  cases := []invokeCase{
+    {issuer, "helloworld", []string{}, "Received unknown function invocation"},
+
+    {issuer, "additional", []string{}, "Incorrect number of arguments. Expecting 1 or 2"},
+    {client("10086"), "additional", []string{"100"}, "caller is not issuer"},
+    {issuer, "additional", []string{"f00"}, "want Integer number"},
+    {issuer, "additional", []string{"-1"}, "want positive Integer number"},
+    {issuer, "additional", []string{"100", "m9"}, "merchant m9 does not exist"},
+
+    {issuer, "createUser", []string{"1"}, "Incorrect number of arguments. Expecting 3 or 4"},
+    {client("10086"), "createUser", []string{"1", "one", "0"}, "caller is not issuer"},
+    {merchant, "createUser", []string{"1", "one", "0", "m2"}, "caller is not merchant m2"},
+    {issuer, "createUser", []string{"1", "one", "f00"}, "Expecting integer value for asset holding"},
+    {issuer, "createUser", []string{"10086", "one", "0"}, "user 10086 already exists"},
+    {issuer, "createUser", []string{"1", "one", "-1"}, "want positive Integer number"},
+    {issuer, "createUser", []string{"1", "one", "0", "m9"}, "merchant m9 does not exist"},
+    {issuer, "createUser", []string{"1", "one", "60000"}, "Root 剩余善圆不足"},
+    {issuer, "createUser", []string{"1", "one", "600", "m2"}, "Merchant m2 剩余善圆不足"},
+
+    {client("10086"), "exchange", []string{"10086"}, "Incorrect number of arguments. Expecting 2 or 3"},
+    {client("10000"), "exchange", []string{"10086", "1"}, "caller is not the owner of user 10086"},
+    {client("10086"), "exchange", []string{"10086", "f00"}, "want Integer number"},
+    {client("10086"), "exchange", []string{"10086", "0"}, "want positive Integer number"},
+    {client("9"), "exchange", []string{"9", "1"}, "user 9 does not exist"},
+    {client("10086"), "exchange", []string{"10086", "60000"}, "Root 剩余善圆不足"},
+    {client("u2"), "exchange", []string{"u2", "600"}, "Merchant m2 剩余善圆不足"},
+    {client("10086"), "exchange", []string{"10086", "1"}, "transaction tx1 already exists"},
+    {client("10086"), "exchange", []string{"10086", "1", ""}, "want non-empty request ID"},
+
+    {client("10086"), "transfer", []string{"10086"}, "Incorrect number of arguments. Expecting 3 or 4"},
+    {client("10000"), "transfer", []string{"10086", "10000", "1"}, "caller is not the owner of user 10086"},
+    {client("10086"), "transfer", []string{"10086", "10000", "f00"}, "want Integer number"},
+    {client("10086"), "transfer", []string{"10086", "10000", "0"}, "want positive Integer number"},
+    {client("10086"), "transfer", []string{"10086", "10086", "1"}, "cannot transfer to the same user"},
+    {client("9"), "transfer", []string{"9", "10000", "1"}, "user 9 does not exist"},
+    {client("10086"), "transfer", []string{"10086", "10000", "1001"}, "用户 china mobile 剩余善圆不足本次交易"},
+    {client("10086"), "transfer", []string{"10086", "9", "1"}, "user 9 does not exist"},
+    {client("10086"), "transfer", []string{"10086", "u1", "1"}, "transfer from merchant 0001 to merchant m1 is not allowed"},
+    {client("10086"), "transfer", []string{"10086", "10000", "1"}, "transaction tx1 already exists"},
+
+    {issuer, "migrate", []string{"1"}, "Incorrect number of arguments. Expecting 0"},
+    {client("10086"), "migrate", []string{}, "caller is not issuer"},
+    {issuer, "migrate", []string{}, "storage layout v1 already in use"},
+
+    {issuer, "setPointExpiry", []string{}, "Incorrect number of arguments. Expecting 1"},
+    {client("10086"), "setPointExpiry", []string{"1"}, "caller is not issuer"},
+    {issuer, "setPointExpiry", []string{"-1"}, "want non-negative Integer days"},
+
+    {client("10086"), "expire", []string{}, "caller is not issuer"},
+    {issuer, "expire", []string{"9"}, "user 9 does not exist"},
+
+    {issuer, "setExchangePolicy", []string{}, "Incorrect number of arguments. Expecting 1"},
+    {client("10086"), "setExchangePolicy", []string{"{}"}, "caller is not issuer"},
+    {issuer, "setExchangePolicy", []string{"{"}, "Error unmarshalling policy"},
+    {issuer, "setExchangePolicy", []string{"{}"}, "policy needs a first tier starting at 0 steps"},
+    {issuer, "setExchangePolicy", []string{"{\"Tiers\":[{\"FromSteps\":0,\"StepsPerPoint\":0}]}"}, "policy tier StepsPerPoint must be positive"},
+    {issuer, "setExchangePolicy", []string{"{\"Tiers\":[{\"FromSteps\":0,\"StepsPerPoint\":1},{\"FromSteps\":0,\"StepsPerPoint\":1}]}"}, "policy tiers must have increasing FromSteps"},
+    {issuer, "setExchangePolicy", []string{"{\"Tiers\":[{\"FromSteps\":0,\"StepsPerPoint\":1}],\"DailyCap\":-1}"}, "policy DailyCap must not be negative"},
+    {issuer, "setExchangePolicy", []string{"{\"Tiers\":[{\"FromSteps\":0,\"StepsPerPoint\":1}],\"Promotions\":[{\"Percent\":0,\"Start\":0,\"End\":1}]}"}, "promotion Percent must be positive"},
+    {issuer, "setExchangePolicy", []string{"{\"Tiers\":[{\"FromSteps\":0,\"StepsPerPoint\":1}],\"Promotions\":[{\"Percent\":100,\"Start\":1,\"End\":1}]}"}, "promotion must end after it starts"},
+
+    {issuer, "createMerchant", []string{"m3"}, "Incorrect number of arguments. Expecting 3"},
+    {client("10086"), "createMerchant", []string{"m3", "three", "0"}, "caller is not issuer"},
+    {issuer, "createMerchant", []string{"m3", "three", "-1"}, "want non-negative Integer budget"},
+    {issuer, "createMerchant", []string{"m1", "one", "0"}, "merchant m1 already exists"},
+
+    {issuer, "setTransferRule", []string{"m1"}, "Incorrect number of arguments. Expecting 3"},
+    {client("10086"), "setTransferRule", []string{"m1", "m2", "true"}, "caller is not issuer"},
+    {issuer, "setTransferRule", []string{"m1", "m2", "yes please"}, "want Boolean allowed"},
+    {issuer, "setTransferRule", []string{"m1", "m1", "true"}, "transfers within a merchant are always allowed"},
+    {issuer, "setTransferRule", []string{"m1", "m9", "true"}, "merchant m9 does not exist"},
+
+    {issuer, "freezeUser", []string{"10086"}, "Incorrect number of arguments. Expecting 2"},
+    {client("10086"), "freezeUser", []string{"10086", "fraud"}, "caller is not issuer"},
+    {issuer, "freezeUser", []string{"10086", "bored"}, "unknown reason code bored"},
+    {issuer, "freezeUser", []string{"9", "fraud"}, "user 9 does not exist"},
+    {issuer, "unfreezeUser", []string{"10086", "resolved"}, "user 10086 is active"},
+    {issuer, "closeUser", []string{"10086"}, "Incorrect number of arguments. Expecting 2"},
+    {client("10086"), "closeUser", []string{"10086", "customer_request"}, "caller is not issuer"},
+  }
+  for _, c := range cases {
+    stub := newFixture(t)
+    _, err := invokeAs(stub, c.attrs, "tx1", c.function, c.args)
+    if err == nil || err.Error() != c.expect {
+      fmt.Println("Invoke", c.function, c.args, "returned", err, "instead of", c.expect)
+      t.FailNow()
+    }
+  }
+}
+
+type queryCase struct {
+  function string
+  args     []string
+  expect   string
+}
+
+func TestShanchain_Query_Validation(t *testing.T) {
+  cases := []queryCase{
+    {"getRoot", []string{"1"}, "Incorrect number of arguments. Expecting 0"},
+    {"getUser", []string{}, "Incorrect number of arguments. Expecting 1"},
+    {"getTransaction", []string{}, "Incorrect number of arguments. Expecting 1"},
+    {"getUserTransactions", []string{"10086"}, "Incorrect number of arguments. Expecting 4 or 5"},
+    {"getUserTransactions", []string{"10086", "f00", "1", "1"}, "want Integer startTime"},
+    {"getUserTransactions", []string{"10086", "0", "f00", "1"}, "want Integer endTime"},
+    {"getUserTransactions", []string{"10086", "2", "1", "1"}, "want 0 <= startTime <= endTime"},
+    {"getUserTransactions", []string{"10086", "0", "1", "0"}, "want positive Integer pageSize"},
+    {"getUserTransactions", []string{"10086", "0", "1", "1", "nocursor"}, "invalid cursor"},
+    {"getTransactionsByTime", []string{"0"}, "Incorrect number of arguments. Expecting 3 or 4"},
+    {"getTransactionsByTime", []string{"0", "1", "f00"}, "want positive Integer pageSize"},
+    {"getUserBalance", []string{"10086"}, "Incorrect number of arguments. Expecting 2"},
+    {"getUserBalance", []string{"10086", "f00"}, "want Integer time"},
+    {"getUserBalance", []string{"9", "1477000000"}, "user 9 does not exist"},
+    {"getExchangePolicy", []string{"1", "2"}, "Incorrect number of arguments. Expecting 0 or 1"},
+    {"getExchangePolicy", []string{"0"}, "want positive Integer version"},
+    {"getExchangePolicy", []string{"1"}, "exchange policy version 1 does not exist"},
+    {"getExchangePolicyHistory", []string{"1"}, "Incorrect number of arguments. Expecting 0"},
+    {"getRequestStatus", []string{"10086"}, "Incorrect number of arguments. Expecting 2"},
+    {"getMerchant", []string{}, "Incorrect number of arguments. Expecting 1"},
+    {"getMerchant", []string{"m9"}, "merchant m9 does not exist"},
+    {"getTransferRule", []string{"m1"}, "Incorrect number of arguments. Expecting 2"},
+    {"getSettlement", []string{"m1"}, "Incorrect number of arguments. Expecting 3"},
+    {"getSettlement", []string{"m1", "f00", "1"}, "want Integer startTime"},
+    {"getSettlement", []string{"m1", "1", "f00"}, "want Integer endTime"},
+    {"getSettlement", []string{"m1", "2", "1"}, "want 0 <= startTime <= endTime"},
+    {"getSettlement", []string{"m9", "0", "1"}, "merchant m9 does not exist"},
+    {"getSettlements", []string{"0"}, "Incorrect number of arguments. Expecting 2"},
+    {"getUserStatus", []string{}, "Incorrect number of arguments. Expecting 1"},
+    {"getUserStatus", []string{"9"}, "user 9 does not exist"},
+    {"getUserAudit", []string{}, "Incorrect number of arguments. Expecting 1"},
+  }
+  stub := newFixture(t)
+  for _, c := range cases {
+    _, err := stub.MockQuery(c.function, c.args)
+    if err == nil || err.Error() != c.expect {
+      fmt.Println("Query", c.function, c.args, "returned", err, "instead of", c.expect)
+      t.FailNow()
+    }
+  }
+}
+
+func TestShanchain_Query_Paths(t *testing.T) {
+  stub := newFixture(t)
+  cases := []queryCase{
+    {"getRoot", []string{}, "{\"ID\":\"0001\",\"Name\":\"shanchain\",\"TotalIntegral\":50000,\"RestIntegral\":49000}"},
+    {"getUser", []string{"10086"}, "{\"ID\":\"10086\",\"Name\":\"china mobile\",\"Integral\":1000}"},
+    {"getUser", []string{"9"}, ""},
+    {"getTransaction", []string{"tx1"}, "{\"ID\":\"tx1\",\"Step\":900,\"Integral\":900,\"FromType\":0,\"FromID\":\"0001\",\"ToType\":1,\"ToID\":\"10086\",\"Time\":1477000000}"},
+    {"getTransaction", []string{"tx9"}, ""},
+    {"getUserTransactions", []string{"10086", "0", "1477000000", "10"}, "{\"Transactions\":[{\"ID\":\"tx1\",\"Step\":900,\"Integral\":900,\"FromType\":0,\"FromID\":\"0001\",\"ToType\":1,\"ToID\":\"10086\",\"Time\":1477000000}],\"Cursor\":\"\"}"},
+    {"getTransactionsByTime", []string{"0", "1476999999", "10"}, "{\"Transactions\":[],\"Cursor\":\"\"}"},
+    {"getUserBalance", []string{"10000", "1477000000"}, "{\"ID\":\"10000\",\"Integral\":0,\"Expired\":0,\"Buckets\":[]}"},
+    {"getExchangePolicy", []string{}, "{\"Version\":0,\"Tiers\":[{\"FromSteps\":0,\"StepsPerPoint\":1}],\"DailyCap\":0,\"Promotions\":null,\"UpdatedAt\":0}"},
+    {"getExchangePolicyHistory", []string{}, "[]"},
+    {"getRequestStatus", []string{"10086", "r1"}, "{\"RequestID\":\"r1\",\"UserID\":\"10086\",\"Status\":\"unknown\",\"Function\":\"\",\"Args\":null,\"TxID\":\"\",\"Time\":0,\"Result\":null}"},
+    {"getMerchant", []string{"m2"}, "{\"ID\":\"m2\",\"Name\":\"merchant two\",\"TotalIntegral\":500,\"RestIntegral\":500}"},
+    {"getTransferRule", []string{"m1", "m2"}, "{\"FromMerchant\":\"m1\",\"ToMerchant\":\"m2\",\"Allowed\":false}"},
+    {"getSettlement", []string{"0001", "0", "1477000000"}, "{\"MerchantID\":\"0001\",\"StartTime\":0,\"EndTime\":1477000000,\"Exchanged\":900,\"In\":0,\"Out\":0,\"Net\":0,\"Counterparties\":[]}"},
+    {"getUserStatus", []string{"10086"}, "{\"ID\":\"10086\",\"Status\":\"active\",\"Reason\":\"\",\"By\":\"\",\"Time\":0}"},
+    {"getUserAudit", []string{"10086"}, "[]"},
+    {"helloworld", []string{}, ""},
+  }
+  for _, c := range cases {
+    bytes, err := stub.MockQuery(c.function, c.args)
+    if err != nil || string(bytes) != c.expect {
+      fmt.Println("Query", c.function, c.args, "returned", string(bytes), err, "instead of", c.expect)
+      t.FailNow()
+    }
+  }
+}
+
+// ledger sums the accounts in the state of stub.
+type ledger struct {
+  root     Root
+  pools    []Root
+  users    []User
+  lots     map[string]int
+  negative bool
+}
+
+func readLedger(t *testing.T, stub *shim.MockStub) ledger {
+  var l ledger
+  l.lots = make(map[string]int)
+  for key, value := range stub.State {
+    parts := strings.Split(key, keySeparator)
+    var err error
+    switch parts[1] {
+    case rootKeyType:
+      err = json.Unmarshal(value, &l.root)
+      l.pools = append(l.pools, l.root)
+    case merchantKeyType:
+      var merchant Root
+      err = json.Unmarshal(value, &merchant)
+      l.pools = append(l.pools, merchant)
+    case userKeyType:
+      var user User
+      err = json.Unmarshal(value, &user)
+      l.users = append(l.users, user)
+    case lotsKeyType:
+      var lots []Lot
+      err = json.Unmarshal(value, &lots)
+      for _, lot := range lots {
+        l.lots[parts[2]] = l.lots[parts[2]] + lot.Integral
+        l.negative = l.negative || lot.Integral <= 0
+      }
+    }
+    if err != nil {
+      fmt.Println("State", key, "is not valid JSON", err)
+      t.FailNow()
+    }
+  }
+  for _, pool := range l.pools {
+    l.negative = l.negative || pool.RestIntegral < 0
+  }
+  for _, user := range l.users {
+    l.negative = l.negative || user.Integral < 0
+  }
+  return l
+}
+
+// checkConservation fails unless no points were created or lost: the points
+// still in the pools plus those held by users add up to what was issued.
+func checkConservation(t *testing.T, stub *shim.MockStub, step string) {
+  l := readLedger(t, stub)
+  issued, rest, held := 0, 0, 0
+  for _, pool := range l.pools {
+    issued = issued + pool.TotalIntegral
+    rest = rest + pool.RestIntegral
+  }
+  for _, user := range l.users {
+    held = held + user.Integral
+    if l.lots[user.ID] != user.Integral {
+      fmt.Println(step, ": lots of", user.ID, "hold", l.lots[user.ID], "but Integral is", user.Integral)
+      t.FailNow()
+    }
+  }
+  if rest+held != issued || l.negative {
+    fmt.Println(step, ": pools keep", rest, "and users hold", held, "of", issued, "issued points, negative", l.negative)
+    t.FailNow()
+  }
+}
+
+// randomOperations runs n random invokes, valid or not, against stub and
+// checks that points are conserved after each of them. Time moves forward so
+// points expire along the way, and the users created with an opening balance
+// join the others.
+func randomOperations(t *testing.T, stub *shim.MockStub, r *rand.Rand, users []string, pools []string, n int) {
+  // the MockStub debug log of thousands of invokes only slows the run down
+  level := logging.GetLevel("mock")
+  logging.SetLevel(logging.WARNING, "mock")
+  defer logging.SetLevel(level, "mock")
+  applied := make(map[string]int)
+  now := int64(testNow)
+  for i := 0; i < n; i++ {
+    now = now + r.Int63n(5*secondsPerDay)
+    txID := "tx" + strconv.Itoa(i)
+    number := strconv.Itoa(r.Intn(400) - 20)
+    user := users[r.Intn(len(users))]
+    var (
+      attrs    map[string]string
+      function string
+      args     []string
+    )
+    switch r.Intn(7) {
+    case 0:
+      attrs, function, args = issuer, "additional", []string{number, pools[r.Intn(len(pools))]}
+    case 1, 2:
+      attrs, function, args = client(user), "exchange", []string{user, number}
+    case 3:
+      attrs, function, args = client(user), "transfer", []string{user, users[r.Intn(len(users))], number}
+    case 4:
+      attrs, function, args = issuer, "expire", []string{}
+    case 5:
+      // closing an account now and then hands its balance back to its pool
+      attrs, function, args = issuer, "closeUser", []string{user, "dormant"}
+      if r.Intn(40) != 0 {
+        function = "expire"
+        args = []string{}
+      }
+    case 6:
+      // now and then a new user joins a pool, which pays the opening balance
+      attrs, function, args = client(user), "transfer", []string{user, users[r.Intn(len(users))], number}
+      if r.Intn(4) == 0 {
+        attrs, function, args = issuer, "createUser", []string{"n" + txID, "new user", number}
+        if pool := pools[r.Intn(len(pools))]; pool != "0001" {
+          args = append(args, pool)
+        }
+      }
+    }
+    _, err := invokeAt(stub, attrs, now, txID, function, args)
+    if err == nil {
+      applied[function]++
+      if function == "createUser" {
+        users = append(users, args[0])
+      }
+    }
+    checkConservation(t, stub, fmt.Sprint(txID, " ", function, args))
+  }
+  // make sure the sequence did move points around
+  if applied["additional"] == 0 || applied["exchange"] == 0 || applied["transfer"] == 0 || applied["createUser"] == 0 {
+    fmt.Println("random sequence applied too few invokes", applied)
+    t.FailNow()
+  }
+}
+
+func TestShanchain_Conservation_Root(t *testing.T) {
+  users := []string{"a", "b", "c", "d"}
+  for seed := int64(1); seed <= 20; seed++ {
+    stub := shim.NewMockStub("shanchain_api", new(ShanChainAPI))
+    checkInit(t, stub, []string{"shanchain", "5000"})
+    invokeAs(stub, issuer, "setup", "setPointExpiry", []string{"30"})
+    for _, user := range users {
+      invokeAs(stub, issuer, "setup", "createUser", []string{user, user, "500"})
+    }
+    randomOperations(t, stub, rand.New(rand.NewSource(seed)), users, []string{"0001"}, 200)
+
+    // with a single pool this is Root.RestIntegral + sum(User.Integral) == Root.TotalIntegral
+    l := readLedger(t, stub)
+    sum := 0
+    for _, user := range l.users {
+      sum = sum + user.Integral
+    }
+    if l.root.RestIntegral+sum != l.root.TotalIntegral {
+      fmt.Println("seed", seed, ": root keeps", l.root.RestIntegral, "and users hold", sum, "of", l.root.TotalIntegral)
+      t.FailNow()
+    }
+  }
+}
+
+func TestShanchain_Conservation_Merchants(t *testing.T) {
+  users := []string{"a", "b", "c", "d", "e", "f"}
+  pools := []string{"0001", "m1", "m2"}
+  for seed := int64(1); seed <= 20; seed++ {
+    stub := shim.NewMockStub("shanchain_api", new(ShanChainAPI))
+    checkInit(t, stub, []string{"shanchain", "5000"})
+    invokeAs(stub, issuer, "setup", "setPointExpiry", []string{"30"})
+    invokeAs(stub, issuer, "setup", "createMerchant", []string{"m1", "merchant one", "2000"})
+    invokeAs(stub, issuer, "setup", "createMerchant", []string{"m2", "merchant two", "1000"})
+    invokeAs(stub, issuer, "setup", "setTransferRule", []string{"0001", "m1", "true"})
+    invokeAs(stub, issuer, "setup", "setTransferRule", []string{"m1", "m2", "true"})
+    invokeAs(stub, issuer, "setup", "setTransferRule", []string{"m2", "0001", "true"})
+    for i, user := range users {
+      args := []string{user, user, "500"}
+      if pools[i%3] != "0001" {
+        args = append(args, pools[i%3])
+      }
+      invokeAs(stub, issuer, "setup", "createUser", args)
+    }
+    randomOperations(t, stub, rand.New(rand.NewSource(seed)), users, pools, 200)
+  }
+}
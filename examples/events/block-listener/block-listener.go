@@ -125,6 +125,9 @@ func main() {
 			fmt.Printf("Received rejected transaction\n")
 			fmt.Printf("--------------\n")
 			fmt.Printf("Transaction error:\n%s\t%s\n", r.Rejection.Tx.Txid, r.Rejection.ErrorMsg)
+			if r.Rejection.ChaincodeError != nil {
+				fmt.Printf("Chaincode error code: %d\n", r.Rejection.ChaincodeError.Code)
+			}
 		case ce := <-a.cEvent:
 			fmt.Printf("\n")
 			fmt.Printf("\n")
@@ -52,6 +52,7 @@ func Cmd() *cobra.Command {
 	chaincodeCmd.AddCommand(deployCmd())
 	chaincodeCmd.AddCommand(invokeCmd())
 	chaincodeCmd.AddCommand(queryCmd())
+	chaincodeCmd.AddCommand(vetCmd())
 
 	return chaincodeCmd
 }
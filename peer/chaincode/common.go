@@ -132,6 +132,9 @@ func chaincodeInvokeOrQuery(cmd *cobra.Command, args []string, invoke bool) (err
 		resp, err = devopsClient.Invoke(context.Background(), invocation)
 	} else {
 		resp, err = devopsClient.Query(context.Background(), invocation)
+		if err == nil && resp.ChaincodeError != nil {
+			err = fmt.Errorf("error code %d: %s", resp.ChaincodeError.Code, resp.ChaincodeError)
+		}
 	}
 
 	if err != nil {
@@ -0,0 +1,67 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/chaincode/platforms/golang"
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/cobra"
+)
+
+func vetCmd() *cobra.Command {
+	return chaincodeVetCmd
+}
+
+var chaincodeVetCmd = &cobra.Command{
+	Use:   "vet",
+	Short: fmt.Sprintf("Check the specified %s for non-deterministic constructs.", chainFuncName),
+	Long: fmt.Sprintf(`Check the Go %s at the specified path under GOPATH for constructs that can make validating peers reach different results: wall-clock time, random numbers, goroutines, network and file IO, and state writes in loops over maps.`,
+		chainFuncName),
+	ValidArgs: []string{"1"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return chaincodeVet(cmd, args)
+	},
+}
+
+// chaincodeVet prints each non-deterministic construct found in the chaincode
+// on STDOUT, and fails if there is any.
+func chaincodeVet(cmd *cobra.Command, args []string) error {
+	if chaincodePath == common.UndefinedParamValue {
+		return fmt.Errorf("Must supply value for %s path parameter.\n", chainFuncName)
+	}
+	if strings.ToLower(chaincodeLang) != "golang" {
+		return fmt.Errorf("Only Go %ss can be vetted, not %s", chainFuncName, chaincodeLang)
+	}
+
+	spec := &pb.ChaincodeSpec{Type: pb.ChaincodeSpec_GOLANG, ChaincodeID: &pb.ChaincodeID{Path: chaincodePath}}
+	findings, err := (&golang.Platform{}).CheckDeterminism(spec)
+	if err != nil {
+		return fmt.Errorf("Error vetting %s: %s", chainFuncName, err)
+	}
+	for _, finding := range findings {
+		fmt.Println(finding)
+	}
+	if len(findings) > 0 {
+		return fmt.Errorf("Found %d non-deterministic construct(s) in %s", len(findings), chaincodePath)
+	}
+	logger.Infof("No non-deterministic constructs found in %s", chaincodePath)
+	return nil
+}
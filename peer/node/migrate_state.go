@@ -0,0 +1,77 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger/statemgmt/state"
+	"github.com/hyperledger/fabric/core/peer"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func migrateStateCmd() *cobra.Command {
+	return nodeMigrateStateCmd
+}
+
+var nodeMigrateStateCmd = &cobra.Command{
+	Use:   "migrate-state",
+	Short: "Rebuilds the world state for the configured data structure.",
+	Long: `Rebuilds the persisted world state for the data structure configured in
+ledger.state.dataStructure, from the key-values of the state persisted with the
+data structure recorded in the database. The peer must be stopped, as this
+command opens its database; an interrupted migration is completed by running
+it again. The state hash changes with the data structure, so all the peers of a
+network have to migrate at the same block.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return migrateState()
+	},
+}
+
+func migrateState() error {
+	if err := peer.CacheConfiguration(); err != nil {
+		return err
+	}
+	db.Start()
+	defer db.Stop()
+
+	spoolFile := filepath.Join(viper.GetString("peer.fileSystemPath"), "stateMigration")
+	migration, err := state.MigrateStructure(spoolFile)
+	if err != nil {
+		return fmt.Errorf("Error migrating the state: %s", err)
+	}
+	if !migration.Migrated {
+		fmt.Printf("The state is already persisted with data structure %s\n", describeStateStructure(migration.To))
+		return nil
+	}
+	fmt.Printf("Migrated %d keys of the state from data structure %s to %s\n",
+		migration.NumKeys, describeStateStructure(migration.From), describeStateStructure(migration.To))
+	fmt.Printf("New state hash: %x\n", migration.CryptoHash)
+	return nil
+}
+
+func describeStateStructure(structure *pb.StateStructure) string {
+	if len(structure.Configs) == 0 {
+		return structure.Name
+	}
+	return fmt.Sprintf("%s(%s)", structure.Name, strings.Join(structure.Configs, ", "))
+}
@@ -35,6 +35,9 @@ func Cmd() *cobra.Command {
 	nodeCmd.AddCommand(startCmd())
 	nodeCmd.AddCommand(statusCmd())
 	nodeCmd.AddCommand(stopCmd())
+	nodeCmd.AddCommand(snapshotCmd())
+	nodeCmd.AddCommand(verifyCmd())
+	nodeCmd.AddCommand(migrateStateCmd())
 
 	return nodeCmd
 }
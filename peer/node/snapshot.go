@@ -0,0 +1,157 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/peer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotBlockNumber int64
+	snapshotFile        string
+	snapshotBlockHash   string
+)
+
+func snapshotCmd() *cobra.Command {
+	flags := nodeSnapshotExportCmd.Flags()
+	flags.Int64VarP(&snapshotBlockNumber, "block", "b", -1,
+		"Number of the block to export the state at, the last block by default")
+	flags.StringVarP(&snapshotFile, "file", "f", "", "State snapshot file to write")
+	flags = nodeSnapshotImportCmd.Flags()
+	flags.StringVarP(&snapshotFile, "file", "f", "", "State snapshot file to read")
+	flags.StringVar(&snapshotBlockHash, "block-hash", "",
+		"Hex encoded hash of the block of the snapshot, as obtained from a trusted peer")
+
+	nodeSnapshotCmd.AddCommand(nodeSnapshotExportCmd)
+	nodeSnapshotCmd.AddCommand(nodeSnapshotImportCmd)
+	return nodeSnapshotCmd
+}
+
+var nodeSnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Exports or imports the world state.",
+	Long: `Exports the world state at a block to a checksummed file, or bootstraps a
+new peer from such a file instead of replaying the whole chain. The peer must
+be stopped, as these commands open its database.`,
+}
+
+var nodeSnapshotExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports the world state at a block to a file.",
+	Long: `Writes the world state at a block, together with the block, to a state
+snapshot file. The state at an older block than the last one can only be
+exported while the state deltas of the blocks after it are kept.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportSnapshot()
+	},
+}
+
+var nodeSnapshotImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Bootstraps the ledger of a new peer from a state snapshot file.",
+	Long: `Loads the world state of a state snapshot file into an empty ledger and
+puts the block of the snapshot on the chain, after checking the block hash
+given with --block-hash and the state hash. The blocks before it are not
+fetched.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importSnapshot()
+	},
+}
+
+func openSnapshotLedger() (*ledger.Ledger, error) {
+	if snapshotFile == "" {
+		return nil, errors.New("A state snapshot file must be given with --file")
+	}
+	if err := peer.CacheConfiguration(); err != nil {
+		return nil, err
+	}
+	db.Start()
+	return ledger.GetLedger()
+}
+
+func exportSnapshot() error {
+	ledger, err := openSnapshotLedger()
+	if err != nil {
+		return err
+	}
+	defer db.Stop()
+	if snapshotBlockNumber < 0 {
+		size := ledger.GetBlockchainSize()
+		if size == 0 {
+			return errors.New("The blockchain has no blocks")
+		}
+		snapshotBlockNumber = int64(size - 1)
+	}
+
+	file, err := os.Create(snapshotFile)
+	if err != nil {
+		return err
+	}
+	header, err := ledger.ExportStateSnapshot(uint64(snapshotBlockNumber), file)
+	if err == nil {
+		err = file.Close()
+	} else {
+		file.Close()
+	}
+	if err != nil {
+		os.Remove(snapshotFile)
+		return fmt.Errorf("Error exporting the state snapshot: %s", err)
+	}
+	blockHash, err := header.Block.GetHash()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported the state at block %d with block hash %x and state hash %x to %s\n",
+		header.BlockNumber, blockHash, header.Block.StateHash, snapshotFile)
+	return nil
+}
+
+func importSnapshot() error {
+	if snapshotBlockHash == "" {
+		return errors.New("The expected hash of the block of the snapshot must be given with --block-hash")
+	}
+	blockHash, err := hex.DecodeString(snapshotBlockHash)
+	if err != nil {
+		return fmt.Errorf("Invalid block hash %s: %s", snapshotBlockHash, err)
+	}
+	ledger, err := openSnapshotLedger()
+	if err != nil {
+		return err
+	}
+	defer db.Stop()
+
+	file, err := os.Open(snapshotFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	header, err := ledger.ImportStateSnapshot(file, blockHash)
+	if err != nil {
+		return fmt.Errorf("Error importing the state snapshot: %s", err)
+	}
+	fmt.Printf("Imported the state at block %d with state hash %x from %s\n",
+		header.BlockNumber, header.Block.StateHash, snapshotFile)
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/hyperledger/fabric/core/db"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/peer"
+	pb "github.com/hyperledger/fabric/protos"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+func verifyCmd() *cobra.Command {
+	return nodeVerifyCmd
+}
+
+var nodeVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verifies the world state against the last block.",
+	Long: `Recomputes the state hash from the world state and compares it with the
+state hash of the last block. When they differ, reports the first bucket or trie
+node whose crypto-hash in the database differs from the recomputed one. The
+running node verifies a snapshot of its database without stopping; if no node
+is running, its database is opened instead.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return verify()
+	},
+}
+
+func verify() error {
+	verification, err := verifyState()
+	if err != nil {
+		return fmt.Errorf("Error verifying the state: %s", err)
+	}
+	fmt.Printf("State hash of block %d: %x\n", verification.BlockNumber, verification.BlockStateHash)
+	fmt.Printf("Recomputed state hash: %x\n", verification.ComputedStateHash)
+	if verification.Divergence != "" {
+		fmt.Println(verification.Divergence)
+	}
+	if !verification.Consistent {
+		return fmt.Errorf("The state is not consistent with block %d", verification.BlockNumber)
+	}
+	fmt.Printf("The state is consistent with block %d\n", verification.BlockNumber)
+	return nil
+}
+
+func verifyState() (*pb.StateVerification, error) {
+	clientConn, err := peer.NewPeerClientConnection()
+	if err != nil {
+		logger.Infof("Error trying to connect to local peer: %s", err)
+		logger.Info("Verifying the state in the database of the stopped peer")
+		if err = peer.CacheConfiguration(); err != nil {
+			return nil, err
+		}
+		db.Start()
+		defer db.Stop()
+		ledgerPtr, err := ledger.GetLedger()
+		if err != nil {
+			return nil, err
+		}
+		return ledgerPtr.VerifyState()
+	}
+	defer clientConn.Close()
+
+	logger.Info("Verifying the state using grpc")
+	serverClient := pb.NewAdminClient(clientConn)
+	return serverClient.VerifyState(context.Background(), &empty.Empty{})
+}
@@ -0,0 +1,36 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protos
+
+import "fmt"
+
+// Error makes a ChaincodeError usable as an error. The message, followed by
+// the details if any, is the text that clients unaware of the structured
+// error receive.
+func (chaincodeError *ChaincodeError) Error() string {
+	if chaincodeError.Details == "" {
+		return chaincodeError.Message
+	}
+	return fmt.Sprintf("%s: %s", chaincodeError.Message, chaincodeError.Details)
+}
+
+// ChaincodeErrorOf returns err if it is a ChaincodeError, so that it can be
+// passed on with its structure, and nil for any other error.
+func ChaincodeErrorOf(err error) *ChaincodeError {
+	chaincodeError, _ := err.(*ChaincodeError)
+	return chaincodeError
+}
@@ -118,6 +118,10 @@ const (
 	ChaincodeMessage_RANGE_QUERY_STATE_NEXT  ChaincodeMessage_Type = 18
 	ChaincodeMessage_RANGE_QUERY_STATE_CLOSE ChaincodeMessage_Type = 19
 	ChaincodeMessage_KEEPALIVE               ChaincodeMessage_Type = 20
+	ChaincodeMessage_GET_STATE_MULTIPLE      ChaincodeMessage_Type = 21
+	ChaincodeMessage_PUT_STATE_MULTIPLE      ChaincodeMessage_Type = 22
+	ChaincodeMessage_QUERY_STATE             ChaincodeMessage_Type = 23
+	ChaincodeMessage_GET_HISTORY_FOR_KEY     ChaincodeMessage_Type = 24
 )
 
 var ChaincodeMessage_Type_name = map[int32]string{
@@ -142,6 +146,10 @@ var ChaincodeMessage_Type_name = map[int32]string{
 	18: "RANGE_QUERY_STATE_NEXT",
 	19: "RANGE_QUERY_STATE_CLOSE",
 	20: "KEEPALIVE",
+	21: "GET_STATE_MULTIPLE",
+	22: "PUT_STATE_MULTIPLE",
+	23: "QUERY_STATE",
+	24: "GET_HISTORY_FOR_KEY",
 }
 var ChaincodeMessage_Type_value = map[string]int32{
 	"UNDEFINED":               0,
@@ -165,6 +173,10 @@ var ChaincodeMessage_Type_value = map[string]int32{
 	"RANGE_QUERY_STATE_NEXT":  18,
 	"RANGE_QUERY_STATE_CLOSE": 19,
 	"KEEPALIVE":               20,
+	"GET_STATE_MULTIPLE":      21,
+	"PUT_STATE_MULTIPLE":      22,
+	"QUERY_STATE":             23,
+	"GET_HISTORY_FOR_KEY":     24,
 }
 
 func (x ChaincodeMessage_Type) String() string {
@@ -302,6 +314,9 @@ type ChaincodeSecurityContext struct {
 	Metadata       []byte                     `protobuf:"bytes,5,opt,name=metadata,proto3" json:"metadata,omitempty"`
 	ParentMetadata []byte                     `protobuf:"bytes,6,opt,name=parentMetadata,proto3" json:"parentMetadata,omitempty"`
 	TxTimestamp    *google_protobuf.Timestamp `protobuf:"bytes,7,opt,name=txTimestamp" json:"txTimestamp,omitempty"`
+	// names of the chaincodes that called this one in the transaction,
+	// outermost first; empty when the transaction called it directly
+	CallStack []string `protobuf:"bytes,8,rep,name=callStack" json:"callStack,omitempty"`
 }
 
 func (m *ChaincodeSecurityContext) Reset()                    { *m = ChaincodeSecurityContext{} }
@@ -326,6 +341,9 @@ type ChaincodeMessage struct {
 	// This event is then stored (currently)
 	// with Block.NonHashData.TransactionResult
 	ChaincodeEvent *ChaincodeEvent `protobuf:"bytes,6,opt,name=chaincodeEvent" json:"chaincodeEvent,omitempty"`
+	// Structured error returned by the chaincode with shim.Error. Only set on
+	// ERROR and QUERY_ERROR, whose payload still carries the error message.
+	ChaincodeError *ChaincodeError `protobuf:"bytes,7,opt,name=chaincodeError" json:"chaincodeError,omitempty"`
 }
 
 func (m *ChaincodeMessage) Reset()                    { *m = ChaincodeMessage{} }
@@ -354,6 +372,26 @@ func (m *ChaincodeMessage) GetChaincodeEvent() *ChaincodeEvent {
 	return nil
 }
 
+func (m *ChaincodeMessage) GetChaincodeError() *ChaincodeError {
+	if m != nil {
+		return m.ChaincodeError
+	}
+	return nil
+}
+
+// An error returned by a chaincode, with a code defined by the chaincode
+// that clients can act on without parsing the message.
+type ChaincodeError struct {
+	Code    int32  `protobuf:"varint,1,opt,name=code" json:"code,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message" json:"message,omitempty"`
+	Details string `protobuf:"bytes,3,opt,name=details" json:"details,omitempty"`
+}
+
+func (m *ChaincodeError) Reset()                    { *m = ChaincodeError{} }
+func (m *ChaincodeError) String() string            { return proto.CompactTextString(m) }
+func (*ChaincodeError) ProtoMessage()               {}
+func (*ChaincodeError) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{7} }
+
 type PutStateInfo struct {
 	Key   string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
 	Value []byte `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
@@ -362,7 +400,45 @@ type PutStateInfo struct {
 func (m *PutStateInfo) Reset()                    { *m = PutStateInfo{} }
 func (m *PutStateInfo) String() string            { return proto.CompactTextString(m) }
 func (*PutStateInfo) ProtoMessage()               {}
-func (*PutStateInfo) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{7} }
+func (*PutStateInfo) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{8} }
+
+// Batches several GET_STATE requests in one GET_STATE_MULTIPLE round trip.
+type GetStateMultiple struct {
+	Keys []string `protobuf:"bytes,1,rep,name=keys" json:"keys,omitempty"`
+}
+
+func (m *GetStateMultiple) Reset()                    { *m = GetStateMultiple{} }
+func (m *GetStateMultiple) String() string            { return proto.CompactTextString(m) }
+func (*GetStateMultiple) ProtoMessage()               {}
+func (*GetStateMultiple) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{9} }
+
+// The values of the keys of a GetStateMultiple, in the same order. Keys
+// without state have an empty value.
+type GetStateMultipleResponse struct {
+	Values [][]byte `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+}
+
+func (m *GetStateMultipleResponse) Reset()                    { *m = GetStateMultipleResponse{} }
+func (m *GetStateMultipleResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetStateMultipleResponse) ProtoMessage()               {}
+func (*GetStateMultipleResponse) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{10} }
+
+// Batches several PUT_STATE requests in one PUT_STATE_MULTIPLE round trip.
+type PutStateMultiple struct {
+	KeysAndValues []*PutStateInfo `protobuf:"bytes,1,rep,name=keysAndValues" json:"keysAndValues,omitempty"`
+}
+
+func (m *PutStateMultiple) Reset()                    { *m = PutStateMultiple{} }
+func (m *PutStateMultiple) String() string            { return proto.CompactTextString(m) }
+func (*PutStateMultiple) ProtoMessage()               {}
+func (*PutStateMultiple) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{11} }
+
+func (m *PutStateMultiple) GetKeysAndValues() []*PutStateInfo {
+	if m != nil {
+		return m.KeysAndValues
+	}
+	return nil
+}
 
 type RangeQueryState struct {
 	StartKey string `protobuf:"bytes,1,opt,name=startKey" json:"startKey,omitempty"`
@@ -372,7 +448,7 @@ type RangeQueryState struct {
 func (m *RangeQueryState) Reset()                    { *m = RangeQueryState{} }
 func (m *RangeQueryState) String() string            { return proto.CompactTextString(m) }
 func (*RangeQueryState) ProtoMessage()               {}
-func (*RangeQueryState) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{8} }
+func (*RangeQueryState) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{12} }
 
 type RangeQueryStateNext struct {
 	ID string `protobuf:"bytes,1,opt,name=ID,json=iD" json:"ID,omitempty"`
@@ -381,7 +457,7 @@ type RangeQueryStateNext struct {
 func (m *RangeQueryStateNext) Reset()                    { *m = RangeQueryStateNext{} }
 func (m *RangeQueryStateNext) String() string            { return proto.CompactTextString(m) }
 func (*RangeQueryStateNext) ProtoMessage()               {}
-func (*RangeQueryStateNext) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{9} }
+func (*RangeQueryStateNext) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{13} }
 
 type RangeQueryStateClose struct {
 	ID string `protobuf:"bytes,1,opt,name=ID,json=iD" json:"ID,omitempty"`
@@ -390,7 +466,7 @@ type RangeQueryStateClose struct {
 func (m *RangeQueryStateClose) Reset()                    { *m = RangeQueryStateClose{} }
 func (m *RangeQueryStateClose) String() string            { return proto.CompactTextString(m) }
 func (*RangeQueryStateClose) ProtoMessage()               {}
-func (*RangeQueryStateClose) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{10} }
+func (*RangeQueryStateClose) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{14} }
 
 type RangeQueryStateKeyValue struct {
 	Key   string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
@@ -400,7 +476,70 @@ type RangeQueryStateKeyValue struct {
 func (m *RangeQueryStateKeyValue) Reset()                    { *m = RangeQueryStateKeyValue{} }
 func (m *RangeQueryStateKeyValue) String() string            { return proto.CompactTextString(m) }
 func (*RangeQueryStateKeyValue) ProtoMessage()               {}
-func (*RangeQueryStateKeyValue) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{11} }
+func (*RangeQueryStateKeyValue) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{15} }
+
+// Rich query over the JSON values of the chaincode state. The results are
+// returned in a RangeQueryStateResponse and paged with RANGE_QUERY_STATE_NEXT
+// and RANGE_QUERY_STATE_CLOSE.
+type QueryState struct {
+	Query string `protobuf:"bytes,1,opt,name=query" json:"query,omitempty"`
+}
+
+func (m *QueryState) Reset()                    { *m = QueryState{} }
+func (m *QueryState) String() string            { return proto.CompactTextString(m) }
+func (*QueryState) ProtoMessage()               {}
+func (*QueryState) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{16} }
+
+// Requests the committed modifications of a key, oldest first.
+type GetHistoryForKey struct {
+	Key string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+}
+
+func (m *GetHistoryForKey) Reset()                    { *m = GetHistoryForKey{} }
+func (m *GetHistoryForKey) String() string            { return proto.CompactTextString(m) }
+func (*GetHistoryForKey) ProtoMessage()               {}
+func (*GetHistoryForKey) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{17} }
+
+// A committed modification of a key by a transaction.
+type KeyModification struct {
+	TxID        string                     `protobuf:"bytes,1,opt,name=txID" json:"txID,omitempty"`
+	BlockNumber uint64                     `protobuf:"varint,2,opt,name=blockNumber" json:"blockNumber,omitempty"`
+	Value       []byte                     `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Timestamp   *google_protobuf.Timestamp `protobuf:"bytes,4,opt,name=timestamp" json:"timestamp,omitempty"`
+	IsDelete    bool                       `protobuf:"varint,5,opt,name=isDelete" json:"isDelete,omitempty"`
+}
+
+func (m *KeyModification) Reset()                    { *m = KeyModification{} }
+func (m *KeyModification) String() string            { return proto.CompactTextString(m) }
+func (*KeyModification) ProtoMessage()               {}
+func (*KeyModification) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{18} }
+
+func (m *KeyModification) GetTimestamp() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.Timestamp
+	}
+	return nil
+}
+
+// The modifications of a key. Blocks put on the chain without the results of
+// their transactions, by state transfer or a state snapshot import, leave no
+// history; the modifications are complete only from completeFromBlock on.
+type GetHistoryForKeyResponse struct {
+	Modifications     []*KeyModification `protobuf:"bytes,1,rep,name=modifications" json:"modifications,omitempty"`
+	CompleteFromBlock uint64             `protobuf:"varint,2,opt,name=completeFromBlock" json:"completeFromBlock,omitempty"`
+}
+
+func (m *GetHistoryForKeyResponse) Reset()                    { *m = GetHistoryForKeyResponse{} }
+func (m *GetHistoryForKeyResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetHistoryForKeyResponse) ProtoMessage()               {}
+func (*GetHistoryForKeyResponse) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{19} }
+
+func (m *GetHistoryForKeyResponse) GetModifications() []*KeyModification {
+	if m != nil {
+		return m.Modifications
+	}
+	return nil
+}
 
 type RangeQueryStateResponse struct {
 	KeysAndValues []*RangeQueryStateKeyValue `protobuf:"bytes,1,rep,name=keysAndValues" json:"keysAndValues,omitempty"`
@@ -411,7 +550,7 @@ type RangeQueryStateResponse struct {
 func (m *RangeQueryStateResponse) Reset()                    { *m = RangeQueryStateResponse{} }
 func (m *RangeQueryStateResponse) String() string            { return proto.CompactTextString(m) }
 func (*RangeQueryStateResponse) ProtoMessage()               {}
-func (*RangeQueryStateResponse) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{12} }
+func (*RangeQueryStateResponse) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{20} }
 
 func (m *RangeQueryStateResponse) GetKeysAndValues() []*RangeQueryStateKeyValue {
 	if m != nil {
@@ -428,11 +567,19 @@ func init() {
 	proto.RegisterType((*ChaincodeInvocationSpec)(nil), "protos.ChaincodeInvocationSpec")
 	proto.RegisterType((*ChaincodeSecurityContext)(nil), "protos.ChaincodeSecurityContext")
 	proto.RegisterType((*ChaincodeMessage)(nil), "protos.ChaincodeMessage")
+	proto.RegisterType((*ChaincodeError)(nil), "protos.ChaincodeError")
 	proto.RegisterType((*PutStateInfo)(nil), "protos.PutStateInfo")
+	proto.RegisterType((*GetStateMultiple)(nil), "protos.GetStateMultiple")
+	proto.RegisterType((*GetStateMultipleResponse)(nil), "protos.GetStateMultipleResponse")
+	proto.RegisterType((*PutStateMultiple)(nil), "protos.PutStateMultiple")
 	proto.RegisterType((*RangeQueryState)(nil), "protos.RangeQueryState")
 	proto.RegisterType((*RangeQueryStateNext)(nil), "protos.RangeQueryStateNext")
 	proto.RegisterType((*RangeQueryStateClose)(nil), "protos.RangeQueryStateClose")
 	proto.RegisterType((*RangeQueryStateKeyValue)(nil), "protos.RangeQueryStateKeyValue")
+	proto.RegisterType((*QueryState)(nil), "protos.QueryState")
+	proto.RegisterType((*GetHistoryForKey)(nil), "protos.GetHistoryForKey")
+	proto.RegisterType((*KeyModification)(nil), "protos.KeyModification")
+	proto.RegisterType((*GetHistoryForKeyResponse)(nil), "protos.GetHistoryForKeyResponse")
 	proto.RegisterType((*RangeQueryStateResponse)(nil), "protos.RangeQueryStateResponse")
 	proto.RegisterEnum("protos.ConfidentialityLevel", ConfidentialityLevel_name, ConfidentialityLevel_value)
 	proto.RegisterEnum("protos.ChaincodeSpec_Type", ChaincodeSpec_Type_name, ChaincodeSpec_Type_value)
@@ -547,79 +694,97 @@ var _ChaincodeSupport_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("chaincode.proto", fileDescriptor2) }
 
 var fileDescriptor2 = []byte{
-	// 1173 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xa4, 0x56, 0x4b, 0x6f, 0xdb, 0x46,
-	0x10, 0x8e, 0x5e, 0x7e, 0x8c, 0x5e, 0xcc, 0x5a, 0x71, 0x04, 0xf7, 0x91, 0x80, 0x48, 0x03, 0xa3,
-	0x07, 0x25, 0x55, 0xd3, 0xa2, 0x40, 0x8b, 0xa0, 0x0c, 0xb9, 0x71, 0x19, 0xcb, 0x94, 0xb2, 0xa2,
-	0x8d, 0xe4, 0x64, 0xd0, 0xd4, 0x9a, 0x26, 0x22, 0x93, 0x04, 0xb9, 0x32, 0xec, 0x5b, 0xcf, 0x3d,
-	0xf5, 0x37, 0xf4, 0x47, 0xf4, 0xd0, 0x1f, 0xd4, 0xdf, 0xd1, 0xdd, 0xe5, 0x43, 0xcf, 0x04, 0x01,
-	0x7a, 0xd2, 0xce, 0xcc, 0x37, 0xb3, 0xb3, 0xdf, 0x7e, 0x3b, 0x14, 0xb4, 0xdd, 0x2b, 0xc7, 0x0f,
-	0xdc, 0x70, 0x42, 0x7b, 0x51, 0x1c, 0xb2, 0x10, 0x6d, 0xc9, 0x9f, 0xe4, 0xa0, 0x53, 0x04, 0xe8,
-	0x0d, 0x0d, 0x58, 0x1a, 0x3d, 0x78, 0xe4, 0x85, 0xa1, 0x37, 0xa5, 0xcf, 0xa4, 0x75, 0x31, 0xbb,
-	0x7c, 0xc6, 0xfc, 0x6b, 0x9a, 0x30, 0xe7, 0x3a, 0x4a, 0x01, 0xea, 0x0f, 0x50, 0xd7, 0xf3, 0x44,
-	0xd3, 0x40, 0x08, 0xaa, 0x91, 0xc3, 0xae, 0xba, 0xa5, 0xc7, 0xa5, 0xc3, 0x5d, 0x22, 0xd7, 0xc2,
-	0x17, 0x38, 0xd7, 0xb4, 0x5b, 0x4e, 0x7d, 0x62, 0xad, 0x3e, 0x81, 0xd6, 0x3c, 0x2d, 0x88, 0x66,
-	0x4c, 0xa0, 0x9c, 0xd8, 0x4b, 0x78, 0x66, 0xe5, 0xb0, 0x41, 0xe4, 0x5a, 0xfd, 0xbb, 0x02, 0xcd,
-	0x02, 0x36, 0x8e, 0xa8, 0x8b, 0x7a, 0x50, 0x65, 0x77, 0x11, 0x95, 0xf5, 0x5b, 0xfd, 0x83, 0xb4,
-	0x89, 0xa4, 0xb7, 0x04, 0xea, 0xd9, 0x1c, 0x41, 0x24, 0x0e, 0xf1, 0xf6, 0xdc, 0x79, 0x7b, 0xb2,
-	0x85, 0x7a, 0x7f, 0x6f, 0x2d, 0xcd, 0x34, 0xc8, 0x22, 0x0e, 0x3d, 0x87, 0x6d, 0x97, 0x85, 0xf1,
-	0x49, 0xe2, 0x75, 0x2b, 0x32, 0x65, 0x7f, 0x3d, 0x45, 0x74, 0x4d, 0x72, 0x18, 0xea, 0xc2, 0xb6,
-	0xa0, 0x26, 0x9c, 0xb1, 0x6e, 0x95, 0x67, 0xd4, 0x48, 0x6e, 0xa2, 0x27, 0xd0, 0x4c, 0xa8, 0x3b,
-	0x8b, 0xa9, 0x1e, 0x06, 0x8c, 0xde, 0xb2, 0x6e, 0x4d, 0xf2, 0xb0, 0xec, 0x44, 0x23, 0xe8, 0xb8,
-	0x61, 0x70, 0xe9, 0x4f, 0x38, 0xf7, 0xbe, 0x33, 0xf5, 0xd9, 0xdd, 0x80, 0xdf, 0xc3, 0xb4, 0xbb,
-	0x25, 0x0f, 0xfa, 0x65, 0xb1, 0xfd, 0x06, 0x0c, 0xd9, 0x98, 0x89, 0x0e, 0x60, 0xe7, 0x9a, 0x32,
-	0x67, 0xe2, 0x30, 0xa7, 0xbb, 0xcd, 0xab, 0x34, 0x48, 0x61, 0xa3, 0xaf, 0x01, 0x1c, 0xc6, 0x62,
-	0xff, 0x62, 0xc6, 0x68, 0xd2, 0xdd, 0xe1, 0x94, 0xef, 0x92, 0x05, 0x8f, 0xfa, 0x12, 0xaa, 0x82,
-	0x44, 0xd4, 0x84, 0xdd, 0x53, 0xcb, 0xc0, 0xaf, 0x4d, 0x0b, 0x1b, 0xca, 0x3d, 0x04, 0xb0, 0x75,
-	0x34, 0x1c, 0x68, 0xd6, 0x91, 0x52, 0x42, 0x3b, 0x50, 0xb5, 0x86, 0x06, 0x56, 0xca, 0x68, 0x1b,
-	0x2a, 0xba, 0x46, 0x94, 0x8a, 0x70, 0xbd, 0xd1, 0xce, 0x34, 0xa5, 0xaa, 0xfe, 0x53, 0x86, 0x87,
-	0x05, 0x53, 0x06, 0x8d, 0xa6, 0xe1, 0xdd, 0x35, 0x6f, 0x4f, 0x5e, 0xe1, 0xcf, 0xd0, 0x74, 0x17,
-	0xaf, 0x4b, 0xde, 0x65, 0xbd, 0xff, 0x60, 0xe3, 0x5d, 0x92, 0x65, 0x2c, 0xfa, 0x15, 0x9a, 0xf4,
-	0xf2, 0x92, 0xba, 0xcc, 0xbf, 0xa1, 0x86, 0xc3, 0x68, 0x76, 0xa3, 0x07, 0xbd, 0x54, 0xa7, 0xbd,
-	0x5c, 0xa7, 0x3d, 0x3b, 0xd7, 0x29, 0x59, 0x4e, 0x40, 0x8f, 0xb9, 0x22, 0x78, 0xb5, 0x91, 0xe3,
-	0x7e, 0x70, 0x3c, 0x2a, 0xaf, 0xb7, 0x41, 0x16, 0x5d, 0xc8, 0x82, 0x6d, 0x7a, 0x4b, 0x5d, 0x1c,
-	0xdc, 0xc8, 0xab, 0x6c, 0xf5, 0x5f, 0xac, 0xb5, 0xb6, 0x7c, 0xa4, 0x1e, 0xe6, 0xf0, 0x19, 0xf3,
-	0xc3, 0x80, 0xe7, 0xf8, 0x71, 0x18, 0x88, 0x00, 0xc9, 0x8b, 0xa8, 0x3d, 0xe8, 0x6c, 0x02, 0x08,
-	0x36, 0x8d, 0xa1, 0x7e, 0x8c, 0x49, 0xca, 0xec, 0xf8, 0xfd, 0xd8, 0xc6, 0x27, 0x4a, 0x49, 0xfd,
-	0xbd, 0xb4, 0x40, 0x9e, 0x19, 0xdc, 0x84, 0xae, 0x23, 0x52, 0xff, 0x3f, 0x79, 0x87, 0xd0, 0xf6,
-	0x27, 0x47, 0x34, 0xa0, 0xb1, 0x2c, 0xa8, 0x4d, 0xbd, 0xec, 0x4d, 0xae, 0xba, 0xd5, 0x3f, 0xcb,
-	0xd0, 0x9d, 0x97, 0x12, 0x42, 0xe5, 0xb2, 0xca, 0xa5, 0xca, 0xc5, 0xe3, 0x3a, 0xd3, 0x29, 0x8d,
-	0x75, 0x1a, 0x33, 0xd9, 0x40, 0x83, 0x2c, 0x78, 0xe6, 0xf1, 0xb1, 0xef, 0x05, 0x72, 0x87, 0x22,
-	0x2e, 0x3c, 0xe2, 0xa9, 0x44, 0xce, 0xdd, 0x34, 0x74, 0x26, 0x19, 0xfb, 0xb9, 0x29, 0x22, 0x17,
-	0x7e, 0x30, 0xf1, 0x03, 0x4f, 0x32, 0xcf, 0x23, 0x99, 0xb9, 0x24, 0xe6, 0xda, 0x8a, 0x98, 0x9f,
-	0x42, 0x2b, 0x72, 0x62, 0xce, 0xe8, 0x49, 0x8e, 0xd8, 0x92, 0x88, 0x15, 0x2f, 0xfa, 0x05, 0xea,
-	0xec, 0xb6, 0xd0, 0x85, 0x7c, 0x13, 0x9f, 0x56, 0xce, 0x22, 0x5c, 0xfd, 0xab, 0x06, 0x4a, 0x41,
-	0xc9, 0x09, 0x4d, 0x12, 0x21, 0x95, 0xef, 0x96, 0xc6, 0xd1, 0x57, 0x6b, 0xb7, 0x90, 0xe1, 0x16,
-	0x27, 0xd2, 0x4f, 0xb0, 0x5b, 0xcc, 0xd0, 0xcf, 0x50, 0xef, 0x1c, 0xfc, 0x09, 0xde, 0xf8, 0xec,
-	0x64, 0xb7, 0xfe, 0x44, 0x92, 0xc6, 0x27, 0xac, 0x58, 0xa3, 0x37, 0xd0, 0x4e, 0x96, 0x2f, 0x4e,
-	0x12, 0x57, 0xef, 0x3f, 0x5e, 0xd7, 0xca, 0x32, 0x8e, 0xac, 0x26, 0xa2, 0x97, 0xd0, 0x2a, 0x94,
-	0x84, 0xc5, 0xd7, 0x41, 0x32, 0xbc, 0x69, 0x2a, 0xca, 0x28, 0x59, 0x41, 0xab, 0xff, 0x96, 0x37,
-	0xcf, 0x93, 0x06, 0xec, 0x10, 0x7c, 0x64, 0x72, 0xdd, 0x13, 0x3e, 0x51, 0x5a, 0x00, 0xb9, 0xc5,
-	0xa3, 0x65, 0x31, 0x4e, 0x4c, 0xcb, 0xb4, 0xf9, 0x60, 0xd9, 0x85, 0x1a, 0xc1, 0x9a, 0xf1, 0x5e,
-	0xa9, 0xa2, 0x36, 0xd4, 0x6d, 0xa2, 0x59, 0x63, 0x4d, 0xb7, 0xcd, 0xa1, 0xa5, 0xd4, 0x44, 0x49,
-	0x7d, 0x78, 0x32, 0x1a, 0x60, 0x9b, 0x27, 0x6d, 0x09, 0x28, 0x26, 0x64, 0x48, 0x94, 0x6d, 0x11,
-	0x39, 0xc2, 0xf6, 0xf9, 0xd8, 0xd6, 0x6c, 0xac, 0xec, 0x08, 0x73, 0x74, 0x9a, 0x9b, 0xbb, 0xc2,
-	0x34, 0xf0, 0x20, 0x33, 0x01, 0x75, 0x40, 0x31, 0xad, 0xb3, 0xe1, 0x31, 0x3e, 0xd7, 0x7f, 0xd3,
-	0x4c, 0x4b, 0x17, 0xa3, 0xad, 0x8e, 0x14, 0x68, 0x64, 0xde, 0xb7, 0xa7, 0x98, 0xbc, 0x57, 0x1a,
-	0x69, 0xcb, 0xe3, 0xd1, 0xd0, 0x1a, 0x63, 0xa5, 0x29, 0x76, 0x4b, 0x03, 0x2d, 0xb4, 0x07, 0x6d,
-	0xb9, 0x3c, 0x9f, 0x77, 0xd3, 0x16, 0xdd, 0xa6, 0xce, 0xb4, 0x27, 0x05, 0x3d, 0x80, 0xfb, 0xbc,
-	0xfb, 0xa3, 0xac, 0x5e, 0xb6, 0xfb, 0x7d, 0x2e, 0xef, 0xfd, 0x35, 0xf7, 0xb9, 0x85, 0xdf, 0xd9,
-	0x0a, 0x42, 0x5f, 0xc0, 0xc3, 0xf5, 0x98, 0x3e, 0x18, 0xf2, 0x06, 0xf6, 0xc4, 0x29, 0x8e, 0x31,
-	0x1e, 0x69, 0x03, 0xf3, 0x0c, 0x2b, 0x1d, 0xf5, 0x47, 0x68, 0x8c, 0x66, 0x6c, 0xcc, 0xf8, 0xa0,
-	0x33, 0x83, 0xcb, 0x90, 0xf7, 0x5f, 0xf9, 0x40, 0xef, 0xb2, 0xaf, 0xb1, 0x58, 0xf2, 0x73, 0xd6,
-	0x6e, 0x9c, 0xe9, 0x8c, 0x66, 0xef, 0x32, 0x35, 0x54, 0x0c, 0x6d, 0xe2, 0x04, 0x1e, 0x7d, 0x3b,
-	0xa3, 0xf1, 0x9d, 0x4c, 0x17, 0x2f, 0x8e, 0xcb, 0x2e, 0x66, 0xc7, 0x45, 0x7e, 0x61, 0xa3, 0x7d,
-	0xd8, 0xa2, 0xc1, 0x44, 0x44, 0xd2, 0xf9, 0x91, 0x59, 0xea, 0x37, 0xb0, 0xb7, 0x52, 0xc6, 0x12,
-	0xf2, 0x69, 0x41, 0x99, 0x7f, 0x7b, 0xd3, 0x22, 0x65, 0xdf, 0x50, 0x9f, 0x42, 0x67, 0x05, 0xa6,
-	0x4f, 0xc3, 0x84, 0xae, 0xe1, 0x34, 0x7e, 0xf2, 0x65, 0x1c, 0xdf, 0xe4, 0x4c, 0x34, 0xfc, 0xd9,
-	0x07, 0xfb, 0xa3, 0xb4, 0x56, 0x83, 0xd0, 0x24, 0x0a, 0x03, 0xbe, 0x1d, 0x86, 0x26, 0x4f, 0x4c,
-	0xb4, 0x60, 0x22, 0x6b, 0xa6, 0x7f, 0x3d, 0xea, 0xfd, 0x47, 0xb9, 0xa8, 0x3f, 0xb2, 0x37, 0x59,
-	0xce, 0x12, 0xcf, 0xf2, 0xca, 0x49, 0x4e, 0xc2, 0x38, 0xdd, 0x7a, 0x87, 0xe4, 0x66, 0x76, 0x9e,
-	0x4a, 0x7e, 0x9e, 0x6f, 0x5f, 0x40, 0x67, 0xd3, 0xf7, 0x5b, 0x0c, 0xff, 0xd1, 0xe9, 0xab, 0x81,
-	0xa9, 0xf3, 0x27, 0xc1, 0x15, 0xa7, 0x0f, 0xad, 0xd7, 0xa6, 0x81, 0x2d, 0xdb, 0xd4, 0x06, 0x4a,
-	0xa9, 0xff, 0x6e, 0x61, 0xee, 0x8c, 0x67, 0x51, 0x14, 0xf2, 0x11, 0x6b, 0x70, 0x15, 0x52, 0xcf,
-	0x4f, 0x18, 0x8d, 0x51, 0xf7, 0x63, 0x53, 0xe7, 0xe0, 0xa3, 0x11, 0xf5, 0xde, 0x61, 0xe9, 0x79,
-	0xe9, 0x55, 0x17, 0xf6, 0xc3, 0xd8, 0xeb, 0x5d, 0xf1, 0x97, 0x19, 0x4f, 0xe9, 0xc4, 0xa3, 0x71,
-	0x96, 0x70, 0x91, 0xfe, 0x29, 0xfc, 0xfe, 0xbf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x6b, 0x55, 0xfc,
-	0xa4, 0x2e, 0x0a, 0x00, 0x00,
+	// 1472 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xa4, 0x57, 0x5b, 0x6f, 0xdb, 0xca,
+	0x11, 0x8e, 0xee, 0xd2, 0xe8, 0xc6, 0xac, 0x15, 0x9b, 0x50, 0xd3, 0xc4, 0x20, 0xd2, 0xc0, 0x28,
+	0x0a, 0x25, 0x55, 0x93, 0xa2, 0xe8, 0x25, 0xa8, 0x22, 0xae, 0x1d, 0xc6, 0x12, 0xa5, 0xac, 0x64,
+	0x23, 0x7e, 0x32, 0x68, 0x6a, 0x2d, 0x13, 0xa6, 0x48, 0x96, 0x5c, 0x19, 0xd6, 0x5b, 0xdf, 0x0a,
+	0xf4, 0xc7, 0xf4, 0xa5, 0x40, 0x1f, 0x8a, 0xf3, 0x70, 0x7e, 0xda, 0xc1, 0x2e, 0x2f, 0xa2, 0x24,
+	0xfb, 0x9c, 0x1c, 0x9c, 0x27, 0xef, 0xcc, 0x7c, 0x33, 0x9a, 0xcb, 0xb7, 0xc3, 0x35, 0x34, 0xcd,
+	0x1b, 0xc3, 0x72, 0x4c, 0x77, 0x46, 0x3b, 0x9e, 0xef, 0x32, 0x17, 0x15, 0xc5, 0x9f, 0xa0, 0xdd,
+	0x4a, 0x0c, 0xf4, 0x8e, 0x3a, 0x2c, 0xb4, 0xb6, 0x5f, 0xce, 0x5d, 0x77, 0x6e, 0xd3, 0x37, 0x42,
+	0xba, 0x5a, 0x5e, 0xbf, 0x61, 0xd6, 0x82, 0x06, 0xcc, 0x58, 0x78, 0x21, 0x40, 0x79, 0x0f, 0xd5,
+	0x7e, 0xec, 0xa8, 0xa9, 0x08, 0x41, 0xde, 0x33, 0xd8, 0x8d, 0x9c, 0x39, 0xcc, 0x1c, 0x55, 0x88,
+	0x38, 0x73, 0x9d, 0x63, 0x2c, 0xa8, 0x9c, 0x0d, 0x75, 0xfc, 0xac, 0xbc, 0x82, 0xc6, 0xda, 0xcd,
+	0xf1, 0x96, 0x8c, 0xa3, 0x0c, 0x7f, 0x1e, 0xc8, 0x99, 0xc3, 0xdc, 0x51, 0x8d, 0x88, 0xb3, 0xf2,
+	0xbf, 0x1c, 0xd4, 0x13, 0xd8, 0xc4, 0xa3, 0x26, 0xea, 0x40, 0x9e, 0xad, 0x3c, 0x2a, 0xe2, 0x37,
+	0xba, 0xed, 0x30, 0x89, 0xa0, 0xb3, 0x01, 0xea, 0x4c, 0x57, 0x1e, 0x25, 0x02, 0x87, 0xde, 0x43,
+	0xd5, 0x5c, 0xa7, 0x27, 0x52, 0xa8, 0x76, 0xf7, 0x76, 0xdc, 0x34, 0x95, 0xa4, 0x71, 0xe8, 0x2d,
+	0x94, 0x4c, 0xe6, 0xfa, 0xc3, 0x60, 0x2e, 0xe7, 0x84, 0xcb, 0xfe, 0xae, 0x0b, 0xcf, 0x9a, 0xc4,
+	0x30, 0x24, 0x43, 0x89, 0xb7, 0xc6, 0x5d, 0x32, 0x39, 0x7f, 0x98, 0x39, 0x2a, 0x90, 0x58, 0x44,
+	0xaf, 0xa0, 0x1e, 0x50, 0x73, 0xe9, 0xd3, 0xbe, 0xeb, 0x30, 0x7a, 0xcf, 0xe4, 0x82, 0xe8, 0xc3,
+	0xa6, 0x12, 0x8d, 0xa1, 0x65, 0xba, 0xce, 0xb5, 0x35, 0xa3, 0x0e, 0xb3, 0x0c, 0xdb, 0x62, 0xab,
+	0x01, 0xbd, 0xa3, 0xb6, 0x5c, 0x14, 0x85, 0x3e, 0x4f, 0x7e, 0xfe, 0x01, 0x0c, 0x79, 0xd0, 0x13,
+	0xb5, 0xa1, 0xbc, 0xa0, 0xcc, 0x98, 0x19, 0xcc, 0x90, 0x4b, 0x87, 0x99, 0xa3, 0x1a, 0x49, 0x64,
+	0xf4, 0x02, 0xc0, 0x60, 0xcc, 0xb7, 0xae, 0x96, 0x8c, 0x06, 0x72, 0xf9, 0x30, 0x77, 0x54, 0x21,
+	0x29, 0x8d, 0xf2, 0x01, 0xf2, 0xbc, 0x89, 0xa8, 0x0e, 0x95, 0x33, 0x5d, 0xc5, 0xc7, 0x9a, 0x8e,
+	0x55, 0xe9, 0x09, 0x02, 0x28, 0x9e, 0x8c, 0x06, 0x3d, 0xfd, 0x44, 0xca, 0xa0, 0x32, 0xe4, 0xf5,
+	0x91, 0x8a, 0xa5, 0x2c, 0x2a, 0x41, 0xae, 0xdf, 0x23, 0x52, 0x8e, 0xab, 0x3e, 0xf7, 0xce, 0x7b,
+	0x52, 0x5e, 0xf9, 0x7f, 0x16, 0x0e, 0x92, 0x4e, 0xa9, 0xd4, 0xb3, 0xdd, 0xd5, 0x82, 0x3a, 0x4c,
+	0x8c, 0xf0, 0x2f, 0x50, 0x37, 0xd3, 0xe3, 0x12, 0xb3, 0xac, 0x76, 0x9f, 0x3d, 0x38, 0x4b, 0xb2,
+	0x89, 0x45, 0x7f, 0x87, 0x3a, 0xbd, 0xbe, 0xa6, 0x26, 0xb3, 0xee, 0xa8, 0x6a, 0x30, 0x1a, 0x4d,
+	0xb4, 0xdd, 0x09, 0x79, 0xda, 0x89, 0x79, 0xda, 0x99, 0xc6, 0x3c, 0x25, 0x9b, 0x0e, 0xe8, 0x10,
+	0xaa, 0x3c, 0xda, 0xd8, 0x30, 0x6f, 0x8d, 0x39, 0x15, 0xe3, 0xad, 0x91, 0xb4, 0x0a, 0xe9, 0x50,
+	0xa2, 0xf7, 0xd4, 0xc4, 0xce, 0x9d, 0x18, 0x65, 0xa3, 0xfb, 0x6e, 0x27, 0xb5, 0xcd, 0x92, 0x3a,
+	0xf8, 0x9e, 0x9a, 0x4b, 0x66, 0xb9, 0x0e, 0x76, 0xee, 0x2c, 0xdf, 0x75, 0xb8, 0x81, 0xc4, 0x41,
+	0x94, 0x0e, 0xb4, 0x1e, 0x02, 0xf0, 0x6e, 0xaa, 0xa3, 0xfe, 0x29, 0x26, 0x61, 0x67, 0x27, 0x17,
+	0x93, 0x29, 0x1e, 0x4a, 0x19, 0xe5, 0x9f, 0x99, 0x54, 0xf3, 0x34, 0xe7, 0xce, 0x35, 0x0d, 0xee,
+	0xfa, 0xcb, 0x9b, 0x77, 0x04, 0x4d, 0x6b, 0x76, 0x42, 0x1d, 0xea, 0x8b, 0x80, 0x3d, 0x7b, 0x1e,
+	0xdd, 0xc9, 0x6d, 0xb5, 0xf2, 0x9f, 0x2c, 0xc8, 0xeb, 0x50, 0x9c, 0xa8, 0x16, 0x5b, 0xc5, 0x54,
+	0x7d, 0x01, 0x60, 0x1a, 0xb6, 0x4d, 0xfd, 0x3e, 0xf5, 0x99, 0x48, 0xa0, 0x46, 0x52, 0x9a, 0xb5,
+	0x7d, 0x62, 0xcd, 0x1d, 0x39, 0x9b, 0xb6, 0x73, 0x0d, 0xbf, 0x2a, 0x9e, 0xb1, 0xb2, 0x5d, 0x63,
+	0x16, 0x75, 0x3f, 0x16, 0xb9, 0xe5, 0xca, 0x72, 0x66, 0x96, 0x33, 0x17, 0x9d, 0xaf, 0x91, 0x58,
+	0xdc, 0x20, 0x73, 0x61, 0x8b, 0xcc, 0xaf, 0xa1, 0xe1, 0x19, 0x3e, 0x75, 0xd8, 0x30, 0x46, 0x14,
+	0x05, 0x62, 0x4b, 0x8b, 0xfe, 0x0a, 0x55, 0x76, 0x9f, 0xf0, 0x42, 0x2e, 0xfd, 0x24, 0x73, 0xd2,
+	0x70, 0xf4, 0x1c, 0x2a, 0xbc, 0x86, 0x09, 0x33, 0xcc, 0xdb, 0xe8, 0xc6, 0xac, 0x15, 0xca, 0x77,
+	0x45, 0x90, 0x92, 0x86, 0x0d, 0x69, 0x10, 0x70, 0x22, 0xfd, 0x7e, 0x63, 0x59, 0xfd, 0x7a, 0x67,
+	0x46, 0x11, 0x2e, 0xbd, 0xaf, 0xfe, 0x04, 0x95, 0x64, 0xc3, 0x7e, 0x03, 0xb7, 0xd7, 0xe0, 0x1f,
+	0xe9, 0x2a, 0x82, 0x3c, 0xbb, 0xb7, 0x66, 0xa2, 0xa5, 0x15, 0x22, 0xce, 0xe8, 0x33, 0x34, 0x83,
+	0xcd, 0xb1, 0x8a, 0xb6, 0x56, 0xbb, 0x87, 0xbb, 0x4c, 0xda, 0xc4, 0x91, 0x6d, 0x47, 0xf4, 0x01,
+	0x1a, 0x09, 0xcf, 0x30, 0xff, 0x76, 0xc8, 0xc5, 0x47, 0x76, 0xa6, 0xb0, 0x92, 0x2d, 0xf4, 0xa6,
+	0xbf, 0xef, 0xbb, 0xbe, 0x5c, 0x7a, 0xcc, 0x9f, 0x5b, 0xc9, 0x16, 0x5a, 0xf9, 0x3e, 0xf7, 0xf0,
+	0xb6, 0xaa, 0x41, 0x99, 0xe0, 0x13, 0x6d, 0x32, 0xc5, 0x44, 0xca, 0xa0, 0x06, 0x40, 0x2c, 0x61,
+	0x55, 0xca, 0xf2, 0x65, 0xa5, 0xe9, 0xda, 0x54, 0xca, 0xa1, 0x0a, 0x14, 0x08, 0xee, 0xa9, 0x17,
+	0x52, 0x1e, 0x35, 0xa1, 0x3a, 0x25, 0x3d, 0x7d, 0xd2, 0xeb, 0x4f, 0xb5, 0x91, 0x2e, 0x15, 0x78,
+	0xc8, 0xfe, 0x68, 0x38, 0x1e, 0xe0, 0x29, 0x56, 0xa5, 0x22, 0x87, 0x62, 0x42, 0x46, 0x44, 0x2a,
+	0x71, 0xcb, 0x09, 0x9e, 0x5e, 0x4e, 0xa6, 0xbd, 0x29, 0x96, 0xca, 0x5c, 0x1c, 0x9f, 0xc5, 0x62,
+	0x85, 0x8b, 0x2a, 0x1e, 0x44, 0x22, 0xa0, 0x16, 0x48, 0x9a, 0x7e, 0x3e, 0x3a, 0xc5, 0x97, 0xfd,
+	0x4f, 0x3d, 0x4d, 0xef, 0xf3, 0xc5, 0x59, 0x45, 0x12, 0xd4, 0x22, 0xed, 0x97, 0x33, 0x4c, 0x2e,
+	0xa4, 0x5a, 0x98, 0xf2, 0x64, 0x3c, 0xd2, 0x27, 0x58, 0xaa, 0xf3, 0x5f, 0x0b, 0x0d, 0x0d, 0xb4,
+	0x07, 0x4d, 0x71, 0xbc, 0x5c, 0x67, 0xd3, 0xe4, 0xd9, 0x86, 0xca, 0x30, 0x27, 0x09, 0x3d, 0x83,
+	0xa7, 0xa4, 0xa7, 0x9f, 0x44, 0xf1, 0xa2, 0x5f, 0x7f, 0x8a, 0xda, 0xb0, 0xbf, 0xa3, 0xbe, 0xd4,
+	0xf1, 0xd7, 0xa9, 0x84, 0xd0, 0xaf, 0xe0, 0x60, 0xd7, 0xd6, 0x1f, 0x8c, 0x26, 0x58, 0xda, 0xe3,
+	0x55, 0x9c, 0x62, 0x3c, 0xee, 0x0d, 0xb4, 0x73, 0x2c, 0xb5, 0xd0, 0x3e, 0xa0, 0xa4, 0xe4, 0xcb,
+	0xe1, 0xd9, 0x60, 0xaa, 0x8d, 0x07, 0x58, 0x7a, 0xc6, 0xf5, 0xe3, 0xb3, 0x1d, 0xfd, 0xfe, 0x3a,
+	0xbf, 0x30, 0x91, 0x03, 0x74, 0x00, 0x7b, 0x3c, 0xc0, 0x27, 0x6d, 0x32, 0x1d, 0x91, 0x8b, 0xcb,
+	0xe3, 0x11, 0xb9, 0x3c, 0xc5, 0x17, 0x92, 0xac, 0x7c, 0x4d, 0x3d, 0x07, 0xc4, 0x50, 0x39, 0x69,
+	0xb9, 0x20, 0xee, 0x4e, 0x81, 0x88, 0x33, 0xa7, 0xf8, 0x22, 0xbc, 0x32, 0xd1, 0xde, 0x8a, 0x45,
+	0x6e, 0x99, 0x51, 0x66, 0x58, 0x76, 0x20, 0xc8, 0x5f, 0x21, 0xb1, 0xa8, 0xfc, 0x11, 0x6a, 0xe3,
+	0x25, 0x9b, 0x30, 0x83, 0x51, 0xcd, 0xb9, 0x76, 0x91, 0x04, 0xb9, 0x5b, 0xba, 0x8a, 0xde, 0x27,
+	0xfc, 0x88, 0x5a, 0x50, 0xb8, 0x33, 0xec, 0x25, 0x8d, 0x36, 0x55, 0x28, 0x28, 0xaf, 0x41, 0x3a,
+	0xa1, 0xa1, 0xdf, 0x70, 0x69, 0x33, 0xcb, 0xb3, 0x29, 0xcf, 0xe9, 0x96, 0xae, 0xc2, 0x27, 0x4a,
+	0x85, 0x88, 0xb3, 0xd2, 0x05, 0x79, 0x1b, 0x47, 0x68, 0xe0, 0xb9, 0x4e, 0x40, 0xd1, 0x3e, 0x14,
+	0x45, 0xb0, 0xf8, 0x51, 0x13, 0x49, 0x8a, 0x0e, 0xd2, 0x78, 0xb9, 0xe9, 0x83, 0xfe, 0x0c, 0x75,
+	0x1e, 0xaf, 0xe7, 0xcc, 0xce, 0xd7, 0x2e, 0xd5, 0x6e, 0x2b, 0xbe, 0x03, 0xe9, 0x22, 0xc8, 0x26,
+	0x54, 0xc1, 0xd0, 0x24, 0x86, 0x33, 0xa7, 0x5f, 0x96, 0xd4, 0x5f, 0x09, 0x14, 0xdf, 0x97, 0x01,
+	0x33, 0x7c, 0x76, 0x9a, 0xd4, 0x9a, 0xc8, 0x3c, 0x2d, 0xea, 0xcc, 0xb8, 0x25, 0xec, 0x62, 0x24,
+	0x29, 0xbf, 0x81, 0xbd, 0xad, 0x30, 0x3a, 0xbf, 0xde, 0x0d, 0xc8, 0x6a, 0x6a, 0x14, 0x24, 0x6b,
+	0xa9, 0xca, 0x6b, 0x68, 0x6d, 0xc1, 0xfa, 0xb6, 0x1b, 0xd0, 0x1d, 0x5c, 0x0f, 0x0e, 0xb6, 0x70,
+	0xa7, 0x74, 0x25, 0x32, 0xfe, 0xe6, 0x21, 0x28, 0x00, 0xa9, 0x9a, 0x5a, 0x50, 0xf8, 0x07, 0x97,
+	0x22, 0xbf, 0x50, 0x50, 0x5e, 0x89, 0x41, 0x7d, 0xb2, 0x02, 0xe6, 0xfa, 0xab, 0x63, 0xd7, 0xe7,
+	0x15, 0xee, 0xc4, 0x57, 0xfe, 0x9b, 0x81, 0xe6, 0x29, 0x5d, 0x0d, 0xdd, 0x99, 0x75, 0x6d, 0x85,
+	0xdf, 0xd3, 0x70, 0x2f, 0x26, 0x29, 0x8b, 0x33, 0x7f, 0x1d, 0x5c, 0xd9, 0xae, 0x79, 0xab, 0x2f,
+	0x17, 0x57, 0xd4, 0x17, 0xd9, 0xe4, 0x49, 0x5a, 0xb5, 0xce, 0x34, 0x97, 0xca, 0x74, 0x73, 0x6f,
+	0xe7, 0x7f, 0xce, 0xde, 0x6e, 0x43, 0xd9, 0x0a, 0x54, 0x6a, 0x53, 0x46, 0xc5, 0x0a, 0x2e, 0x93,
+	0x44, 0x56, 0xfe, 0x95, 0x01, 0x79, 0xbb, 0xb8, 0x84, 0x5d, 0x7f, 0x83, 0xfa, 0x22, 0x55, 0x4e,
+	0xcc, 0x98, 0x83, 0x98, 0x31, 0x5b, 0xe5, 0x92, 0x4d, 0x34, 0xfa, 0x1d, 0x3c, 0x35, 0xdd, 0x85,
+	0xc7, 0x7f, 0xe7, 0xd8, 0x77, 0x17, 0x1f, 0x79, 0x89, 0x51, 0xbd, 0xbb, 0x06, 0xe5, 0xdf, 0x99,
+	0x9d, 0x69, 0x26, 0x89, 0xe0, 0x87, 0xa9, 0xfb, 0x32, 0x4e, 0xe4, 0x11, 0x16, 0x6c, 0xb1, 0x98,
+	0xdf, 0xe1, 0x1b, 0x23, 0x18, 0xba, 0x7e, 0x48, 0x82, 0x32, 0x89, 0xc5, 0x88, 0x59, 0xb9, 0x98,
+	0x59, 0xbf, 0x7d, 0x07, 0xad, 0x87, 0xde, 0xc1, 0xfc, 0x11, 0x35, 0x3e, 0xfb, 0x38, 0xd0, 0xfa,
+	0xd2, 0x13, 0xbe, 0x5b, 0xfb, 0x23, 0xfd, 0x58, 0x53, 0xb1, 0x3e, 0xd5, 0x7a, 0x03, 0x29, 0xd3,
+	0xfd, 0x9a, 0xfa, 0x42, 0x4f, 0x96, 0x9e, 0xe7, 0xfa, 0x0c, 0xa9, 0x50, 0x26, 0x74, 0x6e, 0x05,
+	0x8c, 0xfa, 0x48, 0x7e, 0xec, 0xfb, 0xdc, 0x7e, 0xd4, 0xa2, 0x3c, 0x39, 0xca, 0xbc, 0xcd, 0x7c,
+	0x94, 0x61, 0xdf, 0xf5, 0xe7, 0x9d, 0x9b, 0x95, 0x47, 0x7d, 0x9b, 0xce, 0xe6, 0xd4, 0x8f, 0x1c,
+	0xae, 0xc2, 0x7f, 0xae, 0xfe, 0xf0, 0xc3, 0x00, 0xcb, 0xd5, 0xc6, 0x65, 0x76, 0x0d, 0x00, 0x00,
 }
@@ -177,6 +177,8 @@ func (m *Register) GetEvents() []*Interest {
 type Rejection struct {
 	Tx       *Transaction `protobuf:"bytes,1,opt,name=tx" json:"tx,omitempty"`
 	ErrorMsg string       `protobuf:"bytes,2,opt,name=errorMsg" json:"errorMsg,omitempty"`
+	// Set if the chaincode failed the transaction with a structured error
+	ChaincodeError *ChaincodeError `protobuf:"bytes,3,opt,name=chaincodeError" json:"chaincodeError,omitempty"`
 }
 
 func (m *Rejection) Reset()                    { *m = Rejection{} }
@@ -191,6 +193,13 @@ func (m *Rejection) GetTx() *Transaction {
 	return nil
 }
 
+func (m *Rejection) GetChaincodeError() *ChaincodeError {
+	if m != nil {
+		return m.ChaincodeError
+	}
+	return nil
+}
+
 // ---------- producer events ---------
 type Unregister struct {
 	Events []*Interest `protobuf:"bytes,1,rep,name=events" json:"events,omitempty"`
@@ -544,33 +553,35 @@ var _Events_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("events.proto", fileDescriptor4) }
 
 var fileDescriptor4 = []byte{
-	// 445 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x94, 0x53, 0x5d, 0x6f, 0xd3, 0x30,
-	0x14, 0x6d, 0xda, 0xb5, 0xab, 0x6f, 0xd3, 0x29, 0xbb, 0x4c, 0x28, 0x8a, 0x78, 0x98, 0x82, 0x90,
-	0xaa, 0x3d, 0x14, 0x08, 0x15, 0xcf, 0x90, 0x2c, 0x62, 0x81, 0x91, 0x4a, 0x26, 0xfc, 0x80, 0x34,
-	0x78, 0x23, 0x7c, 0x24, 0x93, 0x1d, 0x10, 0xfc, 0x05, 0x1e, 0xf9, 0xc5, 0x78, 0x8e, 0x9d, 0x64,
-	0xf4, 0x69, 0x4f, 0xf5, 0xbd, 0xe7, 0x9c, 0x7b, 0x8f, 0x4f, 0x1d, 0xb0, 0xd9, 0x4f, 0x56, 0x35,
-	0x62, 0x7d, 0xc3, 0xeb, 0xa6, 0xc6, 0x99, 0xfa, 0x11, 0xde, 0x49, 0xf1, 0x39, 0x2f, 0xab, 0xa2,
-	0xfe, 0xc4, 0x14, 0xdc, 0xa2, 0x9e, 0x7d, 0x95, 0xef, 0x78, 0x59, 0xb4, 0x95, 0x9f, 0x82, 0x1d,
-	0x19, 0x16, 0x65, 0xd7, 0x78, 0x0a, 0x8b, 0x4e, 0x95, 0x9c, 0xbb, 0xd6, 0xa9, 0xb5, 0x22, 0x74,
-	0xd8, 0xc2, 0x47, 0x40, 0xd4, 0xb8, 0x34, 0xff, 0xce, 0xdc, 0xb1, 0xc2, 0xfb, 0x86, 0xff, 0xc7,
-	0x82, 0x79, 0x52, 0x35, 0x8c, 0x33, 0xd1, 0xe0, 0x53, 0x4d, 0xcd, 0x7e, 0xdf, 0x30, 0x35, 0xea,
-	0x28, 0x38, 0x6e, 0xf7, 0x8a, 0x75, 0x6c, 0x00, 0xda, 0x73, 0x30, 0x04, 0xa7, 0x18, 0xb8, 0x49,
-	0xaa, 0xab, 0x5a, 0xad, 0x58, 0x04, 0x27, 0x46, 0x37, 0x74, 0x7b, 0x31, 0xa2, 0x7b, 0xfc, 0x90,
-	0xc0, 0xa1, 0x3e, 0xfa, 0x1b, 0x98, 0xcb, 0x63, 0x29, 0xa4, 0x1d, 0x5c, 0xc1, 0xac, 0x0d, 0x49,
-	0x1a, 0x99, 0xc8, 0x81, 0x8e, 0x19, 0x68, 0xdc, 0x52, 0x8d, 0xfb, 0x97, 0x40, 0x28, 0xfb, 0xc2,
-	0x8a, 0xa6, 0xac, 0x2b, 0x7c, 0x0c, 0xe3, 0xe6, 0x97, 0xf2, 0xbe, 0x08, 0x1e, 0x18, 0x49, 0xc6,
-	0xf3, 0x4a, 0xe4, 0x8a, 0x40, 0x25, 0x8c, 0x1e, 0xcc, 0x19, 0xe7, 0x35, 0x7f, 0x2f, 0xae, 0x75,
-	0x22, 0x5d, 0xed, 0xbf, 0x04, 0xf8, 0x58, 0xf1, 0xfb, 0xbb, 0xf8, 0x3b, 0x86, 0xa9, 0xca, 0x08,
-	0xd7, 0x30, 0x37, 0x7a, 0x6d, 0xa4, 0x53, 0x99, 0xdb, 0xc9, 0x20, 0x3a, 0x0e, 0x3e, 0x81, 0xe9,
-	0xee, 0x5b, 0x5d, 0x7c, 0xd5, 0xc9, 0x2d, 0x0d, 0x39, 0xbc, 0x6d, 0x4a, 0x66, 0x8b, 0xe2, 0x2b,
-	0x38, 0xea, 0xb2, 0x53, 0x8b, 0xdc, 0x89, 0xe2, 0x3f, 0xdc, 0x4b, 0x5a, 0xa1, 0x52, 0xf8, 0x1f,
-	0x1f, 0x9f, 0x03, 0xe1, 0x26, 0x28, 0xf7, 0x40, 0x89, 0x8f, 0x7b, 0x67, 0x1a, 0x90, 0xba, 0x9e,
-	0x85, 0x1b, 0x80, 0x1f, 0x5d, 0x1a, 0xee, 0x54, 0x69, 0xd0, 0x68, 0xfa, 0x9c, 0xa4, 0x68, 0xc0,
-	0x0b, 0x0f, 0x75, 0x14, 0x67, 0x21, 0x90, 0xee, 0xdd, 0xa0, 0x2d, 0xff, 0xdd, 0xf8, 0x4d, 0xf2,
-	0x21, 0x8b, 0xa9, 0x33, 0x42, 0x02, 0xd3, 0xf0, 0x72, 0x1b, 0xbd, 0x73, 0x2c, 0x5c, 0x02, 0x89,
-	0x2e, 0x5e, 0x27, 0x69, 0xb4, 0x3d, 0x8f, 0x9d, 0xf1, 0x6d, 0x49, 0xe3, 0xb7, 0x71, 0x94, 0x25,
-	0xdb, 0xd4, 0x99, 0x04, 0x1b, 0x98, 0xa9, 0x19, 0x02, 0xcf, 0xe0, 0x40, 0xde, 0xb1, 0xc1, 0xe5,
-	0x9d, 0x37, 0xe9, 0xdd, 0x2d, 0xfd, 0xd1, 0xca, 0x7a, 0x66, 0xed, 0xda, 0x6f, 0xea, 0xc5, 0xbf,
-	0x00, 0x00, 0x00, 0xff, 0xff, 0x15, 0xb7, 0x5f, 0x65, 0x6a, 0x03, 0x00, 0x00,
+	// 467 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x94, 0x53, 0x4d, 0x6f, 0xd3, 0x40,
+	0x10, 0xb5, 0x93, 0x26, 0xb5, 0x27, 0x49, 0x71, 0x87, 0x0a, 0x59, 0x11, 0x87, 0xc8, 0x08, 0x29,
+	0xea, 0x21, 0x80, 0x89, 0x38, 0x22, 0xb0, 0x6b, 0x61, 0xf3, 0x91, 0x48, 0x43, 0xf8, 0x01, 0x8e,
+	0xd9, 0xa6, 0xe1, 0xc3, 0xae, 0xd6, 0x0b, 0x2a, 0x7f, 0x01, 0x71, 0xe2, 0x17, 0x23, 0xaf, 0x77,
+	0x6d, 0xb7, 0x15, 0x87, 0x9e, 0x92, 0x99, 0xf7, 0xde, 0xcc, 0x9b, 0xa7, 0x35, 0x8c, 0xd9, 0x4f,
+	0x96, 0x8b, 0x72, 0x71, 0xc9, 0x0b, 0x51, 0xe0, 0x50, 0xfe, 0x94, 0xd3, 0x7b, 0xd9, 0x45, 0xba,
+	0xcf, 0xb3, 0xe2, 0x33, 0xab, 0x81, 0xe9, 0x49, 0xd3, 0x90, 0x7c, 0xd5, 0x1d, 0x9f, 0xa7, 0x5b,
+	0xbe, 0xcf, 0xea, 0xca, 0x5b, 0xc1, 0x38, 0xd4, 0x2c, 0x62, 0x3b, 0x9c, 0xc1, 0xa8, 0x51, 0x25,
+	0x67, 0xae, 0x39, 0x33, 0xe7, 0x36, 0x75, 0x5b, 0xf8, 0x10, 0x6c, 0x39, 0x6e, 0x95, 0x7e, 0x67,
+	0x6e, 0x4f, 0xe2, 0x6d, 0xc3, 0xfb, 0x6d, 0x82, 0x95, 0xe4, 0x82, 0x71, 0x56, 0x0a, 0x7c, 0xa2,
+	0xa8, 0x9b, 0x5f, 0x97, 0x4c, 0x8e, 0x3a, 0xf2, 0x8f, 0xeb, 0xbd, 0xe5, 0x22, 0xd2, 0x00, 0xb5,
+	0x1c, 0x0c, 0xc0, 0xc9, 0x3a, 0x6e, 0x92, 0xfc, 0xbc, 0x90, 0x2b, 0x46, 0xfe, 0x89, 0xd6, 0x75,
+	0xdd, 0xc6, 0x06, 0xdd, 0xe2, 0x07, 0x36, 0x1c, 0xaa, 0xbf, 0xde, 0x12, 0x2c, 0x62, 0xbb, 0x7d,
+	0x29, 0x18, 0xc7, 0x39, 0x0c, 0xeb, 0xd4, 0x5c, 0x73, 0xd6, 0x9f, 0x8f, 0x7c, 0x47, 0x0f, 0xd4,
+	0x6e, 0x49, 0xe1, 0xde, 0x1f, 0x13, 0x6c, 0x62, 0x5f, 0x58, 0x26, 0xf6, 0x45, 0x8e, 0x8f, 0xa0,
+	0x27, 0xae, 0xa4, 0xf9, 0x91, 0x7f, 0x5f, 0x6b, 0x36, 0x3c, 0xcd, 0xcb, 0x54, 0x12, 0xa8, 0x27,
+	0xae, 0x70, 0x0a, 0x16, 0xe3, 0xbc, 0xe0, 0x1f, 0xca, 0x9d, 0x8a, 0xa4, 0xa9, 0xf1, 0x25, 0x1c,
+	0x35, 0x1e, 0xa3, 0xaa, 0xe9, 0xf6, 0xe5, 0xb0, 0x07, 0xb7, 0x2e, 0x92, 0x28, 0xdd, 0x60, 0x7b,
+	0x2f, 0x00, 0x3e, 0xe5, 0xfc, 0xee, 0x67, 0xfc, 0xed, 0xc1, 0x40, 0x86, 0x8c, 0x0b, 0xb0, 0xb4,
+	0x5e, 0x1d, 0xd2, 0xa8, 0x74, 0x3c, 0xb1, 0x41, 0x0d, 0x07, 0x1f, 0xc3, 0x60, 0xfb, 0xad, 0xc8,
+	0xbe, 0xaa, 0xe8, 0x27, 0x9a, 0x1c, 0x54, 0xcd, 0xd8, 0xa0, 0x1a, 0xc5, 0x57, 0xdd, 0xc3, 0xaa,
+	0x45, 0xff, 0x3f, 0xac, 0x42, 0x63, 0x83, 0x6e, 0xf0, 0xf1, 0x19, 0xd8, 0x5c, 0x07, 0xed, 0x1e,
+	0x48, 0xf1, 0x71, 0xeb, 0x4c, 0x01, 0xb1, 0x41, 0x2d, 0x0b, 0x97, 0x00, 0x3f, 0x9a, 0x34, 0xdc,
+	0x81, 0xd4, 0xa0, 0xd6, 0xb4, 0x39, 0xc5, 0x06, 0x75, 0x78, 0xc1, 0xa1, 0x8a, 0xe2, 0x34, 0x00,
+	0xbb, 0x79, 0x78, 0x38, 0x06, 0x8b, 0xa2, 0x37, 0xc9, 0xc7, 0x4d, 0x44, 0x8e, 0x81, 0x36, 0x0c,
+	0x82, 0xf7, 0xeb, 0xf0, 0x9d, 0x63, 0xe2, 0x04, 0xec, 0x30, 0x7e, 0x9d, 0xac, 0xc2, 0xf5, 0x59,
+	0xe4, 0xf4, 0xaa, 0x92, 0xa2, 0xb7, 0x51, 0xb8, 0x49, 0xd6, 0x2b, 0xa7, 0xef, 0x2f, 0x61, 0x28,
+	0x67, 0x94, 0x78, 0x0a, 0x07, 0xe1, 0x45, 0x2a, 0x70, 0x72, 0xed, 0x51, 0x4f, 0xaf, 0x97, 0x9e,
+	0x31, 0x37, 0x9f, 0x9a, 0xdb, 0xfa, 0x2b, 0x7d, 0xfe, 0x6f, 0x00, 0x5e, 0xe6, 0x17, 0xb7, 0xbc,
+	0x03, 0x00, 0x00,
 }
@@ -75,7 +75,7 @@ var PeerEndpoint_Type_value = map[string]int32{
 func (x PeerEndpoint_Type) String() string {
 	return proto.EnumName(PeerEndpoint_Type_name, int32(x))
 }
-func (PeerEndpoint_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptor5, []int{8, 0} }
+func (PeerEndpoint_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptor5, []int{9, 0} }
 
 type Message_Type int32
 
@@ -138,7 +138,7 @@ var Message_Type_value = map[string]int32{
 func (x Message_Type) String() string {
 	return proto.EnumName(Message_Type_name, int32(x))
 }
-func (Message_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptor5, []int{12, 0} }
+func (Message_Type) EnumDescriptor() ([]byte, []int) { return fileDescriptor5, []int{13, 0} }
 
 type Response_StatusCode int32
 
@@ -162,7 +162,7 @@ var Response_StatusCode_value = map[string]int32{
 func (x Response_StatusCode) String() string {
 	return proto.EnumName(Response_StatusCode_name, int32(x))
 }
-func (Response_StatusCode) EnumDescriptor() ([]byte, []int) { return fileDescriptor5, []int{13, 0} }
+func (Response_StatusCode) EnumDescriptor() ([]byte, []int) { return fileDescriptor5, []int{14, 0} }
 
 // Transaction defines a function call to a contract.
 // `args` is an array of type string so that the chaincode writer can choose
@@ -227,6 +227,11 @@ type TransactionResult struct {
 	ErrorCode      uint32          `protobuf:"varint,3,opt,name=errorCode" json:"errorCode,omitempty"`
 	Error          string          `protobuf:"bytes,4,opt,name=error" json:"error,omitempty"`
 	ChaincodeEvent *ChaincodeEvent `protobuf:"bytes,5,opt,name=chaincodeEvent" json:"chaincodeEvent,omitempty"`
+	// Keys read and written by each chaincode, for transactions that called
+	// other chaincodes. The chaincode called by the transaction comes first.
+	ReadWriteSets []*ChaincodeReadWriteSet `protobuf:"bytes,6,rep,name=readWriteSets" json:"readWriteSets,omitempty"`
+	// Set if the chaincode failed the transaction with a structured error
+	ChaincodeError *ChaincodeError `protobuf:"bytes,7,opt,name=chaincodeError" json:"chaincodeError,omitempty"`
 }
 
 func (m *TransactionResult) Reset()                    { *m = TransactionResult{} }
@@ -241,6 +246,33 @@ func (m *TransactionResult) GetChaincodeEvent() *ChaincodeEvent {
 	return nil
 }
 
+func (m *TransactionResult) GetReadWriteSets() []*ChaincodeReadWriteSet {
+	if m != nil {
+		return m.ReadWriteSets
+	}
+	return nil
+}
+
+func (m *TransactionResult) GetChaincodeError() *ChaincodeError {
+	if m != nil {
+		return m.ChaincodeError
+	}
+	return nil
+}
+
+// ChaincodeReadWriteSet holds the keys of its state that a chaincode read
+// and wrote in a transaction, both sorted.
+type ChaincodeReadWriteSet struct {
+	ChaincodeID string   `protobuf:"bytes,1,opt,name=chaincodeID" json:"chaincodeID,omitempty"`
+	Reads       []string `protobuf:"bytes,2,rep,name=reads" json:"reads,omitempty"`
+	Writes      []string `protobuf:"bytes,3,rep,name=writes" json:"writes,omitempty"`
+}
+
+func (m *ChaincodeReadWriteSet) Reset()                    { *m = ChaincodeReadWriteSet{} }
+func (m *ChaincodeReadWriteSet) String() string            { return proto.CompactTextString(m) }
+func (*ChaincodeReadWriteSet) ProtoMessage()               {}
+func (*ChaincodeReadWriteSet) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{3} }
+
 // Block carries The data that describes a block in the blockchain.
 // version - Version used to track any protocol changes.
 // timestamp - The time at which the block or transaction order
@@ -266,7 +298,7 @@ type Block struct {
 func (m *Block) Reset()                    { *m = Block{} }
 func (m *Block) String() string            { return proto.CompactTextString(m) }
 func (*Block) ProtoMessage()               {}
-func (*Block) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{3} }
+func (*Block) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{4} }
 
 func (m *Block) GetTimestamp() *google_protobuf.Timestamp {
 	if m != nil {
@@ -300,7 +332,7 @@ type BlockchainInfo struct {
 func (m *BlockchainInfo) Reset()                    { *m = BlockchainInfo{} }
 func (m *BlockchainInfo) String() string            { return proto.CompactTextString(m) }
 func (*BlockchainInfo) ProtoMessage()               {}
-func (*BlockchainInfo) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{4} }
+func (*BlockchainInfo) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{5} }
 
 // NonHashData is data that is recorded on the block, but not included in
 // the block hash when verifying the blockchain.
@@ -316,7 +348,7 @@ type NonHashData struct {
 func (m *NonHashData) Reset()                    { *m = NonHashData{} }
 func (m *NonHashData) String() string            { return proto.CompactTextString(m) }
 func (*NonHashData) ProtoMessage()               {}
-func (*NonHashData) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{5} }
+func (*NonHashData) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{6} }
 
 func (m *NonHashData) GetLocalLedgerCommitTimestamp() *google_protobuf.Timestamp {
 	if m != nil {
@@ -340,7 +372,7 @@ type PeerAddress struct {
 func (m *PeerAddress) Reset()                    { *m = PeerAddress{} }
 func (m *PeerAddress) String() string            { return proto.CompactTextString(m) }
 func (*PeerAddress) ProtoMessage()               {}
-func (*PeerAddress) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{6} }
+func (*PeerAddress) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{7} }
 
 type PeerID struct {
 	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
@@ -349,7 +381,7 @@ type PeerID struct {
 func (m *PeerID) Reset()                    { *m = PeerID{} }
 func (m *PeerID) String() string            { return proto.CompactTextString(m) }
 func (*PeerID) ProtoMessage()               {}
-func (*PeerID) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{7} }
+func (*PeerID) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{8} }
 
 type PeerEndpoint struct {
 	ID      *PeerID           `protobuf:"bytes,1,opt,name=ID,json=iD" json:"ID,omitempty"`
@@ -361,7 +393,7 @@ type PeerEndpoint struct {
 func (m *PeerEndpoint) Reset()                    { *m = PeerEndpoint{} }
 func (m *PeerEndpoint) String() string            { return proto.CompactTextString(m) }
 func (*PeerEndpoint) ProtoMessage()               {}
-func (*PeerEndpoint) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{8} }
+func (*PeerEndpoint) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{9} }
 
 func (m *PeerEndpoint) GetID() *PeerID {
 	if m != nil {
@@ -377,7 +409,7 @@ type PeersMessage struct {
 func (m *PeersMessage) Reset()                    { *m = PeersMessage{} }
 func (m *PeersMessage) String() string            { return proto.CompactTextString(m) }
 func (*PeersMessage) ProtoMessage()               {}
-func (*PeersMessage) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{9} }
+func (*PeersMessage) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{10} }
 
 func (m *PeersMessage) GetPeers() []*PeerEndpoint {
 	if m != nil {
@@ -393,7 +425,7 @@ type PeersAddresses struct {
 func (m *PeersAddresses) Reset()                    { *m = PeersAddresses{} }
 func (m *PeersAddresses) String() string            { return proto.CompactTextString(m) }
 func (*PeersAddresses) ProtoMessage()               {}
-func (*PeersAddresses) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{10} }
+func (*PeersAddresses) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{11} }
 
 type HelloMessage struct {
 	PeerEndpoint   *PeerEndpoint   `protobuf:"bytes,1,opt,name=peerEndpoint" json:"peerEndpoint,omitempty"`
@@ -403,7 +435,7 @@ type HelloMessage struct {
 func (m *HelloMessage) Reset()                    { *m = HelloMessage{} }
 func (m *HelloMessage) String() string            { return proto.CompactTextString(m) }
 func (*HelloMessage) ProtoMessage()               {}
-func (*HelloMessage) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{11} }
+func (*HelloMessage) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{12} }
 
 func (m *HelloMessage) GetPeerEndpoint() *PeerEndpoint {
 	if m != nil {
@@ -429,7 +461,7 @@ type Message struct {
 func (m *Message) Reset()                    { *m = Message{} }
 func (m *Message) String() string            { return proto.CompactTextString(m) }
 func (*Message) ProtoMessage()               {}
-func (*Message) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{12} }
+func (*Message) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{13} }
 
 func (m *Message) GetTimestamp() *google_protobuf.Timestamp {
 	if m != nil {
@@ -441,12 +473,21 @@ func (m *Message) GetTimestamp() *google_protobuf.Timestamp {
 type Response struct {
 	Status Response_StatusCode `protobuf:"varint,1,opt,name=status,enum=protos.Response_StatusCode" json:"status,omitempty"`
 	Msg    []byte              `protobuf:"bytes,2,opt,name=msg,proto3" json:"msg,omitempty"`
+	// Set when a chaincode failed a query with a structured error
+	ChaincodeError *ChaincodeError `protobuf:"bytes,3,opt,name=chaincodeError" json:"chaincodeError,omitempty"`
 }
 
 func (m *Response) Reset()                    { *m = Response{} }
 func (m *Response) String() string            { return proto.CompactTextString(m) }
 func (*Response) ProtoMessage()               {}
-func (*Response) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{13} }
+func (*Response) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{14} }
+
+func (m *Response) GetChaincodeError() *ChaincodeError {
+	if m != nil {
+		return m.ChaincodeError
+	}
+	return nil
+}
 
 // BlockState is the payload of Message.SYNC_BLOCK_ADDED. When a VP
 // commits a new block to the ledger, it will notify its connected NVPs of the
@@ -461,7 +502,7 @@ type BlockState struct {
 func (m *BlockState) Reset()                    { *m = BlockState{} }
 func (m *BlockState) String() string            { return proto.CompactTextString(m) }
 func (*BlockState) ProtoMessage()               {}
-func (*BlockState) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{14} }
+func (*BlockState) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{15} }
 
 func (m *BlockState) GetBlock() *Block {
 	if m != nil {
@@ -484,19 +525,22 @@ type SyncBlockRange struct {
 func (m *SyncBlockRange) Reset()                    { *m = SyncBlockRange{} }
 func (m *SyncBlockRange) String() string            { return proto.CompactTextString(m) }
 func (*SyncBlockRange) ProtoMessage()               {}
-func (*SyncBlockRange) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{15} }
+func (*SyncBlockRange) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{16} }
 
 // SyncBlocks is the payload of Message.SYNC_BLOCKS, where the range
-// indicates the blocks responded to the request SYNC_GET_BLOCKS
+// indicates the blocks responded to the request SYNC_GET_BLOCKS. pruned is
+// set, with no blocks, when the first block of the range has been pruned
+// from the blockchain of the responding peer.
 type SyncBlocks struct {
 	Range  *SyncBlockRange `protobuf:"bytes,1,opt,name=range" json:"range,omitempty"`
 	Blocks []*Block        `protobuf:"bytes,2,rep,name=blocks" json:"blocks,omitempty"`
+	Pruned bool            `protobuf:"varint,3,opt,name=pruned" json:"pruned,omitempty"`
 }
 
 func (m *SyncBlocks) Reset()                    { *m = SyncBlocks{} }
 func (m *SyncBlocks) String() string            { return proto.CompactTextString(m) }
 func (*SyncBlocks) ProtoMessage()               {}
-func (*SyncBlocks) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{16} }
+func (*SyncBlocks) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{17} }
 
 func (m *SyncBlocks) GetRange() *SyncBlockRange {
 	if m != nil {
@@ -520,7 +564,7 @@ type SyncStateSnapshotRequest struct {
 func (m *SyncStateSnapshotRequest) Reset()                    { *m = SyncStateSnapshotRequest{} }
 func (m *SyncStateSnapshotRequest) String() string            { return proto.CompactTextString(m) }
 func (*SyncStateSnapshotRequest) ProtoMessage()               {}
-func (*SyncStateSnapshotRequest) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{17} }
+func (*SyncStateSnapshotRequest) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{18} }
 
 // SyncStateSnapshot is the payload of Message.SYNC_SNAPSHOT, which is a response
 // to penchainMessage.SYNC_GET_SNAPSHOT. It contains the snapshot or a chunk of the
@@ -536,7 +580,7 @@ type SyncStateSnapshot struct {
 func (m *SyncStateSnapshot) Reset()                    { *m = SyncStateSnapshot{} }
 func (m *SyncStateSnapshot) String() string            { return proto.CompactTextString(m) }
 func (*SyncStateSnapshot) ProtoMessage()               {}
-func (*SyncStateSnapshot) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{18} }
+func (*SyncStateSnapshot) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{19} }
 
 func (m *SyncStateSnapshot) GetRequest() *SyncStateSnapshotRequest {
 	if m != nil {
@@ -556,7 +600,7 @@ type SyncStateDeltasRequest struct {
 func (m *SyncStateDeltasRequest) Reset()                    { *m = SyncStateDeltasRequest{} }
 func (m *SyncStateDeltasRequest) String() string            { return proto.CompactTextString(m) }
 func (*SyncStateDeltasRequest) ProtoMessage()               {}
-func (*SyncStateDeltasRequest) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{19} }
+func (*SyncStateDeltasRequest) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{20} }
 
 func (m *SyncStateDeltasRequest) GetRange() *SyncBlockRange {
 	if m != nil {
@@ -575,7 +619,7 @@ type SyncStateDeltas struct {
 func (m *SyncStateDeltas) Reset()                    { *m = SyncStateDeltas{} }
 func (m *SyncStateDeltas) String() string            { return proto.CompactTextString(m) }
 func (*SyncStateDeltas) ProtoMessage()               {}
-func (*SyncStateDeltas) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{20} }
+func (*SyncStateDeltas) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{21} }
 
 func (m *SyncStateDeltas) GetRange() *SyncBlockRange {
 	if m != nil {
@@ -584,10 +628,90 @@ func (m *SyncStateDeltas) GetRange() *SyncBlockRange {
 	return nil
 }
 
+// StateSnapshotHeader starts a state snapshot file, as written by peer node
+// snapshot export. It is followed by the world state at the block, as
+// chunks of marshalled state deltas, and a checksum of the file.
+type StateSnapshotHeader struct {
+	Version     uint32 `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	BlockNumber uint64 `protobuf:"varint,2,opt,name=blockNumber" json:"blockNumber,omitempty"`
+	Block       *Block `protobuf:"bytes,3,opt,name=block" json:"block,omitempty"`
+}
+
+func (m *StateSnapshotHeader) Reset()                    { *m = StateSnapshotHeader{} }
+func (m *StateSnapshotHeader) String() string            { return proto.CompactTextString(m) }
+func (*StateSnapshotHeader) ProtoMessage()               {}
+func (*StateSnapshotHeader) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{22} }
+
+func (m *StateSnapshotHeader) GetBlock() *Block {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+// PrunedBlock is stored in place of a block whose transactions have been
+// pruned from the blockchain. The block keeps its other fields, so that it
+// still links to the previous block, and blockHash is the hash of the full
+// block.
+type PrunedBlock struct {
+	Block     *Block `protobuf:"bytes,1,opt,name=block" json:"block,omitempty"`
+	BlockHash []byte `protobuf:"bytes,2,opt,name=blockHash,proto3" json:"blockHash,omitempty"`
+}
+
+func (m *PrunedBlock) Reset()                    { *m = PrunedBlock{} }
+func (m *PrunedBlock) String() string            { return proto.CompactTextString(m) }
+func (*PrunedBlock) ProtoMessage()               {}
+func (*PrunedBlock) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{23} }
+
+func (m *PrunedBlock) GetBlock() *Block {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+// ArchivedBlock is a record of the block archive files written when the
+// blockchain is pruned. stateDelta is the marshalled state delta of the
+// block, if it was still kept.
+type ArchivedBlock struct {
+	BlockNumber uint64 `protobuf:"varint,1,opt,name=blockNumber" json:"blockNumber,omitempty"`
+	Block       *Block `protobuf:"bytes,2,opt,name=block" json:"block,omitempty"`
+	StateDelta  []byte `protobuf:"bytes,3,opt,name=stateDelta,proto3" json:"stateDelta,omitempty"`
+}
+
+func (m *ArchivedBlock) Reset()                    { *m = ArchivedBlock{} }
+func (m *ArchivedBlock) String() string            { return proto.CompactTextString(m) }
+func (*ArchivedBlock) ProtoMessage()               {}
+func (*ArchivedBlock) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{24} }
+
+func (m *ArchivedBlock) GetBlock() *Block {
+	if m != nil {
+		return m.Block
+	}
+	return nil
+}
+
+// StateStructure records in the DB the data structure the world state is
+// persisted with. configs are the configurations of the data structure which
+// change the way it is persisted, as name=value sorted by name. migrating is
+// set while the state is rebuilt for another data structure.
+type StateStructure struct {
+	Version   uint32   `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	Name      string   `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Configs   []string `protobuf:"bytes,3,rep,name=configs" json:"configs,omitempty"`
+	Migrating bool     `protobuf:"varint,4,opt,name=migrating" json:"migrating,omitempty"`
+}
+
+func (m *StateStructure) Reset()                    { *m = StateStructure{} }
+func (m *StateStructure) String() string            { return proto.CompactTextString(m) }
+func (*StateStructure) ProtoMessage()               {}
+func (*StateStructure) Descriptor() ([]byte, []int) { return fileDescriptor5, []int{25} }
+
 func init() {
 	proto.RegisterType((*Transaction)(nil), "protos.Transaction")
 	proto.RegisterType((*TransactionBlock)(nil), "protos.TransactionBlock")
 	proto.RegisterType((*TransactionResult)(nil), "protos.TransactionResult")
+	proto.RegisterType((*ChaincodeReadWriteSet)(nil), "protos.ChaincodeReadWriteSet")
 	proto.RegisterType((*Block)(nil), "protos.Block")
 	proto.RegisterType((*BlockchainInfo)(nil), "protos.BlockchainInfo")
 	proto.RegisterType((*NonHashData)(nil), "protos.NonHashData")
@@ -606,6 +730,10 @@ func init() {
 	proto.RegisterType((*SyncStateSnapshot)(nil), "protos.SyncStateSnapshot")
 	proto.RegisterType((*SyncStateDeltasRequest)(nil), "protos.SyncStateDeltasRequest")
 	proto.RegisterType((*SyncStateDeltas)(nil), "protos.SyncStateDeltas")
+	proto.RegisterType((*StateSnapshotHeader)(nil), "protos.StateSnapshotHeader")
+	proto.RegisterType((*PrunedBlock)(nil), "protos.PrunedBlock")
+	proto.RegisterType((*ArchivedBlock)(nil), "protos.ArchivedBlock")
+	proto.RegisterType((*StateStructure)(nil), "protos.StateStructure")
 	proto.RegisterEnum("protos.Transaction_Type", Transaction_Type_name, Transaction_Type_value)
 	proto.RegisterEnum("protos.PeerEndpoint_Type", PeerEndpoint_Type_name, PeerEndpoint_Type_value)
 	proto.RegisterEnum("protos.Message_Type", Message_Type_name, Message_Type_value)
@@ -759,97 +887,109 @@ var _Peer_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("fabric.proto", fileDescriptor5) }
 
 var fileDescriptor5 = []byte{
-	// 1463 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x57, 0xcd, 0x6e, 0xdb, 0xc6,
-	0x13, 0x8f, 0x3e, 0x1d, 0x8d, 0x64, 0x99, 0xde, 0x38, 0x09, 0xe3, 0x04, 0xf9, 0x07, 0xfc, 0xb7,
-	0x40, 0x50, 0xb4, 0x4a, 0xe1, 0xa0, 0x48, 0x10, 0xa0, 0x45, 0x14, 0x89, 0x8e, 0x85, 0x28, 0x94,
-	0xb3, 0x94, 0x1d, 0xa4, 0x87, 0x1a, 0xb4, 0xb4, 0xb6, 0x89, 0x48, 0xa4, 0x4a, 0x52, 0x41, 0x7d,
-	0xed, 0xa9, 0xe8, 0x6b, 0xf4, 0xd8, 0x63, 0x9f, 0xa1, 0x1f, 0x8f, 0xd0, 0xb7, 0xe8, 0xa5, 0x0f,
-	0xd0, 0xd9, 0x59, 0x92, 0x22, 0x65, 0x25, 0x4d, 0x7a, 0xb1, 0x39, 0x5f, 0xbb, 0xf3, 0xf1, 0x9b,
-	0x99, 0x15, 0x34, 0x4e, 0x9c, 0xe3, 0xc0, 0x1d, 0xb5, 0x66, 0x81, 0x1f, 0xf9, 0xac, 0x4a, 0xff,
-	0xc2, 0xed, 0x8d, 0xd1, 0x99, 0xe3, 0x7a, 0x23, 0x7f, 0x2c, 0x94, 0x60, 0x7b, 0x2b, 0x65, 0x88,
-	0x37, 0xc2, 0x8b, 0x62, 0xee, 0xff, 0x4e, 0x7d, 0xff, 0x74, 0x22, 0xee, 0x11, 0x75, 0x3c, 0x3f,
-	0xb9, 0x17, 0xb9, 0x53, 0x11, 0x46, 0xce, 0x74, 0xa6, 0x14, 0x8c, 0x3f, 0xcb, 0x50, 0x1f, 0x06,
-	0x8e, 0x17, 0x3a, 0xa3, 0xc8, 0xf5, 0x3d, 0xf6, 0x29, 0x94, 0xa3, 0xf3, 0x99, 0xd0, 0x0b, 0x77,
-	0x0a, 0x77, 0x9b, 0x3b, 0xba, 0xd2, 0x0a, 0x5b, 0x19, 0x95, 0xd6, 0x10, 0xe5, 0x9c, 0xb4, 0xd8,
-	0x1d, 0xa8, 0xa7, 0xd7, 0xf6, 0xba, 0x7a, 0x11, 0x8d, 0x1a, 0x3c, 0xcb, 0x62, 0x3a, 0xac, 0xcd,
-	0x9c, 0xf3, 0x89, 0xef, 0x8c, 0xf5, 0x12, 0x49, 0x13, 0x92, 0x6d, 0xc3, 0xe5, 0xa9, 0x88, 0x9c,
-	0xb1, 0x13, 0x39, 0x7a, 0x99, 0x44, 0x29, 0xcd, 0x18, 0x7a, 0xf1, 0x9d, 0x3b, 0xd6, 0x2b, 0xc8,
-	0xaf, 0x71, 0xfa, 0x66, 0x0f, 0xa1, 0x96, 0x3a, 0xaf, 0x57, 0x51, 0x50, 0xdf, 0xd9, 0x6e, 0xa9,
-	0xf0, 0x5a, 0x49, 0x78, 0xad, 0x61, 0xa2, 0xc1, 0x17, 0xca, 0x6c, 0x1f, 0xb6, 0x46, 0xbe, 0x77,
-	0xe2, 0x8e, 0x31, 0x2f, 0xae, 0x33, 0x71, 0xa3, 0xf3, 0x3e, 0xe6, 0x68, 0xa2, 0xaf, 0x51, 0x8c,
-	0xb7, 0x92, 0x18, 0x3b, 0x2b, 0x74, 0xf8, 0x4a, 0x4b, 0xb6, 0x0b, 0xb7, 0x97, 0xf8, 0xfb, 0xf2,
-	0x8c, 0x91, 0x3f, 0x39, 0x14, 0x41, 0x88, 0x49, 0xd2, 0x2f, 0x93, 0xe7, 0xff, 0xa2, 0xc5, 0xb6,
-	0xa0, 0xe2, 0xf9, 0xde, 0x48, 0xe8, 0x35, 0x4a, 0x80, 0x22, 0x98, 0x01, 0x8d, 0xc8, 0x3f, 0x44,
-	0x03, 0xcc, 0x85, 0x1f, 0x84, 0x3a, 0x90, 0x30, 0xc7, 0x93, 0x19, 0x1a, 0x89, 0x20, 0xd2, 0xeb,
-	0x24, 0xa3, 0x6f, 0x76, 0x0b, 0x6a, 0xa1, 0x7b, 0xea, 0x39, 0xd1, 0x3c, 0x10, 0x7a, 0x83, 0x04,
-	0x0b, 0x86, 0xe1, 0x43, 0x59, 0x56, 0x8e, 0xad, 0x43, 0xed, 0xc0, 0xea, 0x9a, 0xbb, 0x3d, 0xcb,
-	0xec, 0x6a, 0x97, 0xd0, 0x05, 0xad, 0xb3, 0xd7, 0xee, 0x59, 0x9d, 0x41, 0xd7, 0x3c, 0xea, 0x9a,
-	0xfb, 0xfd, 0xc1, 0x2b, 0xad, 0x90, 0xe7, 0xf6, 0xac, 0xc3, 0xc1, 0x33, 0x53, 0x2b, 0xb2, 0x2b,
-	0xb0, 0xb1, 0xe0, 0xbe, 0x38, 0x30, 0xf9, 0x2b, 0xad, 0xc4, 0xae, 0xc3, 0x95, 0x05, 0x73, 0x68,
-	0xf2, 0xe7, 0x3d, 0xab, 0x3d, 0x34, 0xb5, 0xb2, 0xf1, 0x0c, 0xb4, 0x0c, 0x6c, 0x9e, 0x4c, 0xfc,
-	0xd1, 0x6b, 0xf6, 0x00, 0x43, 0x5b, 0xf0, 0x42, 0x84, 0x59, 0x09, 0xeb, 0x78, 0x65, 0x05, 0xcc,
-	0x78, 0x4e, 0xd1, 0xf8, 0xa5, 0x00, 0x9b, 0x59, 0xa9, 0x08, 0xe7, 0x93, 0x28, 0xc5, 0x49, 0x21,
-	0x83, 0x93, 0x6b, 0x50, 0x0d, 0x48, 0x1a, 0xc3, 0x31, 0xa6, 0x64, 0x76, 0x44, 0x10, 0xf8, 0x41,
-	0x07, 0x81, 0x49, 0x58, 0x5c, 0xe7, 0x0b, 0x86, 0xac, 0x04, 0x11, 0x04, 0xc5, 0x1a, 0x57, 0x04,
-	0xfb, 0x0a, 0x9a, 0x29, 0x98, 0x4d, 0xd9, 0x56, 0x84, 0xc8, 0xfa, 0xce, 0xb5, 0x14, 0x33, 0x39,
-	0x29, 0x5f, 0xd2, 0x36, 0x7e, 0x2d, 0x42, 0x45, 0x05, 0x8e, 0x7d, 0xf0, 0x26, 0x86, 0x46, 0x81,
-	0xee, 0x4e, 0xc8, 0x3c, 0xae, 0x8b, 0x1f, 0x82, 0xeb, 0xe5, 0x64, 0x96, 0xde, 0x33, 0x99, 0x04,
-	0x94, 0xc8, 0x89, 0xc4, 0x9e, 0x13, 0x9e, 0xc5, 0xbd, 0xb7, 0x60, 0xe0, 0x08, 0xd8, 0x9c, 0x05,
-	0xe2, 0x8d, 0xeb, 0xcf, 0x43, 0xf2, 0x9d, 0xb4, 0x2a, 0xa4, 0x75, 0x51, 0x20, 0xb5, 0x11, 0xe4,
-	0xa1, 0xf0, 0xc2, 0x79, 0xf8, 0x3c, 0xe9, 0xe7, 0xaa, 0xd2, 0xbe, 0x20, 0x60, 0x5f, 0x40, 0x1d,
-	0x31, 0x2e, 0x0d, 0xbb, 0x52, 0x6f, 0x8d, 0xc2, 0x4d, 0x3d, 0xb6, 0x16, 0x22, 0x9e, 0xd5, 0x33,
-	0xbe, 0x2f, 0x40, 0x93, 0xae, 0xa4, 0xfc, 0xf6, 0xbc, 0x13, 0x5f, 0x96, 0xf9, 0x4c, 0xb8, 0xa7,
-	0x67, 0x11, 0xe5, 0xb3, 0xcc, 0x63, 0x8a, 0x7d, 0x02, 0xda, 0x68, 0x1e, 0x04, 0x98, 0xfd, 0x85,
-	0xf3, 0x0a, 0x08, 0x17, 0xf8, 0xab, 0x23, 0x2d, 0xbd, 0x25, 0x52, 0xe3, 0xe7, 0x02, 0xd4, 0x33,
-	0x1e, 0xb2, 0xaf, 0x61, 0x1b, 0x65, 0xce, 0xa4, 0x2f, 0xc6, 0xa7, 0x02, 0x51, 0x34, 0x9d, 0xba,
-	0x51, 0x5a, 0x27, 0xf2, 0xea, 0xdd, 0x95, 0x7c, 0x87, 0x35, 0x7b, 0x0c, 0x1b, 0x79, 0x28, 0x85,
-	0x18, 0x44, 0xe9, 0x1d, 0xc8, 0x5b, 0x56, 0x37, 0x30, 0xd3, 0xfb, 0x42, 0x04, 0xed, 0xf1, 0x18,
-	0xf1, 0x4f, 0xf3, 0xe2, 0xcc, 0x0f, 0xa3, 0xa4, 0x53, 0xe4, 0xb7, 0xe4, 0xcd, 0xfc, 0x40, 0xf5,
-	0x49, 0x85, 0xd3, 0xb7, 0x71, 0x0b, 0xaa, 0xd2, 0x0c, 0x27, 0x37, 0x4a, 0x3d, 0x67, 0x2a, 0x12,
-	0x0b, 0xf9, 0x6d, 0xfc, 0x56, 0x80, 0x86, 0x14, 0x9b, 0xde, 0x78, 0xe6, 0xbb, 0x5e, 0xc4, 0x6e,
-	0x43, 0x11, 0xe7, 0xbe, 0x8a, 0xb5, 0x99, 0xb8, 0xa6, 0x0e, 0xe0, 0x45, 0x97, 0xc6, 0xbf, 0xa3,
-	0x3c, 0xa0, 0x5b, 0x6a, 0x3c, 0x21, 0xd9, 0x67, 0xf1, 0xa2, 0x29, 0xd1, 0x10, 0xbe, 0x91, 0xb5,
-	0x4d, 0x4e, 0xcf, 0x6e, 0x1a, 0xec, 0xcf, 0xd9, 0x6b, 0x17, 0xef, 0x52, 0x70, 0x55, 0x84, 0xf1,
-	0x60, 0xf5, 0x4c, 0x43, 0xf2, 0xb0, 0xdd, 0xef, 0x75, 0xdb, 0xc3, 0x01, 0xc7, 0x61, 0xb6, 0x09,
-	0xeb, 0xd6, 0xc0, 0x3a, 0x5a, 0xb0, 0x8a, 0xc6, 0x23, 0x15, 0x07, 0x02, 0x33, 0x0c, 0x9d, 0x53,
-	0x81, 0xa8, 0xa9, 0xcc, 0x24, 0x1d, 0x0f, 0xa4, 0xad, 0x55, 0xee, 0x70, 0xa5, 0x62, 0xb4, 0xa0,
-	0x49, 0xb6, 0x71, 0x6a, 0x05, 0xf5, 0x93, 0x93, 0x10, 0x74, 0x42, 0x8d, 0x2f, 0x18, 0xc6, 0x0f,
-	0x98, 0xb4, 0x3d, 0x31, 0x99, 0xf8, 0xc9, 0x65, 0x0f, 0xa1, 0x31, 0xcb, 0x9c, 0x1b, 0xa7, 0x6f,
-	0xf5, 0x9d, 0x39, 0x4d, 0x39, 0x8f, 0x8e, 0x73, 0x6d, 0x10, 0x0f, 0x8c, 0x14, 0x15, 0xf9, 0x26,
-	0xe1, 0x4b, 0xda, 0xc6, 0x5f, 0x25, 0x58, 0x4b, 0xbc, 0xb8, 0x9b, 0xdb, 0xf4, 0xe9, 0xed, 0xb1,
-	0x38, 0x9b, 0xfb, 0xff, 0x3e, 0xa1, 0xde, 0xbe, 0xfd, 0x73, 0xbb, 0xaa, 0xbc, 0xbc, 0xab, 0x7e,
-	0x2f, 0xae, 0x2e, 0x6c, 0x13, 0xa0, 0xdb, 0xb3, 0x3b, 0x47, 0x7b, 0x66, 0xbf, 0x3f, 0xc0, 0xca,
-	0xe2, 0x42, 0x22, 0x5a, 0xfe, 0x19, 0x58, 0x96, 0xd9, 0x19, 0xe2, 0x96, 0x62, 0xd0, 0x24, 0xe6,
-	0x53, 0x73, 0x78, 0xb4, 0x6f, 0x9a, 0xdc, 0xc6, 0x25, 0x95, 0x18, 0x2a, 0xba, 0xcc, 0x36, 0xa0,
-	0x4e, 0xb4, 0x65, 0xbe, 0x7c, 0x6e, 0x3f, 0xd5, 0x2a, 0xec, 0x2a, 0x6c, 0xd2, 0x16, 0x3b, 0x1a,
-	0xf2, 0xb6, 0x65, 0xb7, 0x3b, 0xc3, 0xde, 0xc0, 0xd2, 0xaa, 0xf2, 0x02, 0xfb, 0x95, 0xa5, 0xce,
-	0x7a, 0xd2, 0x1f, 0x74, 0x9e, 0xd9, 0x5a, 0x5d, 0x1a, 0x13, 0x33, 0x66, 0x34, 0xe4, 0xb6, 0x5c,
-	0x30, 0x8e, 0xda, 0xdd, 0x2e, 0x3a, 0xbb, 0xce, 0x6e, 0xc2, 0x75, 0xe2, 0xda, 0x43, 0xdc, 0x87,
-	0x74, 0x82, 0x6d, 0xb5, 0xf7, 0xed, 0xbd, 0xc1, 0x50, 0x6b, 0xca, 0xad, 0x99, 0x11, 0xa6, 0x82,
-	0x0d, 0x76, 0x03, 0xae, 0x2e, 0x59, 0x75, 0xcd, 0xfe, 0xb0, 0x6d, 0x6b, 0x9a, 0xf4, 0x31, 0x23,
-	0x8a, 0xd9, 0x9b, 0xac, 0x01, 0x97, 0xb9, 0x69, 0xef, 0x0f, 0x2c, 0xdb, 0xd4, 0xb6, 0x64, 0xc6,
-	0x3a, 0xf2, 0xd3, 0xb2, 0x0f, 0x6c, 0xed, 0xaa, 0xf1, 0x63, 0x01, 0xa5, 0x22, 0x9c, 0xc9, 0x49,
-	0xcc, 0xee, 0x43, 0x55, 0x8e, 0xf9, 0x79, 0x18, 0x17, 0xfd, 0x66, 0x52, 0xf4, 0x44, 0xa3, 0x65,
-	0x93, 0x58, 0x6e, 0x44, 0x1e, 0xab, 0x32, 0x0d, 0x4a, 0xd3, 0xf0, 0x34, 0x9e, 0xa1, 0xf2, 0x13,
-	0xbb, 0x0e, 0x16, 0x7a, 0xcb, 0x25, 0x6a, 0xc0, 0x9a, 0x7d, 0xd0, 0xe9, 0x98, 0xb6, 0xad, 0xfd,
-	0x51, 0x90, 0xd4, 0x6e, 0xbb, 0xd7, 0x3f, 0xe0, 0xa6, 0xf6, 0x77, 0xc9, 0x78, 0x01, 0x40, 0x00,
-	0x95, 0xd6, 0x82, 0xfd, 0x1f, 0x2a, 0x04, 0xcf, 0x18, 0xff, 0xeb, 0x39, 0x0c, 0x73, 0x25, 0xc3,
-	0x01, 0x03, 0xb4, 0x99, 0xba, 0x62, 0x82, 0xfb, 0x42, 0x39, 0x91, 0xe1, 0x18, 0xdf, 0x40, 0xd3,
-	0x3e, 0xf7, 0x46, 0xca, 0xc6, 0xf1, 0x10, 0xd7, 0x1f, 0xc1, 0xfa, 0xc8, 0xc7, 0x41, 0x3f, 0x71,
-	0xe4, 0xb2, 0xeb, 0x8d, 0xe3, 0xfd, 0x90, 0x67, 0xca, 0x79, 0x82, 0xa7, 0xc4, 0xc3, 0xaf, 0xcc,
-	0x15, 0x21, 0x63, 0x15, 0x9e, 0xc2, 0x6a, 0x99, 0xcb, 0x4f, 0xc3, 0xc1, 0x58, 0x93, 0xf3, 0x43,
-	0x5c, 0x18, 0x95, 0x40, 0x5e, 0x12, 0xbb, 0x9c, 0xb6, 0x5d, 0xde, 0x05, 0xae, 0x94, 0xd8, 0xc7,
-	0x50, 0xa5, 0x20, 0x92, 0xd9, 0xbd, 0x14, 0x61, 0x2c, 0x34, 0x1e, 0x83, 0x2e, 0xed, 0x29, 0x29,
-	0xb6, 0xe7, 0xcc, 0xc2, 0x33, 0x3f, 0xe2, 0xe2, 0xdb, 0x39, 0xf6, 0xd0, 0xfb, 0x05, 0x63, 0xfc,
-	0x84, 0x8f, 0xa3, 0x0b, 0x47, 0xc8, 0x10, 0xc7, 0x94, 0xb5, 0x82, 0x1a, 0x99, 0x44, 0xc8, 0x67,
-	0x77, 0x28, 0x0f, 0x97, 0xaf, 0x4e, 0x15, 0x7b, 0x4a, 0xcb, 0xe7, 0x3c, 0xf9, 0x64, 0xcd, 0xa7,
-	0xc7, 0x22, 0x88, 0xd3, 0x90, 0x65, 0xb1, 0x47, 0xb0, 0x16, 0x28, 0xd7, 0xa8, 0x69, 0xeb, 0x3b,
-	0x77, 0xb2, 0x29, 0x58, 0x15, 0x02, 0x4f, 0x0c, 0x8c, 0x5d, 0xb8, 0x96, 0x2a, 0x51, 0xf1, 0xc2,
-	0x24, 0xca, 0x0f, 0x4a, 0xab, 0xf1, 0x12, 0x7b, 0x32, 0x7f, 0xce, 0x07, 0xd6, 0x05, 0x9f, 0x0e,
-	0x94, 0x0b, 0x55, 0x17, 0x7c, 0x21, 0x2a, 0x6a, 0x67, 0x0e, 0x65, 0x39, 0x7b, 0x59, 0x0b, 0xca,
-	0xb8, 0x5d, 0x23, 0xb6, 0xb1, 0x34, 0x13, 0xb7, 0x97, 0x19, 0xc6, 0xa5, 0xbb, 0x85, 0xcf, 0x0b,
-	0xec, 0x4b, 0x60, 0xf8, 0xb0, 0x1f, 0x21, 0x2f, 0xfb, 0x4b, 0x6a, 0xd5, 0x3b, 0x6c, 0x5b, 0x5b,
-	0xee, 0x38, 0xe3, 0xd2, 0xb1, 0xfa, 0x49, 0x77, 0xff, 0x9f, 0x00, 0x00, 0x00, 0xff, 0xff, 0xc8,
-	0xc4, 0xd0, 0x63, 0xe9, 0x0d, 0x00, 0x00,
+	// 1662 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x58, 0xdb, 0x6e, 0xe3, 0xc8,
+	0x11, 0x1d, 0xea, 0x66, 0xab, 0x74, 0x31, 0xdd, 0xbe, 0x2c, 0xd7, 0xeb, 0x4c, 0x04, 0x26, 0x01,
+	0x8c, 0xc5, 0x44, 0x1b, 0x78, 0xb1, 0x98, 0xc5, 0x02, 0x09, 0x56, 0x2b, 0xd1, 0x63, 0x61, 0x64,
+	0x4a, 0xd3, 0x94, 0x3d, 0x98, 0x3c, 0xc4, 0xa0, 0xa9, 0xb6, 0x44, 0x8c, 0x44, 0x2a, 0xec, 0x96,
+	0x12, 0xbf, 0xe6, 0x29, 0xff, 0x91, 0xc7, 0xfc, 0x40, 0x7e, 0x20, 0x17, 0xe4, 0x0b, 0xf2, 0x17,
+	0x79, 0xc9, 0x07, 0x04, 0xdd, 0xcd, 0xbb, 0x65, 0x8f, 0xbd, 0x2f, 0x33, 0xac, 0x53, 0x55, 0xdd,
+	0x75, 0xaf, 0xb6, 0xa0, 0x7e, 0x6b, 0xdf, 0x04, 0xae, 0xd3, 0x5e, 0x06, 0x3e, 0xf3, 0x51, 0x45,
+	0xfc, 0x47, 0x8f, 0x76, 0x9c, 0x99, 0xed, 0x7a, 0x8e, 0x3f, 0x21, 0x92, 0x71, 0xb4, 0x1f, 0x03,
+	0x64, 0x4d, 0x3c, 0x16, 0xa2, 0x3f, 0x9d, 0xfa, 0xfe, 0x74, 0x4e, 0xbe, 0x12, 0xd4, 0xcd, 0xea,
+	0xf6, 0x2b, 0xe6, 0x2e, 0x08, 0x65, 0xf6, 0x62, 0x29, 0x05, 0xf4, 0xff, 0x94, 0xa0, 0x36, 0x0e,
+	0x6c, 0x8f, 0xda, 0x0e, 0x73, 0x7d, 0x0f, 0xbd, 0x82, 0x12, 0xbb, 0x5b, 0x12, 0x4d, 0x69, 0x29,
+	0x27, 0xcd, 0x53, 0x4d, 0x4a, 0xd1, 0x76, 0x4a, 0xa4, 0x3d, 0xbe, 0x5b, 0x12, 0x2c, 0xa4, 0x50,
+	0x0b, 0x6a, 0xf1, 0xb5, 0xfd, 0x9e, 0x56, 0x68, 0x29, 0x27, 0x75, 0x9c, 0x86, 0x90, 0x06, 0x5b,
+	0x4b, 0xfb, 0x6e, 0xee, 0xdb, 0x13, 0xad, 0x28, 0xb8, 0x11, 0x89, 0x8e, 0x60, 0x7b, 0x41, 0x98,
+	0x3d, 0xb1, 0x99, 0xad, 0x95, 0x04, 0x2b, 0xa6, 0x11, 0x82, 0x12, 0xfb, 0xa3, 0x3b, 0xd1, 0xca,
+	0x2d, 0xe5, 0xa4, 0x8a, 0xc5, 0x37, 0xfa, 0x16, 0xaa, 0xb1, 0xf1, 0x5a, 0xa5, 0xa5, 0x9c, 0xd4,
+	0x4e, 0x8f, 0xda, 0xd2, 0xbd, 0x76, 0xe4, 0x5e, 0x7b, 0x1c, 0x49, 0xe0, 0x44, 0x18, 0x8d, 0x60,
+	0xdf, 0xf1, 0xbd, 0x5b, 0x77, 0x42, 0x3c, 0xe6, 0xda, 0x73, 0x97, 0xdd, 0x0d, 0xc8, 0x9a, 0xcc,
+	0xb5, 0x2d, 0xe1, 0xe3, 0x71, 0xe4, 0x63, 0x77, 0x83, 0x0c, 0xde, 0xa8, 0x89, 0xce, 0xe0, 0x65,
+	0x0e, 0x1f, 0xf1, 0x33, 0x1c, 0x7f, 0x7e, 0x45, 0x02, 0xea, 0xfa, 0x9e, 0xb6, 0x2d, 0x2c, 0xff,
+	0x84, 0x14, 0xda, 0x87, 0xb2, 0xe7, 0x7b, 0x0e, 0xd1, 0xaa, 0x22, 0x00, 0x92, 0x40, 0x3a, 0xd4,
+	0x99, 0x7f, 0x65, 0xcf, 0xdd, 0x89, 0xcd, 0xfc, 0x80, 0x6a, 0x20, 0x98, 0x19, 0x8c, 0x47, 0xc8,
+	0x21, 0x01, 0xd3, 0x6a, 0x82, 0x27, 0xbe, 0xd1, 0x31, 0x54, 0xa9, 0x3b, 0xf5, 0x6c, 0xb6, 0x0a,
+	0x88, 0x56, 0x17, 0x8c, 0x04, 0xd0, 0x7d, 0x28, 0xf1, 0xcc, 0xa1, 0x06, 0x54, 0x2f, 0xcd, 0x9e,
+	0x71, 0xd6, 0x37, 0x8d, 0x9e, 0xfa, 0x02, 0xed, 0x83, 0xda, 0x3d, 0xef, 0xf4, 0xcd, 0xee, 0xb0,
+	0x67, 0x5c, 0xf7, 0x8c, 0xd1, 0x60, 0xf8, 0x41, 0x55, 0xb2, 0x68, 0xdf, 0xbc, 0x1a, 0xbe, 0x35,
+	0xd4, 0x02, 0xda, 0x83, 0x9d, 0x04, 0x7d, 0x77, 0x69, 0xe0, 0x0f, 0x6a, 0x11, 0x7d, 0x06, 0x7b,
+	0x09, 0x38, 0x36, 0xf0, 0x45, 0xdf, 0xec, 0x8c, 0x0d, 0xb5, 0xa4, 0xbf, 0x05, 0x35, 0x55, 0x36,
+	0x3f, 0xcc, 0x7d, 0xe7, 0x23, 0x7a, 0x0d, 0x75, 0x96, 0x60, 0x54, 0x53, 0x5a, 0xc5, 0x93, 0xda,
+	0xe9, 0xde, 0x86, 0x32, 0xc3, 0x19, 0x41, 0xfd, 0x6f, 0x05, 0xd8, 0x4d, 0x73, 0x09, 0x5d, 0xcd,
+	0x59, 0x5c, 0x27, 0x4a, 0xaa, 0x4e, 0x0e, 0xa1, 0x12, 0x08, 0x6e, 0x58, 0x8e, 0x21, 0xc5, 0xa3,
+	0x43, 0x82, 0xc0, 0x0f, 0xba, 0xfe, 0x84, 0x88, 0x5a, 0x6c, 0xe0, 0x04, 0xe0, 0x99, 0x10, 0x84,
+	0x28, 0xc5, 0x2a, 0x96, 0x04, 0xfa, 0x0d, 0x34, 0xe3, 0x62, 0x36, 0x78, 0x5b, 0x89, 0x8a, 0xac,
+	0x9d, 0x1e, 0xc6, 0x35, 0x93, 0xe1, 0xe2, 0x9c, 0x34, 0xea, 0x42, 0x23, 0x20, 0xf6, 0xe4, 0x7d,
+	0xe0, 0x32, 0x62, 0x11, 0x46, 0xb5, 0x8a, 0xf0, 0xf7, 0x27, 0xf7, 0xd4, 0x71, 0x4a, 0x0a, 0x67,
+	0x75, 0xb2, 0x46, 0x08, 0x1b, 0xb7, 0x1e, 0x32, 0x82, 0x73, 0x71, 0x4e, 0x5a, 0x9f, 0xc2, 0xc1,
+	0xc6, 0x7b, 0xf2, 0xdd, 0x2b, 0x83, 0x98, 0x86, 0x78, 0x54, 0xb8, 0x2d, 0x54, 0x2b, 0xb4, 0x8a,
+	0x3c, 0x2a, 0x82, 0xe0, 0x11, 0xfe, 0x03, 0x3f, 0x83, 0x6a, 0x45, 0x01, 0x87, 0x94, 0xfe, 0xf7,
+	0x02, 0x94, 0x65, 0x9a, 0x35, 0xd8, 0x5a, 0x87, 0x8d, 0xa0, 0x88, 0x48, 0x47, 0x64, 0xb6, 0x8b,
+	0x0b, 0xcf, 0xe9, 0xe2, 0x7c, 0xe9, 0x14, 0x9f, 0x58, 0x3a, 0xa2, 0x2d, 0x98, 0xcd, 0xc8, 0xb9,
+	0x4d, 0x67, 0xe1, 0xa4, 0x49, 0x00, 0xf4, 0x0a, 0x76, 0x97, 0x01, 0x59, 0xbb, 0xfe, 0x8a, 0x0a,
+	0xdb, 0x85, 0x54, 0x59, 0x48, 0xdd, 0x67, 0x70, 0x69, 0xc7, 0xf7, 0x28, 0xf1, 0xe8, 0x8a, 0x5e,
+	0x44, 0xd3, 0xab, 0x22, 0xa5, 0xef, 0x31, 0xd0, 0x37, 0x50, 0xf3, 0x7c, 0x8f, 0x2b, 0xf6, 0xb8,
+	0x9c, 0x4c, 0x5b, 0x6c, 0xb1, 0x99, 0xb0, 0x70, 0x5a, 0x4e, 0xff, 0x93, 0x02, 0x4d, 0x71, 0xa5,
+	0x48, 0x45, 0xdf, 0xbb, 0xf5, 0x79, 0xc8, 0x67, 0xc4, 0x9d, 0xce, 0x98, 0x88, 0x67, 0x09, 0x87,
+	0x14, 0xfa, 0x12, 0x54, 0x67, 0x15, 0x04, 0xc4, 0x63, 0x89, 0xf1, 0xb2, 0xec, 0xef, 0xe1, 0x9b,
+	0x3d, 0x2d, 0x3e, 0xe0, 0xa9, 0xfe, 0x57, 0x05, 0x6a, 0x29, 0x0b, 0xd1, 0x6f, 0xe1, 0x68, 0xee,
+	0x3b, 0xf6, 0x7c, 0x40, 0x26, 0x53, 0x12, 0x74, 0xfd, 0xc5, 0xc2, 0x65, 0x71, 0x9e, 0x34, 0xe5,
+	0x93, 0x99, 0x7c, 0x44, 0x1b, 0x7d, 0x0f, 0x3b, 0xd9, 0xc6, 0x91, 0x05, 0xf7, 0x70, 0x9f, 0xe5,
+	0xc5, 0xf5, 0x6f, 0xa0, 0x36, 0x22, 0x24, 0xe8, 0x4c, 0x26, 0x01, 0xa1, 0x62, 0x3a, 0xce, 0x7c,
+	0xca, 0xa2, 0xb9, 0xc0, 0xbf, 0x39, 0xb6, 0xf4, 0x03, 0x39, 0x15, 0xca, 0x58, 0x7c, 0xeb, 0xc7,
+	0x50, 0xe1, 0x6a, 0xfd, 0x1e, 0xe7, 0x7a, 0xf6, 0x82, 0x44, 0x1a, 0xfc, 0x5b, 0xff, 0x87, 0x02,
+	0x75, 0xce, 0x36, 0xbc, 0xc9, 0xd2, 0x77, 0x3d, 0x86, 0x5e, 0x42, 0x21, 0xec, 0x93, 0xda, 0x69,
+	0x33, 0x32, 0x4d, 0x1e, 0x80, 0x0b, 0xae, 0x58, 0x76, 0xb6, 0xb4, 0x40, 0xdc, 0x52, 0xc5, 0x11,
+	0x89, 0x7e, 0x19, 0xae, 0xd5, 0xa2, 0x58, 0x39, 0x9f, 0xa7, 0x75, 0xa3, 0xd3, 0xd3, 0x7b, 0x75,
+	0x1f, 0xca, 0xcb, 0x8f, 0x6e, 0xbf, 0x17, 0x96, 0xab, 0x24, 0xf4, 0xd7, 0x9b, 0x27, 0x78, 0x03,
+	0xaa, 0x57, 0x9d, 0x41, 0xbf, 0xd7, 0x19, 0x0f, 0xb1, 0xaa, 0xa0, 0x5d, 0x68, 0x98, 0x43, 0xf3,
+	0x3a, 0x81, 0x0a, 0xfa, 0x77, 0xd2, 0x0f, 0x7a, 0x41, 0x28, 0xb5, 0xa7, 0x04, 0x7d, 0x09, 0xe5,
+	0x25, 0xa7, 0xc3, 0xf1, 0xbb, 0xbf, 0xc9, 0x1c, 0x2c, 0x45, 0xf4, 0x36, 0x34, 0x85, 0x6e, 0x18,
+	0x5a, 0x22, 0xfa, 0xc9, 0x8e, 0x08, 0x71, 0x42, 0x15, 0x27, 0x80, 0xfe, 0x67, 0x05, 0xea, 0xe7,
+	0x64, 0x3e, 0xf7, 0xa3, 0xcb, 0xbe, 0x85, 0xfa, 0x32, 0x75, 0x6e, 0x18, 0xbe, 0xcd, 0x77, 0x66,
+	0x24, 0xf9, 0xe0, 0xbb, 0xc9, 0xb4, 0x41, 0x38, 0x30, 0xe2, 0xaa, 0xc8, 0x36, 0x09, 0xce, 0x49,
+	0xeb, 0xff, 0x2d, 0xc2, 0x56, 0x64, 0xc5, 0x49, 0xe6, 0x5d, 0x13, 0xdf, 0x1e, 0xb2, 0xd3, 0xb1,
+	0xff, 0xf1, 0x13, 0xea, 0xe1, 0xb7, 0x4e, 0x66, 0x33, 0x97, 0xf2, 0x9b, 0xf9, 0x9f, 0x85, 0xcd,
+	0x89, 0x6d, 0x02, 0xf4, 0xfa, 0x56, 0xf7, 0xfa, 0xdc, 0x18, 0x0c, 0x86, 0xaa, 0xc2, 0xd7, 0xaf,
+	0xa0, 0xf9, 0x3f, 0x43, 0xd3, 0x34, 0xba, 0x63, 0xb5, 0x80, 0x10, 0x34, 0x05, 0xf8, 0xc6, 0x18,
+	0x5f, 0x8f, 0x0c, 0x03, 0x5b, 0x6a, 0x31, 0x56, 0x94, 0x74, 0x09, 0xed, 0x40, 0x4d, 0xd0, 0xa6,
+	0xf1, 0xfe, 0xc2, 0x7a, 0xa3, 0x96, 0xd1, 0x01, 0xec, 0x8a, 0x9d, 0x7d, 0x3d, 0xc6, 0x1d, 0xd3,
+	0xea, 0x74, 0xc7, 0xfd, 0xa1, 0xa9, 0x56, 0xf8, 0x05, 0xd6, 0x07, 0x53, 0x9e, 0xf5, 0xc3, 0x60,
+	0xd8, 0x7d, 0x6b, 0xa9, 0x35, 0xae, 0x2c, 0xc0, 0x10, 0xa8, 0xf3, 0xb7, 0x41, 0x02, 0x5c, 0x77,
+	0x7a, 0x3d, 0xa3, 0xa7, 0x36, 0xd0, 0x17, 0xf0, 0x99, 0x40, 0xad, 0x71, 0x67, 0x6c, 0x88, 0x13,
+	0x2c, 0xb3, 0x33, 0xb2, 0xce, 0x87, 0x63, 0xb5, 0xc9, 0xdf, 0x08, 0x29, 0x66, 0xcc, 0xd8, 0x41,
+	0x9f, 0xc3, 0x41, 0x4e, 0xab, 0x67, 0x0c, 0xc6, 0x1d, 0x4b, 0x55, 0xb9, 0x8d, 0x29, 0x56, 0x08,
+	0xef, 0xa2, 0x3a, 0x6c, 0x63, 0xc3, 0x1a, 0x0d, 0x4d, 0xcb, 0x50, 0xf7, 0x79, 0xc4, 0xba, 0xfc,
+	0xd3, 0xb4, 0x2e, 0x2d, 0xf5, 0x40, 0xff, 0xb7, 0x02, 0xdb, 0x98, 0xd0, 0x25, 0x9f, 0xc4, 0xe8,
+	0x6b, 0xa8, 0xf0, 0x31, 0xbf, 0xa2, 0x61, 0xd2, 0xbf, 0x88, 0x92, 0x1e, 0x49, 0xb4, 0x2d, 0xc1,
+	0xe6, 0xfb, 0x1f, 0x87, 0xa2, 0x48, 0x85, 0xe2, 0x82, 0x4e, 0xc3, 0x19, 0xca, 0x3f, 0x37, 0xac,
+	0xdf, 0xe2, 0xb3, 0xd6, 0xef, 0x6b, 0x80, 0xe4, 0x9e, 0x7c, 0x8a, 0xeb, 0xb0, 0x65, 0x5d, 0x76,
+	0xbb, 0x86, 0x65, 0xa9, 0xff, 0x52, 0x38, 0x75, 0xd6, 0xe9, 0x0f, 0x2e, 0xb1, 0xa1, 0xfe, 0xaf,
+	0xa8, 0xbf, 0x03, 0x10, 0x05, 0xce, 0xb5, 0x09, 0xfa, 0x19, 0x94, 0x45, 0x79, 0x87, 0xfd, 0xd3,
+	0xc8, 0xf4, 0x00, 0x96, 0x3c, 0xf4, 0x12, 0x40, 0x6c, 0xb6, 0x1e, 0x99, 0x33, 0x3b, 0x74, 0x22,
+	0x85, 0xe8, 0xbf, 0x83, 0xa6, 0x75, 0xe7, 0x39, 0x52, 0xc7, 0xf6, 0xa6, 0x04, 0xfd, 0x1c, 0x1a,
+	0x8e, 0x1f, 0x04, 0x64, 0x6e, 0xf3, 0x65, 0xd9, 0x9f, 0x84, 0xfb, 0x25, 0x0b, 0xf2, 0x79, 0x44,
+	0x99, 0x1d, 0x0e, 0xcf, 0x12, 0x96, 0x04, 0x8f, 0x15, 0xf1, 0x64, 0xad, 0x97, 0x30, 0xff, 0xd4,
+	0xef, 0x00, 0xe2, 0xf3, 0x29, 0x7a, 0x05, 0xe5, 0x80, 0x5f, 0xa2, 0x29, 0xd9, 0x80, 0x65, 0x4d,
+	0xc0, 0x52, 0x08, 0xfd, 0x02, 0x2a, 0xc2, 0x89, 0x68, 0xf6, 0xe7, 0x3c, 0x0c, 0x99, 0x7c, 0x13,
+	0x2e, 0x83, 0x95, 0x47, 0xe4, 0xbd, 0xdb, 0x38, 0xa4, 0xf4, 0xef, 0x41, 0xe3, 0xe7, 0x8a, 0x60,
+	0x59, 0x9e, 0xbd, 0xa4, 0x33, 0x9f, 0x61, 0xf2, 0xfb, 0x15, 0xa1, 0xec, 0x69, 0x4e, 0xea, 0x7f,
+	0x51, 0x60, 0xf7, 0xde, 0x11, 0xdc, 0xf5, 0x89, 0x88, 0xa6, 0x22, 0x47, 0xb1, 0x20, 0xf8, 0x1f,
+	0x2f, 0x94, 0x1f, 0xce, 0xdf, 0xee, 0x32, 0x26, 0x31, 0xcd, 0x9f, 0x55, 0xc2, 0x56, 0x73, 0xb5,
+	0xb8, 0x21, 0x41, 0x18, 0x9e, 0x34, 0x84, 0xbe, 0x83, 0xad, 0x40, 0x9a, 0x26, 0x86, 0x41, 0xed,
+	0xb4, 0x95, 0x0e, 0xcd, 0x26, 0x17, 0x70, 0xa4, 0xa0, 0x9f, 0xc1, 0x61, 0x2c, 0x24, 0x92, 0x4a,
+	0x23, 0x2f, 0x9f, 0x15, 0x6e, 0xfd, 0x3d, 0xec, 0xe4, 0xce, 0x79, 0x66, 0xbe, 0x0e, 0xa1, 0x22,
+	0x62, 0x21, 0xf3, 0x55, 0xc7, 0x21, 0xa5, 0xaf, 0x61, 0x2f, 0xe3, 0xc1, 0x39, 0xb1, 0x27, 0x24,
+	0x78, 0xe4, 0x49, 0x98, 0x8b, 0x57, 0xe1, 0x7e, 0xbc, 0xe2, 0xda, 0x2f, 0x3e, 0x5c, 0xfb, 0xfa,
+	0x08, 0x6a, 0x23, 0x51, 0x0a, 0x02, 0x7d, 0x5a, 0xbf, 0x1c, 0x43, 0xf5, 0x26, 0xf7, 0x6e, 0x4a,
+	0x00, 0x7d, 0x0d, 0x8d, 0x4e, 0xe0, 0xcc, 0xdc, 0x75, 0x74, 0x66, 0xce, 0x52, 0xe5, 0x11, 0x4b,
+	0x0b, 0x4f, 0xee, 0xd2, 0xe2, 0xbd, 0x2e, 0x5d, 0x43, 0x53, 0x46, 0x90, 0x05, 0x2b, 0x87, 0x6f,
+	0x88, 0x47, 0x82, 0x17, 0xbd, 0x5b, 0x0a, 0xc9, 0xbb, 0x85, 0x4b, 0x8b, 0xbf, 0x3b, 0xa7, 0xd1,
+	0x03, 0x3d, 0x22, 0xb9, 0xbf, 0x0b, 0x77, 0x1a, 0xd8, 0xcc, 0xf5, 0xa6, 0xa2, 0xf4, 0xb6, 0x71,
+	0x02, 0x9c, 0xae, 0xa0, 0xc4, 0xb7, 0x31, 0x6a, 0x43, 0xa9, 0x3b, 0xb3, 0x19, 0xda, 0xc9, 0x6d,
+	0xc9, 0xa3, 0x3c, 0xa0, 0xbf, 0x38, 0x51, 0x7e, 0xa5, 0xa0, 0x5f, 0x03, 0x1a, 0x05, 0xbe, 0x43,
+	0x28, 0x4d, 0xff, 0x92, 0xb0, 0xe9, 0x65, 0x7e, 0xa4, 0xe6, 0x67, 0xb0, 0xfe, 0xe2, 0x46, 0xfe,
+	0xa4, 0xf1, 0xf5, 0xff, 0x07, 0x00, 0x60, 0x0f, 0x9e, 0x9f, 0xe9, 0x10, 0x00, 0x00,
 }
@@ -61,8 +61,26 @@ func (m *ServerStatus) String() string            { return proto.CompactTextStri
 func (*ServerStatus) ProtoMessage()               {}
 func (*ServerStatus) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{0} }
 
+// StateVerification is the result of recomputing the state hash from the
+// world state and comparing it with the state hash of the last block.
+type StateVerification struct {
+	BlockNumber       uint64 `protobuf:"varint,1,opt,name=blockNumber" json:"blockNumber,omitempty"`
+	BlockStateHash    []byte `protobuf:"bytes,2,opt,name=blockStateHash,proto3" json:"blockStateHash,omitempty"`
+	ComputedStateHash []byte `protobuf:"bytes,3,opt,name=computedStateHash,proto3" json:"computedStateHash,omitempty"`
+	// The first bucket or trie node whose crypto-hash in the DB differs from
+	// the recomputed one, empty if there is none
+	Divergence string `protobuf:"bytes,4,opt,name=divergence" json:"divergence,omitempty"`
+	Consistent bool   `protobuf:"varint,5,opt,name=consistent" json:"consistent,omitempty"`
+}
+
+func (m *StateVerification) Reset()                    { *m = StateVerification{} }
+func (m *StateVerification) String() string            { return proto.CompactTextString(m) }
+func (*StateVerification) ProtoMessage()               {}
+func (*StateVerification) Descriptor() ([]byte, []int) { return fileDescriptor6, []int{1} }
+
 func init() {
 	proto.RegisterType((*ServerStatus)(nil), "protos.ServerStatus")
+	proto.RegisterType((*StateVerification)(nil), "protos.StateVerification")
 	proto.RegisterEnum("protos.ServerStatus_StatusCode", ServerStatus_StatusCode_name, ServerStatus_StatusCode_value)
 }
 
@@ -81,6 +99,9 @@ type AdminClient interface {
 	GetStatus(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*ServerStatus, error)
 	StartServer(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*ServerStatus, error)
 	StopServer(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*ServerStatus, error)
+	// Recompute the state hash from the world state and compare it with the
+	// state hash of the last block.
+	VerifyState(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*StateVerification, error)
 }
 
 type adminClient struct {
@@ -118,6 +139,15 @@ func (c *adminClient) StopServer(ctx context.Context, in *google_protobuf1.Empty
 	return out, nil
 }
 
+func (c *adminClient) VerifyState(ctx context.Context, in *google_protobuf1.Empty, opts ...grpc.CallOption) (*StateVerification, error) {
+	out := new(StateVerification)
+	err := grpc.Invoke(ctx, "/protos.Admin/VerifyState", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Admin service
 
 type AdminServer interface {
@@ -125,6 +155,9 @@ type AdminServer interface {
 	GetStatus(context.Context, *google_protobuf1.Empty) (*ServerStatus, error)
 	StartServer(context.Context, *google_protobuf1.Empty) (*ServerStatus, error)
 	StopServer(context.Context, *google_protobuf1.Empty) (*ServerStatus, error)
+	// Recompute the state hash from the world state and compare it with the
+	// state hash of the last block.
+	VerifyState(context.Context, *google_protobuf1.Empty) (*StateVerification, error)
 }
 
 func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
@@ -185,6 +218,24 @@ func _Admin_StopServer_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Admin_VerifyState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(google_protobuf1.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).VerifyState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/VerifyState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).VerifyState(ctx, req.(*google_protobuf1.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Admin_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "protos.Admin",
 	HandlerType: (*AdminServer)(nil),
@@ -201,6 +252,10 @@ var _Admin_serviceDesc = grpc.ServiceDesc{
 			MethodName: "StopServer",
 			Handler:    _Admin_StopServer_Handler,
 		},
+		{
+			MethodName: "VerifyState",
+			Handler:    _Admin_VerifyState_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: fileDescriptor6,
@@ -209,21 +264,29 @@ var _Admin_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("server_admin.proto", fileDescriptor6) }
 
 var fileDescriptor6 = []byte{
-	// 248 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0x12, 0x2a, 0x4e, 0x2d, 0x2a,
-	0x4b, 0x2d, 0x8a, 0x4f, 0x4c, 0xc9, 0xcd, 0xcc, 0xd3, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62,
-	0x03, 0x53, 0xc5, 0x52, 0xd2, 0xe9, 0xf9, 0xf9, 0xe9, 0x39, 0xa9, 0xfa, 0x60, 0x6e, 0x52, 0x69,
-	0x9a, 0x7e, 0x6a, 0x6e, 0x41, 0x49, 0x25, 0x44, 0x91, 0xd2, 0x22, 0x46, 0x2e, 0x9e, 0x60, 0xb0,
-	0xde, 0xe0, 0x92, 0xc4, 0x92, 0xd2, 0x62, 0x21, 0x73, 0x2e, 0xb6, 0x62, 0x30, 0x4b, 0x82, 0x51,
-	0x81, 0x51, 0x83, 0xcf, 0x48, 0x1e, 0xa2, 0xb0, 0x58, 0x0f, 0x59, 0x95, 0x1e, 0x84, 0x72, 0xce,
-	0x4f, 0x49, 0x0d, 0x82, 0x2a, 0x57, 0x8a, 0xe4, 0xe2, 0x42, 0x88, 0x0a, 0xf1, 0x72, 0x71, 0x86,
-	0xfa, 0xb9, 0xb8, 0xba, 0x79, 0xfa, 0xb9, 0xba, 0x08, 0x30, 0x08, 0x71, 0x73, 0xb1, 0x07, 0x87,
-	0x38, 0x06, 0x85, 0x00, 0x39, 0x8c, 0x10, 0x8e, 0x7f, 0x40, 0x00, 0x90, 0xc3, 0x24, 0xc4, 0xc5,
-	0xc5, 0x16, 0xe0, 0x18, 0x1a, 0x0c, 0x64, 0x33, 0x0b, 0x71, 0x72, 0xb1, 0xba, 0x06, 0x05, 0xf9,
-	0x07, 0x09, 0xb0, 0x80, 0xd4, 0x84, 0xfa, 0x79, 0xfb, 0xf9, 0x87, 0xfb, 0x09, 0xb0, 0x1a, 0x1d,
-	0x64, 0xe4, 0x62, 0x75, 0x04, 0xf9, 0x4c, 0xc8, 0x9a, 0x8b, 0xd3, 0x3d, 0xb5, 0x04, 0xea, 0x54,
-	0x31, 0x3d, 0x88, 0xcf, 0xf4, 0x60, 0x3e, 0xd3, 0x73, 0x05, 0xf9, 0x4c, 0x4a, 0x04, 0x9b, 0x93,
-	0x95, 0x18, 0x84, 0x6c, 0xb9, 0xb8, 0x81, 0xec, 0xa2, 0x12, 0x88, 0x30, 0xc9, 0xda, 0x6d, 0x40,
-	0x1e, 0xcc, 0x2f, 0x20, 0x4f, 0x77, 0x12, 0x24, 0x36, 0x8c, 0x01, 0x01, 0x00, 0x00, 0xff, 0xff,
-	0x5b, 0x2d, 0xff, 0xc2, 0xaa, 0x01, 0x00, 0x00,
+	// 384 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x92, 0x4d, 0x8f, 0x93, 0x40,
+	0x18, 0xc7, 0x3b, 0x6d, 0xa9, 0xf2, 0x50, 0x1b, 0x3a, 0x31, 0x06, 0x6b, 0xa2, 0x84, 0x83, 0xe1,
+	0x60, 0x68, 0x52, 0x0f, 0x1e, 0xd4, 0x43, 0x15, 0x7c, 0x89, 0x09, 0x6d, 0x86, 0x56, 0xe3, 0xc9,
+	0xf0, 0x32, 0xad, 0xc4, 0xc2, 0x10, 0x66, 0x68, 0xd2, 0x0f, 0xe0, 0x17, 0xf1, 0xeb, 0xf8, 0xa5,
+	0x36, 0x0c, 0xdd, 0x2d, 0xe9, 0xee, 0x1e, 0x76, 0x4f, 0xc3, 0xf3, 0x7f, 0x7e, 0x7f, 0x42, 0xf8,
+	0x0d, 0x60, 0x4e, 0xcb, 0x3d, 0x2d, 0x7f, 0x85, 0x49, 0x96, 0xe6, 0x4e, 0x51, 0x32, 0xc1, 0xf0,
+	0x40, 0x1e, 0x7c, 0xf2, 0x6c, 0xcb, 0xd8, 0x76, 0x47, 0xa7, 0x72, 0x8c, 0xaa, 0xcd, 0x94, 0x66,
+	0x85, 0x38, 0x34, 0x90, 0xf5, 0x0f, 0xc1, 0x30, 0x90, 0xdd, 0x40, 0x84, 0xa2, 0xe2, 0xf8, 0x0d,
+	0x0c, 0xb8, 0x7c, 0x32, 0x90, 0x89, 0xec, 0xd1, 0xec, 0x45, 0x03, 0x72, 0xa7, 0x4d, 0x39, 0xcd,
+	0xf1, 0x91, 0x25, 0x94, 0x1c, 0x71, 0xeb, 0x27, 0xc0, 0x29, 0xc5, 0x8f, 0x40, 0x5d, 0xfb, 0xae,
+	0xf7, 0xe9, 0xab, 0xef, 0xb9, 0x7a, 0x07, 0x6b, 0xf0, 0x20, 0x58, 0xcd, 0xc9, 0xca, 0x73, 0x75,
+	0xd4, 0x0c, 0x8b, 0xe5, 0xd2, 0x73, 0xf5, 0x2e, 0x06, 0x18, 0x2c, 0xe7, 0xeb, 0xc0, 0x73, 0xf5,
+	0x1e, 0x56, 0x41, 0xf1, 0x08, 0x59, 0x10, 0xbd, 0x5f, 0x33, 0x6b, 0xff, 0x9b, 0xbf, 0xf8, 0xe1,
+	0xeb, 0x8a, 0xf5, 0x1f, 0xc1, 0xb8, 0x7e, 0x37, 0xfd, 0x4e, 0xcb, 0x74, 0x93, 0xc6, 0xa1, 0x48,
+	0x59, 0x8e, 0x4d, 0xd0, 0xa2, 0x1d, 0x8b, 0xff, 0xf8, 0x55, 0x16, 0xd1, 0x52, 0x7e, 0x6e, 0x9f,
+	0xb4, 0x23, 0xfc, 0x12, 0x46, 0x72, 0x94, 0xdd, 0x2f, 0x21, 0xff, 0x6d, 0x74, 0x4d, 0x64, 0x0f,
+	0xc9, 0x59, 0x8a, 0x5f, 0xc1, 0x38, 0x66, 0x59, 0x51, 0x09, 0x9a, 0x9c, 0xd0, 0x9e, 0x44, 0xaf,
+	0x2f, 0xf0, 0x73, 0x80, 0x24, 0xdd, 0xd3, 0x72, 0x4b, 0xf3, 0x98, 0x1a, 0x7d, 0x13, 0xd9, 0x2a,
+	0x69, 0x25, 0xf5, 0x3e, 0x66, 0x39, 0x4f, 0xb9, 0xa0, 0xb9, 0x30, 0x14, 0x13, 0xd9, 0x0f, 0x49,
+	0x2b, 0x99, 0xfd, 0xed, 0x82, 0x32, 0xaf, 0x3d, 0xe1, 0xb7, 0xa0, 0x7e, 0xa6, 0xe2, 0xf8, 0xe3,
+	0x9f, 0x38, 0x8d, 0x27, 0xe7, 0xd2, 0x93, 0xe3, 0xd5, 0x9e, 0x26, 0x8f, 0x6f, 0x12, 0x60, 0x75,
+	0xf0, 0x7b, 0xd0, 0x02, 0x11, 0x96, 0xa2, 0x89, 0xef, 0x5c, 0x7f, 0x57, 0xeb, 0x62, 0xc5, 0x3d,
+	0xdb, 0x1f, 0x40, 0x93, 0x2e, 0x0e, 0x75, 0x42, 0x6f, 0xad, 0x3f, 0xbd, 0xaa, 0x9f, 0xdb, 0xb3,
+	0x3a, 0x51, 0x73, 0x3f, 0x5f, 0x5f, 0x0c, 0x00, 0xde, 0xff, 0x17, 0x55, 0xbc, 0x02, 0x00, 0x00,
 }
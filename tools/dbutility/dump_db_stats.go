@@ -25,7 +25,6 @@ import (
 	"github.com/hyperledger/fabric/core/db"
 	"github.com/hyperledger/fabric/protos"
 	"github.com/spf13/viper"
-	"github.com/tecbot/gorocksdb"
 )
 
 const (
@@ -79,63 +78,55 @@ func main() {
 
 func printLiveFilesMetaData(openchainDB *db.OpenchainDB) {
 	fmt.Println("------ Details of LiveFilesMetaData ---")
-	db := openchainDB.DB
-	liveFileMetadata := db.GetLiveFilesMetaData()
-	for _, file := range liveFileMetadata {
-		fmt.Printf("file.Name=[%s], file.Level=[%d], file.Size=[%d]\n",
-			file.Name, file.Level, file.Size)
-	}
+	fmt.Println(openchainDB.GetProperty("", "rocksdb.sstables"))
 }
 
 func printProperties(openchainDB *db.OpenchainDB) {
 	fmt.Println("------ Details of Properties ---")
-	db := openchainDB.DB
+	property := openchainDB.GetProperty
 	fmt.Printf("rocksdb.estimate-live-data-size:- BlockchainCF:%s, StateCF:%s, StateDeltaCF:%s, IndexesCF:%s, PersistCF:%s\n\n",
-		db.GetPropertyCF("rocksdb.estimate-live-data-size", openchainDB.BlockchainCF),
-		db.GetPropertyCF("rocksdb.estimate-live-data-size", openchainDB.StateCF),
-		db.GetPropertyCF("rocksdb.estimate-live-data-size", openchainDB.StateDeltaCF),
-		db.GetPropertyCF("rocksdb.estimate-live-data-size", openchainDB.IndexesCF),
-		db.GetPropertyCF("rocksdb.estimate-live-data-size", openchainDB.PersistCF))
-	fmt.Printf("Default:%s\n", db.GetProperty("rocksdb.estimate-live-data-size"))
+		property(openchainDB.BlockchainCF, "rocksdb.estimate-live-data-size"),
+		property(openchainDB.StateCF, "rocksdb.estimate-live-data-size"),
+		property(openchainDB.StateDeltaCF, "rocksdb.estimate-live-data-size"),
+		property(openchainDB.IndexesCF, "rocksdb.estimate-live-data-size"),
+		property(openchainDB.PersistCF, "rocksdb.estimate-live-data-size"))
+	fmt.Printf("Default:%s\n", property("", "rocksdb.estimate-live-data-size"))
 
 	fmt.Printf("rocksdb.num-live-versions:- BlockchainCF:%s, StateCF:%s, StateDeltaCF:%s, IndexesCF:%s, PersistCF:%s\n\n",
-		db.GetPropertyCF("rocksdb.num-live-versions", openchainDB.BlockchainCF),
-		db.GetPropertyCF("rocksdb.num-live-versions", openchainDB.StateCF),
-		db.GetPropertyCF("rocksdb.num-live-versions", openchainDB.StateDeltaCF),
-		db.GetPropertyCF("rocksdb.num-live-versions", openchainDB.IndexesCF),
-		db.GetPropertyCF("rocksdb.num-live-versions", openchainDB.PersistCF))
+		property(openchainDB.BlockchainCF, "rocksdb.num-live-versions"),
+		property(openchainDB.StateCF, "rocksdb.num-live-versions"),
+		property(openchainDB.StateDeltaCF, "rocksdb.num-live-versions"),
+		property(openchainDB.IndexesCF, "rocksdb.num-live-versions"),
+		property(openchainDB.PersistCF, "rocksdb.num-live-versions"))
 
 	fmt.Printf("rocksdb.cfstats:\n %s %s %s %s %s\n\n",
-		db.GetPropertyCF("rocksdb.cfstats", openchainDB.BlockchainCF),
-		db.GetPropertyCF("rocksdb.cfstats", openchainDB.StateCF),
-		db.GetPropertyCF("rocksdb.cfstats", openchainDB.StateDeltaCF),
-		db.GetPropertyCF("rocksdb.cfstats", openchainDB.IndexesCF),
-		db.GetPropertyCF("rocksdb.cfstats", openchainDB.PersistCF))
+		property(openchainDB.BlockchainCF, "rocksdb.cfstats"),
+		property(openchainDB.StateCF, "rocksdb.cfstats"),
+		property(openchainDB.StateDeltaCF, "rocksdb.cfstats"),
+		property(openchainDB.IndexesCF, "rocksdb.cfstats"),
+		property(openchainDB.PersistCF, "rocksdb.cfstats"))
 }
 
-func scan(openchainDB *db.OpenchainDB, cfName string, cf *gorocksdb.ColumnFamilyHandle, printer detailPrinter) (int, int) {
+func scan(openchainDB *db.OpenchainDB, cfName string, cf string, printer detailPrinter) (int, int) {
 	fmt.Printf("------- Printing Key-values larger than [%d] bytes in Column family [%s]--------\n", MaxValueSize, cfName)
 	itr := openchainDB.GetIterator(cf)
 	totalKVs := 0
 	overSizeKVs := 0
 	itr.SeekToFirst()
 	for ; itr.Valid(); itr.Next() {
-		k := itr.Key()
-		v := itr.Value()
-		keyBytes := k.Data()
-		valueSize := v.Size()
+		keyBytes := itr.Key()
+		value := itr.Value()
+		valueSize := len(value)
 		totalKVs++
 		if valueSize >= MaxValueSize {
 			overSizeKVs++
 			fmt.Printf("key=[%x], valueSize=[%d]\n", keyBytes, valueSize)
 			if printer != nil {
 				fmt.Println("=== KV Details === ")
-				printer(v.Data())
+				printer(value)
 				fmt.Println("")
 			}
 		}
-		k.Free()
-		v.Free()
 	}
 	itr.Close()
 	fmt.Printf("totalKVs=[%d], overSizeKVs=[%d]\n", totalKVs, overSizeKVs)